@@ -2,19 +2,21 @@ package auth
 
 import (
 	"bufio"
-	"crypto/sha256"
+	"crypto/subtle"
 	"fmt"
 	"io"
-	"os"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	cmdutil "github.com/GGP1/kure/commands"
 	"github.com/GGP1/kure/config"
 	"github.com/GGP1/kure/crypt"
+	dbutil "github.com/GGP1/kure/db"
 	"github.com/GGP1/kure/db/auth"
 	authDB "github.com/GGP1/kure/db/auth"
+	"github.com/GGP1/kure/db/entry"
 
 	"github.com/awnumar/memguard"
 	"github.com/pkg/errors"
@@ -25,24 +27,31 @@ import (
 // Key file path configuration key
 const keyfilePath string = "keyfile.path"
 
+var errWrongMasterPassword = errors.New("invalid master password")
+
 // Login verifies that the human/machine that is trying to execute
 // a command is effectively the owner of the information.
 //
 // If it's the first record the user is registered.
 func Login(db *bolt.DB) cmdutil.RunEFunc {
 	return func(cmd *cobra.Command, args []string) error {
-		// If auth is not nil it means the user is already logged in (session)
-		if auth := config.Get("auth"); auth != nil {
+		// If the password is set it means the user is already logged in (session)
+		if config.IsSet("auth.password") {
 			return nil
 		}
 
-		params, err := authDB.GetParameters(db)
+		// Databases created before the vaults existed are upgraded to the current layout
+		if err := authDB.Upgrade(db); err != nil {
+			return err
+		}
+
+		vaults, err := getVaults(db)
 		if err != nil {
 			return err
 		}
 		// The auth key will be nil only on the user's first (successful) command
-		if params.AuthKey == nil {
-			return Register(db, os.Stdin)
+		if vaults[0].AuthKey == nil {
+			return Register(db, cmd.InOrStdin(), cmd.OutOrStdout(), allowWeak(cmd))
 		}
 
 		password, err := AskPassword("Enter master password", false)
@@ -50,32 +59,137 @@ func Login(db *bolt.DB) cmdutil.RunEFunc {
 			return err
 		}
 
-		if params.UseKeyfile {
-			password, err = combineKeys(os.Stdin, password)
+		// Every vault may use a different key file, or none
+		var withKeyfile *memguard.Enclave
+		var fingerprint []byte
+		if usesKeyfile(vaults) {
+			salt, err := authDB.KeyfileSalt(db)
 			if err != nil {
 				return err
 			}
+			withKeyfile, fingerprint, err = combineKeys(cmd.InOrStdin(), password, salt)
+			if err != nil {
+				return err
+			}
+
+			if !matchesKeyfile(vaults, fingerprint) {
+				return ErrKeyfileMismatch
+			}
 		}
 
-		setAuthToConfig(password, params)
+		if err := unlock(db, password, withKeyfile, fingerprint, vaults); err != nil {
+			return err
+		}
 
-		// Try to decrypt the authentication key
-		if _, err := crypt.Decrypt(params.AuthKey); err != nil {
-			return errors.New("invalid master password")
+		if err := authDB.MigrateRecords(db, entry.MigrateExpiration); err != nil {
+			return err
 		}
 
+		warnExpiring(db, cmd.ErrOrStderr(), time.Now())
 		return nil
 	}
 }
 
-// Register registers the user when there aren't any records yet.
-func Register(db *bolt.DB, r io.Reader) error {
+// getVaults returns the parameters of all the vaults.
+func getVaults(db *bolt.DB) ([]authDB.Parameters, error) {
+	vaults := make([]authDB.Parameters, len(dbutil.Vaults))
+	for i, v := range dbutil.Vaults {
+		params, err := authDB.GetParameters(db, v)
+		if err != nil {
+			return nil, err
+		}
+		vaults[i] = params
+	}
+	return vaults, nil
+}
+
+// usesKeyfile returns whether any of the vaults uses a key file.
+func usesKeyfile(vaults []authDB.Parameters) bool {
+	for _, v := range vaults {
+		if v.UseKeyfile {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesKeyfile returns whether the fingerprint matches the key file of any of the vaults. Vaults
+// registered before fingerprints existed match any key file.
+func matchesKeyfile(vaults []authDB.Parameters, fingerprint []byte) bool {
+	match := false
+	for _, v := range vaults {
+		if !v.UseKeyfile {
+			continue
+		}
+		if v.KeyfileFingerprint == nil || subtle.ConstantTimeCompare(fingerprint, v.KeyfileFingerprint) == 1 {
+			match = true
+		}
+	}
+	return match
+}
+
+// unlock opens the vault whose key is decrypted by the password, combined with the key file for the
+// vaults that use one, and, if it's a decoy one registered to do so, destroys the other vault in the
+// background.
+//
+// Both paths do the same work and return the same errors so they can't be told apart.
+func unlock(db *bolt.DB, password, withKeyfile *memguard.Enclave, fingerprint []byte, vaults []authDB.Parameters) error {
+	// Restored after a failed attempt, a locked session keeps the vault in use
+	previous := config.Get("auth")
+
+	// Every vault key is tried so opening any of them takes the same time
+	var opened *authDB.Parameters
+	var openedPassword *memguard.Enclave
+	for i := range vaults {
+		pwd := password
+		if vaults[i].UseKeyfile {
+			pwd = withKeyfile
+		}
+
+		key, err := tryDecrypt(pwd, vaults[i])
+		if err == nil && opened == nil {
+			opened = &vaults[i]
+			opened.Decoy = authDB.IsDecoyKey(key)
+			opened.Destroy = authDB.IsDestroyKey(key)
+			openedPassword = pwd
+		}
+	}
+	if opened == nil {
+		config.Set("auth", previous)
+		return errWrongMasterPassword
+	}
+	setAuthToConfig(openedPassword, *opened)
+
+	// Databases registered before fingerprints existed get it stored on their first successful login
+	if !opened.UseKeyfile || opened.KeyfileFingerprint != nil {
+		fingerprint = nil
+	}
+	if err := authDB.OpenVault(db, opened.Vault, fingerprint); err != nil {
+		return err
+	}
+
+	// Destroying the other vault after logging in keeps it from changing the time it takes
+	if opened.Destroy {
+		authDB.DestroyVaultInBackground(db, opened.Vault.Other())
+	}
+	return nil
+}
+
+// Register registers the user when there aren't any records yet, or the new credentials of the vault
+// in use when restoring it.
+//
+// Passwords weaker than the minimum score configured are rejected unless force is true.
+func Register(db *bolt.DB, r io.Reader, w io.Writer, force bool) error {
 	password, err := AskPassword("New master password", true)
 	if err != nil {
 		return err
 	}
 
-	iterations, memory, threads, err := askArgon2Params(r)
+	if err := checkStrength(w, password, force); err != nil {
+		return err
+	}
+
+	iterations, memory, threads, err := askArgon2Params(r, w)
 	if err != nil {
 		return err
 	}
@@ -85,27 +199,42 @@ func Register(db *bolt.DB, r io.Reader) error {
 		return err
 	}
 
+	var fingerprint []byte
 	if useKeyfile {
-		password, err = combineKeys(r, password)
+		salt, err := authDB.KeyfileSalt(db)
+		if err != nil {
+			return err
+		}
+		password, fingerprint, err = combineKeys(r, password, salt)
 		if err != nil {
 			return err
 		}
 	}
 
 	params := authDB.Parameters{
-		Iterations: iterations,
-		Memory:     memory,
-		Threads:    threads,
-		UseKeyfile: useKeyfile,
+		Iterations:         iterations,
+		Memory:             memory,
+		Threads:            threads,
+		UseKeyfile:         useKeyfile,
+		KeyfileFingerprint: fingerprint,
+		Vault:              dbutil.VaultInUse(),
+		Decoy:              UsingDecoyVault(),
+	}
+	// The real vault is placed at random on the first registration
+	if config.Get("auth") == nil {
+		params.Vault, err = authDB.RandomVault()
+		if err != nil {
+			return err
+		}
 	}
 
 	setAuthToConfig(password, params)
 	return authDB.Register(db, params)
 }
 
-func askArgon2Params(r io.Reader) (iterations, memory, threads uint32, err error) {
-	fmt.Println("Set argon2 parameters, leave blank to use the default value")
-	fmt.Println("For more information visit https://github.com/GGP1/kure/wiki/Authentication")
+func askArgon2Params(r io.Reader, w io.Writer) (iterations, memory, threads uint32, err error) {
+	fmt.Fprintln(w, "Set argon2 parameters, leave blank to use the default value")
+	fmt.Fprintln(w, "For more information visit https://github.com/GGP1/kure/wiki/Authentication")
 
 	reader := bufio.NewReader(r)
 
@@ -143,37 +272,44 @@ func askKeyfile(r io.Reader) (bool, error) {
 	return true, nil
 }
 
-func combineKeys(r io.Reader, password *memguard.Enclave) (*memguard.Enclave, error) {
+// combineKeys appends the password to the key file content and returns them inside
+// an enclave, along with the key file fingerprint derived using the salt passed.
+func combineKeys(r io.Reader, password *memguard.Enclave, salt []byte) (*memguard.Enclave, []byte, error) {
+	if salt == nil {
+		return nil, nil, errors.New("the key file salt does not exist")
+	}
+
 	path := config.GetString(keyfilePath)
 	if path == "" {
 		path = cmdutil.Scanln(bufio.NewReader(r), "Enter key file path")
 		path = strings.Trim(path, "\"")
 		if path == "" || path == "." {
-			return nil, errors.New("invalid key file path")
+			return nil, nil, errors.New("invalid key file path")
 		}
 	}
 
-	key, err := os.ReadFile(path)
+	key, err := ReadKeyfile(path)
 	if err != nil {
-		return nil, errors.Wrap(err, "reading key file")
+		return nil, nil, err
 	}
 	defer memguard.WipeBytes(key)
+	fingerprint := keyfileFingerprint(salt, key)
 
 	pwdBuf, err := password.Open()
 	if err != nil {
-		return nil, errors.Wrap(err, "decrypting password")
-	}
-
-	// If the content is not 32 bytes, hash it and use the hash as the key
-	if len(key) != 32 {
-		keyHash := sha256.Sum256(key)
-		key = keyHash[:]
+		return nil, nil, errors.Wrap(err, "decrypting password")
 	}
 
 	key = append(key, pwdBuf.Bytes()...)
 	pwdBuf.Destroy()
 
-	return memguard.NewEnclave(key), nil
+	return memguard.NewEnclave(key), fingerprint, nil
+}
+
+// tryDecrypt attempts to decrypt the authentication key with the password and parameters passed.
+func tryDecrypt(password *memguard.Enclave, params authDB.Parameters) ([]byte, error) {
+	setAuthToConfig(password, params)
+	return crypt.Decrypt(params.AuthKey)
 }
 
 func scanParameter(r *bufio.Reader, field string, defaultValue uint32) (uint32, error) {
@@ -198,6 +334,8 @@ func setAuthToConfig(password *memguard.Enclave, params auth.Parameters) {
 		"iterations": params.Iterations,
 		"memory":     params.Memory,
 		"threads":    params.Threads,
+		"vault":      uint32(params.Vault),
+		"decoy":      params.Decoy,
 	}
 	config.Set("auth", auth)
 }
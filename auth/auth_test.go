@@ -3,6 +3,7 @@ package auth
 import (
 	"bytes"
 	"crypto/sha256"
+	"io"
 	"os"
 	"runtime"
 	"testing"
@@ -61,7 +62,7 @@ func TestAskArgon2Params(t *testing.T) {
 		t.Run(tc.desc, func(t *testing.T) {
 			buf := bytes.NewBufferString(tc.input)
 
-			iterations, memory, threads, err := askArgon2Params(buf)
+			iterations, memory, threads, err := askArgon2Params(buf, io.Discard)
 			if err != nil {
 				t.Fatalf("Failed taking argon2 parameters: %v", err)
 			}
@@ -104,7 +105,7 @@ func TestArgon2ParamsErrors(t *testing.T) {
 		t.Run("Invalid"+tc.desc, func(t *testing.T) {
 			buf := bytes.NewBufferString(tc.input)
 
-			if _, _, _, err := askArgon2Params(buf); err == nil {
+			if _, _, _, err := askArgon2Params(buf, io.Discard); err == nil {
 				t.Error("Expected an error and got nil")
 			}
 		})
@@ -185,7 +186,7 @@ func TestCombineKeys(t *testing.T) {
 		t.Run(tc.desc, func(t *testing.T) {
 			config.Set(keyfilePath, tc.path)
 
-			enclave, err := combineKeys(nil, memguard.NewEnclave([]byte("test")))
+			enclave, _, err := combineKeys(nil, memguard.NewEnclave([]byte("test")), []byte("salt"))
 			if err != nil {
 				t.Fatalf("Failed combining keys: %v", err)
 			}
@@ -221,7 +222,7 @@ func TestCombineKeysRequestPath(t *testing.T) {
 	path := "./testdata/test-32.key"
 	buf := bytes.NewBufferString(path)
 
-	enclave, err := combineKeys(buf, memguard.NewEnclave([]byte("test")))
+	enclave, _, err := combineKeys(buf, memguard.NewEnclave([]byte("test")), []byte("salt"))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -247,13 +248,20 @@ func TestCombineKeysRequestPath(t *testing.T) {
 func TestCombineKeysErrors(t *testing.T) {
 	config.Set("keyfile.path", "non-existent")
 
-	if _, err := combineKeys(nil, memguard.NewEnclave([]byte("test"))); err == nil {
+	if _, _, err := combineKeys(nil, memguard.NewEnclave([]byte("test")), []byte("salt")); err == nil {
 		t.Error("Expected an error and got nil")
 	}
 
+	t.Run("Missing salt", func(t *testing.T) {
+		config.Set("keyfile.path", "./testdata/test-32.key")
+		if _, _, err := combineKeys(nil, memguard.NewEnclave([]byte("test")), nil); err == nil {
+			t.Error("Expected an error and got nil")
+		}
+	})
+
 	t.Run("Invalid path", func(t *testing.T) {
 		config.Reset()
-		if _, err := combineKeys(bytes.NewBufferString("\n"), nil); err == nil {
+		if _, _, err := combineKeys(bytes.NewBufferString("\n"), nil, []byte("salt")); err == nil {
 			t.Errorf("Expected an error and got nil")
 		}
 	})
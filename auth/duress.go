@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"io"
+
+	"github.com/GGP1/kure/config"
+	"github.com/GGP1/kure/crypt"
+	dbutil "github.com/GGP1/kure/db"
+	authDB "github.com/GGP1/kure/db/auth"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// decoyInUse is set in the authentication parameters when the decoy vault is opened
+const decoyInUse = "auth.decoy"
+
+// RegisterDuress asks for a duress password and creates an empty decoy vault, in place of the vault
+// that isn't in use, that is opened when the duress password is used to log in.
+//
+// The user must be logged in already. Any existing decoy vault is replaced. Passwords weaker than the
+// minimum score configured are rejected unless force is true. If destroy is true, logging in with the
+// duress password destroys the vault in use.
+//
+// From the decoy vault the password goes through the same steps but nothing is stored, the real vault
+// is left untouched and the output doesn't reveal which vault is in use.
+func RegisterDuress(db *bolt.DB, r io.Reader, w io.Writer, force, destroy bool) error {
+	params, err := authDB.GetParameters(db, dbutil.VaultInUse())
+	if err != nil {
+		return err
+	}
+
+	password, err := AskPassword("New duress password", true)
+	if err != nil {
+		return err
+	}
+
+	if err := checkStrength(w, password, force); err != nil {
+		return err
+	}
+
+	// The decoy vault may use a different key file than the one in use
+	var fingerprint []byte
+	if params.UseKeyfile {
+		salt, err := authDB.KeyfileSalt(db)
+		if err != nil {
+			return err
+		}
+		password, fingerprint, err = combineKeys(r, password, salt)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The decoy vault uses the same argon2 parameters so it takes the same time to open
+	decoy := authDB.Parameters{
+		Iterations:         params.Iterations,
+		Memory:             params.Memory,
+		Threads:            params.Threads,
+		UseKeyfile:         params.UseKeyfile,
+		KeyfileFingerprint: fingerprint,
+		Vault:              params.Vault.Other(),
+		Destroy:            destroy,
+	}
+
+	current := config.Get("auth")
+	defer config.Set("auth", current)
+
+	if _, err := tryDecrypt(password, params); err == nil {
+		return errors.New("the duress password must be different from the master password")
+	}
+
+	setAuthToConfig(password, decoy)
+	if UsingDecoyVault() {
+		// Derive the key anyway so registering takes the same time
+		_, err := crypt.Encrypt(make([]byte, authDB.KeySize))
+		return err
+	}
+	return authDB.RegisterDecoy(db, decoy)
+}
+
+// RemoveDuress deletes the decoy vault, its key is overwritten with random bytes.
+//
+// The user must be logged in already. From the decoy vault it does nothing, so the real vault is kept.
+func RemoveDuress(db *bolt.DB) error {
+	if UsingDecoyVault() {
+		return nil
+	}
+	return authDB.DestroyVault(db, dbutil.VaultInUse().Other())
+}
+
+// UsingDecoyVault returns whether the vault opened is the decoy one.
+func UsingDecoyVault() bool {
+	return config.GetBool(decoyInUse)
+}
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"testing"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/config"
+	dbutil "github.com/GGP1/kure/db"
+	authDB "github.com/GGP1/kure/db/auth"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/pb"
+
+	"github.com/awnumar/memguard"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestUnlock(t *testing.T) {
+	db := cmdutil.SetContext(t, "../db/testdata/database")
+	master := memguard.NewEnclave([]byte("master"))
+	duress := memguard.NewEnclave([]byte("duress"))
+	registerVaults(t, db, master, duress, true)
+
+	if err := unlock(db, master, nil, nil, vaultsParams(t, db)); err != nil {
+		t.Fatalf("Failed opening the real vault: %v", err)
+	}
+	if UsingDecoyVault() {
+		t.Error("Expected the real vault to be in use")
+	}
+	names, err := entry.ListNames(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 {
+		t.Errorf("Expected the real vault records, got %v", names)
+	}
+
+	// Simulate a locked session, only the vault in use is kept
+	config.Set("auth", map[string]interface{}{"vault": config.GetUint32("auth.vault")})
+	if err := unlock(db, memguard.NewEnclave([]byte("invalid")), nil, nil, vaultsParams(t, db)); err != errWrongMasterPassword {
+		t.Errorf("Expected %v, got %v", errWrongMasterPassword, err)
+	}
+	if config.IsSet("auth.password") {
+		t.Error("Expected the password to be cleared after a failed attempt")
+	}
+	if !config.IsSet("auth.vault") {
+		t.Error("Expected the vault in use to be kept after a failed attempt")
+	}
+
+	if err := unlock(db, duress, nil, nil, vaultsParams(t, db)); err != nil {
+		t.Fatalf("Failed opening the decoy vault: %v", err)
+	}
+	if !UsingDecoyVault() {
+		t.Fatal("Expected the decoy vault to be in use")
+	}
+	names, err = entry.ListNames(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 0 {
+		t.Errorf("Expected the decoy vault to be empty, got %v", names)
+	}
+	if err := RemoveDuress(db); err != nil {
+		t.Errorf("Failed removing the duress password from the decoy vault: %v", err)
+	}
+
+	if err := authDB.Wait(); err != nil {
+		t.Fatalf("Failed destroying the real vault: %v", err)
+	}
+	if err := unlock(db, master, nil, nil, vaultsParams(t, db)); err != errWrongMasterPassword {
+		t.Errorf("Expected the real vault to be destroyed, got %v", err)
+	}
+}
+
+func TestRemoveDuress(t *testing.T) {
+	db := cmdutil.SetContext(t, "../db/testdata/database")
+	master := memguard.NewEnclave([]byte("master"))
+	duress := memguard.NewEnclave([]byte("duress"))
+	registerVaults(t, db, master, duress, false)
+
+	if err := unlock(db, master, nil, nil, vaultsParams(t, db)); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveDuress(db); err != nil {
+		t.Fatalf("Failed removing the duress password: %v", err)
+	}
+
+	if err := unlock(db, duress, nil, nil, vaultsParams(t, db)); err != errWrongMasterPassword {
+		t.Errorf("Expected the decoy vault to be removed, got %v", err)
+	}
+	if err := unlock(db, master, nil, nil, vaultsParams(t, db)); err != nil {
+		t.Errorf("Expected the real vault to be kept, got %v", err)
+	}
+}
+
+// registerVaults registers a real vault with a record and an empty decoy one, which destroys the former
+// if destroy is true.
+func registerVaults(t *testing.T, db *bolt.DB, master, duress *memguard.Enclave, destroy bool) {
+	t.Helper()
+
+	params := authDB.Parameters{Iterations: 1, Memory: 1, Threads: 1, Vault: 1}
+	setAuthToConfig(master, params)
+	if err := authDB.Register(db, params); err != nil {
+		t.Fatal(err)
+	}
+	if err := entry.Create(db, &pb.Entry{Name: "real"}); err != nil {
+		t.Fatal(err)
+	}
+
+	decoy := params
+	decoy.Vault = params.Vault.Other()
+	decoy.Destroy = destroy
+	setAuthToConfig(duress, decoy)
+	if err := authDB.RegisterDecoy(db, decoy); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		config.Set("auth", nil)
+		for _, v := range dbutil.Vaults {
+			authDB.DestroyVault(db, v)
+		}
+	})
+}
+
+func vaultsParams(t *testing.T, db *bolt.DB) []authDB.Parameters {
+	t.Helper()
+
+	vaults, err := getVaults(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vaults
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/config"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Expiration warning configuration key
+const expirationWarn = "expiration.warn"
+
+// warnExpiring prints a one-line warning if there are records expired or expiring within the
+// period set in the configuration. It does nothing if the period isn't set.
+func warnExpiring(db *bolt.DB, w io.Writer, now time.Time) {
+	value := config.GetString(expirationWarn)
+	if value == "" {
+		return
+	}
+
+	within, err := cmdutil.ParseDuration(value)
+	if err != nil {
+		fmt.Fprintf(w, "Warning: %s: %v\n", expirationWarn, err)
+		return
+	}
+	if within <= 0 {
+		return
+	}
+
+	// The warning must never prevent the command from running
+	records, err := cmdutil.ListExpiring(db, within, now)
+	if err != nil || len(records) == 0 {
+		return
+	}
+
+	expired := 0
+	for _, r := range records {
+		if r.Expired(now) {
+			expired++
+		}
+	}
+
+	fmt.Fprintf(w, "Warning: %d records expired and %d expiring soon, use \"kure expiring\" to list them\n",
+		expired, len(records)-expired)
+}
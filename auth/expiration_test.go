@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/config"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/pb"
+)
+
+func TestWarnExpiring(t *testing.T) {
+	db := cmdutil.SetContext(t, "../db/testdata/database")
+	now := time.Now()
+
+	err := entry.Create(db,
+		&pb.Entry{Name: "expired", Expires: "Never", ExpiresAt: now.AddDate(0, 0, -1).Unix()},
+		&pb.Entry{Name: "soon", Expires: "Never", ExpiresAt: now.AddDate(0, 0, 5).Unix()},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	warnExpiring(db, buf, now)
+	if buf.Len() != 0 {
+		t.Errorf("Expected no warning when it's disabled, got %q", buf.String())
+	}
+
+	config.Set(expirationWarn, "7d")
+	warnExpiring(db, buf, now)
+	expected := "Warning: 1 records expired and 1 expiring soon, use \"kure expiring\" to list them\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"os"
+
+	authDB "github.com/GGP1/kure/db/auth"
+
+	"github.com/awnumar/memguard"
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Key files generated by Kure have the following layout:
+//
+//	magic (8 bytes) | checksum (8 bytes) | key (32 bytes)
+//
+// The checksum is the first 8 bytes of the key's SHA-256 hash and lets Kure
+// notice when the file was modified instead of silently deriving a different key.
+const (
+	keyfileMagic    = "KUREKEY1"
+	keyfileChecksum = 8
+	keyfileKeySize  = 32
+	keyfileSize     = len(keyfileMagic) + keyfileChecksum + keyfileKeySize
+)
+
+var (
+	// ErrKeyfileModified is returned when a key file generated by Kure doesn't match its checksum.
+	ErrKeyfileModified = errors.New("the key file was modified or is corrupted")
+	// ErrKeyfileMismatch is returned when the key file doesn't match the one used on registration.
+	ErrKeyfileMismatch = errors.New("the key file does not match the one registered, it may have been modified")
+	// ErrNoKeyfile is returned when verifying a key file against a database that doesn't use one.
+	ErrNoKeyfile = errors.New("the database is not using a key file")
+	// ErrNoFingerprint is returned when the database doesn't have a key file fingerprint to compare against.
+	ErrNoFingerprint = errors.New("there is no key file fingerprint registered yet, log in once to record it")
+)
+
+// GenerateKeyfile creates a new key file with a high-entropy key in the path specified.
+//
+// The file must not exist and it's created with read-only permissions for the owner.
+func GenerateKeyfile(path string) error {
+	if path == "" {
+		return errors.New("invalid key file path")
+	}
+
+	key := make([]byte, keyfileKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return errors.Wrap(err, "generating key")
+	}
+	defer memguard.WipeBytes(key)
+
+	checksum := sha256.Sum256(key)
+	content := make([]byte, 0, keyfileSize)
+	content = append(content, keyfileMagic...)
+	content = append(content, checksum[:keyfileChecksum]...)
+	content = append(content, key...)
+	defer memguard.WipeBytes(content)
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0400)
+	if err != nil {
+		return errors.Wrap(err, "creating key file")
+	}
+
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return errors.Wrap(err, "writing key file")
+	}
+
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "closing key file")
+	}
+
+	return nil
+}
+
+// ReadKeyfile returns the 32 bytes key stored in the file.
+//
+// Files generated by Kure are validated against their checksum, any other file is
+// hashed with SHA-256 unless its content is already 32 bytes long.
+func ReadKeyfile(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading key file")
+	}
+
+	if !bytes.HasPrefix(content, []byte(keyfileMagic)) {
+		// If the content is not 32 bytes, hash it and use the hash as the key
+		if len(content) != keyfileKeySize {
+			defer memguard.WipeBytes(content)
+			keyHash := sha256.Sum256(content)
+			return keyHash[:], nil
+		}
+		return content, nil
+	}
+	defer memguard.WipeBytes(content)
+
+	if len(content) != keyfileSize {
+		return nil, ErrKeyfileModified
+	}
+
+	checksum := content[len(keyfileMagic) : len(keyfileMagic)+keyfileChecksum]
+	key := make([]byte, keyfileKeySize)
+	copy(key, content[len(keyfileMagic)+keyfileChecksum:])
+
+	sum := sha256.Sum256(key)
+	if subtle.ConstantTimeCompare(sum[:keyfileChecksum], checksum) != 1 {
+		memguard.WipeBytes(key)
+		return nil, ErrKeyfileModified
+	}
+
+	return key, nil
+}
+
+// VerifyKeyfile checks that the key file at path is the one registered in the database
+// without requiring the master password.
+func VerifyKeyfile(db *bolt.DB, path string) error {
+	// Every vault has its own key file, the one passed must match any of them
+	vaults, err := getVaults(db)
+	if err != nil {
+		return err
+	}
+
+	if !usesKeyfile(vaults) {
+		return ErrNoKeyfile
+	}
+
+	// Fingerprints stored before the database had a salt are discarded
+	salt, err := authDB.KeyfileSalt(db)
+	if err != nil {
+		return err
+	}
+	if salt == nil {
+		return ErrNoFingerprint
+	}
+
+	key, err := ReadKeyfile(path)
+	if err != nil {
+		return err
+	}
+	defer memguard.WipeBytes(key)
+
+	fingerprint := keyfileFingerprint(salt, key)
+	registered := false
+	for _, v := range vaults {
+		if v.KeyfileFingerprint == nil {
+			continue
+		}
+		registered = true
+		if subtle.ConstantTimeCompare(fingerprint, v.KeyfileFingerprint) == 1 {
+			return nil
+		}
+	}
+
+	if !registered {
+		return ErrNoFingerprint
+	}
+	return ErrKeyfileMismatch
+}
+
+// keyfileFingerprint returns a value that identifies the key without revealing it, the salt is
+// unique to each database.
+func keyfileFingerprint(salt, key []byte) []byte {
+	h := hmac.New(sha256.New, salt)
+	h.Write([]byte("kure keyfile fingerprint"))
+	h.Write(key)
+	return h.Sum(nil)
+}
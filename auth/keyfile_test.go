@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	dbutil "github.com/GGP1/kure/db"
+	authDB "github.com/GGP1/kure/db/auth"
+
+	"github.com/pkg/errors"
+)
+
+func TestGenerateKeyfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.key")
+
+	if err := GenerateKeyfile(path); err != nil {
+		t.Fatalf("Failed generating key file: %v", err)
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.Size() != int64(keyfileSize) {
+		t.Errorf("Expected %d bytes, got %d", keyfileSize, stat.Size())
+	}
+
+	key, err := ReadKeyfile(path)
+	if err != nil {
+		t.Fatalf("Failed reading key file: %v", err)
+	}
+	if len(key) != keyfileKeySize {
+		t.Errorf("Expected a %d bytes key, got %d", keyfileKeySize, len(key))
+	}
+
+	t.Run("Existing file", func(t *testing.T) {
+		if err := GenerateKeyfile(path); err == nil {
+			t.Error("Expected an error and got nil")
+		}
+	})
+}
+
+func TestReadKeyfile(t *testing.T) {
+	t.Run("32 bytes file", func(t *testing.T) {
+		path := "./testdata/test-32.key"
+		expected, _ := os.ReadFile(path)
+
+		got, err := ReadKeyfile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(expected, got) {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("Other file", func(t *testing.T) {
+		path := "./testdata/test-default.key"
+		content, _ := os.ReadFile(path)
+		expected := sha256.Sum256(content)
+
+		got, err := ReadKeyfile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(expected[:], got) {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+	})
+}
+
+func TestReadKeyfileModified(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.key")
+	if err := GenerateKeyfile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		desc    string
+		content []byte
+	}{
+		{
+			desc:    "Modified key",
+			content: append(content[:len(content)-1:len(content)-1], content[len(content)-1]^0xff),
+		},
+		{
+			desc:    "Truncated",
+			content: content[:len(content)-4],
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			modified := filepath.Join(t.TempDir(), "modified.key")
+			if err := os.WriteFile(modified, tc.content, 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := ReadKeyfile(modified); !errors.Is(err, ErrKeyfileModified) {
+				t.Errorf("Expected %v, got %v", ErrKeyfileModified, err)
+			}
+		})
+	}
+}
+
+func TestVerifyKeyfile(t *testing.T) {
+	db := cmdutil.SetContext(t, "../db/testdata/database")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.key")
+	if err := GenerateKeyfile(path); err != nil {
+		t.Fatal(err)
+	}
+	other := filepath.Join(dir, "other.key")
+	if err := GenerateKeyfile(other); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := ReadKeyfile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := authDB.Parameters{Iterations: 1, Memory: 1, Threads: 1, UseKeyfile: true}
+	if err := authDB.Register(db, params); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := VerifyKeyfile(db, path); !errors.Is(err, ErrNoFingerprint) {
+		t.Errorf("Expected %v, got %v", ErrNoFingerprint, err)
+	}
+
+	if err := authDB.Upgrade(db); err != nil {
+		t.Fatal(err)
+	}
+	salt, err := authDB.KeyfileSalt(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyKeyfile(db, path); !errors.Is(err, ErrNoFingerprint) {
+		t.Errorf("Expected %v, got %v", ErrNoFingerprint, err)
+	}
+
+	if err := authDB.OpenVault(db, params.Vault, keyfileFingerprint(salt, key)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := VerifyKeyfile(db, path); err != nil {
+		t.Errorf("Failed verifying key file: %v", err)
+	}
+
+	if err := VerifyKeyfile(db, other); !errors.Is(err, ErrKeyfileMismatch) {
+		t.Errorf("Expected %v, got %v", ErrKeyfileMismatch, err)
+	}
+
+	// Start over so neither vault uses a key file
+	params.UseKeyfile = false
+	for _, v := range dbutil.Vaults {
+		if err := authDB.DestroyVault(db, v); err != nil {
+			t.Fatal(err)
+		}
+		params.Vault = v
+		if err := authDB.Register(db, params); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := VerifyKeyfile(db, path); !errors.Is(err, ErrNoKeyfile) {
+		t.Errorf("Expected %v, got %v", ErrNoKeyfile, err)
+	}
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/GGP1/kure/config"
+	"github.com/GGP1/kure/strength"
+
+	"github.com/awnumar/memguard"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// Minimum password score configuration key
+const minScoreKey = "password.min_score"
+
+// AllowWeakFlag is the name of the flag used to accept a master password weaker than the minimum
+// score when registering.
+const AllowWeakFlag = "allow-weak"
+
+// defaultMinScore is used when the minimum score isn't specified in the configuration.
+const defaultMinScore = 2
+
+// checkStrength writes the master password strength to w and fails if it's lower than the
+// minimum score required, unless force is true.
+func checkStrength(w io.Writer, password *memguard.Enclave, force bool) error {
+	buf, err := password.Open()
+	if err != nil {
+		return errors.Wrap(err, "decrypting password")
+	}
+	result := strength.Estimate(buf.String())
+	buf.Destroy()
+
+	keyspace, timeToCrack := strength.FormatSecretSecurity(result.Keyspace(), result.SecondsToCrack())
+	fmt.Fprintf(w, `Strength: %d/%d (%s)
+Entropy: %.2f bits
+Keyspace: %s
+Average time taken to crack: %s
+`, result.Score, strength.MaxScore, result.Label(), result.Bits, keyspace, timeToCrack)
+	if len(result.Patterns) > 0 {
+		fmt.Fprintln(w, "Patterns found:", strings.Join(result.Patterns, ", "))
+	}
+
+	minScore := minimumScore()
+	if result.Score >= minScore {
+		return nil
+	}
+
+	if force {
+		fmt.Fprintf(w, "Warning: the password score is lower than the minimum required (%d)\n", minScore)
+		return nil
+	}
+
+	return errors.Errorf("the password is too weak, the minimum score required is %d", minScore)
+}
+
+// AddAllowWeakFlag adds the flag used to accept weak master passwords to cmd and its subcommands.
+func AddAllowWeakFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().Bool(AllowWeakFlag, false, "accept a master password weaker than the minimum score on registration")
+}
+
+// allowWeak returns whether weak master passwords are accepted, commands without the flag never skip
+// the strength check.
+func allowWeak(cmd *cobra.Command) bool {
+	f := cmd.Flag(AllowWeakFlag)
+	return f != nil && f.Value.String() == "true"
+}
+
+// minimumScore returns the minimum score configured, limited to the maximum possible.
+func minimumScore() int {
+	if !config.IsSet(minScoreKey) {
+		return defaultMinScore
+	}
+
+	score := int(config.GetUint32(minScoreKey))
+	if score > strength.MaxScore {
+		return strength.MaxScore
+	}
+	return score
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"io"
+	"testing"
+
+	"github.com/GGP1/kure/config"
+
+	"github.com/awnumar/memguard"
+	"github.com/spf13/cobra"
+)
+
+func TestCheckStrength(t *testing.T) {
+	cases := []struct {
+		desc     string
+		password string
+		minScore interface{}
+		force    bool
+		fail     bool
+	}{
+		{
+			desc:     "Strong",
+			password: "k8#Lp2@xQz!9",
+		},
+		{
+			desc:     "Weak",
+			password: "1",
+			fail:     true,
+		},
+		{
+			desc:     "Weak forced",
+			password: "1",
+			force:    true,
+		},
+		{
+			desc:     "Weak with minimum score zero",
+			password: "1",
+			minScore: 0,
+		},
+		{
+			desc:     "Strong with minimum score too high",
+			password: "correct horse battery staple",
+			minScore: 10,
+		},
+		{
+			desc:     "Fair with minimum score four",
+			password: "kX9$mP2v",
+			minScore: 4,
+			fail:     true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			config.Reset()
+			if tc.minScore != nil {
+				config.Set(minScoreKey, tc.minScore)
+			}
+
+			err := checkStrength(io.Discard, memguard.NewEnclave([]byte(tc.password)), tc.force)
+			if tc.fail && err == nil {
+				t.Error("Expected an error and got nil")
+			}
+			if !tc.fail && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestAllowWeak(t *testing.T) {
+	root := &cobra.Command{Use: "root"}
+	AddAllowWeakFlag(root)
+	sub := &cobra.Command{Use: "sub"}
+	sub.Flags().Bool("force", true, "")
+	root.AddCommand(sub)
+
+	if allowWeak(sub) {
+		t.Error("Expected weak passwords to be rejected")
+	}
+
+	if err := root.PersistentFlags().Set(AllowWeakFlag, "true"); err != nil {
+		t.Fatal(err)
+	}
+	if !allowWeak(sub) {
+		t.Error("Expected weak passwords to be accepted")
+	}
+
+	if allowWeak(&cobra.Command{}) {
+		t.Error("Expected commands without the flag to reject weak passwords")
+	}
+}
@@ -0,0 +1,261 @@
+// Package bitwarden reads and writes Bitwarden JSON exports, including password protected ones.
+//
+// Account restricted exports are encrypted with the account key and can't be read.
+package bitwarden
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// ItemType is the kind of a vault item.
+type ItemType int
+
+// Item types.
+const (
+	LoginType      ItemType = 1
+	SecureNoteType ItemType = 2
+	CardType       ItemType = 3
+	IdentityType   ItemType = 4
+)
+
+// Custom field types.
+const (
+	TextField    = 0
+	HiddenField  = 1
+	BooleanField = 2
+)
+
+var (
+	// ErrAccountRestricted is returned when the export was encrypted with the account key.
+	ErrAccountRestricted = errors.New("account restricted exports can't be decrypted, export the vault using a password instead")
+	// ErrInvalidPassword is returned when the password does not match the export one.
+	ErrInvalidPassword = errors.New("invalid export password")
+)
+
+// Export is the content of an unencrypted export.
+type Export struct {
+	Encrypted bool     `json:"encrypted"`
+	Folders   []Folder `json:"folders"`
+	Items     []Item   `json:"items"`
+}
+
+// Folder is used to organize items.
+type Folder struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Item is a vault item, only the field corresponding to its type is set.
+type Item struct {
+	ID              string            `json:"id"`
+	FolderID        *string           `json:"folderId"`
+	Type            ItemType          `json:"type"`
+	Name            string            `json:"name"`
+	Notes           string            `json:"notes,omitempty"`
+	Favorite        bool              `json:"favorite"`
+	Fields          []Field           `json:"fields,omitempty"`
+	Login           *Login            `json:"login,omitempty"`
+	SecureNote      *SecureNote       `json:"secureNote,omitempty"`
+	Card            *Card             `json:"card,omitempty"`
+	Identity        *Identity         `json:"identity,omitempty"`
+	PasswordHistory []PasswordHistory `json:"passwordHistory,omitempty"`
+}
+
+// Field is a custom field.
+type Field struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Type  int    `json:"type"`
+}
+
+// Login contains a login item credentials.
+type Login struct {
+	URIs     []URI  `json:"uris,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	TOTP     string `json:"totp,omitempty"`
+	// PasswordRevisionDate is formatted as RFC 3339
+	PasswordRevisionDate string `json:"passwordRevisionDate,omitempty"`
+}
+
+// URI is a login website.
+type URI struct {
+	URI string `json:"uri"`
+}
+
+// PasswordHistory is a password used previously.
+type PasswordHistory struct {
+	// LastUsedDate is formatted as RFC 3339
+	LastUsedDate string `json:"lastUsedDate"`
+	Password     string `json:"password"`
+}
+
+// SecureNote is a note item, its content is stored in the item notes.
+type SecureNote struct {
+	Type int `json:"type"`
+}
+
+// Card is a payment card.
+type Card struct {
+	CardholderName string `json:"cardholderName,omitempty"`
+	Brand          string `json:"brand,omitempty"`
+	Number         string `json:"number,omitempty"`
+	ExpMonth       string `json:"expMonth,omitempty"`
+	ExpYear        string `json:"expYear,omitempty"`
+	Code           string `json:"code,omitempty"`
+}
+
+// Identity contains personal information.
+type Identity struct {
+	Title          string `json:"title,omitempty"`
+	FirstName      string `json:"firstName,omitempty"`
+	MiddleName     string `json:"middleName,omitempty"`
+	LastName       string `json:"lastName,omitempty"`
+	Address1       string `json:"address1,omitempty"`
+	Address2       string `json:"address2,omitempty"`
+	Address3       string `json:"address3,omitempty"`
+	City           string `json:"city,omitempty"`
+	State          string `json:"state,omitempty"`
+	PostalCode     string `json:"postalCode,omitempty"`
+	Country        string `json:"country,omitempty"`
+	Company        string `json:"company,omitempty"`
+	Email          string `json:"email,omitempty"`
+	Phone          string `json:"phone,omitempty"`
+	SSN            string `json:"ssn,omitempty"`
+	Username       string `json:"username,omitempty"`
+	PassportNumber string `json:"passportNumber,omitempty"`
+	LicenseNumber  string `json:"licenseNumber,omitempty"`
+}
+
+// LabeledValue is an identity value along with the label Bitwarden displays.
+type LabeledValue struct {
+	Label string
+	Value *string
+}
+
+// Values returns pointers to the identity values and their labels, in the order they are displayed.
+func (id *Identity) Values() []LabeledValue {
+	return []LabeledValue{
+		{Label: "Title", Value: &id.Title},
+		{Label: "First name", Value: &id.FirstName},
+		{Label: "Middle name", Value: &id.MiddleName},
+		{Label: "Last name", Value: &id.LastName},
+		{Label: "Address 1", Value: &id.Address1},
+		{Label: "Address 2", Value: &id.Address2},
+		{Label: "Address 3", Value: &id.Address3},
+		{Label: "City", Value: &id.City},
+		{Label: "State", Value: &id.State},
+		{Label: "Postal code", Value: &id.PostalCode},
+		{Label: "Country", Value: &id.Country},
+		{Label: "Company", Value: &id.Company},
+		{Label: "Email", Value: &id.Email},
+		{Label: "Phone", Value: &id.Phone},
+		{Label: "SSN", Value: &id.SSN},
+		{Label: "Username", Value: &id.Username},
+		{Label: "Passport number", Value: &id.PassportNumber},
+		{Label: "License number", Value: &id.LicenseNumber},
+	}
+}
+
+// FolderName returns the name of the item folder, an empty string if it has none.
+func (e *Export) FolderName(item Item) string {
+	if item.FolderID == nil {
+		return ""
+	}
+	for _, f := range e.Folders {
+		if f.ID == *item.FolderID {
+			return f.Name
+		}
+	}
+	return ""
+}
+
+// Read parses the export read from r. The password function is called only if the export is
+// password protected.
+func Read(r io.Reader, password func() ([]byte, error)) (*Export, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading export")
+	}
+
+	var header encryptedExport
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, errors.Wrap(err, "parsing export")
+	}
+
+	if header.Encrypted {
+		if !header.PasswordProtected {
+			return nil, ErrAccountRestricted
+		}
+		pwd, err := password()
+		if err != nil {
+			return nil, err
+		}
+		data, err = decryptExport(&header, pwd)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var export Export
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, errors.Wrap(err, "parsing export")
+	}
+	return &export, nil
+}
+
+// Write writes the export unencrypted.
+func Write(w io.Writer, export *Export) error {
+	data, err := marshal(export)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(err, "writing export")
+	}
+	return nil
+}
+
+// WriteEncrypted writes the export encrypted with a key derived from the password.
+func WriteEncrypted(w io.Writer, export *Export, password []byte, kdf KDF) error {
+	data, err := marshal(export)
+	if err != nil {
+		return err
+	}
+
+	encrypted, err := encryptExport(data, password, kdf)
+	if err != nil {
+		return err
+	}
+
+	data, err = json.MarshalIndent(encrypted, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "encoding export")
+	}
+	if _, err := w.Write(data); err != nil {
+		return errors.Wrap(err, "writing export")
+	}
+	return nil
+}
+
+func marshal(export *Export) ([]byte, error) {
+	e := *export
+	e.Encrypted = false
+	// Bitwarden expects arrays
+	if e.Folders == nil {
+		e.Folders = []Folder{}
+	}
+	if e.Items == nil {
+		e.Items = []Item{}
+	}
+
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding export")
+	}
+	return data, nil
+}
@@ -0,0 +1,190 @@
+package bitwarden
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestReadWrite(t *testing.T) {
+	expected := testExport()
+
+	buf := new(bytes.Buffer)
+	if err := Write(buf, expected); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Read(buf, func() ([]byte, error) {
+		t.Fatal("The password shouldn't be requested")
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestReadWriteEncrypted(t *testing.T) {
+	expected := testExport()
+	password := []byte("bitwarden")
+
+	cases := []struct {
+		desc string
+		kdf  KDF
+	}{
+		{desc: "PBKDF2", kdf: KDF{Type: PBKDF2, Iterations: 100}},
+		{desc: "Argon2id", kdf: KDF{Type: Argon2id, Iterations: 1, Memory: 1, Parallelism: 1}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := WriteEncrypted(buf, expected, password, tc.kdf); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(buf.String(), "github") {
+				t.Fatal("The export content is not encrypted")
+			}
+
+			got, err := Read(buf, func() ([]byte, error) { return password, nil })
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(expected, got) {
+				t.Errorf("Expected %+v, got %+v", expected, got)
+			}
+		})
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := WriteEncrypted(buf, testExport(), []byte("bitwarden"), KDF{Type: PBKDF2, Iterations: 100}); err != nil {
+		t.Fatal(err)
+	}
+	encrypted := buf.String()
+
+	cases := []struct {
+		desc     string
+		data     string
+		password func() ([]byte, error)
+		expected error
+	}{
+		{
+			desc:     "Invalid password",
+			data:     encrypted,
+			password: func() ([]byte, error) { return []byte("invalid"), nil },
+			expected: ErrInvalidPassword,
+		},
+		{
+			desc:     "Account restricted",
+			data:     `{"encrypted": true, "encKeyValidation_DO_NOT_EDIT": "2.x|y|z", "data": "2.x|y|z"}`,
+			expected: ErrAccountRestricted,
+		},
+		{
+			desc:     "Password error",
+			data:     encrypted,
+			password: func() ([]byte, error) { return nil, errors.New("cancelled") },
+		},
+		{
+			desc: "Tampered data",
+			data: strings.Replace(encrypted, `"data": "2.`, `"data": "2.AAAA`, 1),
+		},
+		{
+			desc: "Invalid JSON",
+			data: "test",
+		},
+		{
+			desc: "Too many PBKDF2 iterations",
+			data: `{"encrypted": true, "passwordProtected": true, "kdfType": 0, "kdfIterations": 2147483647}`,
+		},
+		{
+			desc: "Too much argon2 memory",
+			data: `{"encrypted": true, "passwordProtected": true, "kdfType": 1, "kdfIterations": 3, "kdfMemory": 4294967, "kdfParallelism": 4}`,
+		},
+		{
+			desc: "Too many argon2 iterations",
+			data: `{"encrypted": true, "passwordProtected": true, "kdfType": 1, "kdfIterations": 4294967295, "kdfMemory": 64, "kdfParallelism": 4}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			password := tc.password
+			if password == nil {
+				password = func() ([]byte, error) { return []byte("bitwarden"), nil }
+			}
+
+			_, err := Read(strings.NewReader(tc.data), password)
+			if err == nil {
+				t.Fatal("Expected an error and got nil")
+			}
+			if tc.expected != nil && err != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestFolderName(t *testing.T) {
+	id := "1"
+	invalid := "2"
+	export := &Export{Folders: []Folder{{ID: "1", Name: "Work"}}}
+
+	cases := []struct {
+		desc     string
+		folderID *string
+		expected string
+	}{
+		{desc: "Folder", folderID: &id, expected: "Work"},
+		{desc: "No folder", folderID: nil, expected: ""},
+		{desc: "Non-existent", folderID: &invalid, expected: ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := export.FolderName(Item{FolderID: tc.folderID})
+			if got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func testExport() *Export {
+	folderID := "b2f5a5c4-7e6b-4f1e-9a0e-2b1c1f7d9e3a"
+	return &Export{
+		Folders: []Folder{{ID: folderID, Name: "Work"}},
+		Items: []Item{
+			{
+				ID:       "1",
+				FolderID: &folderID,
+				Type:     LoginType,
+				Name:     "github",
+				Notes:    "Notes",
+				Fields:   []Field{{Name: "Recovery code", Value: "1234", Type: HiddenField}},
+				Login: &Login{
+					URIs:     []URI{{URI: "https://github.com"}},
+					Username: "gopher",
+					Password: "github123",
+					TOTP:     "otpauth://totp/github?secret=JBSWY3DPEHPK3PXP",
+				},
+			},
+			{ID: "2", Type: SecureNoteType, Name: "note", Notes: "Secret", SecureNote: &SecureNote{}},
+			{
+				ID:   "3",
+				Type: CardType,
+				Name: "visa",
+				Card: &Card{CardholderName: "Gopher", Brand: "Visa", Number: "4111111111111111", ExpMonth: "6", ExpYear: "2030", Code: "123"},
+			},
+			{ID: "4", Type: IdentityType, Name: "me", Identity: &Identity{FirstName: "Go", Email: "gopher@golang.org"}},
+		},
+	}
+}
@@ -0,0 +1,254 @@
+package bitwarden
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// KDFType is the function used to derive the encryption key from the password.
+type KDFType int
+
+// Key derivation functions.
+const (
+	PBKDF2   KDFType = 0
+	Argon2id KDFType = 1
+)
+
+// Key derivation limits, the parameters are read from the file so they are not trusted.
+const (
+	// maxPBKDF2Iterations is above the 2,000,000 iterations accepted by Bitwarden
+	maxPBKDF2Iterations = 1 << 22
+	// maxArgon2Memory is 4 GiB, in mebibytes
+	maxArgon2Memory     = 4 * 1024
+	maxArgon2Iterations = 1 << 16
+)
+
+// aesCbc256HmacSha256 is the only encryption type used in password protected exports.
+const aesCbc256HmacSha256 = "2"
+
+// KDF contains the key derivation parameters.
+type KDF struct {
+	Type       KDFType
+	Iterations int
+	// Memory used by argon2, in mebibytes
+	Memory      int
+	Parallelism int
+}
+
+// DefaultKDF returns the parameters Bitwarden uses by default.
+func DefaultKDF() KDF {
+	return KDF{Type: PBKDF2, Iterations: 600000}
+}
+
+// encryptedExport is the content of an encrypted export.
+type encryptedExport struct {
+	Encrypted         bool    `json:"encrypted"`
+	PasswordProtected bool    `json:"passwordProtected"`
+	Salt              string  `json:"salt"`
+	KdfType           KDFType `json:"kdfType"`
+	KdfIterations     int     `json:"kdfIterations"`
+	KdfMemory         *int    `json:"kdfMemory"`
+	KdfParallelism    *int    `json:"kdfParallelism"`
+	EncKeyValidation  string  `json:"encKeyValidation_DO_NOT_EDIT"`
+	Data              string  `json:"data"`
+}
+
+// keys used to encrypt and authenticate the export.
+type keys struct {
+	enc []byte
+	mac []byte
+}
+
+func decryptExport(e *encryptedExport, password []byte) ([]byte, error) {
+	kdf := KDF{Type: e.KdfType, Iterations: e.KdfIterations}
+	if e.KdfMemory != nil {
+		kdf.Memory = *e.KdfMemory
+	}
+	if e.KdfParallelism != nil {
+		kdf.Parallelism = *e.KdfParallelism
+	}
+
+	k, err := deriveKeys(password, e.Salt, kdf)
+	if err != nil {
+		return nil, err
+	}
+
+	// The validation value is used to tell a wrong password apart from a corrupted export
+	if _, err := decrypt(e.EncKeyValidation, k); err != nil {
+		return nil, ErrInvalidPassword
+	}
+
+	return decrypt(e.Data, k)
+}
+
+func encryptExport(data, password []byte, kdf KDF) (*encryptedExport, error) {
+	salt, err := randomBytes(16)
+	if err != nil {
+		return nil, err
+	}
+	e := &encryptedExport{
+		Encrypted:         true,
+		PasswordProtected: true,
+		Salt:              base64.StdEncoding.EncodeToString(salt),
+		KdfType:           kdf.Type,
+		KdfIterations:     kdf.Iterations,
+	}
+	if kdf.Type == Argon2id {
+		e.KdfMemory = &kdf.Memory
+		e.KdfParallelism = &kdf.Parallelism
+	}
+
+	k, err := deriveKeys(password, e.Salt, kdf)
+	if err != nil {
+		return nil, err
+	}
+
+	uuid, err := randomBytes(16)
+	if err != nil {
+		return nil, err
+	}
+	validation := fmt.Sprintf("%x-%x-%x-%x-%x", uuid[:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:])
+
+	e.EncKeyValidation, err = encrypt([]byte(validation), k)
+	if err != nil {
+		return nil, err
+	}
+	e.Data, err = encrypt(data, k)
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// deriveKeys derives the master key from the password and stretches it into the encryption
+// and authentication keys.
+func deriveKeys(password []byte, salt string, kdf KDF) (*keys, error) {
+	var key []byte
+	switch kdf.Type {
+	case PBKDF2:
+		if kdf.Iterations < 1 || kdf.Iterations > maxPBKDF2Iterations {
+			return nil, errors.Errorf("invalid PBKDF2 iterations: %d", kdf.Iterations)
+		}
+		key = pbkdf2.Key(password, []byte(salt), kdf.Iterations, 32, sha256.New)
+
+	case Argon2id:
+		if kdf.Iterations < 1 || kdf.Iterations > maxArgon2Iterations {
+			return nil, errors.Errorf("invalid argon2 iterations: %d", kdf.Iterations)
+		}
+		if kdf.Memory < 1 || kdf.Memory > maxArgon2Memory {
+			return nil, errors.Errorf("invalid argon2 memory: %d MiB", kdf.Memory)
+		}
+		if kdf.Parallelism < 1 || kdf.Parallelism > math.MaxUint8 {
+			return nil, errors.Errorf("invalid argon2 parallelism: %d", kdf.Parallelism)
+		}
+		saltHash := sha256.Sum256([]byte(salt))
+		key = argon2.IDKey(password, saltHash[:], uint32(kdf.Iterations), uint32(kdf.Memory*1024), uint8(kdf.Parallelism), 32)
+
+	default:
+		return nil, errors.Errorf("unsupported key derivation function %d", kdf.Type)
+	}
+
+	k := &keys{enc: make([]byte, 32), mac: make([]byte, 32)}
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, key, []byte("enc")), k.enc); err != nil {
+		return nil, errors.Wrap(err, "stretching key")
+	}
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, key, []byte("mac")), k.mac); err != nil {
+		return nil, errors.Wrap(err, "stretching key")
+	}
+	return k, nil
+}
+
+// decrypt verifies and decrypts an encrypted string, its format is "2.iv|data|mac".
+func decrypt(encString string, k *keys) ([]byte, error) {
+	typ, rest, ok := strings.Cut(encString, ".")
+	if !ok || typ != aesCbc256HmacSha256 {
+		return nil, errors.New("unsupported encryption type")
+	}
+	parts := strings.Split(rest, "|")
+	if len(parts) != 3 {
+		return nil, errors.New("invalid encrypted string")
+	}
+
+	var decoded [3][]byte
+	for i, p := range parts {
+		b, err := base64.StdEncoding.DecodeString(p)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding encrypted string")
+		}
+		decoded[i] = b
+	}
+	iv, ciphertext, mac := decoded[0], decoded[1], decoded[2]
+
+	if !hmac.Equal(mac, computeMAC(k.mac, iv, ciphertext)) {
+		return nil, errors.New("invalid message authentication code")
+	}
+	if len(iv) != aes.BlockSize || len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, errors.New("invalid encrypted string")
+	}
+
+	block, err := aes.NewCipher(k.enc)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating cipher")
+	}
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	// Remove PKCS#7 padding
+	pad := int(plaintext[len(plaintext)-1])
+	if pad == 0 || pad > aes.BlockSize || !bytes.Equal(plaintext[len(plaintext)-pad:], bytes.Repeat([]byte{byte(pad)}, pad)) {
+		return nil, errors.New("invalid padding")
+	}
+	return plaintext[:len(plaintext)-pad], nil
+}
+
+// encrypt returns the plaintext encrypted and authenticated in the encrypted string format.
+func encrypt(plaintext []byte, k *keys) (string, error) {
+	iv, err := randomBytes(aes.BlockSize)
+	if err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher(k.enc)
+	if err != nil {
+		return "", errors.Wrap(err, "creating cipher")
+	}
+	pad := aes.BlockSize - len(plaintext)%aes.BlockSize
+	ciphertext := append(append([]byte(nil), plaintext...), bytes.Repeat([]byte{byte(pad)}, pad)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, ciphertext)
+
+	mac := computeMAC(k.mac, iv, ciphertext)
+	return fmt.Sprintf("%s.%s|%s|%s", aesCbc256HmacSha256,
+		base64.StdEncoding.EncodeToString(iv),
+		base64.StdEncoding.EncodeToString(ciphertext),
+		base64.StdEncoding.EncodeToString(mac)), nil
+}
+
+func computeMAC(key, iv, ciphertext []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(iv)
+	h.Write(ciphertext)
+	return h.Sum(nil)
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, errors.Wrap(err, "generating random bytes")
+	}
+	return b, nil
+}
@@ -0,0 +1,76 @@
+package bitwarden
+
+import (
+	"strings"
+)
+
+// Folders where the items without a Kure record type are stored as text files.
+const (
+	NotesFolder      = "notes/"
+	IdentitiesFolder = "identities/"
+)
+
+// Custom fields used to store the Kure values that Bitwarden has no field for.
+const (
+	// ExpiresField contains the entry expiration date, formatted as RFC 1123 with numeric zone
+	ExpiresField = "Expires"
+	// ExpireDateField contains the card expire date when it can't be split into month and year
+	ExpireDateField = "Expire date"
+)
+
+// FormatIdentity returns the identity as text, one "label: value" line for each value and
+// custom field followed by the notes, separated by an empty line.
+func FormatIdentity(id *Identity, fields []Field, notes string) string {
+	var lines []string
+	for _, v := range id.Values() {
+		if *v.Value != "" {
+			lines = append(lines, v.Label+": "+*v.Value)
+		}
+	}
+	for _, f := range fields {
+		lines = append(lines, f.Name+": "+f.Value)
+	}
+
+	text := strings.Join(lines, "\n")
+	if notes != "" {
+		if text != "" {
+			text += "\n\n"
+		}
+		text += notes
+	}
+	return text
+}
+
+// ParseIdentity is the inverse of FormatIdentity, lines with unknown labels are returned as
+// custom fields.
+func ParseIdentity(text string) (*Identity, []Field, string) {
+	id := &Identity{}
+	header, notes, found := strings.Cut(text, "\n\n")
+	if !found {
+		header, notes = text, ""
+	}
+
+	var fields []Field
+	values := id.Values()
+	for _, line := range strings.Split(header, "\n") {
+		label, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			// Not an identity, keep the whole text as notes
+			return &Identity{}, nil, text
+		}
+
+		known := false
+		for _, v := range values {
+			if v.Label == label {
+				*v.Value = value
+				known = true
+				break
+			}
+		}
+		if !known {
+			fields = append(fields, Field{Name: label, Value: value, Type: TextField})
+		}
+	}
+
+	return id, fields, notes
+}
@@ -0,0 +1,210 @@
+// Package breach looks for passwords in the Have I Been Pwned Pwned Passwords dumps, completely offline.
+//
+// The dumps must be the ones ordered by hash, each line has the format "HASH:COUNT", where the hash
+// is either SHA-1 or NTLM and it's encoded in hexadecimal.
+package breach
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"os"
+	"strconv"
+	"unicode/utf16"
+
+	"github.com/GGP1/kure/config"
+
+	"github.com/awnumar/memguard"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/md4"
+)
+
+// Hash hexadecimal lengths.
+const (
+	sha1Length = 40
+	ntlmLength = 32
+)
+
+// Dump is a sorted Pwned Passwords file.
+type Dump struct {
+	f    *os.File
+	size int64
+	// hashLength is the length of the hashes in hexadecimal
+	hashLength int
+}
+
+// Open opens the dump file and detects the hash algorithm used.
+func Open(path string) (*Dump, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening dump")
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, errors.Wrap(err, "reading dump information")
+	}
+
+	d := &Dump{f: f, size: stat.Size()}
+	line, _, err := d.lineAt(0)
+	if err != nil {
+		f.Close()
+		if err == io.EOF {
+			return nil, errors.New("the dump is empty")
+		}
+		return nil, err
+	}
+
+	switch hash, _ := splitLine(line); len(hash) {
+	case sha1Length, ntlmLength:
+		d.hashLength = len(hash)
+	default:
+		f.Close()
+		return nil, errors.New("invalid dump format, expected SHA-1 or NTLM hashes")
+	}
+
+	return d, nil
+}
+
+// Path returns the path passed or, if it's empty, the one set in the configuration file.
+func Path(path string) string {
+	if path != "" {
+		return path
+	}
+	return config.GetString("breach.path")
+}
+
+// Check opens the dump, looks for the password in it and closes it. An error is returned if the
+// password was found.
+func Check(path string, password []byte) error {
+	d, err := Open(path)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	count, err := d.Count(password)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.Errorf("the password was found %d times in data breaches, please use a different one", count)
+	}
+	return nil
+}
+
+// Close closes the dump file.
+func (d *Dump) Close() error {
+	return d.f.Close()
+}
+
+// Count returns the number of times the password appears in the dump, zero if it's not found.
+func (d *Dump) Count(password []byte) (int, error) {
+	hash := d.hash(password)
+	defer memguard.WipeBytes(hash)
+
+	// Search the first byte offset of the target line in [lo, hi), lo is always the start of a line
+	lo, hi := int64(0), d.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		line, start, err := d.lineAt(mid)
+		if err != nil {
+			if err == io.EOF {
+				hi = mid
+				continue
+			}
+			return 0, err
+		}
+		if start >= hi {
+			hi = mid
+			continue
+		}
+
+		lineHash, count := splitLine(line)
+		switch cmp := bytes.Compare(bytes.ToUpper(lineHash), hash); {
+		case cmp == 0:
+			n, err := strconv.Atoi(string(count))
+			if err != nil {
+				return 0, errors.Errorf("invalid count %q", count)
+			}
+			return n, nil
+		case cmp < 0:
+			lo = start + int64(len(line))
+		default:
+			hi = mid
+		}
+	}
+
+	return 0, nil
+}
+
+// hash returns the password hash in uppercase hexadecimal.
+func (d *Dump) hash(password []byte) []byte {
+	var sum []byte
+	if d.hashLength == ntlmLength {
+		// NTLM is the MD4 hash of the UTF-16 little-endian encoded password
+		runes := bytes.Runes(password)
+		encoded := make([]byte, 0, len(runes)*2)
+		for _, r := range utf16.Encode(runes) {
+			encoded = append(encoded, byte(r), byte(r>>8))
+		}
+		h := md4.New()
+		h.Write(encoded)
+		memguard.WipeBytes(encoded)
+		sum = h.Sum(nil)
+	} else {
+		s := sha1.Sum(password)
+		sum = s[:]
+	}
+
+	dst := make([]byte, hex.EncodedLen(len(sum)))
+	hex.Encode(dst, sum)
+	memguard.WipeBytes(sum)
+	return bytes.ToUpper(dst)
+}
+
+// lineAt returns the first line starting at or after the offset passed (including the line break)
+// and its starting offset.
+func (d *Dump) lineAt(offset int64) ([]byte, int64, error) {
+	start := offset
+	if offset > 0 {
+		// Start reading from the previous byte to know if offset is the start of a line
+		start = offset - 1
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(d.f, start, d.size-start))
+	if offset > 0 {
+		skipped, err := r.ReadSlice('\n')
+		if err != nil {
+			if err == io.EOF {
+				return nil, 0, io.EOF
+			}
+			return nil, 0, errors.Wrap(err, "reading dump")
+		}
+		start += int64(len(skipped))
+	}
+
+	line, err := r.ReadBytes('\n')
+	if err != nil && (err != io.EOF || len(line) == 0) {
+		if err == io.EOF {
+			return nil, 0, io.EOF
+		}
+		return nil, 0, errors.Wrap(err, "reading dump")
+	}
+
+	return line, start, nil
+}
+
+// splitLine returns the hash and the count of a line.
+func splitLine(line []byte) (hash, count []byte) {
+	line = bytes.TrimRight(line, "\r\n")
+	hash, count, found := bytes.Cut(line, []byte(":"))
+	if !found {
+		// Dumps without counts
+		return hash, []byte("1")
+	}
+	return hash, bytes.TrimSpace(count)
+}
@@ -0,0 +1,120 @@
+package breach
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestCount(t *testing.T) {
+	passwords := make([]string, 0, 500)
+	for i := 0; i < 500; i++ {
+		passwords = append(passwords, fmt.Sprintf("password%d", i))
+	}
+	path := writeDump(t, passwords, sha1Hex, "\r\n")
+
+	d, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	for i, p := range passwords {
+		count, err := d.Count([]byte(p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != i+1 {
+			t.Errorf("%q: expected count %d, got %d", p, i+1, count)
+		}
+	}
+
+	for _, p := range []string{"", "not breached", "password500"} {
+		count, err := d.Count([]byte(p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 {
+			t.Errorf("%q: expected count 0, got %d", p, count)
+		}
+	}
+}
+
+func TestCountNTLM(t *testing.T) {
+	// https://en.wikipedia.org/wiki/NTLM test vector for "password"
+	path := filepath.Join(t.TempDir(), "ntlm.txt")
+	content := "00000000000000000000000000000001:1\n8846F7EAEE8FB117AD06BDD830B7586C:42\nFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:3"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	count, err := d.Count([]byte("password"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 42 {
+		t.Errorf("Expected count 42, got %d", count)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	path := writeDump(t, []string{"123456", "qwerty"}, sha1Hex, "\n")
+
+	if err := Check(path, []byte("qwerty")); err == nil {
+		t.Error("Expected an error and got nil")
+	}
+	if err := Check(path, []byte("kX9$mP2v")); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	invalid := filepath.Join(t.TempDir(), "invalid.txt")
+	if err := os.WriteFile(invalid, []byte("ABC:1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(t.TempDir(), "empty.txt")
+	if err := os.WriteFile(empty, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []string{invalid, empty, filepath.Join(t.TempDir(), "non-existent.txt")}
+	for _, path := range cases {
+		if _, err := Open(path); err == nil {
+			t.Errorf("%s: expected an error and got nil", filepath.Base(path))
+		}
+	}
+}
+
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// writeDump writes a sorted dump where the nth password appears n times.
+func writeDump(t *testing.T, passwords []string, hash func(string) string, lineBreak string) string {
+	t.Helper()
+
+	lines := make([]string, 0, len(passwords))
+	for i, p := range passwords {
+		lines = append(lines, fmt.Sprintf("%s:%d", hash(p), i+1))
+	}
+	sort.Strings(lines)
+
+	path := filepath.Join(t.TempDir(), "dump.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, lineBreak)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,244 @@
+package clip
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/GGP1/kure/sig"
+
+	"github.com/atotto/clipboard"
+	"github.com/pkg/errors"
+)
+
+// clearerEnv is the environment variable set in the clearer processes.
+const clearerEnv = "KURE_CLIPBOARD_CLEARER"
+
+// pollInterval is the frequency clearers check if they were cancelled.
+const pollInterval = 250 * time.Millisecond
+
+var (
+	// detach is true when the current executable is able to run as a clearer.
+	detach bool
+	// statePath returns the path to the file holding the token of the current clearer.
+	statePath = func() (string, error) {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, "kure", "clipboard"), nil
+	}
+)
+
+// EnableDetach makes ClearAfter clear the clipboard from a detached process, so the
+// caller doesn't have to wait for the timeout.
+//
+// The current executable is started again with the clearerEnv variable set, it must
+// call RunClearer when IsClearer returns true.
+func EnableDetach() {
+	detach = true
+}
+
+// IsClearer returns whether the current process was started to clear the clipboard.
+func IsClearer() bool {
+	return os.Getenv(clearerEnv) != ""
+}
+
+// ClearAfter clears the clipboard once the timeout expires if it still holds the content,
+// in other words, if nothing else was copied in the meantime. Clearers are cancelled
+// when Kure copies something new.
+//
+// The clipboard is cleared from a detached process if it's enabled, otherwise it blocks
+// until the timeout expires.
+func ClearAfter(timeout time.Duration, content string) error {
+	hash := hashContent(content)
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+
+	// Register the clearer before starting it, replacing (cancelling) the previous one
+	if err := writeState(token); err != nil {
+		return errors.Wrap(err, "registering clipboard clearer")
+	}
+
+	if detach {
+		if err := startClearer(timeout, token, hash); err == nil {
+			return nil
+		}
+	}
+
+	sig.Signal.AddCleanup(func() error { return clearOwned(token, hash) })
+	return wait(timeout, token, hash)
+}
+
+// RunClearer reads the timeout, token and hash from r and clears the clipboard
+// when the timeout expires, if it wasn't cancelled and the clipboard still holds the content.
+func RunClearer(r io.Reader) error {
+	// The clearer shares the terminal process group, make sure it outlives the parent
+	signal.Ignore(os.Interrupt, syscall.SIGHUP)
+
+	scanner := bufio.NewScanner(r)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.Wrap(err, "reading clearer input")
+	}
+	if len(lines) != 3 {
+		return errors.New("invalid clearer input")
+	}
+
+	timeout, err := time.ParseDuration(lines[0])
+	if err != nil {
+		return errors.Wrap(err, "parsing timeout")
+	}
+
+	return wait(timeout, lines[1], lines[2])
+}
+
+// startClearer starts the current executable as a detached clearer.
+func startClearer(timeout time.Duration, token, hash string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(executable)
+	cmd.Env = append(os.Environ(), clearerEnv+"=1")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("%s\n%s\n%s\n", timeout, token, hash))
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// Release the process resources once it finishes, if we are still running
+	go cmd.Wait()
+	return nil
+}
+
+// wait blocks until the timeout expires or the clearer is cancelled.
+func wait(timeout time.Duration, token, hash string) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		if !current(token) {
+			return nil
+		}
+
+		left := time.Until(deadline)
+		if left <= 0 {
+			return clearOwned(token, hash)
+		}
+		if left > pollInterval {
+			left = pollInterval
+		}
+		<-time.After(left)
+	}
+}
+
+// clearOwned clears the clipboard if the clearer wasn't cancelled and the clipboard
+// still holds the content Kure wrote.
+func clearOwned(token, hash string) error {
+	if !current(token) {
+		return nil
+	}
+
+	owned, err := owns(hash)
+	if err != nil {
+		return err
+	}
+	if owned {
+		if err := Clear(); err != nil {
+			return err
+		}
+	}
+
+	return cancel()
+}
+
+// owns returns whether the clipboard holds the content with the hash passed.
+//
+// OSC 52 sequences can't read the clipboard, in that case it relies on
+// clearers being cancelled every time Kure writes to the clipboard.
+func owns(hash string) (bool, error) {
+	backend, err := Backend()
+	if err != nil {
+		return false, err
+	}
+
+	switch backend {
+	case OSC52, None:
+		return true, nil
+	case Auto:
+		if remote() || clipboard.Unsupported {
+			return true, nil
+		}
+	}
+
+	content, err := clipboard.ReadAll()
+	if err != nil {
+		return false, errors.Wrap(err, "reading clipboard")
+	}
+	return hashContent(content) == hash, nil
+}
+
+// current returns whether the token belongs to the latest clearer.
+func current(token string) bool {
+	path, err := statePath()
+	if err != nil {
+		return false
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return string(bytes.TrimSpace(got)) == token
+}
+
+// cancel cancels the clearer waiting to clear the clipboard, if any.
+func cancel() error {
+	path, err := statePath()
+	if err != nil {
+		// There can't be a clearer registered
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "cancelling clipboard clearer")
+	}
+	return nil
+}
+
+func writeState(token string) error {
+	path, err := statePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(token), 0600)
+}
+
+func hashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func newToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", errors.Wrap(err, "generating clearer token")
+	}
+	return hex.EncodeToString(token), nil
+}
@@ -0,0 +1,120 @@
+package clip
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GGP1/kure/config"
+)
+
+func setupClearer(t *testing.T) *fakeTerminal {
+	t.Helper()
+	config.Set(BackendKey, OSC52)
+	t.Setenv("TMUX", "")
+	t.Setenv("TERM", "xterm")
+	t.Setenv("STY", "")
+
+	tty := &fakeTerminal{}
+	openTerminal = func() (io.WriteCloser, error) { return tty, nil }
+
+	path := filepath.Join(t.TempDir(), "clipboard")
+	statePath = func() (string, error) { return path, nil }
+
+	t.Cleanup(config.Reset)
+	return tty
+}
+
+func TestClearAfter(t *testing.T) {
+	tty := setupClearer(t)
+
+	if err := Write("kure"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ClearAfter(time.Millisecond, "kure"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := sequence("kure", "") + sequence("", "")
+	if got := tty.String(); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	path, _ := statePath()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected the clearer to be unregistered")
+	}
+}
+
+func TestClearAfterCancelled(t *testing.T) {
+	tty := setupClearer(t)
+
+	done := make(chan error, 1)
+	go func() { done <- ClearAfter(time.Minute, "kure") }()
+
+	// Wait for the clearer to be registered
+	path, _ := statePath()
+	for i := 0; readToken(t, path) == ""; i++ {
+		if i == 100 {
+			t.Fatal("Clearer not registered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := Write("other"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the clearer to be cancelled")
+	}
+
+	if got := tty.String(); got != sequence("other", "") {
+		t.Errorf("Expected the clipboard not to be cleared, got %q", got)
+	}
+}
+
+func TestRunClearer(t *testing.T) {
+	tty := setupClearer(t)
+
+	if err := writeState("token"); err != nil {
+		t.Fatal(err)
+	}
+
+	input := strings.NewReader("1ms\ntoken\n" + hashContent("kure") + "\n")
+	if err := RunClearer(input); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := tty.String(); got != sequence("", "") {
+		t.Errorf("Expected the clipboard to be cleared, got %q", got)
+	}
+}
+
+func TestRunClearerInvalid(t *testing.T) {
+	setupClearer(t)
+
+	cases := []string{"", "1ms\ntoken\n", "invalid\ntoken\nhash\n"}
+	for _, input := range cases {
+		if err := RunClearer(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected an error with input %q", input)
+		}
+	}
+}
+
+func readToken(t *testing.T, path string) string {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return string(got)
+}
@@ -0,0 +1,154 @@
+// Package clip writes to the clipboard using the backend selected in the configuration.
+//
+// Besides the system clipboard, it supports OSC 52 escape sequences, which make the terminal
+// emulator set the clipboard. They work over SSH, inside containers and in tmux or screen
+// sessions, where the system clipboard utilities are usually unavailable.
+package clip
+
+import (
+	"encoding/base64"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/GGP1/kure/config"
+
+	"github.com/atotto/clipboard"
+	"github.com/pkg/errors"
+)
+
+// BackendKey is the configuration key used to select the clipboard backend.
+const BackendKey = "clipboard.backend"
+
+// Clipboard backends.
+const (
+	Auto   = "auto"
+	System = "system"
+	OSC52  = "osc52"
+	None   = "none"
+)
+
+// screenChunk is the maximum number of bytes screen passes through in a single sequence.
+const screenChunk = 76
+
+// ErrDisabled is returned when writing to the clipboard with the "none" backend.
+var ErrDisabled = errors.New("the clipboard is disabled, see the clipboard.backend configuration key")
+
+// openTerminal opens the controlling terminal for writing.
+var openTerminal = func() (io.WriteCloser, error) {
+	name := "/dev/tty"
+	if runtime.GOOS == "windows" {
+		name = "CONOUT$"
+	}
+	return os.OpenFile(name, os.O_WRONLY, 0)
+}
+
+// Write copies the content to the clipboard, cancelling the clearer of the previous content.
+func Write(content string) error {
+	backend, err := Backend()
+	if err != nil {
+		return err
+	}
+
+	if err := cancel(); err != nil {
+		return err
+	}
+
+	switch backend {
+	case System:
+		return clipboard.WriteAll(content)
+	case OSC52:
+		return writeOSC52(content)
+	case None:
+		return ErrDisabled
+	}
+
+	// Auto: prefer the system clipboard unless we are in a remote session or it's unavailable
+	if !remote() && !clipboard.Unsupported {
+		if err := clipboard.WriteAll(content); err == nil {
+			return nil
+		}
+	}
+	return writeOSC52(content)
+}
+
+// Clear empties the clipboard. It does nothing if the clipboard is disabled.
+func Clear() error {
+	if err := Write(""); err != nil && err != ErrDisabled {
+		return err
+	}
+	return nil
+}
+
+// Backend returns the clipboard backend set in the configuration, "auto" by default.
+func Backend() (string, error) {
+	backend := strings.ToLower(strings.TrimSpace(config.GetString(BackendKey)))
+	switch backend {
+	case "":
+		return Auto, nil
+	case Auto, System, OSC52, None:
+		return backend, nil
+	default:
+		return "", errors.Errorf("invalid %s: %q, expected auto, system, osc52 or none", BackendKey, backend)
+	}
+}
+
+// remote returns whether kure is running inside an SSH session.
+func remote() bool {
+	for _, env := range []string{"SSH_CONNECTION", "SSH_CLIENT", "SSH_TTY"} {
+		if os.Getenv(env) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func writeOSC52(content string) error {
+	tty, err := openTerminal()
+	if err != nil {
+		return errors.Wrap(err, "opening terminal")
+	}
+
+	seq := sequence(content, multiplexer())
+	if _, err := io.WriteString(tty, seq); err != nil {
+		tty.Close()
+		return errors.Wrap(err, "writing OSC 52 sequence")
+	}
+	return tty.Close()
+}
+
+// multiplexer returns the terminal multiplexer kure is running in, if any.
+func multiplexer() string {
+	if os.Getenv("TMUX") != "" {
+		return "tmux"
+	}
+	if strings.HasPrefix(os.Getenv("TERM"), "screen") || os.Getenv("STY") != "" {
+		return "screen"
+	}
+	return ""
+}
+
+// sequence returns the OSC 52 sequence that sets the clipboard content, wrapped to pass
+// through the multiplexer. An empty content clears the clipboard.
+func sequence(content, mux string) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(content)) + "\a"
+
+	switch mux {
+	case "tmux":
+		// Escape characters inside the passthrough must be doubled
+		return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+
+	case "screen":
+		// screen truncates long sequences, split them into multiple passthroughs
+		var sb strings.Builder
+		for len(seq) > screenChunk {
+			sb.WriteString("\x1bP" + seq[:screenChunk] + "\x1b\\")
+			seq = seq[screenChunk:]
+		}
+		sb.WriteString("\x1bP" + seq + "\x1b\\")
+		return sb.String()
+	}
+
+	return seq
+}
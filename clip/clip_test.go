@@ -0,0 +1,111 @@
+package clip
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/GGP1/kure/config"
+)
+
+type fakeTerminal struct {
+	bytes.Buffer
+}
+
+func (fakeTerminal) Close() error { return nil }
+
+func TestSequence(t *testing.T) {
+	cases := []struct {
+		desc     string
+		content  string
+		mux      string
+		expected string
+	}{
+		{
+			desc:     "Plain",
+			content:  "kure",
+			expected: "\x1b]52;c;a3VyZQ==\a",
+		},
+		{
+			desc:     "Clear",
+			content:  "",
+			expected: "\x1b]52;c;\a",
+		},
+		{
+			desc:     "tmux",
+			content:  "kure",
+			mux:      "tmux",
+			expected: "\x1bPtmux;\x1b\x1b]52;c;a3VyZQ==\a\x1b\\",
+		},
+		{
+			desc:     "screen",
+			content:  "kure",
+			mux:      "screen",
+			expected: "\x1bP\x1b]52;c;a3VyZQ==\a\x1b\\",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := sequence(tc.content, tc.mux)
+			if got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestSequenceScreenChunks(t *testing.T) {
+	content := string(bytes.Repeat([]byte("a"), 200))
+	got := sequence(content, "screen")
+
+	chunks := bytes.Count([]byte(got), []byte("\x1bP"))
+	expected := (len(sequence(content, "")) + screenChunk - 1) / screenChunk
+	if chunks != expected {
+		t.Errorf("Expected %d chunks, got %d", expected, chunks)
+	}
+}
+
+func TestWriteOSC52(t *testing.T) {
+	defer config.Reset()
+	config.Set(BackendKey, OSC52)
+	t.Setenv("TMUX", "")
+	t.Setenv("TERM", "xterm")
+	t.Setenv("STY", "")
+
+	tty := &fakeTerminal{}
+	openTerminal = func() (io.WriteCloser, error) { return tty, nil }
+
+	if err := Write("kure"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Clear(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "\x1b]52;c;a3VyZQ==\a\x1b]52;c;\a"
+	if got := tty.String(); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestNone(t *testing.T) {
+	defer config.Reset()
+	config.Set(BackendKey, None)
+
+	if err := Write("kure"); err != ErrDisabled {
+		t.Errorf("Expected %v, got %v", ErrDisabled, err)
+	}
+	if err := Clear(); err != nil {
+		t.Errorf("Expected clearing a disabled clipboard to do nothing, got %v", err)
+	}
+}
+
+func TestInvalidBackend(t *testing.T) {
+	defer config.Reset()
+	config.Set(BackendKey, "invalid")
+
+	if err := Write("kure"); err == nil {
+		t.Error("Expected an error and got nil")
+	}
+}
@@ -7,19 +7,19 @@ import (
 	"encoding/base32"
 	"encoding/binary"
 	"fmt"
+	"io"
 	"math"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/GGP1/kure/auth"
 	cmdutil "github.com/GGP1/kure/commands"
 	"github.com/GGP1/kure/commands/2fa/add"
+	importt "github.com/GGP1/kure/commands/2fa/import"
 	"github.com/GGP1/kure/commands/2fa/rm"
 	"github.com/GGP1/kure/db/totp"
 	"github.com/GGP1/kure/orderedmap"
 	"github.com/GGP1/kure/pb"
-	"github.com/GGP1/kure/tree"
 
 	"github.com/spf13/cobra"
 	bolt "go.etcd.io/bbolt"
@@ -41,7 +41,7 @@ type tfaOptions struct {
 }
 
 // NewCmd returns a new command.
-func NewCmd(db *bolt.DB) *cobra.Command {
+func NewCmd(db *bolt.DB, r io.Reader) *cobra.Command {
 	opts := tfaOptions{}
 
 	cmd := &cobra.Command{
@@ -54,13 +54,9 @@ Use the [-i info] flag to display information about the setup key, it also gener
 		Args:    cmdutil.MustExistLs(db, cmdutil.TOTP),
 		PreRunE: auth.Login(db),
 		RunE:    run2FA(db, &opts),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			// Reset variables (session)
-			opts = tfaOptions{}
-		},
 	}
 
-	cmd.AddCommand(add.NewCmd(db, os.Stdin), rm.NewCmd(db, os.Stdin))
+	cmd.AddCommand(add.NewCmd(db, r), importt.NewCmd(db), rm.NewCmd(db, r))
 
 	f := cmd.Flags()
 	f.BoolVarP(&opts.copy, "copy", "c", false, "copy code to clipboard")
@@ -81,8 +77,12 @@ func run2FA(db *bolt.DB, opts *tfaOptions) cmdutil.RunEFunc {
 				return err
 			}
 
-			tree.Print(totps)
-			return nil
+			return cmdutil.PrintNames(cmd, totps)
+		}
+
+		format, err := cmdutil.OutputFormat(cmd)
+		if err != nil {
+			return err
 		}
 
 		t, err := totp.Get(db, name)
@@ -91,7 +91,15 @@ func run2FA(db *bolt.DB, opts *tfaOptions) cmdutil.RunEFunc {
 		}
 
 		if opts.info {
-			return printKeyInfo(t)
+			if format != cmdutil.OutputDefault {
+				return cmdutil.WriteOutput(cmd.OutOrStdout(), format, &cmdutil.KeyInfoOutput{
+					Name:   t.Name,
+					URL:    keyURL(t),
+					Key:    t.Raw,
+					Digits: t.Digits,
+				})
+			}
+			return printKeyInfo(cmd.OutOrStdout(), t)
 		}
 
 		code := GenerateTOTP(t.Raw, time.Now(), int(t.Digits))
@@ -99,7 +107,11 @@ func run2FA(db *bolt.DB, opts *tfaOptions) cmdutil.RunEFunc {
 			return cmdutil.WriteClipboard(cmd, opts.timeout, "TOTP", code)
 		}
 
-		fmt.Println(strings.Title(t.Name), code)
+		if format != cmdutil.OutputDefault {
+			return cmdutil.WriteOutput(cmd.OutOrStdout(), format, &cmdutil.TOTPOutput{Name: t.Name, Code: code})
+		}
+
+		fmt.Fprintln(cmd.OutOrStdout(), strings.Title(t.Name), code)
 		return nil
 	}
 }
@@ -132,11 +144,16 @@ func GenerateTOTP(key string, t time.Time, digits int) string {
 	return fmt.Sprintf(format, mod)
 }
 
-func printKeyInfo(t *pb.TOTP) error {
+// keyURL returns the setup key in URL format.
+func keyURL(t *pb.TOTP) string {
 	// https://github.com/google/google-authenticator/wiki/Key-Uri-Format
-	URL := fmt.Sprintf("otpauth://totp/%s?secret=%s&digits=%d", strings.Title(t.Name), t.Raw, t.Digits)
+	return fmt.Sprintf("otpauth://totp/%s?secret=%s&digits=%d", strings.Title(t.Name), t.Raw, t.Digits)
+}
+
+func printKeyInfo(w io.Writer, t *pb.TOTP) error {
+	URL := keyURL(t)
 
-	if err := cmdutil.DisplayQRCode(URL); err != nil {
+	if err := cmdutil.DisplayQRCode(w, URL); err != nil {
 		return err
 	}
 	mp := orderedmap.New()
@@ -145,6 +162,6 @@ func printKeyInfo(t *pb.TOTP) error {
 	mp.Set("Digits", fmt.Sprint(t.Digits))
 
 	box := cmdutil.BuildBox(t.Name, mp)
-	fmt.Println(box)
+	fmt.Fprintln(w, box)
 	return nil
 }
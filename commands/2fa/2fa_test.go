@@ -54,7 +54,7 @@ func Test2FA(t *testing.T) {
 		},
 	}
 
-	cmd := NewCmd(db)
+	cmd := NewCmd(db, nil)
 	config.Set("clipboard.timeout", "1ns") // Set default
 
 	for _, tc := range cases {
@@ -85,7 +85,7 @@ func Test2FAErrors(t *testing.T) {
 		},
 	}
 
-	cmd := NewCmd(db)
+	cmd := NewCmd(db, nil)
 
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
@@ -133,10 +133,6 @@ func TestGenerateTOTP(t *testing.T) {
 	}
 }
 
-func TestPostRun(t *testing.T) {
-	NewCmd(nil).PostRun(nil, nil)
-}
-
 func createElements(t *testing.T, db *bolt.DB) {
 	t.Helper()
 	if err := entry.Create(db, &pb.Entry{Name: "test"}); err != nil {
@@ -53,11 +53,6 @@ func NewCmd(db *bolt.DB, r io.Reader) *cobra.Command {
 		},
 		PreRunE: auth.Login(db),
 		RunE:    runAdd(db, r, &opts),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			opts = addOptions{
-				digits: 6,
-			}
-		},
 	}
 
 	f := cmd.Flags()
@@ -73,33 +68,28 @@ func runAdd(db *bolt.DB, r io.Reader, opts *addOptions) cmdutil.RunEFunc {
 		name = cmdutil.NormalizeName(name)
 
 		if opts.url {
-			return addWithURL(db, r)
+			return addWithURL(db, r, cmd.OutOrStdout())
 		}
 
-		return addWithKey(db, r, name, opts.digits)
+		return addWithKey(db, r, cmd.OutOrStdout(), name, opts.digits)
 	}
 }
 
-func addWithKey(db *bolt.DB, r io.Reader, name string, digits int32) error {
+func addWithKey(db *bolt.DB, r io.Reader, w io.Writer, name string, digits int32) error {
 	if digits < 6 || digits > 8 {
 		return errors.Errorf("invalid digits number [%d], it must be either 6, 7 or 8", digits)
 	}
 
-	key := cmdutil.Scanln(bufio.NewReader(r), "Key")
-	// Adjust key
-	key = strings.ReplaceAll(key, " ", "")
-	key += strings.Repeat("=", -len(key)&7)
-	key = strings.ToUpper(key)
-
-	if _, err := base32.StdEncoding.DecodeString(key); err != nil {
+	key, err := cmdutil.NormalizeTOTPKey(cmdutil.Scanln(bufio.NewReader(r), "Key"))
+	if err != nil {
 		return errors.Wrap(err, "invalid key")
 	}
 
-	return createTOTP(db, name, key, digits)
+	return createTOTP(db, w, name, key, digits)
 }
 
 // addWithURL creates a new TOTP using the values passed in the url.
-func addWithURL(db *bolt.DB, r io.Reader) error {
+func addWithURL(db *bolt.DB, r io.Reader, w io.Writer) error {
 	uri := cmdutil.Scanln(bufio.NewReader(r), "URL")
 	URL, err := url.Parse(uri)
 	if err != nil {
@@ -122,10 +112,10 @@ func addWithURL(db *bolt.DB, r io.Reader) error {
 		return errors.Wrap(err, "invalid secret")
 	}
 
-	return createTOTP(db, name, secret, digits)
+	return createTOTP(db, w, name, secret, digits)
 }
 
-func createTOTP(db *bolt.DB, name, key string, digits int32) error {
+func createTOTP(db *bolt.DB, w io.Writer, name, key string, digits int32) error {
 	t := &pb.TOTP{
 		Name:   name,
 		Raw:    key,
@@ -136,7 +126,7 @@ func createTOTP(db *bolt.DB, name, key string, digits int32) error {
 		return err
 	}
 
-	fmt.Printf("\n%q TOTP added\n", name)
+	fmt.Fprintf(w, "\n%q TOTP added\n", name)
 	return nil
 }
 
@@ -2,6 +2,7 @@ package add
 
 import (
 	"bytes"
+	"io"
 	"net/url"
 	"testing"
 
@@ -66,7 +67,7 @@ func TestAddErrors(t *testing.T) {
 	db := cmdutil.SetContext(t, "../../../db/testdata/database")
 
 	name := "test"
-	if err := createTOTP(db, name, "", 0); err != nil {
+	if err := createTOTP(db, io.Discard, name, "", 0); err != nil {
 		t.Fatal(err)
 	}
 
@@ -135,7 +136,7 @@ func TestCreateTOTP(t *testing.T) {
 
 	t.Run("Success", func(t *testing.T) {
 		name := "test"
-		if err := createTOTP(db, name, "secret", 6); err != nil {
+		if err := createTOTP(db, io.Discard, name, "secret", 6); err != nil {
 			t.Fatalf("Failed creating TOTP: %v", err)
 		}
 
@@ -145,7 +146,7 @@ func TestCreateTOTP(t *testing.T) {
 	})
 
 	t.Run("Fail", func(t *testing.T) {
-		if err := createTOTP(db, "", "", 0); err == nil {
+		if err := createTOTP(db, io.Discard, "", "", 0); err == nil {
 			t.Error("Expected an error and got nil")
 		}
 	})
@@ -261,7 +262,3 @@ func TestValidateURL(t *testing.T) {
 		}
 	})
 }
-
-func TestPostRun(t *testing.T) {
-	NewCmd(nil, nil).PostRun(nil, nil)
-}
@@ -0,0 +1,369 @@
+package importt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// formats maps the formats supported to the functions extracting their keys.
+var formats = map[string]func(data []byte) ([]otpKey, error){
+	"aegis":   parseAegis,
+	"andotp":  parseAndOTP,
+	"freeotp": parseFreeOTP,
+	"2fas":    parse2FAS,
+	"uri":     parseURIs,
+}
+
+func parseAegis(data []byte) ([]otpKey, error) {
+	var export struct {
+		DB json.RawMessage `json:"db"`
+	}
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, errors.Wrap(err, "parsing Aegis export")
+	}
+	// The database is a base64 string when the vault is encrypted
+	if bytes.HasPrefix(export.DB, []byte(`"`)) {
+		return nil, errors.New("encrypted Aegis exports are not supported, export the vault without encryption")
+	}
+
+	var db struct {
+		Entries []struct {
+			Type   string `json:"type"`
+			Name   string `json:"name"`
+			Issuer string `json:"issuer"`
+			Info   struct {
+				Secret string `json:"secret"`
+				Algo   string `json:"algo"`
+				Digits int    `json:"digits"`
+				Period int    `json:"period"`
+			} `json:"info"`
+		} `json:"entries"`
+	}
+	if err := json.Unmarshal(export.DB, &db); err != nil {
+		return nil, errors.Wrap(err, "parsing Aegis export")
+	}
+
+	keys := make([]otpKey, len(db.Entries))
+	for i, e := range db.Entries {
+		keys[i] = otpKey{
+			issuer:    e.Issuer,
+			account:   e.Name,
+			secret:    e.Info.Secret,
+			typ:       strings.ToLower(e.Type),
+			algorithm: strings.ToUpper(e.Info.Algo),
+			digits:    e.Info.Digits,
+			period:    e.Info.Period,
+		}
+	}
+	return keys, nil
+}
+
+func parseAndOTP(data []byte) ([]otpKey, error) {
+	var entries []struct {
+		Secret    string `json:"secret"`
+		Issuer    string `json:"issuer"`
+		Label     string `json:"label"`
+		Digits    int    `json:"digits"`
+		Type      string `json:"type"`
+		Algorithm string `json:"algorithm"`
+		Period    int    `json:"period"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, errors.Wrap(err, "parsing andOTP backup (encrypted backups are not supported)")
+	}
+
+	keys := make([]otpKey, len(entries))
+	for i, e := range entries {
+		issuer, account := e.Issuer, e.Label
+		// Old versions store the issuer in the label
+		if issuer == "" {
+			issuer, account = splitLabel(e.Label)
+		}
+		keys[i] = otpKey{
+			issuer:    issuer,
+			account:   account,
+			secret:    e.Secret,
+			typ:       strings.ToLower(e.Type),
+			algorithm: strings.ToUpper(e.Algorithm),
+			digits:    e.Digits,
+			period:    e.Period,
+		}
+	}
+	return keys, nil
+}
+
+func parseFreeOTP(data []byte) ([]otpKey, error) {
+	var backup struct {
+		Tokens []struct {
+			Algo      string `json:"algo"`
+			Digits    int    `json:"digits"`
+			IssuerExt string `json:"issuerExt"`
+			Label     string `json:"label"`
+			Period    int    `json:"period"`
+			// Secret is a list of signed bytes
+			Secret []int  `json:"secret"`
+			Type   string `json:"type"`
+		} `json:"tokens"`
+	}
+	if err := json.Unmarshal(data, &backup); err != nil {
+		return nil, errors.Wrap(err, "parsing FreeOTP+ backup")
+	}
+
+	keys := make([]otpKey, len(backup.Tokens))
+	for i, t := range backup.Tokens {
+		secret := make([]byte, len(t.Secret))
+		for j, b := range t.Secret {
+			secret[j] = byte(b)
+		}
+		keys[i] = otpKey{
+			issuer:    t.IssuerExt,
+			account:   t.Label,
+			secret:    base32.StdEncoding.EncodeToString(secret),
+			typ:       strings.ToLower(t.Type),
+			algorithm: strings.ToUpper(t.Algo),
+			digits:    t.Digits,
+			period:    t.Period,
+		}
+	}
+	return keys, nil
+}
+
+func parse2FAS(data []byte) ([]otpKey, error) {
+	var backup struct {
+		Services []struct {
+			Name   string `json:"name"`
+			Secret string `json:"secret"`
+			OTP    struct {
+				Account   string `json:"account"`
+				Issuer    string `json:"issuer"`
+				Digits    int    `json:"digits"`
+				Period    int    `json:"period"`
+				Algorithm string `json:"algorithm"`
+				TokenType string `json:"tokenType"`
+			} `json:"otp"`
+		} `json:"services"`
+		ServicesEncrypted string `json:"servicesEncrypted"`
+	}
+	if err := json.Unmarshal(data, &backup); err != nil {
+		return nil, errors.Wrap(err, "parsing 2FAS backup")
+	}
+	if len(backup.Services) == 0 && backup.ServicesEncrypted != "" {
+		return nil, errors.New("encrypted 2FAS backups are not supported, export the services without a password")
+	}
+
+	keys := make([]otpKey, len(backup.Services))
+	for i, s := range backup.Services {
+		issuer := s.OTP.Issuer
+		if issuer == "" {
+			issuer = s.Name
+		}
+		keys[i] = otpKey{
+			issuer:    issuer,
+			account:   s.OTP.Account,
+			secret:    s.Secret,
+			typ:       strings.ToLower(s.OTP.TokenType),
+			algorithm: strings.ToUpper(s.OTP.Algorithm),
+			digits:    s.OTP.Digits,
+			period:    s.OTP.Period,
+		}
+	}
+	return keys, nil
+}
+
+// parseURIs parses a list of otpauth and otpauth-migration URIs, one per line. Empty lines and
+// those starting with "#" are ignored.
+func parseURIs(data []byte) ([]otpKey, error) {
+	var keys []otpKey
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		URL, err := url.Parse(line)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", n)
+		}
+
+		switch URL.Scheme {
+		case "otpauth":
+			keys = append(keys, parseOTPAuth(URL))
+
+		case "otpauth-migration":
+			// Spaces are the result of unescaped plus signs
+			payload := strings.ReplaceAll(URL.Query().Get("data"), " ", "+")
+			data, err := base64.StdEncoding.DecodeString(payload)
+			if err != nil {
+				return nil, errors.Wrapf(err, "line %d: decoding migration data", n)
+			}
+			migrated, err := parseMigration(data)
+			if err != nil {
+				return nil, errors.Wrapf(err, "line %d", n)
+			}
+			keys = append(keys, migrated...)
+
+		default:
+			return nil, errors.Errorf("line %d: invalid scheme %q, must be otpauth or otpauth-migration", n, URL.Scheme)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading URIs")
+	}
+
+	return keys, nil
+}
+
+// parseOTPAuth extracts the key of an URL in the format otpauth://{type}/{issuer}:{account}?secret={secret}.
+func parseOTPAuth(URL *url.URL) otpKey {
+	query := URL.Query()
+	issuer, account := splitLabel(strings.TrimPrefix(URL.Path, "/"))
+	if q := query.Get("issuer"); q != "" {
+		issuer = q
+	}
+	digits, _ := strconv.Atoi(query.Get("digits"))
+	period, _ := strconv.Atoi(query.Get("period"))
+
+	return otpKey{
+		issuer:    issuer,
+		account:   account,
+		secret:    query.Get("secret"),
+		typ:       strings.ToLower(URL.Host),
+		algorithm: strings.ToUpper(query.Get("algorithm")),
+		digits:    digits,
+		period:    period,
+	}
+}
+
+// Google Authenticator migration payload enumerations.
+var (
+	migrationAlgorithms = map[uint64]string{1: "SHA1", 2: "SHA256", 3: "SHA512", 4: "MD5"}
+	migrationDigits     = map[uint64]int{1: 6, 2: 8}
+	migrationTypes      = map[uint64]string{1: "hotp", 2: "totp"}
+)
+
+// parseMigration decodes a Google Authenticator migration payload, its protocol buffers definition is:
+//
+//	message MigrationPayload {
+//		repeated OtpParameters otp_parameters = 1;
+//		int32 version = 2;
+//		int32 batch_size = 3;
+//		int32 batch_index = 4;
+//		int32 batch_id = 5;
+//	}
+func parseMigration(data []byte) ([]otpKey, error) {
+	var keys []otpKey
+	err := consumeFields(data, func(num protowire.Number, typ protowire.Type, value []byte, v uint64) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		k, err := parseOTPParameters(value)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, k)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding migration data")
+	}
+	return keys, nil
+}
+
+// parseOTPParameters decodes the parameters of a migrated key:
+//
+//	message OtpParameters {
+//		bytes secret = 1;
+//		string name = 2;
+//		string issuer = 3;
+//		Algorithm algorithm = 4;
+//		DigitCount digits = 5;
+//		OtpType type = 6;
+//		int64 counter = 7;
+//	}
+func parseOTPParameters(data []byte) (otpKey, error) {
+	var (
+		k    otpKey
+		name string
+	)
+	err := consumeFields(data, func(num protowire.Number, typ protowire.Type, value []byte, v uint64) error {
+		switch num {
+		case 1:
+			k.secret = base32.StdEncoding.EncodeToString(value)
+		case 2:
+			name = string(value)
+		case 3:
+			k.issuer = string(value)
+		case 4:
+			k.algorithm = migrationAlgorithms[v]
+		case 5:
+			k.digits = migrationDigits[v]
+		case 6:
+			k.typ = migrationTypes[v]
+		}
+		return nil
+	})
+	if err != nil {
+		return otpKey{}, err
+	}
+
+	// The name may contain the issuer as well
+	issuer, account := splitLabel(name)
+	if k.issuer == "" {
+		k.issuer = issuer
+	}
+	k.account = account
+	return k, nil
+}
+
+// consumeFields calls fn with each field of the protocol buffers message, value is set for
+// length-delimited fields and v for varints.
+func consumeFields(data []byte, fn func(num protowire.Number, typ protowire.Type, value []byte, v uint64) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		var (
+			value []byte
+			v     uint64
+		)
+		switch typ {
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(data)
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		if err := fn(num, typ, value, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitLabel splits a label in the format "issuer:account", the issuer is empty if it's not present.
+func splitLabel(label string) (issuer, account string) {
+	issuer, account, ok := strings.Cut(label, ":")
+	if !ok {
+		return "", strings.TrimSpace(label)
+	}
+	return strings.TrimSpace(issuer), strings.TrimSpace(account)
+}
@@ -0,0 +1,226 @@
+package importt
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/GGP1/kure/auth"
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/totp"
+	"github.com/GGP1/kure/pb"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+)
+
+const example = `
+* Import an Aegis export
+kure 2fa import aegis -p path/to/aegis.json
+
+* Import a file with otpauth:// or otpauth-migration:// URIs, one per line
+kure 2fa import uri -p path/to/uris.txt`
+
+type importOptions struct {
+	path string
+}
+
+// NewCmd returns a new command.
+func NewCmd(db *bolt.DB) *cobra.Command {
+	opts := importOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "import <format>",
+		Short: "Import two-factor authentication codes",
+		Long: `Import two-factor authentication codes from authenticator apps exports.
+
+Exports must be unencrypted. Only TOTPs using SHA1, a 30 seconds period and 6 to 8 digits are supported, the rest are skipped and reported.
+
+TOTPs are named after their issuer (or account if it has none), a number is appended to the name if it's already taken. Those matching an existing TOTP name and key are skipped.
+
+Formats supported:
+	• aegis: Aegis plain JSON export
+	• andotp: andOTP JSON backup
+	• freeotp: FreeOTP+ JSON backup
+	• 2fas: 2FAS JSON backup
+	• uri: a file with one otpauth:// URI per line, Google Authenticator otpauth-migration:// URIs are accepted as well`,
+		Example: example,
+		Args:    formatsSupported(),
+		PreRunE: auth.Login(db),
+		RunE:    runImport(db, &opts),
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.path, "path", "p", "", "source file path")
+
+	return cmd
+}
+
+func runImport(db *bolt.DB, opts *importOptions) cmdutil.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		format := strings.ToLower(strings.Join(args, " "))
+
+		if opts.path == "" {
+			return cmdutil.ErrInvalidPath
+		}
+
+		data, err := os.ReadFile(opts.path)
+		if err != nil {
+			return errors.Wrap(err, "reading file")
+		}
+
+		keys, err := formats[format](data)
+		if err != nil {
+			return err
+		}
+
+		imported, skipped, err := storeKeys(db, keys)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Imported %d TOTPs\n", imported)
+		if len(skipped) > 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "Skipped %d:\n", len(skipped))
+			for _, s := range skipped {
+				fmt.Fprintln(cmd.OutOrStdout(), "  ", s)
+			}
+		}
+		return nil
+	}
+}
+
+// otpKey is a one-time password extracted from an export.
+type otpKey struct {
+	issuer  string
+	account string
+	// secret is encoded in base32
+	secret string
+	// typ is the lowercase OTP type, only "totp" is supported
+	typ string
+	// algorithm is the uppercase hash function name
+	algorithm string
+	digits    int
+	period    int
+}
+
+// name returns the issuer or the account if it's empty.
+func (k otpKey) name() string {
+	if name := cmdutil.NormalizeName(k.issuer); name != "" {
+		return name
+	}
+	return cmdutil.NormalizeName(k.account)
+}
+
+// validate returns an error if Kure doesn't support the key parameters and its normalized secret otherwise.
+func (k otpKey) validate() (string, error) {
+	if k.typ != "" && k.typ != "totp" {
+		return "", errors.Errorf("unsupported type %q", k.typ)
+	}
+	if k.algorithm != "" && k.algorithm != "SHA1" {
+		return "", errors.Errorf("unsupported algorithm %q", k.algorithm)
+	}
+	if k.period != 0 && k.period != 30 {
+		return "", errors.Errorf("unsupported period of %d seconds", k.period)
+	}
+	if k.digits != 0 && (k.digits < 6 || k.digits > 8) {
+		return "", errors.Errorf("unsupported digits number %d", k.digits)
+	}
+
+	secret, err := cmdutil.NormalizeTOTPKey(k.secret)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid key")
+	}
+	return secret, nil
+}
+
+// storeKeys validates and stores the keys, it returns the number of TOTPs imported and the keys
+// skipped along with the reason.
+func storeKeys(db *bolt.DB, keys []otpKey) (int, []string, error) {
+	list, err := totp.List(db)
+	if err != nil {
+		return 0, nil, err
+	}
+	// Keys are compared by their secret as the existing ones may have been renamed
+	existing := make(map[string]struct{}, len(list))
+	secrets := make(map[string]struct{}, len(list))
+	for _, t := range list {
+		existing[t.Name] = struct{}{}
+		secrets[t.Raw] = struct{}{}
+	}
+	used := make(map[string]struct{}, len(keys))
+
+	var (
+		totps   []*pb.TOTP
+		skipped []string
+	)
+	for i, k := range keys {
+		name := k.name()
+		if name == "" {
+			skipped = append(skipped, fmt.Sprintf("#%d: missing issuer and account", i+1))
+			continue
+		}
+		// NormalizeTOTPKey accepts empty keys, which would generate codes out of nothing
+		if strings.TrimSpace(k.secret) == "" {
+			skipped = append(skipped, fmt.Sprintf("#%d (%s): missing key", i+1, name))
+			continue
+		}
+
+		secret, err := k.validate()
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+
+		if _, ok := secrets[secret]; ok {
+			skipped = append(skipped, fmt.Sprintf("%s: already exists", name))
+			continue
+		}
+		// Keys repeated within the same export are stored only once
+		secrets[secret] = struct{}{}
+		name = uniqueName(name, existing, used)
+
+		digits := int32(k.digits)
+		if digits == 0 {
+			digits = 6
+		}
+		totps = append(totps, &pb.TOTP{Name: name, Raw: secret, Digits: digits})
+	}
+
+	for _, t := range totps {
+		if err := totp.Create(db, t); err != nil {
+			return 0, nil, err
+		}
+	}
+	return len(totps), skipped, nil
+}
+
+// uniqueName returns the name passed with a number appended if it's already used by an existing
+// TOTP or a previous key, and marks it as used.
+func uniqueName(name string, existing, used map[string]struct{}) string {
+	unique := name
+	for i := 2; ; i++ {
+		_, exists := existing[unique]
+		_, isUsed := used[unique]
+		if !exists && !isUsed {
+			break
+		}
+		unique = fmt.Sprintf("%s (%d)", name, i)
+	}
+	used[unique] = struct{}{}
+	return unique
+}
+
+func formatsSupported() cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		format := strings.ToLower(strings.Join(args, " "))
+
+		if _, ok := formats[format]; !ok {
+			return errors.Errorf(`%q is not supported
+
+Formats supported: aegis, andotp, freeotp, 2fas, uri`, format)
+		}
+		return nil
+	}
+}
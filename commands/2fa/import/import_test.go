@@ -0,0 +1,223 @@
+package importt
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/totp"
+	"github.com/GGP1/kure/pb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestImport(t *testing.T) {
+	cases := []struct {
+		format   string
+		path     string
+		expected []*pb.TOTP
+		skipped  []string
+	}{
+		{
+			format: "aegis",
+			path:   "testdata/test_aegis.json",
+			expected: []*pb.TOTP{
+				{Name: "github", Raw: "JBSWY3DPEHPK3PXP", Digits: 6},
+				{Name: "github (2)", Raw: "IFGEWRKSIFJUMR2R", Digits: 8},
+			},
+			skipped: []string{`bank: unsupported algorithm "SHA256"`, `steam: unsupported type "steam"`},
+		},
+		{
+			format: "andotp",
+			path:   "testdata/test_andotp.json",
+			expected: []*pb.TOTP{
+				{Name: "github", Raw: "JBSWY3DPEHPK3PXP", Digits: 6},
+				{Name: "dropbox", Raw: "IFGEWRKSIFJUMR2R", Digits: 6},
+			},
+			skipped: []string{`counter: unsupported type "hotp"`},
+		},
+		{
+			format:   "freeotp",
+			path:     "testdata/test_freeotp.json",
+			expected: []*pb.TOTP{{Name: "github", Raw: "JBSWY3DPEHPK3PXP", Digits: 6}},
+		},
+		{
+			format:   "2fas",
+			path:     "testdata/test_2fas.json",
+			expected: []*pb.TOTP{{Name: "github", Raw: "JBSWY3DPEHPK3PXP", Digits: 6}},
+			skipped:  []string{"dropbox: invalid key: illegal base32 data at input byte 7"},
+		},
+		{
+			format: "uri",
+			path:   "testdata/test_uris.txt",
+			expected: []*pb.TOTP{
+				{Name: "github", Raw: "JBSWY3DPEHPK3PXP", Digits: 7},
+			},
+			skipped: []string{
+				"steam: unsupported period of 60 seconds",
+				"google: already exists",
+				`dropbox: unsupported type "hotp"`,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.format, func(t *testing.T) {
+			db := cmdutil.SetContext(t, "../../../db/testdata/database")
+
+			data, err := os.ReadFile(tc.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			keys, err := formats[tc.format](data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			imported, skipped, err := storeKeys(db, keys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if imported != len(tc.expected) {
+				t.Errorf("Expected %d TOTPs imported, got %d", len(tc.expected), imported)
+			}
+			if !reflect.DeepEqual(tc.skipped, skipped) {
+				t.Errorf("Expected skipped keys %q, got %q", tc.skipped, skipped)
+			}
+
+			for _, expected := range tc.expected {
+				got, err := totp.Get(db, expected.Name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !proto.Equal(expected, got) {
+					t.Errorf("Expected %v, got %v", expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestImportCollisions(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../../db/testdata/database")
+
+	if err := totp.Create(db, &pb.TOTP{Name: "github", Raw: "IFGEWRKSIFJUMR2R", Digits: 6}); err != nil {
+		t.Fatal(err)
+	}
+	if err := totp.Create(db, &pb.TOTP{Name: "dropbox", Raw: "IFGEWRKSIFJUMR2R", Digits: 6}); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewCmd(db)
+	cmd.SetArgs([]string{"andotp"})
+	cmd.Flags().Set("path", "testdata/test_andotp.json")
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Failed importing TOTPs: %v", err)
+	}
+
+	// The GitHub key is different so it's imported with another name and the Dropbox one is skipped
+	names, err := totp.ListNames(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"dropbox", "github", "github (2)"}
+	if !reflect.DeepEqual(expected, names) {
+		t.Errorf("Expected %q, got %q", expected, names)
+	}
+
+	got, err := totp.Get(db, "github (2)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Raw != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("Expected %q, got %q", "JBSWY3DPEHPK3PXP", got.Raw)
+	}
+}
+
+func TestImportTwice(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../../db/testdata/database")
+
+	// Both keys share the issuer so the second one is stored as "google (2)"
+	for i := 0; i < 2; i++ {
+		cmd := NewCmd(db)
+		cmd.SetArgs([]string{"uri"})
+		cmd.Flags().Set("path", "testdata/test_google.txt")
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Failed importing TOTPs: %v", err)
+		}
+	}
+
+	names, err := totp.ListNames(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"google", "google (2)"}
+	if !reflect.DeepEqual(expected, names) {
+		t.Errorf("Expected %q, got %q", expected, names)
+	}
+}
+
+func TestStoreKeysSkipped(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../../db/testdata/database")
+
+	keys := []otpKey{
+		{issuer: "GitHub", secret: "JBSWY3DPEHPK3PXP"},
+		{issuer: "GitLab", secret: "jbswy3dpehpk3pxp"},
+		{issuer: "Dropbox", secret: " "},
+		{account: "gopher"},
+	}
+	imported, skipped, err := storeKeys(db, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported != 1 {
+		t.Errorf("Expected 1 TOTP imported, got %d", imported)
+	}
+
+	expected := []string{"gitlab: already exists", "#3 (dropbox): missing key", "#4 (gopher): missing key"}
+	if !reflect.DeepEqual(expected, skipped) {
+		t.Errorf("Expected skipped keys %q, got %q", expected, skipped)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../../db/testdata/database")
+
+	cases := []struct {
+		desc   string
+		format string
+		path   string
+		errMsg string
+	}{
+		{desc: "Unsupported format", format: "authy", path: "testdata/test_aegis.json", errMsg: "not supported"},
+		{desc: "Invalid path", format: "aegis", path: "", errMsg: "invalid path"},
+		{desc: "Non-existent file", format: "aegis", path: "test.json", errMsg: "reading file"},
+		{desc: "Encrypted Aegis", format: "aegis", path: "testdata/test_aegis_encrypted.json", errMsg: "encrypted"},
+		{desc: "Invalid JSON", format: "andotp", path: "testdata/test_uris.txt", errMsg: "parsing andOTP backup"},
+		{desc: "Invalid URI", format: "uri", path: "testdata/test_aegis.json", errMsg: "line 1"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cmd := NewCmd(db)
+			cmd.SetArgs([]string{tc.format})
+			cmd.Flags().Set("path", tc.path)
+
+			err := cmd.Execute()
+			if err == nil {
+				t.Fatal("Expected an error and got nil")
+			}
+			if !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("Expected the error to contain %q, got %q", tc.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestParseMigrationErrors(t *testing.T) {
+	if _, err := parseMigration([]byte{0x0a, 0x05, 0x01}); err == nil {
+		t.Error("Expected an error and got nil")
+	}
+}
@@ -43,7 +43,7 @@ func runRm(db *bolt.DB, r io.Reader) cmdutil.RunEFunc {
 			return err
 		}
 
-		fmt.Printf("\n%q TOTP removed\n", name)
+		fmt.Fprintf(cmd.OutOrStdout(), "\n%q TOTP removed\n", name)
 		return nil
 	}
 }
@@ -6,8 +6,10 @@ import (
 	"io"
 	"math"
 	"strings"
+	"time"
 
 	"github.com/GGP1/kure/auth"
+	"github.com/GGP1/kure/breach"
 	cmdutil "github.com/GGP1/kure/commands"
 	"github.com/GGP1/kure/commands/add/phrase"
 	"github.com/GGP1/kure/db/entry"
@@ -25,13 +27,17 @@ const example = `
 kure add Sample -c
 
 * Add an entry generating a random password
-kure add Sample -l 27 -L 1,2,3,4,5 -i & -e / -r`
+kure add Sample -l 27 -L 1,2,3,4,5 -i & -e / -r
+
+* Add an entry using a profile from the configuration file
+kure add Sample -p bank`
 
 type addOptions struct {
 	include, exclude string
 	levels           []int
 	length           uint64
 	custom, repeat   bool
+	breachDB         string
 }
 
 // NewCmd returns a new command.
@@ -46,10 +52,6 @@ func NewCmd(db *bolt.DB, r io.Reader) *cobra.Command {
 		Args:    cmdutil.MustNotExist(db, cmdutil.Entry),
 		PreRunE: auth.Login(db),
 		RunE:    runAdd(db, r, &opts),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			// Reset variables (session)
-			opts = addOptions{}
-		},
 	}
 
 	cmd.AddCommand(phrase.NewCmd(db, r))
@@ -61,6 +63,8 @@ func NewCmd(db *bolt.DB, r io.Reader) *cobra.Command {
 	f.StringVarP(&opts.include, "include", "i", "", "characters to include in the password")
 	f.StringVarP(&opts.exclude, "exclude", "e", "", "characters to exclude from the password")
 	f.BoolVarP(&opts.repeat, "repeat", "r", false, "allow character repetition")
+	f.StringVar(&opts.breachDB, "breach-db", "", "check the custom password against a breached passwords dump")
+	cmdutil.AddProfileFlag(f)
 
 	return cmd
 }
@@ -70,7 +74,14 @@ func runAdd(db *bolt.DB, r io.Reader, opts *addOptions) cmdutil.RunEFunc {
 		name := strings.Join(args, " ")
 		name = cmdutil.NormalizeName(name)
 
+		var profile *cmdutil.GenProfile
 		if !opts.custom {
+			var err error
+			profile, err = cmdutil.UseProfile(cmd, false)
+			if err != nil {
+				return err
+			}
+
 			if opts.length < 1 || opts.length > math.MaxUint64 {
 				return cmdutil.ErrInvalidLength
 			}
@@ -79,24 +90,31 @@ func runAdd(db *bolt.DB, r io.Reader, opts *addOptions) cmdutil.RunEFunc {
 			}
 		}
 
-		e, err := entryInput(r, name, opts.custom)
+		e, err := entryInput(r, name, opts.custom, breach.Path(opts.breachDB))
 		if err != nil {
 			return err
 		}
 
 		if !opts.custom {
 			// Generate random password
-			e.Password, err = genPassword(opts)
+			e.Password, err = profile.Generate(func() (string, error) { return genPassword(opts) })
+			if err != nil {
+				return err
+			}
+
+			// Store the rules so the password can be rotated using them
+			e.GenRules, err = cmdutil.ProfileFromFlags(cmd.Flags(), false, profile).Rules()
 			if err != nil {
 				return err
 			}
 		}
 
+		e.PasswordUpdatedAt = time.Now().Unix()
 		if err := entry.Create(db, e); err != nil {
 			return err
 		}
 
-		fmt.Printf("\n%q added\n", name)
+		fmt.Fprintf(cmd.OutOrStdout(), "\n%q added\n", name)
 		return nil
 	}
 }
@@ -133,7 +151,9 @@ func genPassword(opts *addOptions) (string, error) {
 	return atoll.NewSecret(p)
 }
 
-func entryInput(r io.Reader, name string, custom bool) (*pb.Entry, error) {
+// entryInput asks for the entry fields, custom passwords are looked up in the breached passwords
+// dump if its path isn't empty.
+func entryInput(r io.Reader, name string, custom bool, breachPath string) (*pb.Entry, error) {
 	var password string
 	reader := bufio.NewReader(r)
 
@@ -149,25 +169,29 @@ func entryInput(r io.Reader, name string, custom bool) (*pb.Entry, error) {
 			return nil, errors.Wrap(err, "opening enclave")
 		}
 
+		if breachPath != "" {
+			if err := breach.Check(breachPath, pwd.Bytes()); err != nil {
+				pwd.Destroy()
+				return nil, err
+			}
+		}
+
 		password = pwd.String()
 	}
 	url := cmdutil.Scanln(reader, "URL")
 	expires := cmdutil.Scanln(reader, "Expires [dd/mm/yy]")
 	notes := cmdutil.Scanlns(reader, "Notes")
 
-	exp, err := cmdutil.FmtExpires(expires)
-	if err != nil {
-		return nil, err
-	}
-
 	entry := &pb.Entry{
 		Name:     name,
 		Username: username,
 		Password: password,
 		URL:      url,
-		Expires:  exp,
 		Notes:    notes,
 	}
+	if err := cmdutil.SetExpires(entry, expires); err != nil {
+		return nil, err
+	}
 
 	return entry, nil
 }
@@ -152,16 +152,17 @@ func TestGenPasswordErrors(t *testing.T) {
 
 func TestEntryInput(t *testing.T) {
 	expected := &pb.Entry{
-		Name:     "test",
-		Username: "username",
-		URL:      "url",
-		Notes:    "notes",
-		Expires:  "Fri, 03 May 2024 00:00:00 +0000",
+		Name:      "test",
+		Username:  "username",
+		URL:       "url",
+		Notes:     "notes",
+		Expires:   "Fri, 03 May 2024 00:00:00 +0000",
+		ExpiresAt: 1714694400,
 	}
 
 	buf := bytes.NewBufferString("username\nurl\n03/05/2024\nnotes<")
 
-	got, err := entryInput(buf, "test", false)
+	got, err := entryInput(buf, "test", false, "")
 	if err != nil {
 		t.Fatalf("Failed creating entry: %v", err)
 	}
@@ -176,11 +177,7 @@ func TestEntryInput(t *testing.T) {
 func TestInvalidExpirationTime(t *testing.T) {
 	buf := bytes.NewBufferString("username\nurl\nnotes\ninvalid<\n")
 
-	if _, err := entryInput(buf, "test", false); err == nil {
+	if _, err := entryInput(buf, "test", false, ""); err == nil {
 		t.Error("Expected an error and got nil")
 	}
 }
-
-func TestPostRun(t *testing.T) {
-	NewCmd(nil, nil).PostRun(nil, nil)
-}
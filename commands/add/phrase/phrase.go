@@ -6,6 +6,7 @@ import (
 	"io"
 	"math"
 	"strings"
+	"time"
 
 	"github.com/GGP1/kure/auth"
 	cmdutil "github.com/GGP1/kure/commands"
@@ -21,7 +22,10 @@ import (
 
 const example = `
 * Add an entry generating a random passphrase
-kure add phrase Sample -l 6 -s $ -i atoll -e admin,login --list nolist`
+kure add phrase Sample -l 6 -s $ -i atoll -e admin,login --list nolist
+
+* Add an entry using a profile from the configuration file
+kure add phrase Sample -p memorable`
 
 type phraseOptions struct {
 	list, separator string
@@ -41,12 +45,6 @@ func NewCmd(db *bolt.DB, r io.Reader) *cobra.Command {
 		Args:    cmdutil.MustNotExist(db, cmdutil.Entry),
 		PreRunE: auth.Login(db),
 		RunE:    runPhrase(db, r, &opts),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			// Reset variables (session)
-			opts = phraseOptions{
-				separator: " ",
-			}
-		},
 	}
 
 	f := cmd.Flags()
@@ -55,6 +53,7 @@ func NewCmd(db *bolt.DB, r io.Reader) *cobra.Command {
 	f.StringSliceVarP(&opts.incl, "include", "i", nil, "words to include in the passphrase")
 	f.StringSliceVarP(&opts.excl, "exclude", "e", nil, "words to exclude from the passphrase")
 	f.StringVarP(&opts.list, "list", "L", "WordList", "passphrase list used {NoList|WordList|SyllableList}")
+	cmdutil.AddProfileFlag(f)
 
 	return cmd
 }
@@ -64,6 +63,11 @@ func runPhrase(db *bolt.DB, r io.Reader, opts *phraseOptions) cmdutil.RunEFunc {
 		name := strings.Join(args, " ")
 		name = cmdutil.NormalizeName(name)
 
+		profile, err := cmdutil.UseProfile(cmd, true)
+		if err != nil {
+			return err
+		}
+
 		if opts.length < 1 || opts.length > math.MaxUint64 {
 			return cmdutil.ErrInvalidLength
 		}
@@ -73,16 +77,23 @@ func runPhrase(db *bolt.DB, r io.Reader, opts *phraseOptions) cmdutil.RunEFunc {
 			return err
 		}
 
-		e.Password, err = genPassphrase(opts)
+		e.Password, err = profile.Generate(func() (string, error) { return genPassphrase(opts) })
+		if err != nil {
+			return err
+		}
+
+		// Store the rules so the passphrase can be rotated using them
+		e.GenRules, err = cmdutil.ProfileFromFlags(cmd.Flags(), true, profile).Rules()
 		if err != nil {
 			return err
 		}
 
+		e.PasswordUpdatedAt = time.Now().Unix()
 		if err := entry.Create(db, e); err != nil {
 			return err
 		}
 
-		fmt.Printf("\n%q added\n", name)
+		fmt.Fprintf(cmd.OutOrStdout(), "\n%q added\n", name)
 		return nil
 	}
 }
@@ -95,18 +106,15 @@ func entryInput(r io.Reader, name string) (*pb.Entry, error) {
 	expires := cmdutil.Scanln(reader, "Expires [dd/mm/yy]")
 	notes := cmdutil.Scanlns(reader, "Notes")
 
-	exp, err := cmdutil.FmtExpires(expires)
-	if err != nil {
-		return nil, err
-	}
-
 	entry := &pb.Entry{
 		Name:     name,
 		Username: username,
 		URL:      url,
-		Expires:  exp,
 		Notes:    notes,
 	}
+	if err := cmdutil.SetExpires(entry, expires); err != nil {
+		return nil, err
+	}
 	return entry, nil
 }
 
@@ -128,11 +128,12 @@ func TestPhraseErrors(t *testing.T) {
 
 func TestEntryInput(t *testing.T) {
 	expected := &pb.Entry{
-		Name:     "test",
-		Username: "username",
-		URL:      "url",
-		Notes:    "notes",
-		Expires:  "Fri, 03 May 2024 00:00:00 +0000",
+		Name:      "test",
+		Username:  "username",
+		URL:       "url",
+		Notes:     "notes",
+		Expires:   "Fri, 03 May 2024 00:00:00 +0000",
+		ExpiresAt: 1714694400,
 	}
 
 	buf := bytes.NewBufferString("username\nurl\n03/05/2024\nnotes<")
@@ -156,7 +157,3 @@ func TestInvalidExpirationTime(t *testing.T) {
 		t.Error("Expected an error and got nil")
 	}
 }
-
-func TestPostRun(t *testing.T) {
-	NewCmd(nil, nil).PostRun(nil, nil)
-}
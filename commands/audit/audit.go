@@ -0,0 +1,271 @@
+package audit
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GGP1/kure/auth"
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/pb"
+	"github.com/GGP1/kure/strength"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+)
+
+const example = `
+* Audit all the entries
+kure audit
+
+* Flag passwords older than 6 months and entries expiring in the next week
+kure audit --max-age 180d --expiring 7d
+
+* Print the report in JSON format
+kure audit --output json`
+
+const day = 24 * time.Hour
+
+type auditOptions struct {
+	maxAge   time.Duration
+	expiring time.Duration
+	minScore int
+}
+
+// report contains the issues found in the entries.
+type report struct {
+	total int
+	// healthy is the number of entries without issues
+	healthy int
+	// unknownAge is the number of entries without a password change date
+	unknownAge int
+	weak       []weakPassword
+	reused     [][]string
+	old        []oldPassword
+	expired    []expiry
+	expiring   []expiry
+}
+
+type weakPassword struct {
+	name   string
+	result strength.Result
+}
+
+type oldPassword struct {
+	name string
+	age  time.Duration
+}
+
+type expiry struct {
+	name    string
+	expires time.Time
+}
+
+// NewCmd returns a new command.
+func NewCmd(db *bolt.DB) *cobra.Command {
+	opts := auditOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Audit the entries passwords",
+		Long: `Audit the entries passwords.
+
+The following issues are reported:
+• Weak: passwords with a strength score lower than --min-score, from 0 (very weak) to 4 (very strong).
+• Reused: groups of entries sharing the same password.
+• Old: passwords that weren't changed in --max-age. Entries created before Kure recorded the date of the last password change have an unknown age and are not flagged.
+• Expired: entries whose expiration date has passed.
+• Expiring: entries that expire within --expiring.
+
+Periods accept days (30d) as well as hours, minutes and seconds (720h).
+
+Passwords are compared using a keyed hash (HMAC-SHA256) with a random key generated on every execution, the plaintext passwords are never compared nor printed.
+
+The summary score is the percentage of entries without issues.`,
+		Example: example,
+		Args:    cobra.NoArgs,
+		PreRunE: auth.Login(db),
+		RunE:    runAudit(db, &opts),
+	}
+
+	f := cmd.Flags()
+	f.Var(cmdutil.NewDurationValue(&opts.maxAge, 365*day), "max-age", "maximum password age")
+	f.Var(cmdutil.NewDurationValue(&opts.expiring, 30*day), "expiring", "report entries expiring within this period")
+	f.IntVar(&opts.minScore, "min-score", 2, "minimum strength score, from 0 to 4")
+
+	return cmd
+}
+
+func runAudit(db *bolt.DB, opts *auditOptions) cmdutil.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		format, err := cmdutil.OutputFormat(cmd)
+		if err != nil {
+			return err
+		}
+
+		if opts.minScore < 0 || opts.minScore > strength.MaxScore {
+			return errors.Errorf("invalid minimum score, it must be between 0 and %d", strength.MaxScore)
+		}
+
+		entries, err := entry.List(db)
+		if err != nil {
+			return err
+		}
+
+		r, err := audit(entries, opts, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if format != cmdutil.OutputDefault {
+			return cmdutil.WriteOutput(cmd.OutOrStdout(), format, r.output())
+		}
+
+		r.print(cmd.OutOrStdout(), opts)
+		return nil
+	}
+}
+
+// audit looks for weak, reused, old and expired passwords.
+func audit(entries []*pb.Entry, opts *auditOptions, now time.Time) (*report, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, errors.Wrap(err, "generating hash key")
+	}
+
+	r := &report{total: len(entries)}
+	issues := make(map[string]bool, len(entries))
+	groups := make(map[string][]string)
+
+	for _, e := range entries {
+		if e.Password != "" {
+			h := hmac.New(sha256.New, key)
+			h.Write([]byte(e.Password))
+			sum := string(h.Sum(nil))
+			groups[sum] = append(groups[sum], e.Name)
+
+			if res := strength.Estimate(e.Password); res.Score < opts.minScore {
+				r.weak = append(r.weak, weakPassword{name: e.Name, result: res})
+				issues[e.Name] = true
+			}
+		}
+
+		if e.PasswordUpdatedAt == 0 {
+			r.unknownAge++
+		} else if age := now.Sub(time.Unix(e.PasswordUpdatedAt, 0)); opts.maxAge > 0 && age > opts.maxAge {
+			r.old = append(r.old, oldPassword{name: e.Name, age: age})
+			issues[e.Name] = true
+		}
+
+		if expires, ok := cmdutil.EntryExpiration(e); ok {
+			switch {
+			case !expires.After(now):
+				r.expired = append(r.expired, expiry{name: e.Name, expires: expires})
+				issues[e.Name] = true
+			case expires.Sub(now) <= opts.expiring:
+				r.expiring = append(r.expiring, expiry{name: e.Name, expires: expires})
+			}
+		}
+	}
+
+	for _, names := range groups {
+		if len(names) < 2 {
+			continue
+		}
+		sort.Strings(names)
+		r.reused = append(r.reused, names)
+		for _, name := range names {
+			issues[name] = true
+		}
+	}
+	sort.Slice(r.reused, func(i, j int) bool { return r.reused[i][0] < r.reused[j][0] })
+
+	r.healthy = r.total - len(issues)
+	return r, nil
+}
+
+// score returns the percentage of entries without issues.
+func (r *report) score() int {
+	if r.total == 0 {
+		return 100
+	}
+	return r.healthy * 100 / r.total
+}
+
+func (r *report) print(w io.Writer, opts *auditOptions) {
+	fmt.Fprintf(w, "Weak passwords: %d\n", len(r.weak))
+	for _, p := range r.weak {
+		line := fmt.Sprintf("  %s - %s", p.name, p.result.Label())
+		if len(p.result.Patterns) > 0 {
+			line += fmt.Sprintf(" (%s)", strings.Join(p.result.Patterns, ", "))
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	fmt.Fprintf(w, "\nReused passwords: %d\n", len(r.reused))
+	for _, names := range r.reused {
+		fmt.Fprintln(w, " ", strings.Join(names, ", "))
+	}
+
+	fmt.Fprintf(w, "\nPasswords older than %d days: %d\n", int(opts.maxAge/day), len(r.old))
+	for _, p := range r.old {
+		fmt.Fprintf(w, "  %s - %d days\n", p.name, int(p.age/day))
+	}
+	if r.unknownAge > 0 {
+		fmt.Fprintf(w, "  %d entries have an unknown password age\n", r.unknownAge)
+	}
+
+	fmt.Fprintf(w, "\nExpired: %d\n", len(r.expired))
+	for _, e := range r.expired {
+		fmt.Fprintf(w, "  %s - %s\n", e.name, e.expires.Format("02/01/2006"))
+	}
+
+	fmt.Fprintf(w, "\nExpiring in the next %d days: %d\n", int(opts.expiring/day), len(r.expiring))
+	for _, e := range r.expiring {
+		fmt.Fprintf(w, "  %s - %s\n", e.name, e.expires.Format("02/01/2006"))
+	}
+
+	fmt.Fprintf(w, "\nScore: %d/100 (%d of %d entries without issues)\n", r.score(), r.healthy, r.total)
+}
+
+func (r *report) output() *cmdutil.AuditOutput {
+	out := &cmdutil.AuditOutput{
+		Score:      r.score(),
+		Total:      r.total,
+		Healthy:    r.healthy,
+		UnknownAge: r.unknownAge,
+		Weak:       make([]cmdutil.AuditWeak, 0, len(r.weak)),
+		Reused:     make([][]string, 0, len(r.reused)),
+		Old:        make([]cmdutil.AuditOld, 0, len(r.old)),
+		Expired:    make([]cmdutil.AuditExpiry, 0, len(r.expired)),
+		Expiring:   make([]cmdutil.AuditExpiry, 0, len(r.expiring)),
+	}
+
+	for _, p := range r.weak {
+		out.Weak = append(out.Weak, cmdutil.AuditWeak{
+			Name:     p.name,
+			Score:    p.result.Score,
+			Strength: p.result.Label(),
+			Patterns: append([]string{}, p.result.Patterns...),
+		})
+	}
+	out.Reused = append(out.Reused, r.reused...)
+	for _, p := range r.old {
+		out.Old = append(out.Old, cmdutil.AuditOld{Name: p.name, AgeDays: int(p.age / day)})
+	}
+	for _, e := range r.expired {
+		out.Expired = append(out.Expired, cmdutil.AuditExpiry{Name: e.name, Expires: e.expires.Format(time.RFC3339)})
+	}
+	for _, e := range r.expiring {
+		out.Expiring = append(out.Expiring, cmdutil.AuditExpiry{Name: e.name, Expires: e.expires.Format(time.RFC3339)})
+	}
+
+	return out
+}
@@ -0,0 +1,120 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/pb"
+)
+
+func TestAudit(t *testing.T) {
+	now := time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC)
+	strong := "Ud*8Lm#2qZ!x9Rv@4Tn"
+	entries := []*pb.Entry{
+		{Name: "healthy", Password: "v8#Kq2!mZr@9Lx$4Wp", Expires: "Never", PasswordUpdatedAt: now.Unix()},
+		{Name: "weak", Password: "password1", Expires: "Never", PasswordUpdatedAt: now.Unix()},
+		{Name: "reused/a", Password: strong, Expires: "Never", PasswordUpdatedAt: now.Unix()},
+		{Name: "reused/b", Password: strong, Expires: "Never", PasswordUpdatedAt: now.Unix()},
+		{Name: "old", Password: "Jp4@Wc9!tYe#2Qs&Mh", Expires: "Never", PasswordUpdatedAt: now.AddDate(-2, 0, 0).Unix()},
+		{Name: "expired", Password: "Bn7$Xd3!kRw@8Zf%Lq", Expires: now.AddDate(0, 0, -1).Format(time.RFC1123Z)},
+		{Name: "expiring", Password: "Gt5#Hy1!pVs@6Ck^Nm", Expires: now.AddDate(0, 0, 10).Format(time.RFC1123Z)},
+	}
+
+	opts := &auditOptions{maxAge: 365 * day, expiring: 30 * day, minScore: 2}
+	r, err := audit(entries, opts, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.weak) != 1 || r.weak[0].name != "weak" {
+		t.Errorf("Expected weak to be reported, got %+v", r.weak)
+	}
+	if expected := [][]string{{"reused/a", "reused/b"}}; !reflect.DeepEqual(r.reused, expected) {
+		t.Errorf("Expected reused %v, got %v", expected, r.reused)
+	}
+	if len(r.old) != 1 || r.old[0].name != "old" {
+		t.Errorf("Expected old to be reported, got %+v", r.old)
+	}
+	if len(r.expired) != 1 || r.expired[0].name != "expired" {
+		t.Errorf("Expected expired to be reported, got %+v", r.expired)
+	}
+	if len(r.expiring) != 1 || r.expiring[0].name != "expiring" {
+		t.Errorf("Expected expiring to be reported, got %+v", r.expiring)
+	}
+	if r.unknownAge != 2 {
+		t.Errorf("Expected 2 entries with unknown age, got %d", r.unknownAge)
+	}
+
+	// Expiring soon is a warning, not an issue
+	if r.healthy != 2 {
+		t.Errorf("Expected 2 healthy entries, got %d", r.healthy)
+	}
+	if score := r.score(); score != 28 {
+		t.Errorf("Expected score 28, got %d", score)
+	}
+}
+
+func TestAuditEmpty(t *testing.T) {
+	r, err := audit(nil, &auditOptions{}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if score := r.score(); score != 100 {
+		t.Errorf("Expected score 100, got %d", score)
+	}
+}
+
+func TestAuditCmd(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+	err := entry.Create(db,
+		&pb.Entry{Name: "a", Password: "123456", Expires: "Never"},
+		&pb.Entry{Name: "b", Password: "123456", Expires: "Never"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	cmd := NewCmd(db)
+	cmdutil.AddOutputFlag(cmd)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--output", "json", "--max-age", "180d", "--expiring", "7d"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got cmdutil.AuditOutput
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Total != 2 || got.Healthy != 0 || got.Score != 0 {
+		t.Errorf("Expected 2 entries with issues, got %+v", got)
+	}
+	if expected := [][]string{{"a", "b"}}; !reflect.DeepEqual(got.Reused, expected) {
+		t.Errorf("Expected reused %v, got %v", expected, got.Reused)
+	}
+	if len(got.Weak) != 2 {
+		t.Errorf("Expected 2 weak passwords, got %d", len(got.Weak))
+	}
+	if bytes.Contains(buf.Bytes(), []byte("123456")) {
+		t.Error("The output contains a password")
+	}
+}
+
+func TestAuditCmdInvalidScore(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+	cmd := NewCmd(db)
+	cmd.SetArgs([]string{"--min-score", "5"})
+	if err := cmd.Execute(); err == nil {
+		t.Error("Expected an error and got nil")
+	}
+}
@@ -49,12 +49,6 @@ func NewCmd(db *bolt.DB) *cobra.Command {
 		Example: example,
 		PreRunE: auth.Login(db),
 		RunE:    opts.runBackup(db),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			// Reset variables (session)
-			opts = backupOptions{
-				port: 8080,
-			}
-		},
 	}
 
 	f := cmd.Flags()
@@ -68,15 +62,15 @@ func NewCmd(db *bolt.DB) *cobra.Command {
 func (opts *backupOptions) runBackup(db *bolt.DB) cmdutil.RunEFunc {
 	return func(cmd *cobra.Command, args []string) error {
 		if opts.httpB {
-			return serveFile(db, opts.port)
+			return serveFile(db, cmd.OutOrStdout(), opts.port)
 		}
 
-		return fileBackup(db, opts.path)
+		return fileBackup(db, cmd.OutOrStdout(), opts.path)
 	}
 }
 
 // serveFile serves the file on localhost.
-func serveFile(db *bolt.DB, port uint16) error {
+func serveFile(db *bolt.DB, w io.Writer, port uint16) error {
 	if port == 0 {
 		return errors.New("invalid port")
 	}
@@ -87,7 +81,7 @@ func serveFile(db *bolt.DB, port uint16) error {
 	sig.Signal.AddCleanup(func() error {
 		// Do not exit after a signal as we are handling the shutdown
 		sig.Signal.KeepAlive()
-		fmt.Println("Shutting down server...")
+		fmt.Fprintln(w, "Shutting down server...")
 
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 		defer cancel()
@@ -107,7 +101,7 @@ func serveFile(db *bolt.DB, port uint16) error {
 	once.Do(func() {
 		http.HandleFunc("/", httpBackup(db))
 	})
-	fmt.Printf("Serving database on http://localhost:%d (Press Ctrl+C to quit)\n", port)
+	fmt.Fprintf(w, "Serving database on http://localhost:%d (Press Ctrl+C to quit)\n", port)
 
 	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		return errors.Wrap(err, "starting server")
@@ -117,7 +111,7 @@ func serveFile(db *bolt.DB, port uint16) error {
 }
 
 // fileBackup writes the database to a new file.
-func fileBackup(db *bolt.DB, path string) error {
+func fileBackup(db *bolt.DB, w io.Writer, path string) error {
 	if path == "" {
 		return cmdutil.ErrInvalidPath
 	}
@@ -146,7 +140,7 @@ func fileBackup(db *bolt.DB, path string) error {
 	}
 
 	abs, _ := filepath.Abs(path)
-	fmt.Println("Backup created at", abs)
+	fmt.Fprintln(w, "Backup created at", abs)
 	return nil
 }
 
@@ -103,7 +103,3 @@ func TestWriteTo(t *testing.T) {
 		t.Error("Expected buffer length not to be zero")
 	}
 }
-
-func TestPostRun(t *testing.T) {
-	NewCmd(nil).PostRun(nil, nil)
-}
@@ -0,0 +1,27 @@
+package breach
+
+import (
+	"github.com/GGP1/kure/commands/breach/check"
+
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+)
+
+const example = `
+kure breach check --db /path/to/pwned-passwords.txt`
+
+// NewCmd returns a new command.
+func NewCmd(db *bolt.DB) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "breach",
+		Short: "Breached passwords operations",
+		Long: `Breached passwords operations.
+
+Passwords are looked up in a local copy of the Have I Been Pwned Pwned Passwords dump (SHA-1 or NTLM, ordered by hash), no information is sent to external services.`,
+		Example: example,
+	}
+
+	cmd.AddCommand(check.NewCmd(db))
+
+	return cmd
+}
@@ -0,0 +1,113 @@
+package check
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/GGP1/kure/auth"
+	"github.com/GGP1/kure/breach"
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/entry"
+
+	"github.com/awnumar/memguard"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+)
+
+const example = `
+* Check all the entries passwords
+kure breach check --db /path/to/pwned-passwords-sha1-ordered-by-hash.txt
+
+* Use the dump set in the configuration file (breach.path)
+kure breach check`
+
+type checkOptions struct {
+	dbPath string
+}
+
+// NewCmd returns a new command.
+func NewCmd(db *bolt.DB) *cobra.Command {
+	opts := checkOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check the entries passwords against a breached passwords dump",
+		Long: `Check the entries passwords against a breached passwords dump.
+
+The dump must be a Have I Been Pwned Pwned Passwords file ordered by hash, using either SHA-1 or NTLM hashes. It's binary searched, the file isn't loaded into memory.
+
+If the --db flag isn't used, the path set in the configuration file (breach.path) is used.`,
+		Example: example,
+		Args:    cobra.NoArgs,
+		PreRunE: auth.Login(db),
+		RunE:    runCheck(db, &opts),
+	}
+
+	cmd.Flags().StringVar(&opts.dbPath, "db", "", "path to the breached passwords dump")
+
+	return cmd
+}
+
+func runCheck(db *bolt.DB, opts *checkOptions) cmdutil.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		format, err := cmdutil.OutputFormat(cmd)
+		if err != nil {
+			return err
+		}
+
+		path := breach.Path(opts.dbPath)
+		if path == "" {
+			return errors.New("no breached passwords dump specified, use --db or set breach.path in the configuration file")
+		}
+
+		dump, err := breach.Open(path)
+		if err != nil {
+			return err
+		}
+		defer dump.Close()
+
+		entries, err := entry.List(db)
+		if err != nil {
+			return err
+		}
+
+		out := &cmdutil.BreachOutput{Checked: len(entries), Breached: []cmdutil.BreachMatch{}}
+		for _, e := range entries {
+			if e.Password == "" {
+				continue
+			}
+
+			password := []byte(e.Password)
+			count, err := dump.Count(password)
+			memguard.WipeBytes(password)
+			if err != nil {
+				return errors.Wrapf(err, "checking %q", e.Name)
+			}
+
+			if count > 0 {
+				out.Breached = append(out.Breached, cmdutil.BreachMatch{Name: e.Name, Count: count})
+			}
+		}
+
+		sort.SliceStable(out.Breached, func(i, j int) bool {
+			return out.Breached[i].Count > out.Breached[j].Count
+		})
+
+		if format != cmdutil.OutputDefault {
+			return cmdutil.WriteOutput(cmd.OutOrStdout(), format, out)
+		}
+
+		w := cmd.OutOrStdout()
+		if len(out.Breached) == 0 {
+			fmt.Fprintf(w, "No breached passwords were found (%d entries checked)\n", out.Checked)
+			return nil
+		}
+
+		fmt.Fprintf(w, "Breached passwords: %d of %d entries\n", len(out.Breached), out.Checked)
+		for _, b := range out.Breached {
+			fmt.Fprintf(w, "  %s - found %d times\n", b.Name, b.Count)
+		}
+		return nil
+	}
+}
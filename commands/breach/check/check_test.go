@@ -0,0 +1,78 @@
+package check
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/pb"
+)
+
+func TestCheck(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../../db/testdata/database")
+	err := entry.Create(db,
+		&pb.Entry{Name: "once", Password: "qwerty", Expires: "Never"},
+		&pb.Entry{Name: "safe", Password: "v8#Kq2!mZr@9Lx$4Wp", Expires: "Never"},
+		&pb.Entry{Name: "twice", Password: "123456", Expires: "Never"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := []string{sha1Hex("qwerty") + ":1", sha1Hex("123456") + ":2", sha1Hex("letmein") + ":3"}
+	sort.Strings(lines)
+	path := filepath.Join(t.TempDir(), "dump.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	cmd := NewCmd(db)
+	cmdutil.AddOutputFlag(cmd)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--db", path, "--output", "json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got cmdutil.BreachOutput
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []cmdutil.BreachMatch{{Name: "twice", Count: 2}, {Name: "once", Count: 1}}
+	if got.Checked != 3 {
+		t.Errorf("Expected 3 entries checked, got %d", got.Checked)
+	}
+	if len(got.Breached) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, got.Breached)
+	}
+	for i, b := range got.Breached {
+		if b != expected[i] {
+			t.Errorf("Expected %v, got %v", expected[i], b)
+		}
+	}
+}
+
+func TestCheckNoDump(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../../db/testdata/database")
+
+	cmd := NewCmd(db)
+	if err := cmd.Execute(); err == nil {
+		t.Error("Expected an error and got nil")
+	}
+}
+
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
@@ -48,7 +48,7 @@ func runAdd(db *bolt.DB, r io.Reader) cmdutil.RunEFunc {
 			return err
 		}
 
-		fmt.Printf("\n%q added\n", name)
+		fmt.Fprintf(cmd.OutOrStdout(), "\n%q added\n", name)
 		return nil
 	}
 }
@@ -63,6 +63,7 @@ func input(db *bolt.DB, name string, r io.Reader) (*pb.Card, error) {
 		ExpireDate:   cmdutil.Scanln(reader, "Expire date"),
 		Notes:        cmdutil.Scanlns(reader, "Notes"),
 	}
+	cmdutil.SetCardExpires(c)
 
 	return c, nil
 }
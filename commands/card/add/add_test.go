@@ -87,6 +87,7 @@ func TestInput(t *testing.T) {
 		Number:       "123456789",
 		SecurityCode: "1234",
 		ExpireDate:   "2021/06",
+		ExpiresAt:    1625097600,
 		Notes:        "notes",
 	}
 
@@ -1,7 +1,7 @@
 package card
 
 import (
-	"os"
+	"io"
 
 	cadd "github.com/GGP1/kure/commands/card/add"
 	ccopy "github.com/GGP1/kure/commands/card/copy"
@@ -17,14 +17,14 @@ const example = `
 kure card (add|copy|edit|ls|rm)`
 
 // NewCmd returns a new command.
-func NewCmd(db *bolt.DB) *cobra.Command {
+func NewCmd(db *bolt.DB, r io.Reader) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "card",
 		Short:   "Card operations",
 		Example: example,
 	}
 
-	cmd.AddCommand(cadd.NewCmd(db, os.Stdin), ccopy.NewCmd(db), cedit.NewCmd(db), cls.NewCmd(db), crm.NewCmd(db, os.Stdin))
+	cmd.AddCommand(cadd.NewCmd(db, r), ccopy.NewCmd(db), cedit.NewCmd(db, r), cls.NewCmd(db), crm.NewCmd(db, r))
 
 	return cmd
 }
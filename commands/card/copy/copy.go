@@ -39,10 +39,6 @@ func NewCmd(db *bolt.DB) *cobra.Command {
 		Args:    cmdutil.MustExist(db, cmdutil.Card),
 		PreRunE: auth.Login(db),
 		RunE:    runCard(db, &opts),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			// Reset variables (session)
-			opts = copyOptions{}
-		},
 	}
 
 	f := cmd.Flags()
@@ -91,7 +91,3 @@ func TestCopyErrors(t *testing.T) {
 	}
 
 }
-
-func TestPostRun(t *testing.T) {
-	NewCmd(nil).PostRun(nil, nil)
-}
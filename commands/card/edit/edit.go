@@ -32,7 +32,7 @@ type editOptions struct {
 }
 
 // NewCmd returns a new command.
-func NewCmd(db *bolt.DB) *cobra.Command {
+func NewCmd(db *bolt.DB, r io.Reader) *cobra.Command {
 	opts := editOptions{}
 
 	cmd := &cobra.Command{
@@ -44,11 +44,7 @@ If the name is edited, Kure will remove the old card and create one with the new
 		Example: example,
 		Args:    cmdutil.MustExist(db, cmdutil.Card),
 		PreRunE: auth.Login(db),
-		RunE:    runEdit(db, &opts),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			// Reset variables (session)
-			opts = editOptions{}
-		},
+		RunE:    runEdit(db, r, &opts),
 	}
 
 	cmd.Flags().BoolVarP(&opts.interactive, "it", "i", false, "use the text editor")
@@ -56,7 +52,7 @@ If the name is edited, Kure will remove the old card and create one with the new
 	return cmd
 }
 
-func runEdit(db *bolt.DB, opts *editOptions) cmdutil.RunEFunc {
+func runEdit(db *bolt.DB, r io.Reader, opts *editOptions) cmdutil.RunEFunc {
 	return func(cmd *cobra.Command, args []string) error {
 		name := strings.Join(args, " ")
 		name = cmdutil.NormalizeName(name)
@@ -67,10 +63,10 @@ func runEdit(db *bolt.DB, opts *editOptions) cmdutil.RunEFunc {
 		}
 
 		if opts.interactive {
-			return useTextEditor(db, oldCard)
+			return useTextEditor(db, cmd.OutOrStdout(), oldCard)
 		}
 
-		return useStdin(db, os.Stdin, oldCard)
+		return useStdin(db, r, cmd.OutOrStdout(), oldCard)
 	}
 }
 
@@ -115,24 +111,25 @@ func readTmpFile(filename string) (*pb.Card, error) {
 
 // updateCard takes the name of the card that's being edited to check if the name was
 // changed. If it was, it will remove the old one.
-func updateCard(db *bolt.DB, name string, c *pb.Card) error {
+func updateCard(db *bolt.DB, w io.Writer, name string, c *pb.Card) error {
 	if c.Name == "" {
 		return cmdutil.ErrInvalidName
 	}
 
 	name = cmdutil.NormalizeName(name)
 	c.Name = cmdutil.NormalizeName(c.Name)
+	cmdutil.SetCardExpires(c)
 
 	if err := card.Update(db, name, c); err != nil {
 		return err
 	}
 
-	fmt.Println(c.Name, "updated")
+	fmt.Fprintln(w, c.Name, "updated")
 	return nil
 }
 
-func useStdin(db *bolt.DB, r io.Reader, oldCard *pb.Card) error {
-	fmt.Println("Type '-' to clear the field or leave blank to use the current value")
+func useStdin(db *bolt.DB, r io.Reader, w io.Writer, oldCard *pb.Card) error {
+	fmt.Fprintln(w, "Type '-' to clear the field or leave blank to use the current value")
 	reader := bufio.NewReader(r)
 
 	scanln := func(field, value string) string {
@@ -161,10 +158,10 @@ func useStdin(db *bolt.DB, r io.Reader, oldCard *pb.Card) error {
 	}
 	newCard.Notes = notes
 
-	return updateCard(db, oldCard.Name, newCard)
+	return updateCard(db, w, oldCard.Name, newCard)
 }
 
-func useTextEditor(db *bolt.DB, oldCard *pb.Card) error {
+func useTextEditor(db *bolt.DB, w io.Writer, oldCard *pb.Card) error {
 	editor := cmdutil.SelectEditor()
 	bin, err := exec.LookPath(editor)
 	if err != nil {
@@ -218,5 +215,5 @@ func useTextEditor(db *bolt.DB, oldCard *pb.Card) error {
 	newCard.ExpireDate = rmTabs(newCard.ExpireDate)
 	newCard.Notes = rmTabs(newCard.Notes)
 
-	return updateCard(db, oldCard.Name, newCard)
+	return updateCard(db, w, oldCard.Name, newCard)
 }
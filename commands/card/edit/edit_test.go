@@ -3,6 +3,7 @@ package edit
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"os"
 	"reflect"
 	"testing"
@@ -40,7 +41,7 @@ func TestEditErrors(t *testing.T) {
 		},
 	}
 
-	cmd := NewCmd(db)
+	cmd := NewCmd(db, nil)
 
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
@@ -130,7 +131,7 @@ func TestUpdateCard(t *testing.T) {
 		Notes:        "",
 	}
 
-	if err := updateCard(db, name, newCard); err != nil {
+	if err := updateCard(db, io.Discard, name, newCard); err != nil {
 		t.Error(err)
 	}
 
@@ -145,7 +146,7 @@ func TestUpdateCard(t *testing.T) {
 
 	t.Run("Invalid name", func(t *testing.T) {
 		newCard.Name = ""
-		if err := updateCard(db, "fail", newCard); err == nil {
+		if err := updateCard(db, io.Discard, "fail", newCard); err == nil {
 			t.Error("Expected an error and got nil")
 		}
 	})
@@ -165,7 +166,7 @@ func TestUseStdin(t *testing.T) {
 
 	buf := bytes.NewBufferString("\nCredit\n\n-\n\n-<\n")
 
-	if err := useStdin(db, buf, oldCard); err != nil {
+	if err := useStdin(db, buf, io.Discard, oldCard); err != nil {
 		t.Fatal(err)
 	}
 
@@ -191,10 +192,6 @@ func TestUseStdin(t *testing.T) {
 	}
 }
 
-func TestPostRun(t *testing.T) {
-	NewCmd(nil).PostRun(nil, nil)
-}
-
 func createCard(t *testing.T, db *bolt.DB, name string) {
 	t.Helper()
 	if err := card.Create(db, &pb.Card{Name: name}); err != nil {
@@ -3,6 +3,7 @@ package ls
 import (
 	"errors"
 	"fmt"
+	"io"
 	"path/filepath"
 	"strings"
 
@@ -11,7 +12,6 @@ import (
 	"github.com/GGP1/kure/db/card"
 	"github.com/GGP1/kure/orderedmap"
 	"github.com/GGP1/kure/pb"
-	"github.com/GGP1/kure/tree"
 
 	"github.com/spf13/cobra"
 	bolt "go.etcd.io/bbolt"
@@ -42,10 +42,6 @@ func NewCmd(db *bolt.DB) *cobra.Command {
 		Args:    cmdutil.MustExistLs(db, cmdutil.Card),
 		PreRunE: auth.Login(db),
 		RunE:    runLs(db, &opts),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			// Reset variables (session)
-			opts = lsOptions{}
-		},
 	}
 
 	f := cmd.Flags()
@@ -68,8 +64,7 @@ func runLs(db *bolt.DB, opts *lsOptions) cmdutil.RunEFunc {
 				return err
 			}
 
-			tree.Print(cards)
-			return nil
+			return cmdutil.PrintNames(cmd, cards)
 		}
 
 		// Filter by name
@@ -95,28 +90,50 @@ func runLs(db *bolt.DB, opts *lsOptions) cmdutil.RunEFunc {
 				return errors.New("no cards were found")
 			}
 
-			tree.Print(matches)
-			return nil
+			return cmdutil.PrintNames(cmd, matches)
 		}
 
 		// List one
+		format, err := cmdutil.OutputFormat(cmd)
+		if err != nil {
+			return err
+		}
+
 		c, err := card.Get(db, name)
 		if err != nil {
 			return err
 		}
 
+		if format != cmdutil.OutputDefault {
+			return cmdutil.WriteOutput(cmd.OutOrStdout(), format, cardOutput(name, c, opts.show))
+		}
+
 		if opts.qr {
-			if err := cmdutil.DisplayQRCode(c.Number); err != nil {
+			if err := cmdutil.DisplayQRCode(cmd.OutOrStdout(), c.Number); err != nil {
 				return err
 			}
 		}
 
-		printCard(name, c, opts.show)
+		printCard(cmd.OutOrStdout(), name, c, opts.show)
 		return nil
 	}
 }
 
-func printCard(name string, c *pb.Card, show bool) {
+func cardOutput(name string, c *pb.Card, show bool) *cmdutil.CardOutput {
+	out := &cmdutil.CardOutput{
+		Name:       name,
+		Type:       c.Type,
+		ExpireDate: c.ExpireDate,
+		Notes:      c.Notes,
+	}
+	if show {
+		out.Number = &c.Number
+		out.SecurityCode = &c.SecurityCode
+	}
+	return out
+}
+
+func printCard(w io.Writer, name string, c *pb.Card, show bool) {
 	if !show {
 		c.Number = "••••••••••••••••"
 		c.SecurityCode = "•••"
@@ -130,5 +147,5 @@ func printCard(name string, c *pb.Card, show bool) {
 	mp.Set("Notes", c.Notes)
 
 	box := cmdutil.BuildBox(name, mp)
-	fmt.Println("\n" + box)
+	fmt.Fprintln(w, "\n"+box)
 }
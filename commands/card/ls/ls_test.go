@@ -117,7 +117,3 @@ func TestLsErrors(t *testing.T) {
 		})
 	}
 }
-
-func TestPostRun(t *testing.T) {
-	NewCmd(nil).PostRun(nil, nil)
-}
@@ -49,11 +49,11 @@ func runRm(db *bolt.DB, r io.Reader) cmdutil.RunEFunc {
 				return err
 			}
 
-			fmt.Printf("\n%q removed\n", name)
+			fmt.Fprintf(cmd.OutOrStdout(), "\n%q removed\n", name)
 			return nil
 		}
 
-		fmt.Printf("Removing %q directory...\n", name)
+		fmt.Fprintf(cmd.OutOrStdout(), "Removing %q directory...\n", name)
 
 		cards, err := card.ListNames(db)
 		if err != nil {
@@ -65,7 +65,7 @@ func runRm(db *bolt.DB, r io.Reader) cmdutil.RunEFunc {
 				if err := card.Remove(db, c); err != nil {
 					return err
 				}
-				fmt.Println("Remove:", c)
+				fmt.Fprintln(cmd.OutOrStdout(), "Remove:", c)
 			}
 		}
 
@@ -8,9 +8,9 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/GGP1/kure/clip"
 	cmdutil "github.com/GGP1/kure/commands"
 
-	"github.com/atotto/clipboard"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -44,10 +44,6 @@ func NewCmd() *cobra.Command {
 Using the command without passing any flags clears the clipboard and the terminal screen.`,
 		Example: example,
 		RunE:    runClear(&opts),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			// Reset variables (session)
-			opts = clearOptions{}
-		},
 	}
 
 	f := cmd.Flags()
@@ -67,7 +63,7 @@ func runClear(opts *clearOptions) cmdutil.RunEFunc {
 		}
 
 		if opts.clip {
-			if err := clipboard.WriteAll(""); err != nil {
+			if err := clip.Clear(); err != nil {
 				return errors.Wrap(err, "clearing clipboard")
 			}
 		}
@@ -81,7 +81,3 @@ func TestClearTerminalHistory(t *testing.T) {
 		t.Error(err)
 	}
 }
-
-func TestPostRun(t *testing.T) {
-	NewCmd().PostRun(nil, nil)
-}
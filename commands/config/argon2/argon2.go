@@ -6,6 +6,7 @@ import (
 	"github.com/GGP1/kure/auth"
 	cmdutil "github.com/GGP1/kure/commands"
 	"github.com/GGP1/kure/commands/config/argon2/test"
+	dbutil "github.com/GGP1/kure/db"
 	authDB "github.com/GGP1/kure/db/auth"
 
 	"github.com/spf13/cobra"
@@ -33,12 +34,12 @@ func NewCmd(db *bolt.DB) *cobra.Command {
 
 func runArgon2(db *bolt.DB) cmdutil.RunEFunc {
 	return func(cmd *cobra.Command, args []string) error {
-		params, err := authDB.GetParameters(db)
+		params, err := authDB.GetParameters(db, dbutil.VaultInUse())
 		if err != nil {
 			return err
 		}
 
-		fmt.Printf("Iterations: %d\nMemory: %d\nThreads: %d\n",
+		fmt.Fprintf(cmd.OutOrStdout(), "Iterations: %d\nMemory: %d\nThreads: %d\n",
 			params.Iterations, params.Memory, params.Threads)
 		return nil
 	}
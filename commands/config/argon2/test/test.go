@@ -43,14 +43,6 @@ If one of the devices that will handle the database has lower than 1GB of memory
 • Threads: number of threads number in parallel. Default is the maximum number of logical CPUs usable.`,
 		Example: example,
 		RunE:    runTest(&opts),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			// Reset variables (session)
-			opts = testOptions{
-				memory:     1048576,
-				iterations: 1,
-				threads:    uint8(runtime.NumCPU()),
-			}
-		},
 	}
 
 	f := cmd.Flags()
@@ -89,7 +81,7 @@ func runTest(opts *testOptions) cmdutil.RunEFunc {
 
 		argon2.IDKey([]byte(password), salt, opts.iterations, opts.memory, opts.threads, 32)
 
-		fmt.Println(time.Since(start))
+		fmt.Fprintln(cmd.OutOrStdout(), time.Since(start))
 		return nil
 	}
 }
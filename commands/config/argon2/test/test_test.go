@@ -90,7 +90,3 @@ func TestTestInvalid(t *testing.T) {
 		})
 	}
 }
-
-func TestPostRun(t *testing.T) {
-	NewCmd().PostRun(nil, nil)
-}
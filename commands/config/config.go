@@ -47,7 +47,16 @@ func runConfig(r io.Reader) cmdutil.RunEFunc {
 		}
 
 		content := strings.TrimSpace(string(data))
-		fmt.Printf(`
+
+		format, err := cmdutil.OutputFormat(cmd)
+		if err != nil {
+			return err
+		}
+		if format != cmdutil.OutputDefault {
+			return cmdutil.WriteOutput(cmd.OutOrStdout(), format, &cmdutil.ConfigOutput{Path: path, Content: content})
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), `
 File location: %s
 		
 %s
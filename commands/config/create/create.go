@@ -27,10 +27,6 @@ func NewCmd() *cobra.Command {
 		Short:   "Create a configuration file",
 		Example: example,
 		RunE:    runCreate(&opts),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			// Reset variables (session)
-			opts = createOptions{}
-		},
 	}
 
 	f := cmd.Flags()
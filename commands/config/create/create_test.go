@@ -45,7 +45,3 @@ func TestCreateErrors(t *testing.T) {
 		t.Errorf("Failed removing file")
 	}
 }
-
-func TestPostRun(t *testing.T) {
-	NewCmd().PostRun(nil, nil)
-}
@@ -40,10 +40,6 @@ func NewCmd(db *bolt.DB) *cobra.Command {
 		Args:    cmdutil.MustExist(db, cmdutil.Entry),
 		PreRunE: auth.Login(db),
 		RunE:    runCopy(db, &opts),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			// Reset variables (session)
-			opts = copyOptions{}
-		},
 	}
 
 	f := cmd.Flags()
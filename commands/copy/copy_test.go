@@ -109,10 +109,6 @@ func TestCopyErrors(t *testing.T) {
 	}
 }
 
-func TestPostRun(t *testing.T) {
-	NewCmd(nil).PostRun(nil, nil)
-}
-
 func createEntry(t *testing.T, db *bolt.DB) {
 	t.Helper()
 
@@ -0,0 +1,81 @@
+package duress
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/GGP1/kure/auth"
+	cmdutil "github.com/GGP1/kure/commands"
+
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+)
+
+const example = `
+* Register a duress password
+kure duress
+
+* Register a duress password that destroys the real vault when used
+kure duress --destroy
+
+* Remove the duress password and the decoy vault
+kure duress --rm`
+
+type duressOptions struct {
+	rm      bool
+	force   bool
+	destroy bool
+}
+
+// NewCmd returns a new command.
+func NewCmd(db *bolt.DB, r io.Reader) *cobra.Command {
+	opts := duressOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "duress",
+		Short: "Register a duress password",
+		Long: `Register a duress password that opens a decoy vault.
+
+Logging in with the duress password opens a separate, initially empty, vault stored in the same database. Both passwords take the same time to be verified and produce the same output, so there is no way of telling which vault was opened. Every database contains two vaults with the same layout, so it doesn't reveal whether a duress password exists either. Record names are stored in plain text, though, adding records to the decoy vault reveals that it exists to anyone with access to the file.
+
+If --destroy is used, logging in with the duress password also destroys the real vault: its records and key are overwritten with random bytes, the records are deleted and the database file is compacted before exiting, so they can't be recovered from it. The destruction runs in the background after logging in and the flag is stored encrypted with the duress password, neither of them can be noticed.
+
+Registering a new duress password deletes the existing decoy vault. Duress passwords weaker than the minimum score configured ("password.min_score") are rejected unless --force is used.
+
+Running this command from the decoy vault produces the same output, but the real vault is never modified.`,
+		Example: example,
+		PreRunE: auth.Login(db),
+		RunE:    runDuress(db, r, &opts),
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&opts.rm, "rm", false, "remove the duress password and the decoy vault")
+	f.BoolVar(&opts.force, "force", false, "accept a password weaker than the minimum score")
+	f.BoolVar(&opts.destroy, "destroy", false, "destroy the real vault when the duress password is used")
+
+	return cmd
+}
+
+func runDuress(db *bolt.DB, r io.Reader, opts *duressOptions) cmdutil.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		if opts.rm {
+			if !cmdutil.Confirm(r, "The decoy vault and all its records will be deleted. Do you want to continue?") {
+				return nil
+			}
+
+			if err := auth.RemoveDuress(db); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), "Duress password removed")
+			return nil
+		}
+
+		if err := auth.RegisterDuress(db, r, cmd.OutOrStdout(), opts.force, opts.destroy); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(cmd.OutOrStdout(), "Duress password registered")
+		return nil
+	}
+}
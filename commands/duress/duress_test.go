@@ -0,0 +1,102 @@
+package duress
+
+import (
+	"bytes"
+	"testing"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/config"
+	dbutil "github.com/GGP1/kure/db"
+	authDB "github.com/GGP1/kure/db/auth"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestRemove(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+	decoy := registerDecoy(t, db)
+
+	buf := bytes.NewBufferString("y")
+	cmd := NewCmd(db, buf)
+	cmd.SetArgs([]string{"--rm"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Failed removing the duress password: %v", err)
+	}
+
+	params, err := authDB.GetParameters(db, decoy.Vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(params.AuthKey, decoy.AuthKey) {
+		t.Error("Expected the decoy vault to be removed")
+	}
+}
+
+func TestRemoveAbort(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+	decoy := registerDecoy(t, db)
+
+	buf := bytes.NewBufferString("n") // Abort operation
+	cmd := NewCmd(db, buf)
+	cmd.SetArgs([]string{"--rm"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Failed aborting: %v", err)
+	}
+
+	params, err := authDB.GetParameters(db, decoy.Vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(params.AuthKey, decoy.AuthKey) {
+		t.Error("Expected the decoy vault to be kept")
+	}
+}
+
+func TestDecoyVault(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+	decoy := registerDecoy(t, db)
+	real, err := authDB.GetParameters(db, decoy.Vault.Other())
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Set("auth.vault", uint32(decoy.Vault))
+	config.Set("auth.decoy", true)
+
+	cmd := NewCmd(db, bytes.NewBufferString("y"))
+	cmd.SetArgs([]string{"--rm"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("Failed removing the duress password: %v", err)
+	}
+
+	params, err := authDB.GetParameters(db, decoy.Vault.Other())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(params.AuthKey, real.AuthKey) {
+		t.Error("Expected the real vault to be kept")
+	}
+}
+
+// registerDecoy registers the vault in use and a decoy in the other one, it returns the decoy parameters.
+func registerDecoy(t *testing.T, db *bolt.DB) authDB.Parameters {
+	t.Helper()
+
+	params := authDB.Parameters{Iterations: 1, Memory: 1, Threads: 1, Vault: dbutil.VaultInUse()}
+	if err := authDB.Register(db, params); err != nil {
+		t.Fatalf("Failed registering: %v", err)
+	}
+
+	params.Vault = params.Vault.Other()
+	if err := authDB.RegisterDecoy(db, params); err != nil {
+		t.Fatalf("Failed registering the decoy vault: %v", err)
+	}
+
+	decoy, err := authDB.GetParameters(db, params.Vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return decoy
+}
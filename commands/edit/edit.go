@@ -11,11 +11,13 @@ import (
 	"time"
 
 	"github.com/GGP1/kure/auth"
+	"github.com/GGP1/kure/breach"
 	cmdutil "github.com/GGP1/kure/commands"
 	"github.com/GGP1/kure/db/entry"
 	"github.com/GGP1/kure/pb"
 	"github.com/GGP1/kure/sig"
 
+	"github.com/awnumar/memguard"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	bolt "go.etcd.io/bbolt"
@@ -30,10 +32,11 @@ kure edit Sample -i`
 
 type editOptions struct {
 	interactive bool
+	breachDB    string
 }
 
 // NewCmd returns a new command.
-func NewCmd(db *bolt.DB) *cobra.Command {
+func NewCmd(db *bolt.DB, r io.Reader) *cobra.Command {
 	opts := editOptions{}
 
 	cmd := &cobra.Command{
@@ -45,19 +48,17 @@ If the name is edited, Kure will remove the entry with the old name and create o
 		Example: example,
 		Args:    cmdutil.MustExist(db, cmdutil.Entry),
 		PreRunE: auth.Login(db),
-		RunE:    runEdit(db, &opts),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			// Reset variables (session)
-			opts = editOptions{}
-		},
+		RunE:    runEdit(db, r, &opts),
 	}
 
-	cmd.Flags().BoolVarP(&opts.interactive, "it", "i", false, "use the text editor")
+	f := cmd.Flags()
+	f.BoolVarP(&opts.interactive, "it", "i", false, "use the text editor")
+	f.StringVar(&opts.breachDB, "breach-db", "", "check the new password against a breached passwords dump")
 
 	return cmd
 }
 
-func runEdit(db *bolt.DB, opts *editOptions) cmdutil.RunEFunc {
+func runEdit(db *bolt.DB, r io.Reader, opts *editOptions) cmdutil.RunEFunc {
 	return func(cmd *cobra.Command, args []string) error {
 		name := strings.Join(args, " ")
 		name = cmdutil.NormalizeName(name)
@@ -68,17 +69,16 @@ func runEdit(db *bolt.DB, opts *editOptions) cmdutil.RunEFunc {
 		}
 
 		// Format the expires fields so it's easy to read
-		if oldEntry.Expires != "Never" {
-			// This never fails as the field "expires" is parsed before stored
-			expires, _ := time.Parse(time.RFC1123Z, oldEntry.Expires)
+		if expires, ok := cmdutil.EntryExpiration(oldEntry); ok {
 			oldEntry.Expires = expires.Format("02/01/2006")
 		}
 
+		breachPath := breach.Path(opts.breachDB)
 		if opts.interactive {
-			return useTextEditor(db, oldEntry)
+			return useTextEditor(db, cmd.OutOrStdout(), oldEntry, breachPath)
 		}
 
-		return useStdin(db, os.Stdin, oldEntry)
+		return useStdin(db, r, cmd.OutOrStdout(), oldEntry, breachPath)
 	}
 }
 
@@ -123,31 +123,29 @@ func readTmpFile(filename string) (*pb.Entry, error) {
 
 // updateEntry takes the name of the entry that's being edited to check if the name was
 // changed. If it was, it will remove the old one.
-func updateEntry(db *bolt.DB, name string, e *pb.Entry) error {
+func updateEntry(db *bolt.DB, w io.Writer, name string, e *pb.Entry) error {
 	if e.Name == "" {
 		return cmdutil.ErrInvalidName
 	}
 
 	// Verify that the "expires" field has a valid format
-	expires, err := cmdutil.FmtExpires(e.Expires)
-	if err != nil {
+	if err := cmdutil.SetExpires(e, e.Expires); err != nil {
 		return err
 	}
 
 	name = cmdutil.NormalizeName(name)
 	e.Name = cmdutil.NormalizeName(e.Name)
-	e.Expires = expires
 
 	if err := entry.Update(db, name, e); err != nil {
 		return err
 	}
 
-	fmt.Println(e.Name, "updated")
+	fmt.Fprintln(w, e.Name, "updated")
 	return nil
 }
 
-func useStdin(db *bolt.DB, r io.Reader, oldEntry *pb.Entry) error {
-	fmt.Println("Type '-' to clear the field (except Name and Password) or leave blank to use the current value")
+func useStdin(db *bolt.DB, r io.Reader, w io.Writer, oldEntry *pb.Entry, breachPath string) error {
+	fmt.Fprintln(w, "Type '-' to clear the field (except Name and Password) or leave blank to use the current value")
 	reader := bufio.NewReader(r)
 
 	scanln := func(field, value string) string {
@@ -178,6 +176,13 @@ func useStdin(db *bolt.DB, r io.Reader, oldEntry *pb.Entry) error {
 			return errors.Wrap(err, "opening enclave")
 		}
 
+		if breachPath != "" {
+			if err := breach.Check(breachPath, pwd.Bytes()); err != nil {
+				pwd.Destroy()
+				return err
+			}
+		}
+
 		newEntry.Password = pwd.String()
 	}
 
@@ -191,11 +196,12 @@ func useStdin(db *bolt.DB, r io.Reader, oldEntry *pb.Entry) error {
 		notes = ""
 	}
 	newEntry.Notes = notes
+	newEntry.PasswordUpdatedAt = passwordUpdatedAt(oldEntry, newEntry)
 
-	return updateEntry(db, oldEntry.Name, newEntry)
+	return updateEntry(db, w, oldEntry.Name, newEntry)
 }
 
-func useTextEditor(db *bolt.DB, oldEntry *pb.Entry) error {
+func useTextEditor(db *bolt.DB, w io.Writer, oldEntry *pb.Entry, breachPath string) error {
 	editor := cmdutil.SelectEditor()
 	bin, err := exec.LookPath(editor)
 	if err != nil {
@@ -247,6 +253,24 @@ func useTextEditor(db *bolt.DB, oldEntry *pb.Entry) error {
 	newEntry.Username = rmTabs(newEntry.Username)
 	newEntry.URL = rmTabs(newEntry.URL)
 	newEntry.Notes = rmTabs(newEntry.Notes)
+	newEntry.PasswordUpdatedAt = passwordUpdatedAt(oldEntry, newEntry)
 
-	return updateEntry(db, oldEntry.Name, newEntry)
+	if breachPath != "" && newEntry.Password != oldEntry.Password {
+		password := []byte(newEntry.Password)
+		err := breach.Check(breachPath, password)
+		memguard.WipeBytes(password)
+		if err != nil {
+			return err
+		}
+	}
+
+	return updateEntry(db, w, oldEntry.Name, newEntry)
+}
+
+// passwordUpdatedAt returns the time of the last password change of the entry edited.
+func passwordUpdatedAt(oldEntry, newEntry *pb.Entry) int64 {
+	if newEntry.Password != oldEntry.Password {
+		return time.Now().Unix()
+	}
+	return oldEntry.PasswordUpdatedAt
 }
@@ -2,6 +2,7 @@ package edit
 
 import (
 	"encoding/json"
+	"io"
 	"os"
 	"reflect"
 	"testing"
@@ -42,7 +43,7 @@ func TestEditErrors(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
 			tc.set()
-			cmd := NewCmd(db)
+			cmd := NewCmd(db, nil)
 			cmd.SetArgs([]string{tc.name})
 			f := cmd.Flags()
 			f.Set("it", tc.it)
@@ -133,7 +134,7 @@ func TestUpdateEntry(t *testing.T) {
 		Notes:    "",
 	}
 
-	if err := updateEntry(db, name, newEntry); err != nil {
+	if err := updateEntry(db, io.Discard, name, newEntry); err != nil {
 		t.Error(err)
 	}
 
@@ -148,16 +149,12 @@ func TestUpdateEntry(t *testing.T) {
 
 	t.Run("Invalid name", func(t *testing.T) {
 		newEntry.Name = ""
-		if err := updateEntry(db, "fail", newEntry); err == nil {
+		if err := updateEntry(db, io.Discard, "fail", newEntry); err == nil {
 			t.Error("Expected an error and got nil")
 		}
 	})
 }
 
-func TestPostRun(t *testing.T) {
-	NewCmd(nil).PostRun(nil, nil)
-}
-
 func createEntry(t *testing.T, db *bolt.DB, name string) {
 	t.Helper()
 	e := &pb.Entry{
@@ -169,3 +166,15 @@ func createEntry(t *testing.T, db *bolt.DB, name string) {
 		t.Fatalf("Failed creating the entry: %v", err)
 	}
 }
+
+func TestPasswordUpdatedAt(t *testing.T) {
+	oldEntry := &pb.Entry{Password: "old", PasswordUpdatedAt: 1}
+
+	if got := passwordUpdatedAt(oldEntry, &pb.Entry{Password: "old"}); got != 1 {
+		t.Errorf("Expected the date to be kept, got %d", got)
+	}
+
+	if got := passwordUpdatedAt(oldEntry, &pb.Entry{Password: "new"}); got <= 1 {
+		t.Errorf("Expected the date to be updated, got %d", got)
+	}
+}
@@ -0,0 +1,76 @@
+package cmdutil
+
+import (
+	"io"
+
+	"github.com/GGP1/kure/sig"
+
+	"github.com/spf13/cobra"
+)
+
+// NewRootFunc returns a new root command with all its subcommands, which read the user input from r.
+type NewRootFunc func(r io.Reader) *cobra.Command
+
+// Executor runs commands on a new command tree every time, options and flags are never
+// shared between executions.
+//
+// Commands read the input from the executor reader and write the output to its writers. Passwords
+// are read from the terminal and text editors run on it, the messages asking for input are written to
+// the standard output.
+type Executor struct {
+	newRoot NewRootFunc
+	in      io.Reader
+	out     io.Writer
+	errOut  io.Writer
+}
+
+// NewExecutor returns an executor whose commands use the streams passed.
+func NewExecutor(newRoot NewRootFunc, in io.Reader, out, errOut io.Writer) *Executor {
+	return &Executor{
+		newRoot: newRoot,
+		in:      in,
+		out:     out,
+		errOut:  errOut,
+	}
+}
+
+// Root returns a new root command. It should be used to inspect the commands only,
+// use Execute to run them.
+func (e *Executor) Root() *cobra.Command {
+	root := e.newRoot(e.in)
+	root.SetIn(e.in)
+	root.SetOut(e.out)
+	root.SetErr(e.errOut)
+	return root
+}
+
+// Execute runs the command matching the arguments.
+func (e *Executor) Execute(args []string) error {
+	if args == nil {
+		// Cobra takes the process arguments when they are nil
+		args = []string{}
+	}
+
+	root := e.Root()
+	root.SetArgs(args)
+	// Keep the cleanups registered outside the command, like the session ones
+	removeCleanups := sig.Signal.ScopeCleanups()
+	defer removeCleanups()
+
+	return root.Execute()
+}
+
+// In returns the executor input.
+func (e *Executor) In() io.Reader {
+	return e.in
+}
+
+// Out returns the executor output.
+func (e *Executor) Out() io.Writer {
+	return e.out
+}
+
+// Err returns the executor error output.
+func (e *Executor) Err() io.Writer {
+	return e.errOut
+}
@@ -0,0 +1,73 @@
+package cmdutil
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestExecutorIsolation(t *testing.T) {
+	newRoot := func(r io.Reader) *cobra.Command {
+		var length int
+		root := &cobra.Command{Use: "kure", SilenceErrors: true, SilenceUsage: true}
+		gen := &cobra.Command{
+			Use: "gen",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				input, err := io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%d %s\n", length, input)
+				return nil
+			},
+		}
+		gen.Flags().IntVarP(&length, "length", "l", 8, "")
+		root.AddCommand(gen)
+		return root
+	}
+
+	out := new(bytes.Buffer)
+	executor := NewExecutor(newRoot, strings.NewReader("input"), out, new(bytes.Buffer))
+
+	if err := executor.Execute([]string{"gen", "-l", "20"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := executor.Execute([]string{"gen"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "20 input\n8 \n"
+	if got := out.String(); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	if executor.Root() == executor.Root() {
+		t.Error("Expected a new root on every call")
+	}
+}
+
+func TestExecutorNilArgs(t *testing.T) {
+	executed := false
+	newRoot := func(r io.Reader) *cobra.Command {
+		return &cobra.Command{
+			Use:  "kure",
+			Args: cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				executed = true
+				return nil
+			},
+		}
+	}
+
+	executor := NewExecutor(newRoot, nil, new(bytes.Buffer), new(bytes.Buffer))
+	if err := executor.Execute(nil); err != nil {
+		t.Errorf("Expected the process arguments to be ignored, got %v", err)
+	}
+	if !executed {
+		t.Error("Expected the root command to be executed")
+	}
+}
@@ -0,0 +1,212 @@
+package cmdutil
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GGP1/kure/db/card"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/pb"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	bolt "go.etcd.io/bbolt"
+)
+
+const day = 24 * time.Hour
+
+// Expiration is a record that expired or is about to.
+type Expiration struct {
+	// Type is either "entry" or "card"
+	Type    string
+	Name    string
+	Expires time.Time
+}
+
+// Expired returns whether the record was already expired at the time passed.
+func (e Expiration) Expired(now time.Time) bool {
+	return !e.Expires.After(now)
+}
+
+// ListExpiring returns the entries and cards that are expired or expire within the duration passed,
+// sorted by expiration date.
+func ListExpiring(db *bolt.DB, within time.Duration, now time.Time) ([]Expiration, error) {
+	entries, err := entry.List(db)
+	if err != nil {
+		return nil, err
+	}
+	cards, err := card.List(db)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := now.Add(within)
+	var list []Expiration
+	for _, e := range entries {
+		if expires, ok := EntryExpiration(e); ok && !expires.After(limit) {
+			list = append(list, Expiration{Type: "entry", Name: e.Name, Expires: expires})
+		}
+	}
+	for _, c := range cards {
+		if expires, ok := CardExpiration(c); ok && !expires.After(limit) {
+			list = append(list, Expiration{Type: "card", Name: c.Name, Expires: expires})
+		}
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Expires.Equal(list[j].Expires) {
+			return list[i].Name < list[j].Name
+		}
+		return list[i].Expires.Before(list[j].Expires)
+	})
+	return list, nil
+}
+
+// SetExpires parses the expiration date entered by the user and sets it to the entry, both
+// formatted and as a timestamp.
+func SetExpires(e *pb.Entry, expires string) error {
+	exp, err := parseExpires(expires)
+	if err != nil {
+		return err
+	}
+
+	if exp.IsZero() {
+		e.Expires = "Never"
+		e.ExpiresAt = 0
+		return nil
+	}
+
+	e.Expires = exp.Format(time.RFC1123Z)
+	e.ExpiresAt = exp.Unix()
+	return nil
+}
+
+// EntryExpiration returns the entry expiration date, false if it never expires.
+func EntryExpiration(e *pb.Entry) (time.Time, bool) {
+	if e.ExpiresAt != 0 {
+		return time.Unix(e.ExpiresAt, 0).UTC(), true
+	}
+
+	// Entries stored before the timestamp was introduced only have the formatted date, they are
+	// migrated on the first login after upgrading the database
+	if e.Expires == "Never" || e.Expires == "" {
+		return time.Time{}, false
+	}
+	expires, err := time.Parse(time.RFC1123Z, e.Expires)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return expires, true
+}
+
+// SetCardExpires sets the card expiration timestamp using its expire date, it's zero if the date
+// couldn't be parsed.
+func SetCardExpires(c *pb.Card) {
+	c.ExpiresAt = 0
+	if expires, ok := parseCardExpiration(c.ExpireDate); ok {
+		c.ExpiresAt = expires.Unix()
+	}
+}
+
+// CardExpiration returns the card expiration date, false if it's unknown.
+func CardExpiration(c *pb.Card) (time.Time, bool) {
+	if c.ExpiresAt != 0 {
+		return time.Unix(c.ExpiresAt, 0).UTC(), true
+	}
+	return parseCardExpiration(c.ExpireDate)
+}
+
+// parseCardExpiration parses the expire date of a card.
+//
+// Dates without a day (mm/yy, mm/yyyy or yyyy/mm) are valid until the end of the month, so the card
+// expires on the first day of the next one.
+func parseCardExpiration(date string) (time.Time, bool) {
+	date = strings.ReplaceAll(strings.TrimSpace(date), "-", "/")
+	if date == "" {
+		return time.Time{}, false
+	}
+
+	for _, layout := range []string{"01/06", "01/2006", "2006/01"} {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t.AddDate(0, 1, 0), true
+		}
+	}
+	for _, layout := range []string{"02/01/2006", "2006/01/02"} {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// parseExpires parses the expiration date entered by the user, the zero time means it never expires.
+func parseExpires(expires string) (time.Time, error) {
+	switch strings.ToLower(expires) {
+	case "never", "", " ", "0", "0s":
+		return time.Time{}, nil
+
+	default:
+		expires = strings.ReplaceAll(expires, "-", "/")
+
+		// If the first format fails, try the second
+		exp, err := time.Parse("02/01/2006", expires)
+		if err != nil {
+			exp, err = time.Parse("2006/01/02", expires)
+			if err != nil {
+				return time.Time{}, errors.New("\"expires\" field has an invalid format. Valid formats: d/m/y or y/m/d")
+			}
+		}
+
+		return exp, nil
+	}
+}
+
+// ParseDuration is like time.ParseDuration but it also accepts a number of days, like "30d".
+func ParseDuration(s string) (time.Duration, error) {
+	if days := strings.TrimSuffix(s, "d"); days != s {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, errors.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * day, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, errors.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
+// durationValue is a flag value that accepts days besides the time.Duration units.
+type durationValue time.Duration
+
+// NewDurationValue returns a flag value that stores the duration in p, see ParseDuration.
+func NewDurationValue(p *time.Duration, value time.Duration) pflag.Value {
+	*p = value
+	return (*durationValue)(p)
+}
+
+func (d *durationValue) Set(s string) error {
+	v, err := ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = durationValue(v)
+	return nil
+}
+
+func (d *durationValue) String() string {
+	v := time.Duration(*d)
+	if v > 0 && v%day == 0 {
+		return strconv.Itoa(int(v/day)) + "d"
+	}
+	return v.String()
+}
+
+func (d *durationValue) Type() string {
+	return "duration"
+}
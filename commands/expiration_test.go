@@ -0,0 +1,148 @@
+package cmdutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GGP1/kure/db/card"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/pb"
+)
+
+func TestSetExpires(t *testing.T) {
+	e := &pb.Entry{}
+	if err := SetExpires(e, "26/06/2029"); err != nil {
+		t.Fatal(err)
+	}
+	if e.Expires != "Tue, 26 Jun 2029 00:00:00 +0000" || e.ExpiresAt != 1877126400 {
+		t.Errorf("Unexpected expiration: %q %d", e.Expires, e.ExpiresAt)
+	}
+
+	if err := SetExpires(e, "never"); err != nil {
+		t.Fatal(err)
+	}
+	if e.Expires != "Never" || e.ExpiresAt != 0 {
+		t.Errorf("Expected the entry to never expire, got %q %d", e.Expires, e.ExpiresAt)
+	}
+
+	if err := SetExpires(e, "invalid"); err == nil {
+		t.Error("Expected an error and got nil")
+	}
+}
+
+func TestEntryExpiration(t *testing.T) {
+	expected := time.Date(2029, time.June, 26, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		desc  string
+		entry *pb.Entry
+		ok    bool
+	}{
+		{desc: "Timestamp", entry: &pb.Entry{Expires: "Never", ExpiresAt: expected.Unix()}, ok: true},
+		{desc: "Formatted date", entry: &pb.Entry{Expires: "Tue, 26 Jun 2029 00:00:00 +0000"}, ok: true},
+		{desc: "Never", entry: &pb.Entry{Expires: "Never"}, ok: false},
+		{desc: "Empty", entry: &pb.Entry{}, ok: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, ok := EntryExpiration(tc.entry)
+			if ok != tc.ok {
+				t.Fatalf("Expected %v, got %v", tc.ok, ok)
+			}
+			if ok && !got.Equal(expected) {
+				t.Errorf("Expected %v, got %v", expected, got)
+			}
+		})
+	}
+}
+
+func TestParseCardExpiration(t *testing.T) {
+	cases := []struct {
+		date     string
+		expected time.Time
+	}{
+		{date: "06/29", expected: time.Date(2029, time.July, 1, 0, 0, 0, 0, time.UTC)},
+		{date: "12/2029", expected: time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{date: "2029-06", expected: time.Date(2029, time.July, 1, 0, 0, 0, 0, time.UTC)},
+		{date: "26/06/2029", expected: time.Date(2029, time.June, 26, 0, 0, 0, 0, time.UTC)},
+		{date: "2029/06/26", expected: time.Date(2029, time.June, 26, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.date, func(t *testing.T) {
+			got, ok := parseCardExpiration(tc.date)
+			if !ok {
+				t.Fatal("Failed parsing date")
+			}
+			if !got.Equal(tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+
+	for _, date := range []string{"", "soon", "13/29"} {
+		if _, ok := parseCardExpiration(date); ok {
+			t.Errorf("%q: expected parsing to fail", date)
+		}
+	}
+}
+
+func TestListExpiring(t *testing.T) {
+	db := SetContext(t, "../db/testdata/database")
+	now := time.Date(2029, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	err := entry.Create(db,
+		&pb.Entry{Name: "expired", Expires: "Never", ExpiresAt: now.AddDate(0, 0, -1).Unix()},
+		&pb.Entry{Name: "later", Expires: "Never", ExpiresAt: now.AddDate(1, 0, 0).Unix()},
+		&pb.Entry{Name: "never", Expires: "Never"},
+		&pb.Entry{Name: "soon", Expires: now.AddDate(0, 0, 20).Format(time.RFC1123Z)},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := card.Create(db, &pb.Card{Name: "card", ExpireDate: "06/29"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ListExpiring(db, 30*day, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"expired", "soon", "card"}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+	for i, e := range got {
+		if e.Name != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], e.Name)
+		}
+	}
+	if !got[0].Expired(now) || got[1].Expired(now) {
+		t.Error("Expected only the first record to be expired")
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"30d":  30 * day,
+		"0d":   0,
+		"720h": 30 * day,
+		"90m":  90 * time.Minute,
+	}
+	for s, expected := range cases {
+		got, err := ParseDuration(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != expected {
+			t.Errorf("%q: expected %v, got %v", s, expected, got)
+		}
+	}
+
+	for _, s := range []string{"", "d", "-1d", "1.5d", "week"} {
+		if _, err := ParseDuration(s); err == nil {
+			t.Errorf("%q: expected an error and got nil", s)
+		}
+	}
+}
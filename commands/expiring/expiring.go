@@ -0,0 +1,105 @@
+package expiring
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/GGP1/kure/auth"
+	cmdutil "github.com/GGP1/kure/commands"
+
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+)
+
+const example = `
+* List the records expired or expiring in the next 30 days
+kure expiring
+
+* List the records expiring in the next week
+kure expiring --within 7d
+
+* Print the list in JSON format
+kure expiring --output json`
+
+const day = 24 * time.Hour
+
+type expiringOptions struct {
+	within time.Duration
+}
+
+// NewCmd returns a new command.
+func NewCmd(db *bolt.DB) *cobra.Command {
+	opts := expiringOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "expiring",
+		Short: "List expired and expiring records",
+		Long: `List the entries and cards that are expired or expire soon, sorted by date.
+
+Cards expiration dates are read from their "expire date" field. Dates without a day (mm/yy, mm/yyyy or yyyy/mm) are valid until the end of the month, cards whose date has a different format are not listed.
+
+The period accepts days (30d) as well as hours, minutes and seconds (720h).`,
+		Example: example,
+		Args:    cobra.NoArgs,
+		PreRunE: auth.Login(db),
+		RunE:    runExpiring(db, &opts),
+	}
+
+	cmd.Flags().Var(cmdutil.NewDurationValue(&opts.within, 30*day), "within", "list records expiring within this period")
+
+	return cmd
+}
+
+func runExpiring(db *bolt.DB, opts *expiringOptions) cmdutil.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		format, err := cmdutil.OutputFormat(cmd)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		records, err := cmdutil.ListExpiring(db, opts.within, now)
+		if err != nil {
+			return err
+		}
+
+		if format != cmdutil.OutputDefault {
+			out := &cmdutil.ExpiringOutput{Records: make([]cmdutil.ExpiringRecord, 0, len(records))}
+			for _, r := range records {
+				out.Records = append(out.Records, cmdutil.ExpiringRecord{
+					Type:    r.Type,
+					Name:    r.Name,
+					Expires: r.Expires.Format(time.RFC3339),
+					Expired: r.Expired(now),
+				})
+			}
+			return cmdutil.WriteOutput(cmd.OutOrStdout(), format, out)
+		}
+
+		w := cmd.OutOrStdout()
+		if len(records) == 0 {
+			fmt.Fprintf(w, "No records expired or expiring in the next %s\n", period(opts.within))
+			return nil
+		}
+
+		for _, r := range records {
+			line := fmt.Sprintf("%s  %-5s  %s", r.Expires.Format("02/01/2006"), r.Type, r.Name)
+			if r.Expired(now) {
+				line += " (expired)"
+			}
+			fmt.Fprintln(w, line)
+		}
+		return nil
+	}
+}
+
+// period returns the duration in days if possible.
+func period(d time.Duration) string {
+	if d%day == 0 {
+		if days := int(d / day); days != 1 {
+			return fmt.Sprintf("%d days", days)
+		}
+		return "day"
+	}
+	return d.String()
+}
@@ -0,0 +1,90 @@
+package expiring
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/card"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/pb"
+)
+
+func TestExpiring(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+	now := time.Now()
+
+	err := entry.Create(db,
+		&pb.Entry{Name: "expired", Expires: "Never", ExpiresAt: now.AddDate(0, 0, -1).Unix()},
+		&pb.Entry{Name: "soon", Expires: "Never", ExpiresAt: now.AddDate(0, 0, 5).Unix()},
+		&pb.Entry{Name: "later", Expires: "Never", ExpiresAt: now.AddDate(0, 0, 20).Unix()},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := card.Create(db, &pb.Card{Name: "card", ExpireDate: "01/2000"}); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	cmd := NewCmd(db)
+	cmdutil.AddOutputFlag(cmd)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--within", "7d", "--output", "json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got cmdutil.ExpiringOutput
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		typ, name string
+		expired   bool
+	}{
+		{typ: "card", name: "card", expired: true},
+		{typ: "entry", name: "expired", expired: true},
+		{typ: "entry", name: "soon", expired: false},
+	}
+	if len(got.Records) != len(expected) {
+		t.Fatalf("Expected %d records, got %+v", len(expected), got.Records)
+	}
+	for i, r := range got.Records {
+		e := expected[i]
+		if r.Type != e.typ || r.Name != e.name || r.Expired != e.expired {
+			t.Errorf("Expected %+v, got %+v", e, r)
+		}
+	}
+}
+
+func TestExpiringNone(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+	buf := new(bytes.Buffer)
+	cmd := NewCmd(db)
+	cmd.SetOut(buf)
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "No records expired or expiring in the next 30 days\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestInvalidWithin(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+	cmd := NewCmd(db)
+	cmd.SetArgs([]string{"--within", "soon"})
+	if err := cmd.Execute(); err == nil {
+		t.Error("Expected an error and got nil")
+	}
+}
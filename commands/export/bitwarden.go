@@ -0,0 +1,212 @@
+package export
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GGP1/kure/bitwarden"
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/card"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/db/file"
+	"github.com/GGP1/kure/db/totp"
+	"github.com/GGP1/kure/pb"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// exportBitwarden creates a Bitwarden JSON export in path, it's encrypted only if the password is not nil.
+func exportBitwarden(db *bolt.DB, path string, password []byte, kdf bitwarden.KDF) error {
+	export, err := bitwardenExport(db)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0600)
+	if err != nil {
+		return errors.Wrap(err, "creating the file")
+	}
+
+	if password != nil {
+		err = bitwarden.WriteEncrypted(f, export, password, kdf)
+	} else {
+		err = bitwarden.Write(f, export)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "closing file")
+	}
+	return nil
+}
+
+// bitwardenExport converts entries to logins, cards to cards and the text files inside the notes
+// and identities folders to secure notes and identities. Folders are converted to Bitwarden folders.
+func bitwardenExport(db *bolt.DB) (*bitwarden.Export, error) {
+	entries, err := entry.List(db)
+	if err != nil {
+		return nil, err
+	}
+	cards, err := card.List(db)
+	if err != nil {
+		return nil, err
+	}
+	files, err := file.List(db)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &bitwardenBuilder{export: &bitwarden.Export{}, folders: make(map[string]string)}
+
+	for _, e := range entries {
+		item, err := b.newItem(bitwarden.LoginType, e.Name)
+		if err != nil {
+			return nil, err
+		}
+		item.Notes = e.Notes
+		item.Login = bitwardenLogin(db, e)
+		if expires, ok := cmdutil.EntryExpiration(e); ok {
+			item.Fields = append(item.Fields, bitwarden.Field{Name: bitwarden.ExpiresField, Value: expires.Format(time.RFC1123Z)})
+		}
+		if e.PreviousPassword != "" {
+			item.PasswordHistory = []bitwarden.PasswordHistory{{
+				LastUsedDate: formatDate(e.PasswordUpdatedAt),
+				Password:     e.PreviousPassword,
+			}}
+		}
+		b.add(item)
+	}
+
+	for _, c := range cards {
+		item, err := b.newItem(bitwarden.CardType, c.Name)
+		if err != nil {
+			return nil, err
+		}
+		item.Notes = c.Notes
+		item.Card = &bitwarden.Card{Brand: c.Type, Number: c.Number, Code: c.SecurityCode}
+		if month, year, ok := splitExpireDate(c.ExpireDate); ok {
+			item.Card.ExpMonth, item.Card.ExpYear = month, year
+		} else if c.ExpireDate != "" {
+			item.Fields = append(item.Fields, bitwarden.Field{Name: bitwarden.ExpireDateField, Value: c.ExpireDate})
+		}
+		b.add(item)
+	}
+
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name, ".txt") {
+			continue
+		}
+		name := strings.TrimSuffix(f.Name, ".txt")
+
+		switch {
+		case strings.HasPrefix(name, bitwarden.NotesFolder):
+			item, err := b.newItem(bitwarden.SecureNoteType, strings.TrimPrefix(name, bitwarden.NotesFolder))
+			if err != nil {
+				return nil, err
+			}
+			item.Notes = string(f.Content)
+			item.SecureNote = &bitwarden.SecureNote{}
+			b.add(item)
+
+		case strings.HasPrefix(name, bitwarden.IdentitiesFolder):
+			item, err := b.newItem(bitwarden.IdentityType, strings.TrimPrefix(name, bitwarden.IdentitiesFolder))
+			if err != nil {
+				return nil, err
+			}
+			item.Identity, item.Fields, item.Notes = bitwarden.ParseIdentity(string(f.Content))
+			b.add(item)
+		}
+	}
+
+	return b.export, nil
+}
+
+func bitwardenLogin(db *bolt.DB, e *pb.Entry) *bitwarden.Login {
+	login := &bitwarden.Login{Username: e.Username, Password: e.Password}
+	if e.URL != "" {
+		login.URIs = []bitwarden.URI{{URI: e.URL}}
+	}
+	if e.PasswordUpdatedAt != 0 {
+		login.PasswordRevisionDate = formatDate(e.PasswordUpdatedAt)
+	}
+	if t, err := totp.Get(db, e.Name); err == nil {
+		login.TOTP = otpURL(t)
+	}
+	return login
+}
+
+// bitwardenBuilder creates the export items and the folders they are in.
+type bitwardenBuilder struct {
+	export *bitwarden.Export
+	// folders maps folder names to their identifiers
+	folders map[string]string
+}
+
+// newItem returns an item inside the folder of the record name passed.
+func (b *bitwardenBuilder) newItem(typ bitwarden.ItemType, name string) (bitwarden.Item, error) {
+	id, err := newID()
+	if err != nil {
+		return bitwarden.Item{}, err
+	}
+	dir, base := splitName(name)
+	item := bitwarden.Item{ID: id, Type: typ, Name: base}
+
+	if dir != "" {
+		folderID, ok := b.folders[dir]
+		if !ok {
+			folderID, err = newID()
+			if err != nil {
+				return bitwarden.Item{}, err
+			}
+			b.folders[dir] = folderID
+			b.export.Folders = append(b.export.Folders, bitwarden.Folder{ID: folderID, Name: dir})
+		}
+		item.FolderID = &folderID
+	}
+
+	return item, nil
+}
+
+func (b *bitwardenBuilder) add(item bitwarden.Item) {
+	b.export.Items = append(b.export.Items, item)
+}
+
+// splitExpireDate returns the month and year of a card expire date.
+func splitExpireDate(date string) (month, year string, ok bool) {
+	date = strings.ReplaceAll(strings.TrimSpace(date), "-", "/")
+	for _, layout := range []string{"01/2006", "01/06", "2006/01", "02/01/2006", "2006/01/02"} {
+		if t, err := time.Parse(layout, date); err == nil {
+			return strconv.Itoa(int(t.Month())), strconv.Itoa(t.Year()), true
+		}
+	}
+	return "", "", false
+}
+
+// formatDate formats the Unix time passed as Bitwarden does, the current time is used if it's zero.
+func formatDate(unix int64) string {
+	t := time.Now()
+	if unix != 0 {
+		t = time.Unix(unix, 0)
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// newID returns a random version 4 UUID.
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "generating identifier")
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
@@ -0,0 +1,200 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/GGP1/kure/bitwarden"
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/card"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/db/file"
+	"github.com/GGP1/kure/db/totp"
+	"github.com/GGP1/kure/pb"
+)
+
+func TestExportBitwarden(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+	// The records are the ones TestImportBitwarden expects after importing the export
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	updated := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	err := entry.Create(db,
+		&pb.Entry{
+			Name:              "github",
+			Username:          "gopher",
+			Password:          "github123",
+			URL:               "https://github.com",
+			Notes:             "Notes",
+			Expires:           expires.Format(time.RFC1123Z),
+			ExpiresAt:         expires.Unix(),
+			PasswordUpdatedAt: updated.Unix(),
+			PreviousPassword:  "old123",
+		},
+		&pb.Entry{Name: "work/mail", Password: "mail123", Expires: "Never"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := totp.Create(db, &pb.TOTP{Name: "github", Raw: "JBSWY3DPEHPK3PXP", Digits: 6}); err != nil {
+		t.Fatal(err)
+	}
+	cards := []*pb.Card{
+		{Name: "bank/visa", Type: "Visa", Number: "4111111111111111", SecurityCode: "123", ExpireDate: "05/2030", Notes: "Notes"},
+		{Name: "gift", Number: "1234", ExpireDate: "never"},
+	}
+	for _, c := range cards {
+		if err := card.Create(db, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := []*pb.File{
+		{Name: "notes/recovery.txt", Content: []byte("Recovery codes")},
+		{Name: "identities/gopher.txt", Content: []byte("First name: Gopher\nEmail: gopher@golang.org\nShoe size: 42\n\nNotes")},
+		{Name: "images/gopher.png", Content: []byte("not exported")},
+	}
+	for _, f := range files {
+		if err := file.Create(db, f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "test.json")
+	kdf := bitwarden.KDF{Type: bitwarden.PBKDF2, Iterations: 1}
+	if err := exportBitwarden(db, path, []byte("bitwarden"), kdf); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	got, err := bitwarden.Read(f, func() ([]byte, error) { return []byte("bitwarden"), nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []bitwarden.Item{
+		{
+			Type:  bitwarden.LoginType,
+			Name:  "github",
+			Notes: "Notes",
+			Fields: []bitwarden.Field{
+				{Name: bitwarden.ExpiresField, Value: expires.Format(time.RFC1123Z)},
+			},
+			Login: &bitwarden.Login{
+				URIs:                 []bitwarden.URI{{URI: "https://github.com"}},
+				Username:             "gopher",
+				Password:             "github123",
+				TOTP:                 "otpauth://totp/github?digits=6&period=30&secret=JBSWY3DPEHPK3PXP",
+				PasswordRevisionDate: "2024-01-01T00:00:00Z",
+			},
+			PasswordHistory: []bitwarden.PasswordHistory{{LastUsedDate: "2024-01-01T00:00:00Z", Password: "old123"}},
+		},
+		{
+			Type:  bitwarden.LoginType,
+			Name:  "mail",
+			Login: &bitwarden.Login{Password: "mail123"},
+		},
+		{
+			Type:  bitwarden.CardType,
+			Name:  "visa",
+			Notes: "Notes",
+			Card:  &bitwarden.Card{Brand: "Visa", Number: "4111111111111111", ExpMonth: "5", ExpYear: "2030", Code: "123"},
+		},
+		{
+			Type:   bitwarden.CardType,
+			Name:   "gift",
+			Fields: []bitwarden.Field{{Name: bitwarden.ExpireDateField, Value: "never"}},
+			Card:   &bitwarden.Card{Number: "1234"},
+		},
+		{
+			Type:     bitwarden.IdentityType,
+			Name:     "gopher",
+			Notes:    "Notes",
+			Fields:   []bitwarden.Field{{Name: "Shoe size", Value: "42"}},
+			Identity: &bitwarden.Identity{FirstName: "Gopher", Email: "gopher@golang.org"},
+		},
+		{
+			Type:       bitwarden.SecureNoteType,
+			Name:       "recovery",
+			Notes:      "Recovery codes",
+			SecureNote: &bitwarden.SecureNote{},
+		},
+	}
+	expectedFolders := map[string]string{"mail": "work", "visa": "bank"}
+
+	if len(got.Items) != len(expected) {
+		t.Fatalf("Expected %d items, got %d", len(expected), len(got.Items))
+	}
+	for i, item := range got.Items {
+		if item.ID == "" {
+			t.Errorf("%q has no identifier", item.Name)
+		}
+		if folder := got.FolderName(item); folder != expectedFolders[item.Name] {
+			t.Errorf("Expected %q folder to be %q, got %q", item.Name, expectedFolders[item.Name], folder)
+		}
+
+		item.ID, item.FolderID = "", nil
+		if !reflect.DeepEqual(expected[i], item) {
+			t.Errorf("Expected %+v, got %+v", expected[i], item)
+		}
+	}
+}
+
+func TestExportBitwardenUnencrypted(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+	if err := entry.Create(db, &pb.Entry{Name: "test", Password: "test", Expires: "Never"}); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "test.json")
+	if err := exportBitwarden(db, path, nil, bitwarden.KDF{}); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	got, err := bitwarden.Read(f, func() ([]byte, error) {
+		t.Fatal("Unencrypted exports shouldn't ask for a password")
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Items) != 1 || got.Items[0].Login.Password != "test" {
+		t.Errorf("Expected the test entry, got %+v", got.Items)
+	}
+}
+
+func TestSplitExpireDate(t *testing.T) {
+	cases := []struct {
+		date  string
+		month string
+		year  string
+		ok    bool
+	}{
+		{date: "05/2030", month: "5", year: "2030", ok: true},
+		{date: "12/30", month: "12", year: "2030", ok: true},
+		{date: "2030-11", month: "11", year: "2030", ok: true},
+		{date: "2030/01/31", month: "1", year: "2030", ok: true},
+		{date: "never", ok: false},
+	}
+
+	for _, tc := range cases {
+		month, year, ok := splitExpireDate(tc.date)
+		if month != tc.month || year != tc.year || ok != tc.ok {
+			t.Errorf("%q: expected (%q, %q, %v), got (%q, %q, %v)", tc.date, tc.month, tc.year, tc.ok, month, year, ok)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package export
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/GGP1/kure/db/totp"
+	"github.com/GGP1/kure/pb"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// browsers supported, their exports only contain website passwords.
+var browsers = map[string]struct{}{
+	"chrome":  {},
+	"edge":    {},
+	"firefox": {},
+	"safari":  {},
+}
+
+// browserRecords formats the entries as the browser passwords exports, so they can be imported back.
+//
+// Browsers only store passwords for websites, entries without a URL are not exported.
+func browserRecords(db *bolt.DB, browser string, entries []*pb.Entry) ([]string, [][]string) {
+	var headers []string
+	switch browser {
+	case "chrome", "edge":
+		headers = []string{"name", "url", "username", "password", "note"}
+	case "firefox":
+		headers = []string{"url", "username", "password", "httpRealm", "formActionOrigin", "guid", "timeCreated", "timeLastUsed", "timePasswordChanged"}
+	case "safari":
+		headers = []string{"Title", "URL", "Username", "Password", "Notes", "OTPAuth"}
+	}
+
+	records := make([][]string, 0, len(entries))
+	for _, e := range entries {
+		URL, ok := websiteURL(e.URL)
+		if !ok {
+			continue
+		}
+		_, name := splitName(e.Name)
+
+		switch browser {
+		case "chrome", "edge":
+			records = append(records, []string{name, URL, e.Username, e.Password, e.Notes})
+
+		case "firefox":
+			changed := ""
+			if e.PasswordUpdatedAt != 0 {
+				changed = strconv.FormatInt(e.PasswordUpdatedAt*1000, 10)
+			}
+			records = append(records, []string{URL, e.Username, e.Password, "", "", "", "", "", changed})
+
+		case "safari":
+			otp := ""
+			if t, err := totp.Get(db, e.Name); err == nil {
+				otp = otpURL(t)
+			}
+			records = append(records, []string{name, URL, e.Username, e.Password, e.Notes, otp})
+		}
+	}
+
+	return headers, records
+}
+
+// websiteURL returns the URL with a scheme, browsers reject the ones without it.
+func websiteURL(rawURL string) (string, bool) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return "", false
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+
+	URL, err := url.Parse(rawURL)
+	if err != nil || URL.Host == "" {
+		return "", false
+	}
+	return rawURL, true
+}
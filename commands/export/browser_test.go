@@ -0,0 +1,73 @@
+package export
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/db/totp"
+	"github.com/GGP1/kure/pb"
+)
+
+func TestBrowserRecords(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+	updated := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	err := entry.Create(db,
+		&pb.Entry{Name: "work/github", Username: "gopher", Password: "github123", URL: "https://github.com", Notes: "Notes", PasswordUpdatedAt: updated},
+		&pb.Entry{Name: "go", Username: "gopher", Password: "go123", URL: "go.dev"},
+		&pb.Entry{Name: "ssh", Password: "ssh123"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := totp.Create(db, &pb.TOTP{Name: "work/github", Raw: "JBSWY3DPEHPK3PXP", Digits: 6}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		browser  string
+		expected [][]string
+	}{
+		{
+			browser: "chrome",
+			expected: [][]string{
+				{"name", "url", "username", "password", "note"},
+				{"go", "https://go.dev", "gopher", "go123", ""},
+				{"github", "https://github.com", "gopher", "github123", "Notes"},
+			},
+		},
+		{
+			browser: "firefox",
+			expected: [][]string{
+				{"url", "username", "password", "httpRealm", "formActionOrigin", "guid", "timeCreated", "timeLastUsed", "timePasswordChanged"},
+				{"https://go.dev", "gopher", "go123", "", "", "", "", "", ""},
+				{"https://github.com", "gopher", "github123", "", "", "", "", "", "1704067200000"},
+			},
+		},
+		{
+			browser: "safari",
+			expected: [][]string{
+				{"Title", "URL", "Username", "Password", "Notes", "OTPAuth"},
+				{"go", "https://go.dev", "gopher", "go123", "", ""},
+				{"github", "https://github.com", "gopher", "github123", "Notes", "otpauth://totp/work%2Fgithub?digits=6&period=30&secret=JBSWY3DPEHPK3PXP"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.browser, func(t *testing.T) {
+			headers, records, err := fmtEntries(db, tc.browser)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := append([][]string{headers}, records...)
+			if !reflect.DeepEqual(tc.expected, got) {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
@@ -3,14 +3,17 @@ package export
 import (
 	"encoding/csv"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/GGP1/kure/auth"
+	"github.com/GGP1/kure/bitwarden"
 	cmdutil "github.com/GGP1/kure/commands"
 	"github.com/GGP1/kure/db/entry"
 	"github.com/GGP1/kure/db/totp"
+	"github.com/GGP1/kure/kdbx"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -18,10 +21,22 @@ import (
 )
 
 const example = `
-kure export <manager-name> -p path/to/file`
+kure export <manager-name> -p path/to/file
+
+* Export to a KeePass database:
+kure export keepassxc --kdbx -p path/to/file.kdbx
+
+* Export to a CSV file a browser can import:
+kure export firefox -p path/to/passwords.csv
+
+* Export to a password protected Bitwarden JSON file:
+kure export bitwarden --json --encrypt -p path/to/file.json`
 
 type exportOptions struct {
-	path string
+	path    string
+	kdbx    bool
+	json    bool
+	encrypt bool
 }
 
 // NewCmd returns a new command.
@@ -35,22 +50,32 @@ func NewCmd(db *bolt.DB) *cobra.Command {
 		
 This command creates a CSV file with all the entries unencrypted, make sure to delete it after it's used.
 
+Use the kdbx flag to create an encrypted KeePass database (KDBX 4) instead, a password for it will be requested. Folders are exported as groups, TOTPs as one-time passwords and the files stored inside an entry (named "<entry>/<file>") as its attachments. Cards, Wi-Fi networks and the files and TOTPs without an entry are exported as standalone entries.
+
+Use the json flag to create a Bitwarden JSON export, it includes entries as logins (with their TOTPs), cards, and the text files inside the "notes" and "identities" folders as secure notes and identities. Add the encrypt flag to protect it with a password.
+
+Browser exports only include the entries with a URL, in the format each browser imports.
+
 Supported:
 	• 1Password
 	• Bitwarden
+	• Chrome
+	• Edge
+	• Firefox
    	• Keepass/X/XC
-   	• Lastpass`,
+   	• Lastpass
+	• Safari`,
 		Example: example,
 		Args:    managersSupported(),
 		PreRunE: auth.Login(db),
 		RunE:    runExport(db, &opts),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			// Reset variables (session)
-			opts = exportOptions{}
-		},
 	}
 
-	cmd.Flags().StringVarP(&opts.path, "path", "p", "", "destination file path")
+	f := cmd.Flags()
+	f.StringVarP(&opts.path, "path", "p", "", "destination file path")
+	f.BoolVar(&opts.kdbx, "kdbx", false, "create a KeePass database instead of a CSV file")
+	f.BoolVar(&opts.json, "json", false, "create a Bitwarden JSON export instead of a CSV file")
+	f.BoolVar(&opts.encrypt, "encrypt", false, "protect the JSON export with a password")
 
 	return cmd
 }
@@ -63,7 +88,25 @@ func runExport(db *bolt.DB, opts *exportOptions) cmdutil.RunEFunc {
 		if opts.path == "" {
 			return cmdutil.ErrInvalidPath
 		}
+		if opts.encrypt && !opts.json {
+			return errors.New("the encrypt flag requires the json one")
+		}
+
 		ext := filepath.Ext(opts.path)
+		switch {
+		case opts.kdbx:
+			if ext == "" || ext == "." {
+				opts.path += ".kdbx"
+			}
+			return runKDBX(db, cmd.OutOrStdout(), manager, opts.path)
+
+		case opts.json:
+			if ext == "" || ext == "." {
+				opts.path += ".json"
+			}
+			return runJSON(db, cmd.OutOrStdout(), manager, opts.path, opts.encrypt)
+		}
+
 		if ext == "" || ext == "." {
 			opts.path += ".csv"
 		}
@@ -78,11 +121,63 @@ func runExport(db *bolt.DB, opts *exportOptions) cmdutil.RunEFunc {
 		}
 
 		abs, _ := filepath.Abs(opts.path)
-		fmt.Println("Created CSV file at", abs)
+		fmt.Fprintln(cmd.OutOrStdout(), "Created CSV file at", abs)
 		return nil
 	}
 }
 
+func runKDBX(db *bolt.DB, w io.Writer, manager, path string) error {
+	if !strings.HasPrefix(manager, "keepass") {
+		return errors.New("the kdbx format is only supported by Keepass/X/XC")
+	}
+
+	password, err := auth.AskPassword("New KDBX password", true)
+	if err != nil {
+		return err
+	}
+	pwd, err := password.Open()
+	if err != nil {
+		return errors.Wrap(err, "opening enclave")
+	}
+	defer pwd.Destroy()
+
+	if err := exportKDBX(db, path, pwd.Bytes(), kdbx.DefaultOptions()); err != nil {
+		return err
+	}
+
+	abs, _ := filepath.Abs(path)
+	fmt.Fprintln(w, "Created KDBX file at", abs)
+	return nil
+}
+
+func runJSON(db *bolt.DB, w io.Writer, manager, path string, encrypt bool) error {
+	if manager != "bitwarden" {
+		return errors.New("the json format is only supported by Bitwarden")
+	}
+
+	var password []byte
+	if encrypt {
+		enclave, err := auth.AskPassword("New export password", true)
+		if err != nil {
+			return err
+		}
+		pwd, err := enclave.Open()
+		if err != nil {
+			return errors.Wrap(err, "opening enclave")
+		}
+		defer pwd.Destroy()
+		password = pwd.Bytes()
+	}
+
+	if err := exportBitwarden(db, path, password, bitwarden.DefaultKDF()); err != nil {
+		return err
+	}
+
+	abs, _ := filepath.Abs(path)
+	fmt.Fprintln(w, "Created JSON file at", abs)
+	return nil
+}
+
 func createCSV(headers []string, records [][]string, path string) error {
 	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0600)
 	if err != nil {
@@ -113,6 +208,11 @@ func fmtEntries(db *bolt.DB, manager string) ([]string, [][]string, error) {
 		return nil, nil, err
 	}
 
+	if _, ok := browsers[manager]; ok {
+		headers, records := browserRecords(db, manager, entries)
+		return headers, records, nil
+	}
+
 	headers := make([]string, 1)
 	records := make([][]string, len(entries))
 
@@ -185,12 +285,12 @@ func managersSupported() cobra.PositionalArgs {
 		manager := strings.Join(args, " ")
 
 		switch strings.ToLower(manager) {
-		case "1password", "bitwarden", "keepass", "keepassx", "keepassxc", "lastpass":
+		case "1password", "bitwarden", "chrome", "edge", "firefox", "keepass", "keepassx", "keepassxc", "lastpass", "safari":
 
 		default:
 			return errors.Errorf(`%q is not supported
 
-Managers supported: 1Password, Bitwarden, Keepass/X/XC, Lastpass`, manager)
+Managers supported: 1Password, Bitwarden, Chrome, Edge, Firefox, Keepass/X/XC, Lastpass, Safari`, manager)
 		}
 		return nil
 	}
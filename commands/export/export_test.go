@@ -229,10 +229,6 @@ func TestSplitName(t *testing.T) {
 	}
 }
 
-func TestPostRun(t *testing.T) {
-	NewCmd(nil).PostRun(nil, nil)
-}
-
 func createEntry(t *testing.T, db *bolt.DB) {
 	t.Helper()
 	e := &pb.Entry{
@@ -0,0 +1,231 @@
+package export
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/card"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/db/file"
+	"github.com/GGP1/kure/db/totp"
+	"github.com/GGP1/kure/db/wifi"
+	"github.com/GGP1/kure/kdbx"
+	"github.com/GGP1/kure/pb"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// exportKDBX creates a KeePass database in path containing all the records, encrypted with the
+// password passed.
+func exportKDBX(db *bolt.DB, path string, password []byte, opts kdbx.Options) error {
+	database, err := kdbxDatabase(db)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0600)
+	if err != nil {
+		return errors.Wrap(err, "creating the file")
+	}
+
+	if err := kdbx.Write(f, database, password, opts); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "closing file")
+	}
+	return nil
+}
+
+// kdbxDatabase builds a KeePass database with the records stored, folders are converted to groups.
+//
+// Files are attached to the entry they are stored in, TOTPs are added to the entry with the same name.
+// Cards, Wi-Fi networks and the files and TOTPs that don't belong to an entry are exported as
+// standalone entries.
+func kdbxDatabase(db *bolt.DB) (*kdbx.Database, error) {
+	entries, err := entry.List(db)
+	if err != nil {
+		return nil, err
+	}
+	files, err := file.List(db)
+	if err != nil {
+		return nil, err
+	}
+	totps, err := totp.List(db)
+	if err != nil {
+		return nil, err
+	}
+	cards, err := card.List(db)
+	if err != nil {
+		return nil, err
+	}
+	wifis, err := wifi.List(db)
+	if err != nil {
+		return nil, err
+	}
+
+	entryNames := make(map[string]struct{}, len(entries))
+	for _, e := range entries {
+		entryNames[e.Name] = struct{}{}
+	}
+
+	attachments := make(map[string][]kdbx.Attachment)
+	var standalone []*pb.File
+	for _, f := range files {
+		dir, name := splitName(f.Name)
+		if _, ok := entryNames[dir]; !ok {
+			standalone = append(standalone, f)
+			continue
+		}
+		attachments[dir] = append(attachments[dir], kdbx.Attachment{Name: name, Data: f.Content})
+	}
+
+	database := &kdbx.Database{Name: "Kure"}
+	for _, e := range entries {
+		dir, name := splitName(e.Name)
+		group := groupAt(&database.Root, dir)
+		group.Entries = append(group.Entries, kdbxEntry(e, name, attachments[e.Name]))
+	}
+
+	// One-time passwords are added to the entry with the same name, if there is one
+	for _, t := range totps {
+		dir, name := splitName(t.Name)
+		group := groupAt(&database.Root, dir)
+		otp := kdbx.Field{Key: "otp", Value: otpURL(t), Protected: true}
+		if e := findEntry(group, name); e != nil {
+			e.Fields = append(e.Fields, otp)
+			continue
+		}
+		group.Entries = append(group.Entries, kdbx.Entry{
+			Fields: []kdbx.Field{{Key: kdbx.TitleField, Value: name}, otp},
+		})
+	}
+
+	for _, f := range standalone {
+		dir, name := splitName(f.Name)
+		group := groupAt(&database.Root, dir)
+		group.Entries = append(group.Entries, kdbx.Entry{
+			Fields:      []kdbx.Field{{Key: kdbx.TitleField, Value: name}},
+			Attachments: []kdbx.Attachment{{Name: name, Data: f.Content}},
+		})
+	}
+
+	for _, c := range cards {
+		dir, name := splitName(c.Name)
+		group := groupAt(&database.Root, dir)
+		group.Entries = append(group.Entries, kdbxCard(c, name))
+	}
+
+	for _, w := range wifis {
+		dir, name := splitName(w.Name)
+		group := groupAt(&database.Root, dir)
+		group.Entries = append(group.Entries, kdbxWifi(w, name))
+	}
+
+	return database, nil
+}
+
+func kdbxEntry(e *pb.Entry, title string, attachments []kdbx.Attachment) kdbx.Entry {
+	entry := kdbx.Entry{
+		Fields: []kdbx.Field{
+			{Key: kdbx.TitleField, Value: title},
+			{Key: kdbx.UserNameField, Value: e.Username},
+			{Key: kdbx.PasswordField, Value: e.Password, Protected: true},
+			{Key: kdbx.URLField, Value: e.URL},
+			{Key: kdbx.NotesField, Value: e.Notes},
+		},
+		Attachments: attachments,
+	}
+
+	if expires, ok := cmdutil.EntryExpiration(e); ok {
+		entry.Expires = true
+		entry.ExpiryTime = expires
+	}
+
+	return entry
+}
+
+// kdbxCard returns a KeePass entry with the card details as custom fields.
+func kdbxCard(c *pb.Card, title string) kdbx.Entry {
+	entry := kdbx.Entry{
+		Fields: []kdbx.Field{
+			{Key: kdbx.TitleField, Value: title},
+			{Key: kdbx.NotesField, Value: c.Notes},
+			{Key: "Type", Value: c.Type},
+			{Key: "Number", Value: c.Number, Protected: true},
+			{Key: "Security code", Value: c.SecurityCode, Protected: true},
+			{Key: "Expire date", Value: c.ExpireDate},
+		},
+	}
+
+	if c.ExpiresAt != 0 {
+		entry.Expires = true
+		entry.ExpiryTime = time.Unix(c.ExpiresAt, 0).UTC()
+	}
+
+	return entry
+}
+
+// kdbxWifi returns a KeePass entry with the network password and its details as custom fields.
+func kdbxWifi(w *pb.Wifi, title string) kdbx.Entry {
+	return kdbx.Entry{
+		Fields: []kdbx.Field{
+			{Key: kdbx.TitleField, Value: title},
+			{Key: kdbx.PasswordField, Value: w.Password, Protected: true},
+			{Key: kdbx.NotesField, Value: w.Notes},
+			{Key: "SSID", Value: w.Ssid},
+			{Key: "Security", Value: w.Security},
+			{Key: "Hidden", Value: strconv.FormatBool(w.Hidden)},
+		},
+	}
+}
+
+// otpURL returns the TOTP in the format used by KeePassXC.
+func otpURL(t *pb.TOTP) string {
+	query := url.Values{}
+	query.Set("secret", strings.TrimRight(t.Raw, "="))
+	query.Set("period", "30")
+	query.Set("digits", fmt.Sprint(t.Digits))
+	return fmt.Sprintf("otpauth://totp/%s?%s", url.PathEscape(t.Name), query.Encode())
+}
+
+// groupAt returns the group at the folder path passed, creating the missing ones.
+func groupAt(root *kdbx.Group, dir string) *kdbx.Group {
+	group := root
+	if dir != "" {
+		for _, folder := range strings.Split(dir, "/") {
+			group = subgroup(group, folder)
+		}
+	}
+	return group
+}
+
+// findEntry returns the entry of the group with the title passed or nil if there is none.
+func findEntry(group *kdbx.Group, title string) *kdbx.Entry {
+	for i := range group.Entries {
+		if group.Entries[i].Fields[0].Value == title {
+			return &group.Entries[i]
+		}
+	}
+	return nil
+}
+
+// subgroup returns the group's subgroup with the name passed, it's created if it doesn't exist.
+func subgroup(group *kdbx.Group, name string) *kdbx.Group {
+	for i := range group.Groups {
+		if group.Groups[i].Name == name {
+			return &group.Groups[i]
+		}
+	}
+	group.Groups = append(group.Groups, kdbx.Group{Name: name})
+	return &group.Groups[len(group.Groups)-1]
+}
@@ -0,0 +1,151 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/card"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/db/file"
+	"github.com/GGP1/kure/db/totp"
+	"github.com/GGP1/kure/db/wifi"
+	"github.com/GGP1/kure/kdbx"
+	"github.com/GGP1/kure/pb"
+)
+
+func TestExportKDBX(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	err := entry.Create(db,
+		&pb.Entry{Name: "github", Username: "gopher", Password: "github123", URL: "https://github.com", Notes: "Notes", Expires: expires.Format(time.RFC1123Z), ExpiresAt: expires.Unix()},
+		&pb.Entry{Name: "work/servers/ssh", Password: "ssh123", Expires: "Never"},
+		&pb.Entry{Name: "work/mail", Password: "mail123", Expires: "Never"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := totp.Create(db, &pb.TOTP{Name: "github", Raw: "JBSWY3DPEHPK3PXP", Digits: 6}); err != nil {
+		t.Fatal(err)
+	}
+	if err := totp.Create(db, &pb.TOTP{Name: "work/vpn", Raw: "IFGEWRKSIFJUMR2R", Digits: 8}); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Create(db, &pb.File{Name: "github/codes.txt", Content: []byte("1234")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Create(db, &pb.File{Name: "work/notes.txt", Content: []byte("notes")}); err != nil {
+		t.Fatal(err)
+	}
+	cardExpires := time.Date(2028, 12, 1, 0, 0, 0, 0, time.UTC)
+	visa := &pb.Card{Name: "visa", Type: "Debit", Number: "4111111111111111", SecurityCode: "123", ExpireDate: "12/28", ExpiresAt: cardExpires.Unix()}
+	if err := card.Create(db, visa); err != nil {
+		t.Fatal(err)
+	}
+	if err := wifi.Create(db, &pb.Wifi{Name: "work/office", Ssid: "Office", Security: "WPA2", Password: "wifi123"}); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "test.kdbx")
+	opts := kdbx.Options{Cipher: kdbx.AES256, KDF: kdbx.Argon2id, Iterations: 1, Memory: 32, Parallelism: 1, Compress: true}
+	if err := exportKDBX(db, path, []byte("kdbx"), opts); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	got, err := kdbx.Read(f, []byte("kdbx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entryFields := func(title, username, password, url, notes string) []kdbx.Field {
+		return []kdbx.Field{
+			{Key: kdbx.TitleField, Value: title},
+			{Key: kdbx.UserNameField, Value: username},
+			{Key: kdbx.PasswordField, Value: password, Protected: true},
+			{Key: kdbx.URLField, Value: url},
+			{Key: kdbx.NotesField, Value: notes},
+		}
+	}
+	expected := &kdbx.Database{
+		Name: "Kure",
+		Root: kdbx.Group{
+			Name: "Kure",
+			Entries: []kdbx.Entry{
+				{
+					Fields: append(entryFields("github", "gopher", "github123", "https://github.com", "Notes"),
+						kdbx.Field{Key: "otp", Value: "otpauth://totp/github?digits=6&period=30&secret=JBSWY3DPEHPK3PXP", Protected: true}),
+					Attachments: []kdbx.Attachment{{Name: "codes.txt", Data: []byte("1234")}},
+					Expires:     true,
+					ExpiryTime:  expires,
+				},
+				{
+					Fields: []kdbx.Field{
+						{Key: kdbx.TitleField, Value: "visa"},
+						{Key: kdbx.NotesField, Value: ""},
+						{Key: "Type", Value: "Debit"},
+						{Key: "Number", Value: "4111111111111111", Protected: true},
+						{Key: "Security code", Value: "123", Protected: true},
+						{Key: "Expire date", Value: "12/28"},
+					},
+					Expires:    true,
+					ExpiryTime: cardExpires,
+				},
+			},
+			Groups: []kdbx.Group{
+				{
+					Name: "work",
+					Entries: []kdbx.Entry{
+						{Fields: entryFields("mail", "", "mail123", "", "")},
+						{
+							Fields: []kdbx.Field{
+								{Key: kdbx.TitleField, Value: "vpn"},
+								{Key: "otp", Value: "otpauth://totp/work%2Fvpn?digits=8&period=30&secret=IFGEWRKSIFJUMR2R", Protected: true},
+							},
+						},
+						{
+							Fields:      []kdbx.Field{{Key: kdbx.TitleField, Value: "notes.txt"}},
+							Attachments: []kdbx.Attachment{{Name: "notes.txt", Data: []byte("notes")}},
+						},
+						{
+							Fields: []kdbx.Field{
+								{Key: kdbx.TitleField, Value: "office"},
+								{Key: kdbx.PasswordField, Value: "wifi123", Protected: true},
+								{Key: kdbx.NotesField, Value: ""},
+								{Key: "SSID", Value: "Office"},
+								{Key: "Security", Value: "WPA2"},
+								{Key: "Hidden", Value: "false"},
+							},
+						},
+					},
+					Groups: []kdbx.Group{
+						{
+							Name: "servers",
+							Entries: []kdbx.Entry{
+								{Fields: entryFields("ssh", "", "ssh123", "", "")},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+
+	// The file must not be overwritten
+	if err := exportKDBX(db, path, []byte("kdbx"), opts); err == nil {
+		t.Error("Expected an error and got nil")
+	}
+}
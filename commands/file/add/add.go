@@ -57,12 +57,6 @@ The user can specify a path to a folder as well, on this occasion, Kure will ite
 		Args:    cmdutil.MustNotExist(db, cmdutil.File),
 		PreRunE: auth.Login(db),
 		RunE:    runAdd(db, r, &opts),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			// Reset variables (session)
-			opts = addOptions{
-				semaphore: 50,
-			}
-		},
 	}
 
 	f := cmd.Flags()
@@ -80,7 +74,7 @@ func runAdd(db *bolt.DB, r io.Reader, opts *addOptions) cmdutil.RunEFunc {
 		name = cmdutil.NormalizeName(name)
 
 		if opts.note {
-			return addNote(db, r, name)
+			return addNote(db, r, cmd.OutOrStdout(), name)
 		}
 
 		if opts.semaphore < 1 {
@@ -95,7 +89,7 @@ func runAdd(db *bolt.DB, r io.Reader, opts *addOptions) cmdutil.RunEFunc {
 		dir, err := os.ReadDir(opts.path)
 		if err != nil {
 			// If it's not a directory, attempt storing a file
-			return storeFile(db, opts.path, name)
+			return storeFile(db, cmd.OutOrStdout(), opts.path, name)
 		}
 
 		if len(dir) == 0 {
@@ -108,14 +102,14 @@ func runAdd(db *bolt.DB, r io.Reader, opts *addOptions) cmdutil.RunEFunc {
 		var wg sync.WaitGroup
 		sem := make(chan struct{}, opts.semaphore)
 		wg.Add(len(dir))
-		walkDir(db, dir, opts.path, name, opts.ignore, &wg, sem)
+		walkDir(cmd, db, dir, opts.path, name, opts.ignore, &wg, sem)
 		wg.Wait()
 		return nil
 	}
 }
 
 // walkDir iterates over the items of a folder and calls checkFile.
-func walkDir(db *bolt.DB, dir []os.DirEntry, path, name string, ignore bool, wg *sync.WaitGroup, sem chan struct{}) {
+func walkDir(cmd *cobra.Command, db *bolt.DB, dir []os.DirEntry, path, name string, ignore bool, wg *sync.WaitGroup, sem chan struct{}) {
 	for _, f := range dir {
 		// If it's not a directory or a regular file, skip
 		if !f.IsDir() && !f.Type().IsRegular() {
@@ -123,7 +117,7 @@ func walkDir(db *bolt.DB, dir []os.DirEntry, path, name string, ignore bool, wg
 			continue
 		}
 
-		go checkFile(db, f, path, name, ignore, wg, sem)
+		go checkFile(cmd, db, f, path, name, ignore, wg, sem)
 	}
 }
 
@@ -134,7 +128,7 @@ func walkDir(db *bolt.DB, dir []os.DirEntry, path, name string, ignore bool, wg
 // If it's a folder it repeats the process until there are no left files to store.
 //
 // Errors are not returned but logged.
-func checkFile(db *bolt.DB, file os.DirEntry, path, name string, ignore bool, wg *sync.WaitGroup, sem chan struct{}) {
+func checkFile(cmd *cobra.Command, db *bolt.DB, file os.DirEntry, path, name string, ignore bool, wg *sync.WaitGroup, sem chan struct{}) {
 	defer func() {
 		wg.Done()
 		<-sem
@@ -148,8 +142,8 @@ func checkFile(db *bolt.DB, file os.DirEntry, path, name string, ignore bool, wg
 	path = filepath.Join(path, file.Name())
 
 	if !file.IsDir() {
-		if err := storeFile(db, path, name); err != nil {
-			fmt.Fprintln(os.Stderr, "error:", err)
+		if err := storeFile(db, cmd.OutOrStdout(), path, name); err != nil {
+			fmt.Fprintln(cmd.ErrOrStderr(), "error:", err)
 		}
 		return
 	}
@@ -161,18 +155,18 @@ func checkFile(db *bolt.DB, file os.DirEntry, path, name string, ignore bool, wg
 
 	subdir, err := os.ReadDir(path)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: reading directory: %v\n", err)
+		fmt.Fprintf(cmd.ErrOrStderr(), "error: reading directory: %v\n", err)
 		return
 	}
 
 	if len(subdir) != 0 {
 		wg.Add(len(subdir))
-		go walkDir(db, subdir, path, name, ignore, wg, sem)
+		go walkDir(cmd, db, subdir, path, name, ignore, wg, sem)
 	}
 }
 
 // storeFile reads and saves a file into the database.
-func storeFile(db *bolt.DB, path, filename string) error {
+func storeFile(db *bolt.DB, w io.Writer, path, filename string) error {
 	content, err := os.ReadFile(path)
 	if err != nil {
 		return errors.Wrap(err, "reading file")
@@ -190,13 +184,13 @@ func storeFile(db *bolt.DB, path, filename string) error {
 	// all the transactions into a single one
 	abs, _ := filepath.Abs(path)
 
-	fmt.Println("Add:", abs)
+	fmt.Fprintln(w, "Add:", abs)
 	return file.Create(db, f)
 }
 
 // addNote takes input from the user and creates a file inside the "notes" folder
 // and with the .txt extension.
-func addNote(db *bolt.DB, r io.Reader, name string) error {
+func addNote(db *bolt.DB, r io.Reader, w io.Writer, name string) error {
 	name = "notes/" + name
 	if filepath.Ext(name) == "" {
 		name += ".txt"
@@ -216,6 +210,6 @@ func addNote(db *bolt.DB, r io.Reader, name string) error {
 		UpdatedAt: time.Time{}.Unix(),
 	}
 
-	fmt.Println("Add:", name)
+	fmt.Fprintln(w, "Add:", name)
 	return file.Create(db, f)
 }
@@ -152,7 +152,3 @@ func TestAddErrors(t *testing.T) {
 		})
 	}
 }
-
-func TestPostRun(t *testing.T) {
-	NewCmd(nil, nil).PostRun(nil, nil)
-}
@@ -5,10 +5,10 @@ import (
 	"io"
 
 	"github.com/GGP1/kure/auth"
+	"github.com/GGP1/kure/clip"
 	cmdutil "github.com/GGP1/kure/commands"
 	"github.com/GGP1/kure/db/file"
 
-	"github.com/atotto/clipboard"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	bolt "go.etcd.io/bbolt"
@@ -29,7 +29,7 @@ type catOptions struct {
 }
 
 // NewCmd returns a new command.
-func NewCmd(db *bolt.DB, w io.Writer) *cobra.Command {
+func NewCmd(db *bolt.DB) *cobra.Command {
 	opts := catOptions{}
 
 	cmd := &cobra.Command{
@@ -38,11 +38,7 @@ func NewCmd(db *bolt.DB, w io.Writer) *cobra.Command {
 		Example: example,
 		Args:    cmdutil.MustExist(db, cmdutil.File),
 		PreRunE: auth.Login(db),
-		RunE:    runCat(db, w, &opts),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			// Reset variables (session)
-			opts = catOptions{}
-		},
+		RunE:    runCat(db, &opts),
 	}
 
 	cmd.Flags().BoolVarP(&opts.copy, "copy", "c", false, "copy file content to the clipboard")
@@ -50,7 +46,7 @@ func NewCmd(db *bolt.DB, w io.Writer) *cobra.Command {
 	return cmd
 }
 
-func runCat(db *bolt.DB, w io.Writer, opts *catOptions) cmdutil.RunEFunc {
+func runCat(db *bolt.DB, opts *catOptions) cmdutil.RunEFunc {
 	return func(cmd *cobra.Command, args []string) error {
 		for i, name := range args {
 			if name == "" {
@@ -67,12 +63,12 @@ func runCat(db *bolt.DB, w io.Writer, opts *catOptions) cmdutil.RunEFunc {
 			buf.WriteString("\n")
 
 			if opts.copy {
-				if err := clipboard.WriteAll(buf.String()); err != nil {
+				if err := clip.Write(buf.String()); err != nil {
 					return errors.Wrap(err, "writing to clipboard")
 				}
 			}
 
-			if _, err := io.Copy(w, buf); err != nil {
+			if _, err := io.Copy(cmd.OutOrStdout(), buf); err != nil {
 				return errors.Wrap(err, "copying content")
 			}
 		}
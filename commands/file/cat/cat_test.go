@@ -42,7 +42,8 @@ func TestCat(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
 			var buf bytes.Buffer
-			cmd := NewCmd(db, &buf)
+			cmd := NewCmd(db)
+			cmd.SetOut(&buf)
 			cmd.SetArgs(tc.args)
 			cmd.Flags().Set("copy", tc.copy)
 
@@ -94,7 +95,7 @@ func TestCatErrors(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
-			cmd := NewCmd(db, nil)
+			cmd := NewCmd(db)
 			cmd.SetArgs(tc.args)
 
 			if err := cmd.Execute(); err == nil {
@@ -104,10 +105,6 @@ func TestCatErrors(t *testing.T) {
 	}
 }
 
-func TestPostRun(t *testing.T) {
-	NewCmd(nil, nil).PostRun(nil, nil)
-}
-
 func createFiles(t *testing.T, db *bolt.DB, name1, name2 string) {
 	f1 := &pb.File{
 		Name:    name1,
@@ -3,6 +3,7 @@ package edit
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -50,10 +51,6 @@ Notes:
 		Args:    cmdutil.MustExist(db, cmdutil.File),
 		PreRunE: auth.Login(db),
 		RunE:    runEdit(db, &opts),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			// Reset variables (session)
-			opts = editOptions{}
-		},
 	}
 
 	f := cmd.Flags()
@@ -93,7 +90,7 @@ func runEdit(db *bolt.DB, opts *editOptions) cmdutil.RunEFunc {
 		defer cmdutil.Erase(filename)
 
 		if opts.log {
-			logTempFilename(filename)
+			logTempFilename(cmd.OutOrStdout(), filename)
 			if err := watchFile(filename); err != nil {
 				return err
 			}
@@ -107,7 +104,7 @@ func runEdit(db *bolt.DB, opts *editOptions) cmdutil.RunEFunc {
 			return err
 		}
 
-		fmt.Printf("\n%q updated\n", name)
+		fmt.Fprintf(cmd.OutOrStdout(), "\n%q updated\n", name)
 		return nil
 	}
 }
@@ -130,8 +127,8 @@ func createTempFile(ext string, content []byte) (string, error) {
 	return f.Name(), nil
 }
 
-func logTempFilename(filename string) {
-	fmt.Printf(`Temporary file path: %s
+func logTempFilename(w io.Writer, filename string) {
+	fmt.Fprintf(w, `Temporary file path: %s
 
 Caution: if any process is accessing the file at the time of modification, Kure won't be able to erase it
 `, filepath.ToSlash(filename))
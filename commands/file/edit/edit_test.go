@@ -113,7 +113,3 @@ func TestUpdate(t *testing.T) {
 		t.Error("Failed editing file, corrupted content")
 	}
 }
-
-func TestPostRun(t *testing.T) {
-	NewCmd(nil).PostRun(nil, nil)
-}
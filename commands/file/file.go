@@ -1,7 +1,7 @@
 package file
 
 import (
-	"os"
+	"io"
 
 	fadd "github.com/GGP1/kure/commands/file/add"
 	fcat "github.com/GGP1/kure/commands/file/cat"
@@ -19,14 +19,14 @@ const example = `
 kure file (add|cat|edit|ls|mv|rm|touch)`
 
 // NewCmd returns a new command.
-func NewCmd(db *bolt.DB) *cobra.Command {
+func NewCmd(db *bolt.DB, r io.Reader) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "file",
 		Short:   "File operations",
 		Example: example,
 	}
 
-	cmd.AddCommand(fadd.NewCmd(db, os.Stdin), fcat.NewCmd(db, os.Stdout), fedit.NewCmd(db), fls.NewCmd(db), fmv.NewCmd(db), frm.NewCmd(db, os.Stdin), ftouch.NewCmd(db))
+	cmd.AddCommand(fadd.NewCmd(db, r), fcat.NewCmd(db), fedit.NewCmd(db), fls.NewCmd(db), fmv.NewCmd(db), frm.NewCmd(db, r), ftouch.NewCmd(db))
 
 	return cmd
 }
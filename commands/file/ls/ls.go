@@ -2,6 +2,7 @@ package ls
 
 import (
 	"fmt"
+	"io"
 	"path/filepath"
 	"strings"
 	"time"
@@ -11,7 +12,6 @@ import (
 	"github.com/GGP1/kure/db/file"
 	"github.com/GGP1/kure/orderedmap"
 	"github.com/GGP1/kure/pb"
-	"github.com/GGP1/kure/tree"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -55,10 +55,6 @@ func NewCmd(db *bolt.DB) *cobra.Command {
 		Args:    cmdutil.MustExistLs(db, cmdutil.File),
 		PreRunE: auth.Login(db),
 		RunE:    runLs(db, &opts),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			// Reset variables (session)
-			opts = lsOptions{}
-		},
 	}
 
 	cmd.Flags().BoolVarP(&opts.filter, "filter", "f", false, "filter by name")
@@ -78,8 +74,7 @@ func runLs(db *bolt.DB, opts *lsOptions) cmdutil.RunEFunc {
 				return err
 			}
 
-			tree.Print(files)
-			return nil
+			return cmdutil.PrintNames(cmd, files)
 		}
 
 		// Filter by name
@@ -105,22 +100,42 @@ func runLs(db *bolt.DB, opts *lsOptions) cmdutil.RunEFunc {
 				return errors.New("no files were found")
 			}
 
-			tree.Print(matches)
-			return nil
+			return cmdutil.PrintNames(cmd, matches)
 		}
 
 		// List one
+		format, err := cmdutil.OutputFormat(cmd)
+		if err != nil {
+			return err
+		}
+
 		f, err := file.GetCheap(db, name)
 		if err != nil {
 			return err
 		}
 
-		printFile(f)
+		if format != cmdutil.OutputDefault {
+			return cmdutil.WriteOutput(cmd.OutOrStdout(), format, fileOutput(f))
+		}
+
+		printFile(cmd.OutOrStdout(), f)
 		return nil
 	}
 }
 
-func printFile(f *pb.FileCheap) {
+func fileOutput(f *pb.FileCheap) *cmdutil.FileOutput {
+	out := &cmdutil.FileOutput{
+		Name:      f.Name,
+		Size:      f.Size,
+		CreatedAt: time.Unix(f.CreatedAt, 0).Format(time.RFC3339),
+	}
+	if f.UpdatedAt != 0 {
+		out.UpdatedAt = time.Unix(f.UpdatedAt, 0).Format(time.RFC3339)
+	}
+	return out
+}
+
+func printFile(w io.Writer, f *pb.FileCheap) {
 	parts := strings.Split(f.Name, "/")
 	path := strings.Join(parts[:len(parts)-1], "/")
 	bytes := f.Size
@@ -148,5 +163,5 @@ func printFile(f *pb.FileCheap) {
 	}
 
 	box := cmdutil.BuildBox(f.Name, mp)
-	fmt.Println("\n" + box)
+	fmt.Fprintln(w, "\n"+box)
 }
@@ -1,6 +1,7 @@
 package ls
 
 import (
+	"io"
 	"testing"
 	"time"
 
@@ -123,7 +124,7 @@ func TestPrintFile(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
-			printFile(&pb.FileCheap{Size: tc.size})
+			printFile(io.Discard, &pb.FileCheap{Size: tc.size})
 		})
 	}
 }
@@ -145,11 +146,7 @@ func TestPrintFileUpdatedAt(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
-			printFile(&pb.FileCheap{UpdatedAt: tc.time})
+			printFile(io.Discard, &pb.FileCheap{UpdatedAt: tc.time})
 		})
 	}
 }
-
-func TestPostRun(t *testing.T) {
-	NewCmd(nil).PostRun(nil, nil)
-}
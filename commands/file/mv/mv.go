@@ -2,6 +2,7 @@ package mv
 
 import (
 	"fmt"
+	"io"
 	"path/filepath"
 	"strings"
 
@@ -63,7 +64,7 @@ func runMv(db *bolt.DB) cmdutil.RunEFunc {
 			if !strings.HasSuffix(newName, "/") {
 				return errors.New("cannot move a directory into a file")
 			}
-			return mvDir(db, oldName, newName)
+			return mvDir(db, cmd.OutOrStdout(), oldName, newName)
 		}
 
 		if filepath.Ext(newName) == "" {
@@ -78,18 +79,18 @@ func runMv(db *bolt.DB) cmdutil.RunEFunc {
 			return err
 		}
 
-		fmt.Printf("\n%q renamed as %q\n", oldName, newName)
+		fmt.Fprintf(cmd.OutOrStdout(), "\n%q renamed as %q\n", oldName, newName)
 		return nil
 	}
 }
 
-func mvDir(db *bolt.DB, oldName, newName string) error {
+func mvDir(db *bolt.DB, w io.Writer, oldName, newName string) error {
 	names, err := file.ListNames(db)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Moving %q directory into %q...\n", strings.TrimSuffix(oldName, "/"), strings.TrimSuffix(newName, "/"))
+	fmt.Fprintf(w, "Moving %q directory into %q...\n", strings.TrimSuffix(oldName, "/"), strings.TrimSuffix(newName, "/"))
 
 	for _, name := range names {
 		if strings.HasPrefix(name, oldName) {
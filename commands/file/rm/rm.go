@@ -45,7 +45,7 @@ func runRm(db *bolt.DB, r io.Reader) cmdutil.RunEFunc {
 
 		// Remove single file
 		if !strings.HasSuffix(name, "/") {
-			fmt.Println("Remove:", name)
+			fmt.Fprintln(cmd.OutOrStdout(), "Remove:", name)
 			if err := file.Remove(db, name); err != nil {
 				return err
 			}
@@ -54,7 +54,7 @@ func runRm(db *bolt.DB, r io.Reader) cmdutil.RunEFunc {
 		}
 
 		// Remove directory
-		fmt.Printf("Removing %q directory...\n", name)
+		fmt.Fprintf(cmd.OutOrStdout(), "Removing %q directory...\n", name)
 		files, err := file.ListNames(db)
 		if err != nil {
 			return err
@@ -64,7 +64,7 @@ func runRm(db *bolt.DB, r io.Reader) cmdutil.RunEFunc {
 		for _, f := range files {
 			if strings.HasPrefix(f, name) {
 				selected = append(selected, f)
-				fmt.Println("Remove:", f)
+				fmt.Fprintln(cmd.OutOrStdout(), "Remove:", f)
 			}
 		}
 
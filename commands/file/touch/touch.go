@@ -2,6 +2,7 @@ package touch
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -55,10 +56,6 @@ In case a path is passed, Kure will create any missing folders for you.`,
 		},
 		PreRunE: auth.Login(db),
 		RunE:    runTouch(db, &opts),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			// Reset variables (session)
-			opts = touchOptions{}
-		},
 	}
 
 	f := cmd.Flags()
@@ -91,12 +88,12 @@ func runTouch(db *bolt.DB, opts *touchOptions) cmdutil.RunEFunc {
 				return err
 			}
 
-			fmt.Println("Creating files at", opts.path)
+			fmt.Fprintln(cmd.OutOrStdout(), "Creating files at", opts.path)
 
 			for _, f := range files {
 				// Log errors, do not return
-				if err := createFiles(f, opts.path, opts.overwrite); err != nil {
-					fmt.Fprintln(os.Stderr, "error:", err)
+				if err := createFiles(cmd.OutOrStdout(), f, opts.path, opts.overwrite); err != nil {
+					fmt.Fprintln(cmd.ErrOrStderr(), "error:", err)
 				}
 			}
 
@@ -105,14 +102,14 @@ func runTouch(db *bolt.DB, opts *touchOptions) cmdutil.RunEFunc {
 
 		// Create one or more, files or directories
 		// Log errors to avoid stopping the whole operation
-		fmt.Println("Creating files at", opts.path)
+		fmt.Fprintln(cmd.OutOrStdout(), "Creating files at", opts.path)
 		for _, name := range args {
 			name = strings.ToLower(strings.TrimSpace(name))
 
 			// Assume the user wants to recreate an entire directory
 			if strings.HasSuffix(name, "/") {
-				if err := createDirectory(db, name, opts.path, opts.overwrite); err != nil {
-					fmt.Fprintln(os.Stderr, "error:", err)
+				if err := createDirectory(db, cmd.OutOrStdout(), name, opts.path, opts.overwrite); err != nil {
+					fmt.Fprintln(cmd.ErrOrStderr(), "error:", err)
 				}
 				continue
 			}
@@ -120,12 +117,12 @@ func runTouch(db *bolt.DB, opts *touchOptions) cmdutil.RunEFunc {
 			// Create single file
 			f, err := file.Get(db, name)
 			if err != nil {
-				fmt.Fprintln(os.Stderr, "error:", err)
+				fmt.Fprintln(cmd.ErrOrStderr(), "error:", err)
 				continue
 			}
 
-			if err := createFile(f, opts.overwrite); err != nil {
-				fmt.Fprintln(os.Stderr, "error:", err)
+			if err := createFile(cmd.OutOrStdout(), f, opts.overwrite); err != nil {
+				fmt.Fprintln(cmd.ErrOrStderr(), "error:", err)
 				continue
 			}
 		}
@@ -134,7 +131,7 @@ func runTouch(db *bolt.DB, opts *touchOptions) cmdutil.RunEFunc {
 	}
 }
 
-func createDirectory(db *bolt.DB, name, path string, overwrite bool) error {
+func createDirectory(db *bolt.DB, w io.Writer, name, path string, overwrite bool) error {
 	files, err := file.List(db)
 	if err != nil {
 		return err
@@ -158,7 +155,7 @@ func createDirectory(db *bolt.DB, name, path string, overwrite bool) error {
 	}
 
 	for _, f := range dir {
-		if err := createFiles(f, path, overwrite); err != nil {
+		if err := createFiles(w, f, path, overwrite); err != nil {
 			return err
 		}
 	}
@@ -166,7 +163,7 @@ func createDirectory(db *bolt.DB, name, path string, overwrite bool) error {
 	return nil
 }
 
-func createFile(file *pb.File, overwrite bool) error {
+func createFile(w io.Writer, file *pb.File, overwrite bool) error {
 	filename := filepath.Base(file.Name)
 
 	// Create if it doesn't exist or if we are allowed to overwrite it
@@ -177,7 +174,7 @@ func createFile(file *pb.File, overwrite bool) error {
 	if err := os.WriteFile(filename, file.Content, 0600); err != nil {
 		return errors.Wrapf(err, "writing %q", filename)
 	}
-	fmt.Println("Create:", file.Name)
+	fmt.Fprintln(w, "Create:", file.Name)
 	return nil
 }
 
@@ -186,14 +183,14 @@ func createFile(file *pb.File, overwrite bool) error {
 // This function works synchronously only, running it concurrently messes up os.Chdir().
 //
 // The path is used only to return to the root folder.
-func createFiles(file *pb.File, path string, overwrite bool) error {
+func createFiles(w io.Writer, file *pb.File, path string, overwrite bool) error {
 	// "the shire/frodo/ring.png" would be [the shire, frodo, ring.png]
 	parts := strings.Split(file.Name, "/")
 
 	for i, p := range parts {
 		// If it's the last element, create the file
 		if i == len(parts)-1 {
-			if err := createFile(file, overwrite); err != nil {
+			if err := createFile(w, file, overwrite); err != nil {
 				return err
 			}
 			// Go back to the root folder
@@ -75,10 +75,6 @@ func TestTouch(t *testing.T) {
 	}
 }
 
-func TestPostRun(t *testing.T) {
-	NewCmd(nil).PostRun(nil, nil)
-}
-
 func createTestFiles(t *testing.T, db *bolt.DB) {
 	t.Helper()
 
@@ -6,6 +6,7 @@ import (
 
 	cmdutil "github.com/GGP1/kure/commands"
 	"github.com/GGP1/kure/commands/gen/phrase"
+	"github.com/GGP1/kure/strength"
 
 	"github.com/GGP1/atoll"
 
@@ -22,7 +23,10 @@ kure gen -l 18 -L 1,2,3 -i %&/ -e ? -r
 kure gen -l 20 -q
 
 * Generate, copy and mute standard output
-kure gen -l 25 -cm`
+kure gen -l 25 -cm
+
+* Generate using a profile from the configuration file
+kure gen -p bank`
 
 type genOptions struct {
 	include string
@@ -48,10 +52,6 @@ Keyspace is the number of possible combinations of the password.
 Average time taken to crack is based on a brute force attack scenario where the guesses per second is 1 trillion.`,
 		Example: example,
 		RunE:    runGen(&opts),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			// Reset variables (session)
-			opts = genOptions{}
-		},
 	}
 
 	cmd.AddCommand(phrase.NewCmd())
@@ -65,12 +65,18 @@ Average time taken to crack is based on a brute force attack scenario where the
 	f.BoolVarP(&opts.repeat, "repeat", "r", false, "allow character repetition")
 	f.BoolVarP(&opts.qr, "qr", "q", false, "show the QR code image on the terminal")
 	f.BoolVarP(&opts.mute, "mute", "m", false, "mute standard output when the password is copied")
+	cmdutil.AddProfileFlag(f)
 
 	return cmd
 }
 
 func runGen(opts *genOptions) cmdutil.RunEFunc {
 	return func(cmd *cobra.Command, args []string) error {
+		profile, err := cmdutil.UseProfile(cmd, false)
+		if err != nil {
+			return err
+		}
+
 		if opts.length < 1 || opts.length > math.MaxUint64 {
 			return cmdutil.ErrInvalidLength
 		}
@@ -106,7 +112,7 @@ func runGen(opts *genOptions) cmdutil.RunEFunc {
 			Repeat:  opts.repeat,
 		}
 
-		password, err := atoll.NewSecret(p)
+		password, err := profile.Generate(func() (string, error) { return atoll.NewSecret(p) })
 		if err != nil {
 			return err
 		}
@@ -116,16 +122,16 @@ func runGen(opts *genOptions) cmdutil.RunEFunc {
 		defer pwdBuf.Destroy()
 
 		if opts.qr {
-			if err := cmdutil.DisplayQRCode(pwdBuf.String()); err != nil {
+			if err := cmdutil.DisplayQRCode(cmd.OutOrStdout(), pwdBuf.String()); err != nil {
 				return err
 			}
 		}
 
 		entropy := p.Entropy()
-		keyspace, timeToCrack := phrase.FormatSecretSecurity(atoll.Keyspace(p), atoll.SecondsToCrack(p)/2)
+		keyspace, timeToCrack := strength.FormatSecretSecurity(atoll.Keyspace(p), atoll.SecondsToCrack(p)/2)
 
 		if !opts.mute || !opts.copy {
-			fmt.Printf(`Password: %s
+			fmt.Fprintf(cmd.OutOrStdout(), `Password: %s
 
 Entropy: %.2f bits
 Keyspace: %s
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/GGP1/kure/config"
+
 	"github.com/atotto/clipboard"
 )
 
@@ -115,6 +117,37 @@ func TestGenErrors(t *testing.T) {
 	}
 }
 
-func TestPostRun(t *testing.T) {
-	NewCmd().PostRun(nil, nil)
+func TestGenProfile(t *testing.T) {
+	config.Reset()
+	config.Set("gen.profiles.bank", map[string]interface{}{"length": 16, "levels": []int{1, 2, 3}, "max_length": 16})
+
+	t.Run("Default", func(t *testing.T) {
+		if err := NewCmd().Execute(); err != nil {
+			t.Errorf("Failed generating a password with the default profile: %v", err)
+		}
+	})
+
+	t.Run("Profile", func(t *testing.T) {
+		cmd := NewCmd()
+		cmd.SetArgs([]string{"--profile", "bank"})
+		if err := cmd.Execute(); err != nil {
+			t.Errorf("Failed generating a password: %v", err)
+		}
+	})
+
+	t.Run("Constraints", func(t *testing.T) {
+		cmd := NewCmd()
+		cmd.SetArgs([]string{"--profile", "bank", "--length", "20"})
+		if err := cmd.Execute(); err == nil {
+			t.Error("Expected an error and got nil")
+		}
+	})
+
+	t.Run("Does not exist", func(t *testing.T) {
+		cmd := NewCmd()
+		cmd.SetArgs([]string{"--profile", "unknown"})
+		if err := cmd.Execute(); err == nil {
+			t.Error("Expected an error and got nil")
+		}
+	})
 }
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/strength"
 
 	"github.com/GGP1/atoll"
 
@@ -14,27 +15,6 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var (
-	thousand    = math.Pow(10, 3)
-	million     = math.Pow(10, 6)
-	billion     = math.Pow(10, 9)
-	trillion    = math.Pow(10, 12)
-	quadrillion = math.Pow(10, 15)
-	quintillion = math.Pow(10, 18)
-	sextillion  = math.Pow(10, 21)
-)
-
-const (
-	minute     = 60
-	hour       = minute * 60
-	day        = hour * 24
-	month      = day * 30
-	year       = month * 12
-	decade     = year * 10
-	century    = decade * 10
-	millennium = century * 10
-)
-
 const example = `
 * Generate a random passphrase
 kure gen phrase -l 8 -L WordList -s &
@@ -43,7 +23,10 @@ kure gen phrase -l 8 -L WordList -s &
 kure gen phrase -l 5 -q
 
 * Generate, copy and mute standard output
-kure gen -l 7 -cm`
+kure gen -l 7 -cm
+
+* Generate using a profile from the configuration file
+kure gen phrase -p memorable`
 
 type phraseOptions struct {
 	list, separator string
@@ -66,12 +49,6 @@ Average time taken to crack is based on a brute force attack scenario where the
 		Aliases: []string{"passphrase"},
 		Example: example,
 		RunE:    runPhrase(&opts),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			// Reset variables (session)
-			opts = phraseOptions{
-				separator: " ",
-			}
-		},
 	}
 
 	f := cmd.Flags()
@@ -83,12 +60,18 @@ Average time taken to crack is based on a brute force attack scenario where the
 	f.StringSliceVarP(&opts.excl, "exclude", "e", nil, "words to exclude from the passphrase")
 	f.BoolVarP(&opts.qr, "qr", "q", false, "show QR code on terminal")
 	f.BoolVarP(&opts.mute, "mute", "m", false, "mute standard output when the passphrase is copied")
+	cmdutil.AddProfileFlag(f)
 
 	return cmd
 }
 
 func runPhrase(opts *phraseOptions) cmdutil.RunEFunc {
 	return func(cmd *cobra.Command, args []string) error {
+		profile, err := cmdutil.UseProfile(cmd, true)
+		if err != nil {
+			return err
+		}
+
 		if opts.length < 1 || opts.length > math.MaxUint64 {
 			return cmdutil.ErrInvalidLength
 		}
@@ -121,7 +104,7 @@ func runPhrase(opts *phraseOptions) cmdutil.RunEFunc {
 			List:      l,
 		}
 
-		passphrase, err := atoll.NewSecret(p)
+		passphrase, err := profile.Generate(func() (string, error) { return atoll.NewSecret(p) })
 		if err != nil {
 			return err
 		}
@@ -131,16 +114,16 @@ func runPhrase(opts *phraseOptions) cmdutil.RunEFunc {
 		defer phraseBuf.Destroy()
 
 		if opts.qr {
-			if err := cmdutil.DisplayQRCode(phraseBuf.String()); err != nil {
+			if err := cmdutil.DisplayQRCode(cmd.OutOrStdout(), phraseBuf.String()); err != nil {
 				return err
 			}
 		}
 
 		entropy := p.Entropy()
-		keyspace, timeToCrack := FormatSecretSecurity(atoll.Keyspace(p), atoll.SecondsToCrack(p)/2)
+		keyspace, timeToCrack := strength.FormatSecretSecurity(atoll.Keyspace(p), atoll.SecondsToCrack(p)/2)
 
 		if !opts.mute || !opts.copy {
-			fmt.Printf(`Passphrase: %s
+			fmt.Fprintf(cmd.OutOrStdout(), `Passphrase: %s
 	
 Entropy: %.2f bits
 Keyspace: %s
@@ -156,86 +139,3 @@ Average time taken to crack: %s
 		return nil
 	}
 }
-
-// FormatSecretSecurity makes the data passed easier to read.
-func FormatSecretSecurity(keyspace, avgTimeToCrack float64) (space, time string) {
-	if math.IsInf(keyspace, 1) {
-		return "+Inf", "+Inf"
-	}
-
-	space = fmt.Sprintf("%.2f", keyspace)
-	time = fmt.Sprintf("%.2f seconds", avgTimeToCrack)
-
-	switch {
-	case keyspace >= sextillion:
-		space = fmt.Sprintf("%.2f sextillion", keyspace/sextillion)
-
-	case keyspace >= quintillion:
-		space = fmt.Sprintf("%.2f quintillion", keyspace/quintillion)
-
-	case keyspace >= quadrillion:
-		space = fmt.Sprintf("%.2f quadrillion", keyspace/quadrillion)
-
-	case keyspace >= trillion:
-		space = fmt.Sprintf("%.2f trillion", keyspace/trillion)
-
-	case keyspace >= billion:
-		space = fmt.Sprintf("%.2f billion", keyspace/billion)
-
-	case keyspace >= million:
-		space = fmt.Sprintf("%.2f million", keyspace/million)
-
-	case keyspace >= thousand:
-		space = fmt.Sprintf("%.2f thousand", keyspace/thousand)
-	}
-
-	switch {
-	case avgTimeToCrack >= millennium:
-		time = fmt.Sprintf("%.2f millenniums", avgTimeToCrack/millennium)
-
-	case avgTimeToCrack >= century:
-		time = fmt.Sprintf("%.2f centuries", avgTimeToCrack/century)
-
-	case avgTimeToCrack >= decade:
-		time = fmt.Sprintf("%.2f decades", avgTimeToCrack/decade)
-
-	case avgTimeToCrack >= year:
-		time = fmt.Sprintf("%.2f years", avgTimeToCrack/year)
-
-	case avgTimeToCrack >= month:
-		time = fmt.Sprintf("%.2f months", avgTimeToCrack/month)
-
-	case avgTimeToCrack >= day:
-		time = fmt.Sprintf("%.2f days", avgTimeToCrack/day)
-
-	case avgTimeToCrack >= hour:
-		time = fmt.Sprintf("%.2f hours", avgTimeToCrack/hour)
-
-	case avgTimeToCrack >= minute:
-		time = fmt.Sprintf("%.2f minutes", avgTimeToCrack/minute)
-	}
-
-	return prettify(space), prettify(time)
-}
-
-// prettify adds commas to the number inside the string to make it easier for humans to read.
-//
-// Example: 51334.21 -> 51,334.21
-func prettify(str string) string {
-	idx := strings.IndexByte(str, '.')
-	num := str[:idx]
-
-	sb := strings.Builder{}
-	j := 0
-	for i := len(num) - 1; i >= 0; i-- {
-		sb.WriteByte(num[j])
-		if i%3 == 0 && i != 0 {
-			sb.WriteByte(',')
-		}
-		j++
-	}
-
-	// Append remaining characters
-	sb.WriteString(str[idx:])
-	return sb.String()
-}
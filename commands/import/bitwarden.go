@@ -0,0 +1,202 @@
+package importt
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/GGP1/kure/bitwarden"
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/pb"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// bitwardenRecords contains the records extracted from a Bitwarden export.
+type bitwardenRecords struct {
+	records
+	entryNames uniqueNames
+	cardNames  uniqueNames
+	fileNames  uniqueNames
+}
+
+// importBitwarden reads the Bitwarden JSON export located in path and stores its items,
+// password is called only if the export is encrypted.
+//
+// Logins are stored as entries, cards as cards and secure notes and identities as text files.
+func importBitwarden(db *bolt.DB, path string, password func() ([]byte, error)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "opening file")
+	}
+	defer f.Close()
+
+	export, err := bitwarden.Read(f, password)
+	if err != nil {
+		return err
+	}
+
+	records := &bitwardenRecords{
+		entryNames: make(uniqueNames),
+		cardNames:  make(uniqueNames),
+		fileNames:  make(uniqueNames),
+	}
+	now := time.Now()
+	for _, item := range export.Items {
+		name := item.Name
+		if folder := export.FolderName(item); folder != "" {
+			name = folder + "/" + name
+		}
+
+		if err := records.addItem(item, name, now); err != nil {
+			return errors.Wrapf(err, "%q", name)
+		}
+	}
+
+	return records.store(db)
+}
+
+func (r *bitwardenRecords) addItem(item bitwarden.Item, name string, now time.Time) error {
+	switch item.Type {
+	case bitwarden.LoginType:
+		if item.Login == nil {
+			item.Login = &bitwarden.Login{}
+		}
+		return r.addLogin(item, r.entryNames.get(cmdutil.NormalizeName(name)))
+
+	case bitwarden.CardType:
+		if item.Card == nil {
+			item.Card = &bitwarden.Card{}
+		}
+		r.addCard(item, r.cardNames.get(cmdutil.NormalizeName(name)))
+
+	case bitwarden.SecureNoteType:
+		notes := appendFields(item.Notes, item.Fields)
+		r.addFile(bitwarden.NotesFolder+name, notes, now)
+
+	case bitwarden.IdentityType:
+		if item.Identity == nil {
+			item.Identity = &bitwarden.Identity{}
+		}
+		text := bitwarden.FormatIdentity(item.Identity, item.Fields, item.Notes)
+		r.addFile(bitwarden.IdentitiesFolder+name, text, now)
+
+	default:
+		return errors.Errorf("unsupported item type %d", item.Type)
+	}
+
+	return nil
+}
+
+func (r *bitwardenRecords) addLogin(item bitwarden.Item, name string) error {
+	login := item.Login
+	entry := &pb.Entry{
+		Name:     name,
+		Username: login.Username,
+		Password: login.Password,
+		Expires:  "Never",
+	}
+
+	var extra []bitwarden.Field
+	for i, uri := range login.URIs {
+		if i == 0 {
+			entry.URL = uri.URI
+			continue
+		}
+		extra = append(extra, bitwarden.Field{Name: "URL", Value: uri.URI})
+	}
+	for _, f := range item.Fields {
+		if f.Name == bitwarden.ExpiresField {
+			if expires, err := time.Parse(time.RFC1123Z, f.Value); err == nil {
+				entry.Expires = f.Value
+				entry.ExpiresAt = expires.Unix()
+				continue
+			}
+		}
+		extra = append(extra, f)
+	}
+	entry.Notes = appendFields(item.Notes, extra)
+
+	if updated, err := time.Parse(time.RFC3339, login.PasswordRevisionDate); err == nil {
+		entry.PasswordUpdatedAt = updated.Unix()
+	}
+	// The most recent password is the first one
+	if len(item.PasswordHistory) > 0 {
+		entry.PreviousPassword = item.PasswordHistory[0].Password
+	}
+	r.entries = append(r.entries, entry)
+
+	if login.TOTP == "" {
+		return nil
+	}
+
+	t, err := parseTOTP(login.TOTP)
+	if err != nil {
+		return errors.Wrap(err, "one-time password")
+	}
+	t.Name = name
+	r.totps = append(r.totps, t)
+
+	return nil
+}
+
+func (r *bitwardenRecords) addCard(item bitwarden.Item, name string) {
+	c := item.Card
+	card := &pb.Card{
+		Name:         name,
+		Type:         c.Brand,
+		Number:       c.Number,
+		SecurityCode: c.Code,
+	}
+	if c.ExpMonth != "" && c.ExpYear != "" {
+		month := c.ExpMonth
+		if len(month) == 1 {
+			month = "0" + month
+		}
+		card.ExpireDate = month + "/" + c.ExpYear
+	}
+
+	var extra []bitwarden.Field
+	if c.CardholderName != "" {
+		extra = append(extra, bitwarden.Field{Name: "Cardholder name", Value: c.CardholderName})
+	}
+	for _, f := range item.Fields {
+		if f.Name == bitwarden.ExpireDateField && card.ExpireDate == "" {
+			card.ExpireDate = f.Value
+			continue
+		}
+		extra = append(extra, f)
+	}
+	card.Notes = appendFields(item.Notes, extra)
+	cmdutil.SetCardExpires(card)
+
+	r.cards = append(r.cards, card)
+}
+
+func (r *bitwardenRecords) addFile(name, content string, now time.Time) {
+	r.files = append(r.files, &pb.File{
+		Name:      r.fileNames.get(cmdutil.NormalizeName(name) + ".txt"),
+		Content:   []byte(content),
+		Size:      int64(len(content)),
+		CreatedAt: now.Unix(),
+		UpdatedAt: time.Time{}.Unix(),
+	})
+}
+
+// appendFields appends the fields to the notes, one per line in the format "name: value".
+func appendFields(notes string, fields []bitwarden.Field) string {
+	var sb strings.Builder
+	sb.WriteString(notes)
+
+	for _, f := range fields {
+		if sb.Len() > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(f.Name)
+		sb.WriteString(": ")
+		sb.WriteString(f.Value)
+	}
+
+	return sb.String()
+}
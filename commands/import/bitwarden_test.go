@@ -0,0 +1,247 @@
+package importt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GGP1/kure/bitwarden"
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/card"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/db/file"
+	"github.com/GGP1/kure/db/totp"
+	"github.com/GGP1/kure/pb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestImportBitwarden(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+	// The export is the one TestExportBitwarden expects, importing it must return the original records
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	work, bank := "work-id", "bank-id"
+	export := &bitwarden.Export{
+		Folders: []bitwarden.Folder{{ID: work, Name: "work"}, {ID: bank, Name: "bank"}},
+		Items: []bitwarden.Item{
+			{
+				Type:   bitwarden.LoginType,
+				Name:   "github",
+				Notes:  "Notes",
+				Fields: []bitwarden.Field{{Name: bitwarden.ExpiresField, Value: expires.Format(time.RFC1123Z)}},
+				Login: &bitwarden.Login{
+					URIs:                 []bitwarden.URI{{URI: "https://github.com"}},
+					Username:             "gopher",
+					Password:             "github123",
+					TOTP:                 "otpauth://totp/github?digits=6&period=30&secret=JBSWY3DPEHPK3PXP",
+					PasswordRevisionDate: "2024-01-01T00:00:00Z",
+				},
+				PasswordHistory: []bitwarden.PasswordHistory{{LastUsedDate: "2024-01-01T00:00:00Z", Password: "old123"}},
+			},
+			{
+				FolderID: &work,
+				Type:     bitwarden.LoginType,
+				Name:     "mail",
+				Login:    &bitwarden.Login{Password: "mail123"},
+			},
+			{
+				FolderID: &bank,
+				Type:     bitwarden.CardType,
+				Name:     "visa",
+				Notes:    "Notes",
+				Card:     &bitwarden.Card{Brand: "Visa", Number: "4111111111111111", ExpMonth: "5", ExpYear: "2030", Code: "123"},
+			},
+			{
+				Type:   bitwarden.CardType,
+				Name:   "gift",
+				Fields: []bitwarden.Field{{Name: bitwarden.ExpireDateField, Value: "never"}},
+				Card:   &bitwarden.Card{Number: "1234"},
+			},
+			{
+				Type:     bitwarden.IdentityType,
+				Name:     "gopher",
+				Notes:    "Notes",
+				Fields:   []bitwarden.Field{{Name: "Shoe size", Value: "42"}},
+				Identity: &bitwarden.Identity{FirstName: "Gopher", Email: "gopher@golang.org"},
+			},
+			{
+				Type:       bitwarden.SecureNoteType,
+				Name:       "recovery",
+				Notes:      "Recovery codes",
+				SecureNote: &bitwarden.SecureNote{},
+			},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "test.json")
+	writeBitwarden(t, path, export, []byte("bitwarden"))
+
+	if err := importBitwarden(db, path, func() ([]byte, error) { return []byte("bitwarden"), nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedEntries := []*pb.Entry{
+		{
+			Name:              "github",
+			Username:          "gopher",
+			Password:          "github123",
+			URL:               "https://github.com",
+			Notes:             "Notes",
+			Expires:           expires.Format(time.RFC1123Z),
+			ExpiresAt:         expires.Unix(),
+			PasswordUpdatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Unix(),
+			PreviousPassword:  "old123",
+		},
+		{Name: "work/mail", Password: "mail123", Expires: "Never"},
+	}
+	for _, e := range expectedEntries {
+		got, err := entry.Get(db, e.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(e, got) {
+			t.Errorf("Expected %v, got %v", e, got)
+		}
+	}
+
+	gotTOTP, err := totp.Get(db, "github")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedTOTP := &pb.TOTP{Name: "github", Raw: "JBSWY3DPEHPK3PXP", Digits: 6}
+	if !proto.Equal(expectedTOTP, gotTOTP) {
+		t.Errorf("Expected %v, got %v", expectedTOTP, gotTOTP)
+	}
+
+	expectedCards := []*pb.Card{
+		{Name: "bank/visa", Type: "Visa", Number: "4111111111111111", SecurityCode: "123", ExpireDate: "05/2030", Notes: "Notes"},
+		{Name: "gift", Number: "1234", ExpireDate: "never"},
+	}
+	for _, c := range expectedCards {
+		cmdutil.SetCardExpires(c)
+		got, err := card.Get(db, c.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(c, got) {
+			t.Errorf("Expected %v, got %v", c, got)
+		}
+	}
+
+	expectedFiles := map[string]string{
+		"notes/recovery.txt":    "Recovery codes",
+		"identities/gopher.txt": "First name: Gopher\nEmail: gopher@golang.org\nShoe size: 42\n\nNotes",
+	}
+	for name, content := range expectedFiles {
+		got, err := file.Get(db, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got.Content) != content {
+			t.Errorf("Expected %q, got %q", content, got.Content)
+		}
+	}
+}
+
+func TestImportBitwardenLogin(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+	export := &bitwarden.Export{
+		Items: []bitwarden.Item{
+			{
+				Type: bitwarden.LoginType,
+				Name: "Shop",
+				Fields: []bitwarden.Field{
+					{Name: "PIN", Value: "1234", Type: bitwarden.HiddenField},
+					{Name: bitwarden.ExpiresField, Value: "invalid date"},
+				},
+				Login: &bitwarden.Login{
+					URIs: []bitwarden.URI{{URI: "https://shop.com"}, {URI: "https://shop.org"}},
+					TOTP: "jbsw y3dp ehpk 3pxp",
+				},
+			},
+			{Type: bitwarden.LoginType, Name: "Shop"},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "test.json")
+	writeBitwarden(t, path, export, nil)
+
+	if err := importBitwarden(db, path, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []*pb.Entry{
+		{
+			Name:    "shop",
+			URL:     "https://shop.com",
+			Notes:   "URL: https://shop.org\nPIN: 1234\nExpires: invalid date",
+			Expires: "Never",
+		},
+		{Name: "shop (2)", Expires: "Never"},
+	}
+	for _, e := range expected {
+		got, err := entry.Get(db, e.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(e, got) {
+			t.Errorf("Expected %v, got %v", e, got)
+		}
+	}
+
+	gotTOTP, err := totp.Get(db, "shop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotTOTP.Raw != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("Expected %q, got %q", "JBSWY3DPEHPK3PXP", gotTOTP.Raw)
+	}
+}
+
+func TestImportBitwardenErrors(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+	path := filepath.Join(t.TempDir(), "test.json")
+	writeBitwarden(t, path, &bitwarden.Export{}, []byte("bitwarden"))
+
+	err := importBitwarden(db, path, func() ([]byte, error) { return []byte("invalid"), nil })
+	if err != bitwarden.ErrInvalidPassword {
+		t.Errorf("Expected %v, got %v", bitwarden.ErrInvalidPassword, err)
+	}
+
+	restricted := filepath.Join(t.TempDir(), "restricted.json")
+	if err := os.WriteFile(restricted, []byte(`{"encrypted": true, "encKeyValidation_DO_NOT_EDIT": "2.a|b|c"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := importBitwarden(db, restricted, nil); err != bitwarden.ErrAccountRestricted {
+		t.Errorf("Expected %v, got %v", bitwarden.ErrAccountRestricted, err)
+	}
+
+	unsupported := filepath.Join(t.TempDir(), "unsupported.json")
+	writeBitwarden(t, unsupported, &bitwarden.Export{Items: []bitwarden.Item{{Type: 5, Name: "ssh"}}}, nil)
+	if err := importBitwarden(db, unsupported, nil); err == nil {
+		t.Error("Expected an error and got nil")
+	}
+}
+
+// writeBitwarden writes the export in path, it's encrypted only if the password is not nil.
+func writeBitwarden(t *testing.T, path string, export *bitwarden.Export, password []byte) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if password != nil {
+		err = bitwarden.WriteEncrypted(f, export, password, bitwarden.KDF{Type: bitwarden.PBKDF2, Iterations: 1})
+	} else {
+		err = bitwarden.Write(f, export)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,158 @@
+package importt
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/pb"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// browserFormat contains the columns of a browser password export, all of them include the url,
+// username and password ones.
+type browserFormat struct {
+	// header contains the columns used to identify the export
+	header []string
+	notes  string
+	otp    string
+	// passwordChanged contains the time of the last password change in Unix milliseconds
+	passwordChanged string
+}
+
+// browserFormats maps the browsers supported to the format of their exports. Column names are lowercase.
+var browserFormats = map[string]browserFormat{
+	"chrome":  {header: []string{"name", "url", "username", "password"}, notes: "note"},
+	"edge":    {header: []string{"name", "url", "username", "password"}, notes: "note"},
+	"firefox": {header: []string{"url", "username", "password", "httprealm", "formactionorigin", "guid"}, passwordChanged: "timepasswordchanged"},
+	"safari":  {header: []string{"title", "url", "username", "password"}, notes: "notes", otp: "otpauth"},
+}
+
+// browserColumns contains the index of each column, optional ones are -1 if the export doesn't have them.
+type browserColumns struct {
+	url             int
+	username        int
+	password        int
+	notes           int
+	otp             int
+	passwordChanged int
+}
+
+// importBrowser stores the passwords of a browser CSV export and returns the rows that were skipped
+// along with the reason.
+//
+// Entries are named "<browser>/<host>/<username>" and rows with the same origin and username as a
+// previous one are skipped.
+func importBrowser(db *bolt.DB, browser string, rows [][]string) ([]string, error) {
+	columns, err := detectColumns(browser, rows[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		r       records
+		skipped []string
+		names   = make(uniqueNames)
+		// origins maps the origin and username of the rows imported to their number
+		origins = make(map[string]int)
+	)
+	for i, row := range rows[1:] {
+		// Add one for the header and another because rows are numbered from one
+		n := i + 2
+		skip := func(format string, a ...interface{}) {
+			skipped = append(skipped, fmt.Sprintf("row %d: ", n)+fmt.Sprintf(format, a...))
+		}
+
+		rawURL, username, password := row[columns.url], row[columns.username], row[columns.password]
+		URL, err := url.Parse(rawURL)
+		if err != nil || URL.Host == "" {
+			skip("invalid URL %q", rawURL)
+			continue
+		}
+		if password == "" {
+			skip("empty password")
+			continue
+		}
+
+		key := URL.Scheme + "://" + URL.Host + "\n" + username
+		if prev, ok := origins[key]; ok {
+			skip("duplicate of row %d", prev)
+			continue
+		}
+		origins[key] = n
+
+		name := browser + "/" + URL.Hostname()
+		if username != "" {
+			name += "/" + username
+		}
+		name = names.get(cmdutil.NormalizeName(name))
+
+		entry := &pb.Entry{
+			Name:     name,
+			Username: username,
+			Password: password,
+			URL:      rawURL,
+			Expires:  "Never",
+		}
+		if columns.notes != -1 {
+			entry.Notes = row[columns.notes]
+		}
+		if columns.passwordChanged != -1 {
+			if ms, err := strconv.ParseInt(row[columns.passwordChanged], 10, 64); err == nil {
+				entry.PasswordUpdatedAt = ms / 1000
+			}
+		}
+		r.entries = append(r.entries, entry)
+
+		if columns.otp != -1 && row[columns.otp] != "" {
+			t, err := parseTOTP(row[columns.otp])
+			if err != nil {
+				// The entry is imported anyway, only the one-time password is skipped
+				skip("one-time password: %v", err)
+				continue
+			}
+			t.Name = name
+			r.totps = append(r.totps, t)
+		}
+	}
+
+	if err := r.store(db); err != nil {
+		return nil, err
+	}
+	return skipped, nil
+}
+
+// detectColumns finds the columns of the browser export in its header row.
+func detectColumns(browser string, header []string) (browserColumns, error) {
+	index := headerIndex(header)
+
+	// Optional columns are -1 if missing
+	find := func(name string) int {
+		if i, ok := index[name]; ok && name != "" {
+			return i
+		}
+		return -1
+	}
+
+	format := browserFormats[browser]
+	for _, name := range format.header {
+		if _, ok := index[name]; !ok {
+			return browserColumns{}, errors.Errorf("invalid %s export: the header must contain the columns %s",
+				browser, strings.Join(format.header, ", "))
+		}
+	}
+
+	columns := browserColumns{
+		url:             find("url"),
+		username:        find("username"),
+		password:        find("password"),
+		notes:           find(format.notes),
+		otp:             find(format.otp),
+		passwordChanged: find(format.passwordChanged),
+	}
+	return columns, nil
+}
@@ -0,0 +1,128 @@
+package importt
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/db/totp"
+	"github.com/GGP1/kure/pb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestImportBrowser(t *testing.T) {
+	updated := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+
+	cases := []struct {
+		browser  string
+		path     string
+		expected []*pb.Entry
+		skipped  []string
+	}{
+		{
+			browser: "chrome",
+			path:    "testdata/test_chrome.csv",
+			expected: []*pb.Entry{
+				{Name: "chrome/github.com/gopher", Username: "gopher", Password: "github123", URL: "https://github.com/login", Notes: "Notes", Expires: "Never"},
+				{Name: "chrome/example.com/gopher", Username: "gopher", Password: "example123", URL: "http://example.com", Expires: "Never"},
+				{Name: "chrome/example.com/gopher (2)", Username: "gopher", Password: "example456", URL: "https://example.com", Expires: "Never"},
+			},
+			skipped: []string{
+				"row 3: duplicate of row 2",
+				`row 6: invalid URL ""`,
+				"row 7: empty password",
+			},
+		},
+		{
+			browser: "firefox",
+			path:    "testdata/test_firefox.csv",
+			expected: []*pb.Entry{
+				{Name: "firefox/github.com/gopher", Username: "gopher", Password: "github123", URL: "https://github.com", Expires: "Never", PasswordUpdatedAt: updated},
+				{Name: "firefox/mail.google.com", Password: "mail123", URL: "https://mail.google.com", Expires: "Never", PasswordUpdatedAt: updated},
+			},
+		},
+		{
+			browser: "safari",
+			path:    "testdata/test_safari.csv",
+			expected: []*pb.Entry{
+				{Name: "safari/github.com/gopher", Username: "gopher", Password: "github123", URL: "https://github.com/", Notes: "Notes", Expires: "Never"},
+				{Name: "safari/go.dev/gopher", Username: "gopher", Password: "go123", URL: "https://go.dev/", Expires: "Never"},
+			},
+			skipped: []string{"row 3: one-time password: only otpauth://totp URLs are supported"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.browser, func(t *testing.T) {
+			db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+			rows, err := readCSV(tc.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			skipped, err := importBrowser(db, tc.browser, rows)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tc.skipped, skipped) {
+				t.Errorf("Expected skipped rows %q, got %q", tc.skipped, skipped)
+			}
+
+			names, err := entry.ListNames(db)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(names) != len(tc.expected) {
+				t.Errorf("Expected %d entries, got %d: %q", len(tc.expected), len(names), names)
+			}
+			for _, e := range tc.expected {
+				got, err := entry.Get(db, e.Name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !proto.Equal(e, got) {
+					t.Errorf("Expected %v, got %v", e, got)
+				}
+			}
+		})
+	}
+}
+
+func TestImportBrowserTOTP(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+	rows, err := readCSV("testdata/test_safari.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := importBrowser(db, "safari", rows); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := totp.Get(db, "safari/github.com/gopher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &pb.TOTP{Name: "safari/github.com/gopher", Raw: "JBSWY3DPEHPK3PXP", Digits: 8}
+	if !proto.Equal(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestImportBrowserInvalidHeader(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+	rows, err := readCSV("testdata/test_lastpass.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := importBrowser(db, "chrome", [][]string{{"name", "url", "password"}}); err == nil {
+		t.Error("Expected an error and got nil")
+	}
+	if _, err := importBrowser(db, "firefox", rows[:1]); err == nil {
+		t.Error("Expected an error and got nil")
+	}
+}
@@ -0,0 +1,145 @@
+package importt
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/pb"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// mappingFields contains the entry fields a column can be mapped to, "folder" is joined with the name.
+var mappingFields = []string{"name", "folder", "username", "password", "url", "notes", "expires", "totp"}
+
+// columnMapping maps entry fields to the name of the CSV columns containing them.
+type columnMapping map[string]string
+
+// parseMapping parses a list of pairs in the format "field=Column".
+func parseMapping(pairs []string) (columnMapping, error) {
+	mapping := make(columnMapping, len(pairs))
+	for _, pair := range pairs {
+		field, column, ok := strings.Cut(pair, "=")
+		field = strings.ToLower(strings.TrimSpace(field))
+		column = strings.TrimSpace(column)
+		if !ok || field == "" || column == "" {
+			return nil, errors.Errorf("invalid mapping %q, the format is field=Column", pair)
+		}
+		if !isMappingField(field) {
+			return nil, errors.Errorf("invalid field %q, valid fields: %s", field, strings.Join(mappingFields, ", "))
+		}
+		if _, ok := mapping[field]; ok {
+			return nil, errors.Errorf("field %q is mapped more than once", field)
+		}
+		mapping[field] = column
+	}
+
+	if _, ok := mapping["name"]; !ok {
+		return nil, errors.New("the name field must be mapped to a column")
+	}
+	return mapping, nil
+}
+
+// readMappingFile reads a mapping from the file located in path, it contains a "field=Column" pair
+// per line. Empty lines and those starting with "#" are ignored.
+func readMappingFile(path string) (columnMapping, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening mapping file")
+	}
+	defer f.Close()
+
+	var pairs []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pairs = append(pairs, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading mapping file")
+	}
+
+	return parseMapping(pairs)
+}
+
+// importGeneric stores the rows as entries, the mapping is used to find the columns containing each field.
+//
+// TOTP columns may contain otpauth URLs or base32 secrets and expiration dates use the same formats
+// as the add command.
+func importGeneric(db *bolt.DB, rows [][]string, mapping columnMapping) error {
+	index := headerIndex(rows[0])
+	columns := make(map[string]int, len(mapping))
+	for _, field := range mappingFields {
+		column, ok := mapping[field]
+		if !ok {
+			continue
+		}
+		i, ok := index[strings.ToLower(column)]
+		if !ok {
+			return errors.Errorf("invalid header: missing %q column (mapped to %s)", column, field)
+		}
+		columns[field] = i
+	}
+
+	var (
+		r     records
+		names = make(uniqueNames)
+	)
+	for i, row := range rows[1:] {
+		n := i + 2
+		get := func(field string) string {
+			if i, ok := columns[field]; ok {
+				return row[i]
+			}
+			return ""
+		}
+
+		name := get("name")
+		if folder := get("folder"); folder != "" {
+			name = folder + "/" + name
+		}
+		name = cmdutil.NormalizeName(name)
+		if name == "" {
+			return errors.Errorf("row %d: the entry name is empty", n)
+		}
+		name = names.get(name)
+
+		entry := &pb.Entry{
+			Name:     name,
+			Username: get("username"),
+			Password: get("password"),
+			URL:      get("url"),
+			Notes:    get("notes"),
+		}
+		if err := cmdutil.SetExpires(entry, strings.TrimSpace(get("expires"))); err != nil {
+			return errors.Wrapf(err, "row %d", n)
+		}
+		r.entries = append(r.entries, entry)
+
+		if value := strings.TrimSpace(get("totp")); value != "" {
+			t, err := parseTOTP(value)
+			if err != nil {
+				return errors.Wrapf(err, "row %d: one-time password", n)
+			}
+			t.Name = name
+			r.totps = append(r.totps, t)
+		}
+	}
+
+	return r.store(db)
+}
+
+func isMappingField(field string) bool {
+	for _, f := range mappingFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package importt
+
+import (
+	"testing"
+	"time"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/db/totp"
+	"github.com/GGP1/kure/pb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestImportGeneric(t *testing.T) {
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	expectedEntries := []*pb.Entry{
+		{
+			Name:      "work/github",
+			Username:  "gopher",
+			Password:  "github123",
+			URL:       "https://github.com",
+			Notes:     "Notes",
+			Expires:   expires.Format(time.RFC1123Z),
+			ExpiresAt: expires.Unix(),
+		},
+		{Name: "mail", Username: "gopher", Password: "mail123", Expires: "Never"},
+		{Name: "mail (2)", Username: "other", Password: "mail456", Expires: "Never"},
+	}
+	expectedTOTPs := []*pb.TOTP{
+		{Name: "work/github", Raw: "JBSWY3DPEHPK3PXP", Digits: 8},
+		{Name: "mail", Raw: "JBSWY3DPEHPK3PXP", Digits: 6},
+	}
+
+	cases := []struct {
+		desc  string
+		flag  string
+		value string
+	}{
+		{
+			desc:  "Map",
+			flag:  "map",
+			value: "name=Title,folder=Group,username=Login,password=Password,url=Website,notes=Comments,expires=Expiry,totp=OTP",
+		},
+		{
+			desc:  "Map file",
+			flag:  "map-file",
+			value: "testdata/test_generic_mapping.txt",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+			cmd := NewCmd(db)
+			cmd.SetArgs([]string{"generic"})
+			f := cmd.Flags()
+			f.Set("path", "testdata/test_generic.csv")
+			f.Set(tc.flag, tc.value)
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Failed importing entries: %v", err)
+			}
+
+			for _, e := range expectedEntries {
+				got, err := entry.Get(db, e.Name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !proto.Equal(e, got) {
+					t.Errorf("Expected %v, got %v", e, got)
+				}
+			}
+			for _, expected := range expectedTOTPs {
+				got, err := totp.Get(db, expected.Name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !proto.Equal(expected, got) {
+					t.Errorf("Expected %v, got %v", expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestImportGenericErrors(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+	cases := []struct {
+		desc    string
+		manager string
+		path    string
+		mapping string
+		file    string
+	}{
+		{desc: "No mapping", manager: "generic", path: "testdata/test_generic.csv"},
+		{desc: "Both mappings", manager: "generic", path: "testdata/test_generic.csv", mapping: "name=Title", file: "testdata/test_generic_mapping.txt"},
+		{desc: "Mapping without name", manager: "generic", path: "testdata/test_generic.csv", mapping: "username=Login"},
+		{desc: "Invalid field", manager: "generic", path: "testdata/test_generic.csv", mapping: "name=Title,email=Login"},
+		{desc: "Invalid pair", manager: "generic", path: "testdata/test_generic.csv", mapping: "name"},
+		{desc: "Repeated field", manager: "generic", path: "testdata/test_generic.csv", mapping: "name=Title,name=Login"},
+		{desc: "Missing column", manager: "generic", path: "testdata/test_generic.csv", mapping: "name=Account"},
+		{desc: "Non-existent mapping file", manager: "generic", path: "testdata/test_generic.csv", file: "mapping.txt"},
+		{desc: "Invalid expiration", manager: "generic", path: "testdata/test_generic.csv", mapping: "name=Title,expires=Login"},
+		{desc: "Invalid TOTP", manager: "generic", path: "testdata/test_generic.csv", mapping: "name=Title,totp=Password"},
+		{desc: "Empty name", manager: "generic", path: "testdata/test_generic.csv", mapping: "name=Website"},
+		{desc: "Mapping another manager", manager: "keepass", path: "testdata/test_keepass.csv", mapping: "name=Account"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cmd := NewCmd(db)
+			cmd.SetArgs([]string{tc.manager})
+			f := cmd.Flags()
+			f.Set("path", tc.path)
+			if tc.mapping != "" {
+				f.Set("map", tc.mapping)
+			}
+			if tc.file != "" {
+				f.Set("map-file", tc.file)
+			}
+
+			if err := cmd.Execute(); err == nil {
+				t.Error("Expected an error but got nil")
+			}
+		})
+	}
+}
@@ -3,16 +3,22 @@ package importt
 import (
 	"encoding/csv"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/GGP1/kure/auth"
 	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/card"
 	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/db/file"
 	"github.com/GGP1/kure/db/totp"
 	"github.com/GGP1/kure/pb"
 
+	"github.com/awnumar/memguard"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	bolt "go.etcd.io/bbolt"
@@ -23,11 +29,31 @@ const example = `
 kure import keepass -p path/to/file
 
 * Import and delete the file:
-kure import 1password -e -p path/to/file`
+kure import 1password -e -p path/to/file
+
+* Import a KeePass database:
+kure import keepassxc --kdbx -p path/to/file.kdbx
+
+* Import a 1Password 1PUX export:
+kure import 1password --1pux -p path/to/file.1pux
+
+* Import a Bitwarden JSON export:
+kure import bitwarden --json -p path/to/file.json
+
+* Import the passwords exported by a browser:
+kure import chrome -p path/to/passwords.csv
+
+* Import any CSV file mapping its columns to entry fields:
+kure import generic -p path/to/file.csv --map name=Title,folder=Group,username=Login,password=Password,totp=OTP`
 
 type importOptions struct {
-	path  string
-	erase bool
+	path        string
+	mappingFile string
+	mapping     []string
+	erase       bool
+	kdbx        bool
+	json        bool
+	onePUX      bool
 }
 
 // NewCmd returns a new command.
@@ -43,24 +69,40 @@ If an entry already exists it will be overwritten.
 
 Delete the CSV used with the erase flag, the file will be deleted only if no errors were encountered.
 
+Use the kdbx flag to read KeePass databases (KDBX 4) directly instead of a CSV file, the database password will be requested. Groups are imported as folders, attachments as files, one-time passwords as TOTPs and custom fields are appended to the notes.
+
+Use the json flag to read Bitwarden JSON exports, the password will be requested only if the export is encrypted. Logins are imported as entries, cards as cards, secure notes as files inside the "notes" folder and identities as files inside the "identities" one.
+
+Use the 1pux flag to read 1Password 1PUX exports. Items are named "<vault>/<category>/<title>", logins and the rest of the categories are imported as entries (with their TOTPs), credit cards as cards and documents as files. Fields without a Kure equivalent and tags are appended to the notes and archived items are skipped.
+
+The generic importer reads any CSV file, the map flag (or a file with one pair per line passed to map-file) maps the entry fields to the header columns: name, folder, username, password, url, notes, expires and totp. Only the name is required, the folder is joined with it and TOTP columns may contain base32 secrets or otpauth URLs.
+
+Browser exports are detected by their header row, entries are named "<browser>/<host>/<username>". Rows without a valid URL or password and those repeating the origin and username of a previous one are skipped and reported.
+
 Supported:
 	• 1Password
 	• Bitwarden
+	• Chrome
+	• Edge
+	• Firefox
+	• Generic
    	• Keepass/X/XC
-	• Lastpass`,
+	• Lastpass
+	• Safari`,
 		Example: example,
 		Args:    managersSupported(),
 		PreRunE: auth.Login(db),
 		RunE:    runImport(db, &opts),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			// Reset flag (session)
-			opts = importOptions{}
-		},
 	}
 
 	f := cmd.Flags()
 	f.StringVarP(&opts.path, "path", "p", "", "source file path")
 	f.BoolVarP(&opts.erase, "erase", "e", false, "erase the file on exit (only if there are no errors)")
+	f.BoolVar(&opts.kdbx, "kdbx", false, "read a KeePass database instead of a CSV file")
+	f.BoolVar(&opts.json, "json", false, "read a Bitwarden JSON export instead of a CSV file")
+	f.BoolVar(&opts.onePUX, "1pux", false, "read a 1Password 1PUX export instead of a CSV file")
+	f.StringSliceVar(&opts.mapping, "map", nil, "map entry fields to CSV columns, used by the generic importer (field=Column)")
+	f.StringVar(&opts.mappingFile, "map-file", "", "file containing the generic importer mapping, one field=Column pair per line")
 
 	return cmd
 }
@@ -73,106 +115,240 @@ func runImport(db *bolt.DB, opts *importOptions) cmdutil.RunEFunc {
 		if opts.path == "" {
 			return cmdutil.ErrInvalidPath
 		}
-		ext := filepath.Ext(opts.path)
-		if ext == "" || ext == "." {
-			opts.path += ".csv"
+		switch {
+		case opts.kdbx:
+			if err := runKDBX(db, manager, opts.path); err != nil {
+				return err
+			}
+		case opts.json:
+			if filepath.Ext(opts.path) == "" {
+				opts.path += ".json"
+			}
+			if err := runJSON(db, manager, opts.path); err != nil {
+				return err
+			}
+		case opts.onePUX:
+			if filepath.Ext(opts.path) == "" {
+				opts.path += ".1pux"
+			}
+			if err := run1PUX(db, cmd.OutOrStdout(), manager, opts.path); err != nil {
+				return err
+			}
+		default:
+			ext := filepath.Ext(opts.path)
+			if ext == "" || ext == "." {
+				opts.path += ".csv"
+			}
+
+			if err := runCSV(db, cmd.OutOrStdout(), manager, opts); err != nil {
+				return err
+			}
 		}
 
-		records, err := readCSV(opts.path)
+		if opts.erase {
+			if err := cmdutil.Erase(opts.path); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "Erased file at", opts.path)
+		}
+
+		fmt.Fprintln(cmd.OutOrStdout(), "Successfully imported the entries from", manager)
+		return nil
+	}
+}
+
+func runCSV(db *bolt.DB, w io.Writer, manager string, opts *importOptions) error {
+	var mapping columnMapping
+	if manager == "generic" {
+		var err error
+		switch {
+		case opts.mappingFile != "" && len(opts.mapping) > 0:
+			return errors.New("use either the map or the map-file flag")
+		case opts.mappingFile != "":
+			mapping, err = readMappingFile(opts.mappingFile)
+		case len(opts.mapping) > 0:
+			mapping, err = parseMapping(opts.mapping)
+		default:
+			return errors.New("the generic importer requires a mapping, use the map or the map-file flag")
+		}
 		if err != nil {
 			return err
 		}
+	} else if opts.mappingFile != "" || len(opts.mapping) > 0 {
+		return errors.New("the map and map-file flags are only supported by the generic importer")
+	}
 
-		if err := createEntries(db, manager, records); err != nil {
+	records, err := readCSV(opts.path)
+	if err != nil {
+		return err
+	}
+
+	if mapping != nil {
+		return importGeneric(db, records, mapping)
+	}
+
+	if _, ok := browserFormats[manager]; ok {
+		skipped, err := importBrowser(db, manager, records)
+		if err != nil {
 			return err
 		}
-
-		if opts.erase {
-			if err := cmdutil.Erase(opts.path); err != nil {
-				return err
+		if len(skipped) > 0 {
+			fmt.Fprintf(w, "Skipped %d rows:\n", len(skipped))
+			for _, s := range skipped {
+				fmt.Fprintln(w, "  ", s)
 			}
-			fmt.Println("Erased file at", opts.path)
 		}
-
-		fmt.Println("Successfully imported the entries from", manager)
 		return nil
 	}
+
+	return createEntries(db, manager, records)
+}
+
+func run1PUX(db *bolt.DB, w io.Writer, manager, path string) error {
+	if manager != "1password" {
+		return errors.New("the 1pux format is only supported by 1Password")
+	}
+
+	summary, err := import1PUX(db, path)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, summary)
+	return nil
+}
+
+func runKDBX(db *bolt.DB, manager, path string) error {
+	if !strings.HasPrefix(manager, "keepass") {
+		return errors.New("the kdbx format is only supported by Keepass/X/XC")
+	}
+
+	password, err := auth.AskPassword("Enter KDBX password", false)
+	if err != nil {
+		return err
+	}
+	pwd, err := password.Open()
+	if err != nil {
+		return errors.Wrap(err, "opening enclave")
+	}
+	defer pwd.Destroy()
+
+	return importKDBX(db, path, pwd.Bytes())
+}
+
+func runJSON(db *bolt.DB, manager, path string) error {
+	if manager != "bitwarden" {
+		return errors.New("the json format is only supported by Bitwarden")
+	}
+
+	var pwd *memguard.LockedBuffer
+	password := func() ([]byte, error) {
+		enclave, err := auth.AskPassword("Enter export password", false)
+		if err != nil {
+			return nil, err
+		}
+		pwd, err = enclave.Open()
+		if err != nil {
+			return nil, errors.Wrap(err, "opening enclave")
+		}
+		return pwd.Bytes(), nil
+	}
+	defer func() {
+		if pwd != nil {
+			pwd.Destroy()
+		}
+	}()
+
+	return importBitwarden(db, path, password)
+}
+
+// managerColumns contains the columns of each password manager CSV export.
+var managerColumns = map[string][]string{
+	"keepass":   {"Account", "Login Name", "Password", "Web Site", "Comments"},
+	"keepassx":  {"Account", "Login Name", "Password", "Web Site", "Comments"},
+	"keepassxc": {"Group", "Title", "Username", "Password", "URL", "Notes"},
+	"1password": {"Title", "Website", "Username", "Password", "Notes", "Member Number", "Recovery Codes"},
+	"lastpass":  {"URL", "Username", "Password", "Extra", "Name", "Grouping"},
+	"bitwarden": {"Folder", "Name", "Notes", "Login_uri", "Login_username", "Login_password", "Login_totp"},
 }
 
 func createEntries(db *bolt.DB, manager string, records [][]string) error {
+	columns, err := findColumns(records[0], managerColumns[manager]...)
+	if err != nil {
+		return err
+	}
 	// [1:] used to skip headers
-	records = records[:][1:]
+	records = records[1:]
 	entries := make([]*pb.Entry, len(records))
 
-	switch manager {
-	case "keepass", "keepassx":
-		for i, record := range records {
+	for i, record := range records {
+		get := func(column string) string {
+			return record[columns[column]]
+		}
+
+		switch manager {
+		case "keepass", "keepassx":
 			entries[i] = &pb.Entry{
-				Name:     cmdutil.NormalizeName(record[0]),
-				Username: record[1],
-				Password: record[2],
-				URL:      record[3],
-				Notes:    record[4],
+				Name:     cmdutil.NormalizeName(get("Account")),
+				Username: get("Login Name"),
+				Password: get("Password"),
+				URL:      get("Web Site"),
+				Notes:    get("Comments"),
 				Expires:  "Never",
 			}
-		}
 
-	case "keepassxc":
-		for i, record := range records {
+		case "keepassxc":
 			entries[i] = &pb.Entry{
 				// Join folder and name
-				Name:     cmdutil.NormalizeName(record[0] + "/" + record[1]),
-				Username: record[2],
-				Password: record[3],
-				URL:      record[4],
-				Notes:    record[5],
+				Name:     cmdutil.NormalizeName(get("Group") + "/" + get("Title")),
+				Username: get("Username"),
+				Password: get("Password"),
+				URL:      get("URL"),
+				Notes:    get("Notes"),
 				Expires:  "Never",
 			}
-		}
 
-	case "1password":
-		for i, record := range records {
+		case "1password":
 			entries[i] = &pb.Entry{
-				Name:     cmdutil.NormalizeName(record[0]),
-				Username: record[2],
-				Password: record[3],
-				URL:      record[1],
-				Notes:    fmt.Sprintf("%s.\nMember number: %s.\nRecovery Codes: %s", record[4], record[5], record[6]),
+				Name:     cmdutil.NormalizeName(get("Title")),
+				Username: get("Username"),
+				Password: get("Password"),
+				URL:      get("Website"),
+				Notes:    fmt.Sprintf("%s.\nMember number: %s.\nRecovery Codes: %s", get("Notes"), get("Member Number"), get("Recovery Codes")),
 				Expires:  "Never",
 			}
-		}
 
-	case "lastpass":
-		for i, record := range records {
+		case "lastpass":
 			entries[i] = &pb.Entry{
 				// Join folder and name
-				Name:     cmdutil.NormalizeName(record[5] + "/" + record[4]),
-				Username: record[1],
-				Password: record[2],
-				URL:      record[0],
-				Notes:    record[3],
+				Name:     cmdutil.NormalizeName(get("Grouping") + "/" + get("Name")),
+				Username: get("Username"),
+				Password: get("Password"),
+				URL:      get("URL"),
+				Notes:    get("Extra"),
 				Expires:  "Never",
 			}
-		}
 
-	case "bitwarden":
-		for i, record := range records {
+		case "bitwarden":
 			// Join folder and name
-			name := cmdutil.NormalizeName(record[0] + "/" + record[3])
+			name := cmdutil.NormalizeName(get("Folder") + "/" + get("Name"))
 			entries[i] = &pb.Entry{
 				Name:     name,
-				Username: record[7],
-				Password: record[8],
-				URL:      record[6],
-				Notes:    record[4],
+				Username: get("Login_username"),
+				Password: get("Login_password"),
+				URL:      get("Login_uri"),
+				Notes:    get("Notes"),
 				Expires:  "Never",
 			}
 
 			// Create TOTP if the entry has one
-			if err := createTOTP(db, name, record[9]); err != nil {
-				return err
+			if err := createTOTP(db, name, get("Login_totp")); err != nil {
+				return errors.Wrapf(err, "row %d", i+2)
 			}
 		}
+
+		if entries[i].Name == "" {
+			return errors.Errorf("row %d: the entry name is empty", i+2)
+		}
 	}
 
 	return entry.Create(db, entries...)
@@ -193,6 +369,99 @@ func createTOTP(db *bolt.DB, name, rawToken string) error {
 	return totp.Create(db, t)
 }
 
+// records contains the records extracted from an export.
+type records struct {
+	entries []*pb.Entry
+	totps   []*pb.TOTP
+	cards   []*pb.Card
+	files   []*pb.File
+}
+
+// store saves all the records, existing ones are overwritten.
+func (r *records) store(db *bolt.DB) error {
+	if err := entry.Create(db, r.entries...); err != nil {
+		return err
+	}
+	for _, t := range r.totps {
+		if err := totp.Create(db, t); err != nil {
+			return err
+		}
+	}
+	for _, c := range r.cards {
+		if err := card.Create(db, c); err != nil {
+			return err
+		}
+	}
+	for _, f := range r.files {
+		if err := file.Create(db, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uniqueNames keeps track of the names used in an import to avoid overwriting records with the same name.
+type uniqueNames map[string]struct{}
+
+// get returns the name passed, with a number appended if it was already used.
+func (u uniqueNames) get(name string) string {
+	unique := name
+	for i := 2; ; i++ {
+		if _, ok := u[unique]; !ok {
+			break
+		}
+		unique = fmt.Sprintf("%s (%d)", name, i)
+	}
+	u[unique] = struct{}{}
+	return unique
+}
+
+// parseOTPURL returns the secret and digits of a TOTP URL, the latter is empty if not specified.
+func parseOTPURL(uri string) (secret, digits string, err error) {
+	URL, err := url.Parse(uri)
+	if err != nil {
+		return "", "", errors.Wrap(err, "parsing url")
+	}
+	if URL.Scheme != "otpauth" || URL.Host != "totp" {
+		return "", "", errors.New("only otpauth://totp URLs are supported")
+	}
+
+	query := URL.Query()
+	return query.Get("secret"), query.Get("digits"), nil
+}
+
+// parseTOTP parses a one-time password, either an otpauth URL or a base32 secret.
+func parseTOTP(value string) (*pb.TOTP, error) {
+	secret, digits := value, ""
+	if strings.HasPrefix(value, "otpauth://") {
+		var err error
+		secret, digits, err = parseOTPURL(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return newTOTP(secret, digits)
+}
+
+// newTOTP validates the base32 secret and the digits passed, six digits are used if empty.
+func newTOTP(secret, digits string) (*pb.TOTP, error) {
+	secret, err := cmdutil.NormalizeTOTPKey(secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid secret")
+	}
+
+	t := &pb.TOTP{Raw: secret, Digits: 6}
+	if digits != "" {
+		d, err := strconv.Atoi(digits)
+		if err != nil || d < 6 || d > 8 {
+			return nil, errors.Errorf("unsupported digits number %q", digits)
+		}
+		t.Digits = int32(d)
+	}
+
+	return t, nil
+}
+
 func readCSV(path string) ([][]string, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -210,25 +479,61 @@ func readCSV(path string) ([][]string, error) {
 	}
 
 	r := csv.NewReader(f)
+	// The width is validated below to report the row number
+	r.FieldsPerRecord = -1
 	records, err := r.ReadAll()
 	if err != nil {
 		return nil, errors.Wrap(err, "reading csv data")
 	}
 
+	if len(records) == 0 {
+		return nil, errors.New("the CSV file is empty")
+	}
+	for i, record := range records[1:] {
+		if len(record) != len(records[0]) {
+			return nil, errors.Errorf("row %d: expected %d columns, got %d", i+2, len(records[0]), len(record))
+		}
+	}
+
 	return records, nil
 }
 
+// headerIndex maps the lowercase column names of the header to their index.
+func headerIndex(header []string) map[string]int {
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		// Files edited with some spreadsheet programs start with a byte order mark
+		h = strings.TrimPrefix(h, "\ufeff")
+		index[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	return index
+}
+
+// findColumns returns the index of each of the columns passed, it fails if the header doesn't contain them.
+func findColumns(header []string, names ...string) (map[string]int, error) {
+	index := headerIndex(header)
+	columns := make(map[string]int, len(names))
+	for _, name := range names {
+		i, ok := index[strings.ToLower(name)]
+		if !ok {
+			return nil, errors.Errorf("invalid header: missing %q column", name)
+		}
+		columns[name] = i
+	}
+	return columns, nil
+}
+
 func managersSupported() cobra.PositionalArgs {
 	return func(cmd *cobra.Command, args []string) error {
 		manager := strings.Join(args, " ")
 
 		switch strings.ToLower(manager) {
-		case "1password", "bitwarden", "keepass", "keepassx", "keepassxc", "lastpass":
+		case "1password", "bitwarden", "chrome", "edge", "firefox", "generic", "keepass", "keepassx", "keepassxc", "lastpass", "safari":
 
 		default:
 			return errors.Errorf(`%q is not supported
 
-Managers supported: 1Password, Bitwarden, Keepass/X/XC, Lastpass`, manager)
+Managers supported: 1Password, Bitwarden, Chrome, Edge, Firefox, Generic, Keepass/X/XC, Lastpass, Safari`, manager)
 		}
 		return nil
 	}
@@ -82,6 +82,19 @@ func TestImport(t *testing.T) {
 				Expires:  "Never",
 			},
 		},
+		{
+			manager: "Chrome",
+			path:    "testdata/test_chrome.csv",
+			// Kure will name entries after the website host and the username
+			expected: &pb.Entry{
+				Name:     "chrome/github.com/gopher",
+				Username: "gopher",
+				Password: "github123",
+				URL:      "https://github.com/login",
+				Notes:    "Notes",
+				Expires:  "Never",
+			},
+		},
 	}
 
 	cmd := NewCmd(db)
@@ -150,6 +163,16 @@ func TestInvalidImport(t *testing.T) {
 			manager: "1password",
 			path:    "testdata/test_invalid_entry.csv",
 		},
+		{
+			desc:    "Invalid row width",
+			manager: "keepass",
+			path:    "testdata/test_invalid_width.csv",
+		},
+		{
+			desc:    "Invalid header",
+			manager: "keepassxc",
+			path:    "testdata/test_keepass.csv",
+		},
 	}
 
 	cmd := NewCmd(db)
@@ -173,7 +196,7 @@ func TestImportAndErase(t *testing.T) {
 	if err != nil {
 		t.Errorf("Failed creating temporary file: %v", err)
 	}
-	tempFile.WriteString("test")
+	tempFile.WriteString("Account,Login Name,Password,Web Site,Comments")
 	tempFile.Close()
 
 	cmd := NewCmd(db)
@@ -201,7 +224,7 @@ func TestImportAndEraseError(t *testing.T) {
 	if err != nil {
 		t.Errorf("Failed creating temporary file: %v", err)
 	}
-	tempFile.WriteString("test")
+	tempFile.WriteString("Account,Login Name,Password,Web Site,Comments")
 
 	cmd := NewCmd(db)
 	cmd.SetArgs([]string{"lastpass"})
@@ -268,7 +291,3 @@ func TestArgs(t *testing.T) {
 		}
 	})
 }
-
-func TestPostRun(t *testing.T) {
-	NewCmd(nil).PostRun(nil, nil)
-}
@@ -0,0 +1,171 @@
+package importt
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/kdbx"
+	"github.com/GGP1/kure/pb"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Fields holding one-time password secrets, they are stored as TOTPs and not added to the notes.
+var otpFields = map[string]struct{}{
+	"otp":                   {},
+	"TOTP Seed":             {},
+	"TOTP Settings":         {},
+	"TimeOtp-Secret-Base32": {},
+	"TimeOtp-Length":        {},
+}
+
+// kdbxRecords contains the records extracted from a KeePass database.
+type kdbxRecords struct {
+	records
+	// names is used to avoid overwriting entries with the same title inside a group
+	names uniqueNames
+}
+
+// importKDBX decrypts the KeePass database located in path and stores its entries,
+// one-time passwords and attachments.
+func importKDBX(db *bolt.DB, path string, password []byte) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "opening file")
+	}
+	defer f.Close()
+
+	database, err := kdbx.Read(f, password)
+	if err != nil {
+		return err
+	}
+
+	records := &kdbxRecords{names: make(uniqueNames)}
+	// The root group name is the database one, it's not used as a folder
+	if err := records.addGroup(database.Root, "", time.Now()); err != nil {
+		return err
+	}
+
+	return records.store(db)
+}
+
+// addGroup converts the group entries and those of its subgroups, folder is the path of the group.
+func (r *kdbxRecords) addGroup(group kdbx.Group, folder string, now time.Time) error {
+	for _, e := range group.Entries {
+		if err := r.addEntry(e, folder, now); err != nil {
+			return err
+		}
+	}
+
+	for _, g := range group.Groups {
+		if err := r.addGroup(g, folder+g.Name+"/", now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *kdbxRecords) addEntry(e kdbx.Entry, folder string, now time.Time) error {
+	title := e.Get(kdbx.TitleField)
+	if title == "" {
+		title = "untitled"
+	}
+	name := r.names.get(cmdutil.NormalizeName(folder + title))
+
+	entry := &pb.Entry{
+		Name:     name,
+		Username: e.Get(kdbx.UserNameField),
+		Password: e.Get(kdbx.PasswordField),
+		URL:      e.Get(kdbx.URLField),
+		Notes:    kdbxNotes(e),
+		Expires:  "Never",
+	}
+	if e.Expires {
+		entry.Expires = e.ExpiryTime.Format(time.RFC1123Z)
+		entry.ExpiresAt = e.ExpiryTime.Unix()
+	}
+	r.entries = append(r.entries, entry)
+
+	t, err := kdbxTOTP(e)
+	if err != nil {
+		return errors.Wrapf(err, "%q one-time password", name)
+	}
+	if t != nil {
+		t.Name = name
+		r.totps = append(r.totps, t)
+	}
+
+	for _, a := range e.Attachments {
+		r.files = append(r.files, &pb.File{
+			Name:      cmdutil.NormalizeName(name + "/" + a.Name),
+			Content:   a.Data,
+			Size:      int64(len(a.Data)),
+			CreatedAt: now.Unix(),
+			UpdatedAt: time.Time{}.Unix(),
+		})
+	}
+
+	return nil
+}
+
+// kdbxNotes returns the entry notes followed by its custom fields.
+func kdbxNotes(e kdbx.Entry) string {
+	var sb strings.Builder
+	sb.WriteString(e.Get(kdbx.NotesField))
+
+	for _, f := range e.Fields {
+		switch f.Key {
+		case kdbx.TitleField, kdbx.UserNameField, kdbx.PasswordField, kdbx.URLField, kdbx.NotesField:
+			continue
+		}
+		if _, ok := otpFields[f.Key]; ok {
+			continue
+		}
+
+		if sb.Len() > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(f.Key)
+		sb.WriteString(": ")
+		sb.WriteString(f.Value)
+	}
+
+	return sb.String()
+}
+
+// kdbxTOTP returns the entry TOTP or nil if it has none.
+//
+// The "otp" field is used by KeePassXC and plugins, "TOTP Seed" by older KeePassXC versions and
+// "TimeOtp-Secret-Base32" by KeePass.
+func kdbxTOTP(e kdbx.Entry) (*pb.TOTP, error) {
+	var (
+		secret string
+		digits string
+	)
+
+	switch {
+	case e.Get("otp") != "":
+		var err error
+		secret, digits, err = parseOTPURL(e.Get("otp"))
+		if err != nil {
+			return nil, err
+		}
+
+	case e.Get("TOTP Seed") != "":
+		secret = e.Get("TOTP Seed")
+		// Format: "period;digits"
+		_, digits, _ = strings.Cut(e.Get("TOTP Settings"), ";")
+
+	case e.Get("TimeOtp-Secret-Base32") != "":
+		secret = e.Get("TimeOtp-Secret-Base32")
+		digits = e.Get("TimeOtp-Length")
+
+	default:
+		return nil, nil
+	}
+
+	return newTOTP(secret, digits)
+}
@@ -0,0 +1,203 @@
+package importt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/db/file"
+	"github.com/GGP1/kure/db/totp"
+	"github.com/GGP1/kure/kdbx"
+	"github.com/GGP1/kure/pb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestImportKDBX(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	database := &kdbx.Database{
+		Name: "Passwords",
+		Root: kdbx.Group{
+			Name: "Passwords",
+			Entries: []kdbx.Entry{
+				{
+					Fields: []kdbx.Field{
+						{Key: kdbx.TitleField, Value: "GitHub"},
+						{Key: kdbx.UserNameField, Value: "gopher"},
+						{Key: kdbx.PasswordField, Value: "github123", Protected: true},
+						{Key: kdbx.URLField, Value: "https://github.com"},
+						{Key: kdbx.NotesField, Value: "Notes"},
+						{Key: "otp", Value: "otpauth://totp/GitHub:gopher?secret=JBSWY3DPEHPK3PXP&digits=8", Protected: true},
+						{Key: "Recovery code", Value: "1234", Protected: true},
+					},
+					Attachments: []kdbx.Attachment{{Name: "codes.txt", Data: []byte("1234")}},
+					Expires:     true,
+					ExpiryTime:  expires,
+				},
+			},
+			Groups: []kdbx.Group{
+				{
+					Name: "Work",
+					Entries: []kdbx.Entry{
+						{Fields: []kdbx.Field{{Key: kdbx.TitleField, Value: "ssh"}, {Key: kdbx.PasswordField, Value: "first"}}},
+						{Fields: []kdbx.Field{{Key: kdbx.TitleField, Value: "ssh"}, {Key: kdbx.PasswordField, Value: "second"}}},
+					},
+				},
+			},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "test.kdbx")
+	writeKDBX(t, path, database, "kdbx")
+
+	if err := importKDBX(db, path, []byte("kdbx")); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []*pb.Entry{
+		{
+			Name:      "github",
+			Username:  "gopher",
+			Password:  "github123",
+			URL:       "https://github.com",
+			Notes:     "Notes\nRecovery code: 1234",
+			Expires:   expires.Format(time.RFC1123Z),
+			ExpiresAt: expires.Unix(),
+		},
+		{Name: "work/ssh", Password: "first", Expires: "Never"},
+		{Name: "work/ssh (2)", Password: "second", Expires: "Never"},
+	}
+	for _, e := range expected {
+		got, err := entry.Get(db, e.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(e, got) {
+			t.Errorf("Expected %v, got %v", e, got)
+		}
+	}
+
+	gotTOTP, err := totp.Get(db, "github")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedTOTP := &pb.TOTP{Name: "github", Raw: "JBSWY3DPEHPK3PXP", Digits: 8}
+	if !proto.Equal(expectedTOTP, gotTOTP) {
+		t.Errorf("Expected %v, got %v", expectedTOTP, gotTOTP)
+	}
+
+	gotFile, err := file.Get(db, "github/codes.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(gotFile.Content) != "1234" {
+		t.Errorf("Expected %q, got %q", "1234", gotFile.Content)
+	}
+}
+
+func TestImportKDBXErrors(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+	path := filepath.Join(t.TempDir(), "test.kdbx")
+	writeKDBX(t, path, &kdbx.Database{Name: "Test"}, "kdbx")
+
+	if err := importKDBX(db, path, []byte("invalid")); err != kdbx.ErrInvalidCredentials {
+		t.Errorf("Expected %v, got %v", kdbx.ErrInvalidCredentials, err)
+	}
+	if err := importKDBX(db, "testdata/test_keepass.csv", []byte("kdbx")); err == nil {
+		t.Error("Expected an error and got nil")
+	}
+}
+
+func TestKDBXTOTP(t *testing.T) {
+	cases := []struct {
+		desc     string
+		fields   []kdbx.Field
+		expected *pb.TOTP
+	}{
+		{
+			desc:     "None",
+			expected: nil,
+		},
+		{
+			desc:     "URL",
+			fields:   []kdbx.Field{{Key: "otp", Value: "otpauth://totp/Test?secret=JBSWY3DPEHPK3PXP"}},
+			expected: &pb.TOTP{Raw: "JBSWY3DPEHPK3PXP", Digits: 6},
+		},
+		{
+			desc: "KeePassXC legacy",
+			fields: []kdbx.Field{
+				{Key: "TOTP Seed", Value: "jbsw y3dp ehpk 3pxp"},
+				{Key: "TOTP Settings", Value: "30;7"},
+			},
+			expected: &pb.TOTP{Raw: "JBSWY3DPEHPK3PXP", Digits: 7},
+		},
+		{
+			desc: "KeePass",
+			fields: []kdbx.Field{
+				{Key: "TimeOtp-Secret-Base32", Value: "JBSWY3DPEHPK3PX"},
+				{Key: "TimeOtp-Length", Value: "8"},
+			},
+			expected: &pb.TOTP{Raw: "JBSWY3DPEHPK3PX=", Digits: 8},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := kdbxTOTP(kdbx.Entry{Fields: tc.fields})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(tc.expected, got) {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestKDBXTOTPErrors(t *testing.T) {
+	cases := []struct {
+		desc   string
+		fields []kdbx.Field
+	}{
+		{
+			desc:   "HOTP",
+			fields: []kdbx.Field{{Key: "otp", Value: "otpauth://hotp/Test?secret=JBSWY3DPEHPK3PXP"}},
+		},
+		{
+			desc:   "Invalid secret",
+			fields: []kdbx.Field{{Key: "otp", Value: "otpauth://totp/Test?secret=not-base32"}},
+		},
+		{
+			desc:   "Steam",
+			fields: []kdbx.Field{{Key: "TOTP Seed", Value: "JBSWY3DPEHPK3PXP"}, {Key: "TOTP Settings", Value: "30;S"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if _, err := kdbxTOTP(kdbx.Entry{Fields: tc.fields}); err == nil {
+				t.Error("Expected an error and got nil")
+			}
+		})
+	}
+}
+
+func writeKDBX(t *testing.T, path string, database *kdbx.Database, password string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	opts := kdbx.Options{Cipher: kdbx.ChaCha20, KDF: kdbx.Argon2d, Iterations: 1, Memory: 32, Parallelism: 1, Compress: true}
+	if err := kdbx.Write(f, database, []byte(password), opts); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,453 @@
+package importt
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/pb"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// 1Password item categories with a Kure record type, the rest are imported as entries.
+const (
+	loginCategory      = "001"
+	creditCardCategory = "002"
+	passwordCategory   = "005"
+	documentCategory   = "006"
+)
+
+// onePUXCategories maps the 1Password categories identifiers to their names.
+var onePUXCategories = map[string]string{
+	"001": "Login",
+	"002": "Credit Card",
+	"003": "Secure Note",
+	"004": "Identity",
+	"005": "Password",
+	"006": "Document",
+	"100": "Software License",
+	"101": "Bank Account",
+	"102": "Database",
+	"103": "Driver License",
+	"104": "Outdoor License",
+	"105": "Membership",
+	"106": "Passport",
+	"107": "Reward Program",
+	"108": "Social Security Number",
+	"109": "Wireless Router",
+	"110": "Server",
+	"111": "Email Account",
+	"112": "API Credential",
+	"113": "Medical Record",
+	"114": "SSH Key",
+	"115": "Crypto Wallet",
+}
+
+// onePUXExport is the content of the export.data file.
+type onePUXExport struct {
+	Accounts []struct {
+		Vaults []struct {
+			Attrs struct {
+				Name string `json:"name"`
+			} `json:"attrs"`
+			Items []onePUXItem `json:"items"`
+		} `json:"vaults"`
+	} `json:"accounts"`
+}
+
+type onePUXItem struct {
+	CreatedAt    int64  `json:"createdAt"`
+	UpdatedAt    int64  `json:"updatedAt"`
+	State        string `json:"state"`
+	CategoryUUID string `json:"categoryUuid"`
+	Details      struct {
+		LoginFields []struct {
+			Value       string `json:"value"`
+			Name        string `json:"name"`
+			FieldType   string `json:"fieldType"`
+			Designation string `json:"designation"`
+		} `json:"loginFields"`
+		NotesPlain string `json:"notesPlain"`
+		// Password is used by the items of the password category
+		Password string `json:"password"`
+		Sections []struct {
+			Title  string        `json:"title"`
+			Fields []onePUXField `json:"fields"`
+		} `json:"sections"`
+		PasswordHistory []struct {
+			Value string `json:"value"`
+			Time  int64  `json:"time"`
+		} `json:"passwordHistory"`
+		DocumentAttributes *onePUXDocument `json:"documentAttributes"`
+	} `json:"details"`
+	Overview struct {
+		Title string `json:"title"`
+		URL   string `json:"url"`
+		URLs  []struct {
+			URL string `json:"url"`
+		} `json:"urls"`
+		Tags []string `json:"tags"`
+	} `json:"overview"`
+}
+
+type onePUXField struct {
+	Title string      `json:"title"`
+	ID    string      `json:"id"`
+	Value onePUXValue `json:"value"`
+}
+
+// onePUXValue contains a section field value, only the field of its type is set.
+type onePUXValue struct {
+	String           *string `json:"string"`
+	Concealed        *string `json:"concealed"`
+	TOTP             *string `json:"totp"`
+	URL              *string `json:"url"`
+	Phone            *string `json:"phone"`
+	Menu             *string `json:"menu"`
+	Gender           *string `json:"gender"`
+	CreditCardType   *string `json:"creditCardType"`
+	CreditCardNumber *string `json:"creditCardNumber"`
+	// Date is a Unix timestamp
+	Date *int64 `json:"date"`
+	// MonthYear is formatted as YYYYMM
+	MonthYear *int `json:"monthYear"`
+	Email     *struct {
+		Address string `json:"email_address"`
+	} `json:"email"`
+	Address *struct {
+		Street  string `json:"street"`
+		City    string `json:"city"`
+		State   string `json:"state"`
+		Zip     string `json:"zip"`
+		Country string `json:"country"`
+	} `json:"address"`
+	SSHKey *struct {
+		PrivateKey string `json:"privateKey"`
+	} `json:"sshKey"`
+	File *onePUXDocument `json:"file"`
+}
+
+// onePUXDocument is a file stored inside the export as "files/<documentId>__<fileName>".
+type onePUXDocument struct {
+	FileName   string `json:"fileName"`
+	DocumentID string `json:"documentId"`
+}
+
+// onePUXRecords contains the records extracted from a 1Password export.
+type onePUXRecords struct {
+	records
+	entryNames uniqueNames
+	cardNames  uniqueNames
+	fileNames  uniqueNames
+	// documents maps the documents identifiers to their files
+	documents map[string]*zip.File
+	archived  int
+}
+
+// import1PUX reads the 1Password export located in path and stores its items, it returns a summary
+// of the records imported.
+//
+// Items are named "<vault>/<category>/<title>". Credit cards are stored as cards, documents as files
+// and the rest of the items as entries.
+func import1PUX(db *bolt.DB, path string) (string, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return "", errors.Wrap(err, "opening file")
+	}
+	defer zr.Close()
+
+	r := &onePUXRecords{
+		entryNames: make(uniqueNames),
+		cardNames:  make(uniqueNames),
+		fileNames:  make(uniqueNames),
+		documents:  make(map[string]*zip.File),
+	}
+
+	var export onePUXExport
+	found := false
+	for _, f := range zr.File {
+		if f.Name == "export.data" {
+			if err := readZipJSON(f, &export); err != nil {
+				return "", err
+			}
+			found = true
+			continue
+		}
+		if strings.HasPrefix(f.Name, "files/") {
+			id, _, _ := strings.Cut(strings.TrimPrefix(f.Name, "files/"), "__")
+			r.documents[id] = f
+		}
+	}
+	if !found {
+		return "", errors.New("invalid 1pux file: export.data not found")
+	}
+
+	for _, account := range export.Accounts {
+		for _, vault := range account.Vaults {
+			for _, item := range vault.Items {
+				if err := r.addItem(item, vault.Attrs.Name); err != nil {
+					return "", errors.Wrapf(err, "%q", item.Overview.Title)
+				}
+			}
+		}
+	}
+
+	if err := r.store(db); err != nil {
+		return "", err
+	}
+	return r.summary(), nil
+}
+
+func (r *onePUXRecords) addItem(item onePUXItem, vault string) error {
+	if item.State == "archived" {
+		r.archived++
+		return nil
+	}
+
+	category, ok := onePUXCategories[item.CategoryUUID]
+	if !ok {
+		category = "Other"
+	}
+	title := item.Overview.Title
+	if title == "" {
+		title = "untitled"
+	}
+	name := cmdutil.NormalizeName(vault + "/" + category + "/" + title)
+
+	switch item.CategoryUUID {
+	case creditCardCategory:
+		r.addCard(item, r.cardNames.get(name))
+		return nil
+
+	case documentCategory:
+		doc := item.Details.DocumentAttributes
+		if doc == nil {
+			return errors.New("missing document attributes")
+		}
+		dir, _ := path.Split(name)
+		return r.addFile(dir+doc.FileName, doc, item)
+
+	default:
+		return r.addEntry(item, r.entryNames.get(name))
+	}
+}
+
+func (r *onePUXRecords) addEntry(item onePUXItem, name string) error {
+	entry := &pb.Entry{
+		Name:     name,
+		Password: item.Details.Password,
+		URL:      item.Overview.URL,
+		Expires:  "Never",
+	}
+
+	var notes []string
+	for _, f := range item.Details.LoginFields {
+		switch {
+		case f.Designation == "username":
+			entry.Username = f.Value
+		case f.Designation == "password":
+			entry.Password = f.Value
+		case f.Value != "" && f.Name != "" && strings.ContainsAny(f.FieldType, "TEP"):
+			// Other text, email and password inputs of the login form
+			notes = append(notes, f.Name+": "+f.Value)
+		}
+	}
+	for _, u := range item.Overview.URLs {
+		if u.URL != "" && u.URL != entry.URL {
+			notes = append(notes, "URL: "+u.URL)
+		}
+	}
+
+	// Items without login fields, like databases or servers, store their credentials in sections
+	isLogin := item.CategoryUUID == loginCategory || item.CategoryUUID == passwordCategory
+	var totpValue string
+	for _, s := range item.Details.Sections {
+		for _, f := range s.Fields {
+			switch {
+			case f.Value.TOTP != nil:
+				// Only one TOTP is supported, the rest are kept in the notes
+				if totpValue == "" {
+					totpValue = *f.Value.TOTP
+					continue
+				}
+			case f.Value.File != nil:
+				if err := r.addFile(name+"/"+f.Value.File.FileName, f.Value.File, item); err != nil {
+					return err
+				}
+				continue
+			case !isLogin && f.ID == "username" && entry.Username == "":
+				entry.Username = f.Value.text()
+				continue
+			case !isLogin && f.ID == "password" && entry.Password == "":
+				entry.Password = f.Value.text()
+				continue
+			}
+			if v := f.Value.text(); v != "" {
+				notes = append(notes, f.Title+": "+v)
+			}
+		}
+	}
+
+	entry.Notes = onePUXNotes(item, notes)
+
+	// Use the most recent password
+	var last int64
+	for _, h := range item.Details.PasswordHistory {
+		if h.Time >= last {
+			entry.PreviousPassword = h.Value
+			last = h.Time
+		}
+	}
+	r.entries = append(r.entries, entry)
+
+	if totpValue != "" {
+		t, err := parseTOTP(totpValue)
+		if err != nil {
+			return errors.Wrap(err, "one-time password")
+		}
+		t.Name = name
+		r.totps = append(r.totps, t)
+	}
+
+	return nil
+}
+
+func (r *onePUXRecords) addCard(item onePUXItem, name string) {
+	card := &pb.Card{Name: name}
+
+	var notes []string
+	for _, s := range item.Details.Sections {
+		for _, f := range s.Fields {
+			switch f.ID {
+			case "type":
+				card.Type = f.Value.text()
+			case "ccnum":
+				card.Number = f.Value.text()
+			case "cvv":
+				card.SecurityCode = f.Value.text()
+			case "expiry":
+				card.ExpireDate = f.Value.text()
+			default:
+				if v := f.Value.text(); v != "" {
+					notes = append(notes, f.Title+": "+v)
+				}
+			}
+		}
+	}
+	card.Notes = onePUXNotes(item, notes)
+	cmdutil.SetCardExpires(card)
+
+	r.cards = append(r.cards, card)
+}
+
+// addFile stores the content of the document passed.
+func (r *onePUXRecords) addFile(name string, doc *onePUXDocument, item onePUXItem) error {
+	f, ok := r.documents[doc.DocumentID]
+	if !ok {
+		return errors.Errorf("file %q not found", doc.FileName)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return errors.Wrapf(err, "opening %q", doc.FileName)
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return errors.Wrapf(err, "reading %q", doc.FileName)
+	}
+
+	r.files = append(r.files, &pb.File{
+		Name:      r.fileNames.get(cmdutil.NormalizeName(name)),
+		Content:   content,
+		Size:      int64(len(content)),
+		CreatedAt: item.CreatedAt,
+		UpdatedAt: item.UpdatedAt,
+	})
+	return nil
+}
+
+// summary returns the number of records of each type imported.
+func (r *onePUXRecords) summary() string {
+	summary := fmt.Sprintf("Imported %d entries, %d TOTPs, %d cards and %d files",
+		len(r.entries), len(r.totps), len(r.cards), len(r.files))
+	if r.archived > 0 {
+		summary += fmt.Sprintf(" (%d archived items skipped)", r.archived)
+	}
+	return summary
+}
+
+// onePUXNotes returns the item notes followed by the fields passed and its tags.
+func onePUXNotes(item onePUXItem, fields []string) string {
+	if len(item.Overview.Tags) > 0 {
+		fields = append(fields, "Tags: "+strings.Join(item.Overview.Tags, ", "))
+	}
+
+	notes := item.Details.NotesPlain
+	if len(fields) > 0 {
+		if notes != "" {
+			notes += "\n"
+		}
+		notes += strings.Join(fields, "\n")
+	}
+	return notes
+}
+
+// text returns the value formatted as text, it's empty for files.
+func (v onePUXValue) text() string {
+	for _, s := range []*string{v.String, v.Concealed, v.TOTP, v.URL, v.Phone, v.Menu,
+		v.Gender, v.CreditCardType, v.CreditCardNumber} {
+		if s != nil {
+			return *s
+		}
+	}
+
+	switch {
+	case v.Date != nil:
+		if *v.Date == 0 {
+			return ""
+		}
+		return time.Unix(*v.Date, 0).UTC().Format("2006-01-02")
+	case v.MonthYear != nil:
+		if *v.MonthYear == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%02d/%d", *v.MonthYear%100, *v.MonthYear/100)
+	case v.Email != nil:
+		return v.Email.Address
+	case v.Address != nil:
+		a := v.Address
+		var parts []string
+		for _, p := range []string{a.Street, a.City, a.State, a.Zip, a.Country} {
+			if p != "" {
+				parts = append(parts, p)
+			}
+		}
+		return strings.Join(parts, ", ")
+	case v.SSHKey != nil:
+		return v.SSHKey.PrivateKey
+	}
+
+	return ""
+}
+
+func readZipJSON(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return errors.Wrapf(err, "opening %s", f.Name)
+	}
+	defer rc.Close()
+
+	if err := json.NewDecoder(rc).Decode(v); err != nil {
+		return errors.Wrapf(err, "parsing %s", f.Name)
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package importt
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/card"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/db/file"
+	"github.com/GGP1/kure/db/totp"
+	"github.com/GGP1/kure/pb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const onePUXData = `{
+  "accounts": [{
+    "attrs": {"accountName": "Gopher", "email": "gopher@golang.org"},
+    "vaults": [{
+      "attrs": {"uuid": "abc", "name": "Personal", "type": "P"},
+      "items": [
+        {
+          "uuid": "1", "createdAt": 1704067200, "updatedAt": 1704067200, "state": "active", "categoryUuid": "001",
+          "details": {
+            "loginFields": [
+              {"value": "gopher", "name": "email", "fieldType": "E", "designation": "username"},
+              {"value": "github123", "name": "password", "fieldType": "P", "designation": "password"},
+              {"value": "✓", "name": "remember", "fieldType": "C"}
+            ],
+            "notesPlain": "Notes",
+            "sections": [{
+              "title": "Security",
+              "fields": [
+                {"title": "one-time password", "id": "TOTP_1", "value": {"totp": "otpauth://totp/GitHub?secret=JBSWY3DPEHPK3PXP&digits=8"}},
+                {"title": "Recovery code", "id": "code", "value": {"concealed": "1234"}},
+                {"title": "codes.txt", "id": "file", "value": {"file": {"fileName": "codes.txt", "documentId": "doc1", "decryptedSize": 4}}}
+              ]
+            }],
+            "passwordHistory": [{"value": "older123", "time": 1600000000}, {"value": "old123", "time": 1700000000}]
+          },
+          "overview": {
+            "title": "GitHub", "url": "https://github.com",
+            "urls": [{"label": "", "url": "https://github.com"}, {"label": "", "url": "https://gist.github.com"}],
+            "tags": ["dev", "work"]
+          }
+        },
+        {
+          "uuid": "2", "state": "active", "categoryUuid": "002",
+          "details": {
+            "sections": [{
+              "title": "",
+              "fields": [
+                {"title": "cardholder name", "id": "cardholder", "value": {"string": "Gopher"}},
+                {"title": "type", "id": "type", "value": {"creditCardType": "visa"}},
+                {"title": "number", "id": "ccnum", "value": {"creditCardNumber": "4111111111111111"}},
+                {"title": "verification number", "id": "cvv", "value": {"concealed": "123"}},
+                {"title": "expiry date", "id": "expiry", "value": {"monthYear": 203005}}
+              ]
+            }]
+          },
+          "overview": {"title": "Visa"}
+        },
+        {
+          "uuid": "3", "createdAt": 1704067200, "updatedAt": 1704067200, "state": "active", "categoryUuid": "006",
+          "details": {"documentAttributes": {"fileName": "passport.pdf", "documentId": "doc2", "decryptedSize": 3}},
+          "overview": {"title": "Passport scan"}
+        },
+        {
+          "uuid": "4", "state": "active", "categoryUuid": "110",
+          "details": {
+            "sections": [{
+              "title": "",
+              "fields": [
+                {"title": "URL", "id": "url", "value": {"string": "ssh.example.com"}},
+                {"title": "username", "id": "username", "value": {"string": "root"}},
+                {"title": "password", "id": "password", "value": {"concealed": "ssh123"}},
+                {"title": "expires", "id": "expires", "value": {"date": 1893456000}}
+              ]
+            }]
+          },
+          "overview": {"title": "Server"}
+        },
+        {
+          "uuid": "5", "state": "archived", "categoryUuid": "001",
+          "details": {"loginFields": [{"value": "old", "designation": "password"}]},
+          "overview": {"title": "Old"}
+        }
+      ]
+    }]
+  }]
+}`
+
+func TestImport1PUX(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+	path := filepath.Join(t.TempDir(), "test.1pux")
+	write1PUX(t, path, map[string]string{
+		"export.attributes":        `{"version": 3}`,
+		"export.data":              onePUXData,
+		"files/doc1__codes.txt":    "1234",
+		"files/doc2__passport.pdf": "pdf",
+	})
+
+	summary, err := import1PUX(db, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedSummary := "Imported 2 entries, 1 TOTPs, 1 cards and 2 files (1 archived items skipped)"
+	if summary != expectedSummary {
+		t.Errorf("Expected %q, got %q", expectedSummary, summary)
+	}
+
+	expectedEntries := []*pb.Entry{
+		{
+			Name:             "personal/login/github",
+			Username:         "gopher",
+			Password:         "github123",
+			URL:              "https://github.com",
+			Notes:            "Notes\nURL: https://gist.github.com\nRecovery code: 1234\nTags: dev, work",
+			Expires:          "Never",
+			PreviousPassword: "old123",
+		},
+		{
+			Name:     "personal/server/server",
+			Username: "root",
+			Password: "ssh123",
+			Notes:    "URL: ssh.example.com\nexpires: 2030-01-01",
+			Expires:  "Never",
+		},
+	}
+	for _, e := range expectedEntries {
+		got, err := entry.Get(db, e.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(e, got) {
+			t.Errorf("Expected %v, got %v", e, got)
+		}
+	}
+
+	gotTOTP, err := totp.Get(db, "personal/login/github")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedTOTP := &pb.TOTP{Name: "personal/login/github", Raw: "JBSWY3DPEHPK3PXP", Digits: 8}
+	if !proto.Equal(expectedTOTP, gotTOTP) {
+		t.Errorf("Expected %v, got %v", expectedTOTP, gotTOTP)
+	}
+
+	expectedCard := &pb.Card{
+		Name:         "personal/credit card/visa",
+		Type:         "visa",
+		Number:       "4111111111111111",
+		SecurityCode: "123",
+		ExpireDate:   "05/2030",
+		Notes:        "cardholder name: Gopher",
+	}
+	cmdutil.SetCardExpires(expectedCard)
+	gotCard, err := card.Get(db, expectedCard.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(expectedCard, gotCard) {
+		t.Errorf("Expected %v, got %v", expectedCard, gotCard)
+	}
+
+	expectedFiles := map[string]string{
+		"personal/login/github/codes.txt": "1234",
+		"personal/document/passport.pdf":  "pdf",
+	}
+	for name, content := range expectedFiles {
+		got, err := file.Get(db, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got.Content) != content {
+			t.Errorf("Expected %q, got %q", content, got.Content)
+		}
+	}
+}
+
+func TestImport1PUXErrors(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+	cases := []struct {
+		desc  string
+		files map[string]string
+	}{
+		{desc: "Missing export data", files: map[string]string{"export.attributes": "{}"}},
+		{desc: "Invalid export data", files: map[string]string{"export.data": "{"}},
+		{desc: "Missing document", files: map[string]string{"export.data": onePUXData}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "test.1pux")
+			write1PUX(t, path, tc.files)
+
+			if _, err := import1PUX(db, path); err == nil {
+				t.Error("Expected an error and got nil")
+			}
+		})
+	}
+
+	if _, err := import1PUX(db, "testdata/test_1password.csv"); err == nil {
+		t.Error("Expected an error and got nil")
+	}
+}
+
+// write1PUX creates a 1PUX file in path containing the files passed.
+func write1PUX(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,6 +2,7 @@ package it
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/GGP1/kure/db/file"
 
@@ -9,13 +10,13 @@ import (
 	bolt "go.etcd.io/bbolt"
 )
 
-func fileMultiselect(db *bolt.DB) ([]string, error) {
+func fileMultiselect(db *bolt.DB, w io.Writer) ([]string, error) {
 	files, err := file.ListNames(db)
 	if err != nil {
 		return nil, err
 	}
 	if len(files) == 0 {
-		fmt.Println("\nNo files to select")
+		fmt.Fprintln(w, "\nNo files to select")
 		return nil, nil
 	}
 
@@ -38,13 +39,13 @@ func fileMultiselect(db *bolt.DB) ([]string, error) {
 	return names, nil
 }
 
-func fileMvNames(db *bolt.DB) ([]string, error) {
+func fileMvNames(db *bolt.DB, w io.Writer) ([]string, error) {
 	files, err := file.ListNames(db)
 	if err != nil {
 		return nil, err
 	}
 	if len(files) == 0 {
-		fmt.Println("\nNo files to select")
+		fmt.Fprintln(w, "\nNo files to select")
 		return nil, nil
 	}
 
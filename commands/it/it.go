@@ -1,6 +1,7 @@
 package it
 
 import (
+	"io"
 	"strings"
 
 	"github.com/GGP1/kure/auth"
@@ -24,7 +25,7 @@ kure it ls -s -q
 kure sample`
 
 // NewCmd returns a new command.
-func NewCmd(db *bolt.DB) *cobra.Command {
+func NewCmd(db *bolt.DB, newRoot cmdutil.NewRootFunc) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "it <command|flags|name>",
 		Short: "Execute commands through an interactive prompt",
@@ -38,38 +39,38 @@ name 				command and flags`,
 		Example:            example,
 		DisableFlagParsing: true,
 		PreRunE:            auth.Login(db),
-		RunE:               runIt(db),
+		RunE:               runIt(db, newRoot),
 	}
 
 	return cmd
 }
 
-func runIt(db *bolt.DB) cmdutil.RunEFunc {
+func runIt(db *bolt.DB, newRoot cmdutil.NewRootFunc) cmdutil.RunEFunc {
 	return func(cmd *cobra.Command, args []string) error {
-		root := cmd.Root()
-		// Get rid of unnecessary information and reset in case we are inside a session
-		defer root.SetUsageTemplate(root.UsageTemplate())
+		executor := cmdutil.NewExecutor(newRoot, cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr())
+		// The root is used only to inspect the commands, get rid of unnecessary information
+		root := executor.Root()
 		root.SetUsageTemplate(template)
 
 		// We received nothing, request all
 		if len(args) == 0 {
-			arguments, err := requestCommands(db, root, nil)
+			arguments, err := requestCommands(db, executor.Out(), root, nil)
 			if err != nil {
 				return err
 			}
 
-			return execute(root, arguments)
+			return execute(executor, arguments)
 		}
 
 		command, _, err := root.Find(args)
 		if err != nil || command == root {
 			// If the command does not exist or is the root, assume the user passed a name
-			arguments, err := gotName(db, root, args)
+			arguments, err := gotName(db, executor.Out(), root, args)
 			if err != nil {
 				return err
 			}
 
-			return execute(root, arguments)
+			return execute(executor, arguments)
 		}
 
 		foundFlags := false
@@ -94,29 +95,29 @@ func runIt(db *bolt.DB) cmdutil.RunEFunc {
 			err := command.ValidateArgs([]string{name})
 			if err != nil || strings.Contains(command.Name(), "ls") {
 				// Received commands+flags, request name
-				arguments, err := requestName(db, args)
+				arguments, err := requestName(db, executor.Out(), args)
 				if err != nil {
 					return err
 				}
 
-				return execute(root, arguments)
+				return execute(executor, arguments)
 			}
 
 			// Received command+flags+name, nothing to request
-			return execute(root, args)
+			return execute(executor, args)
 		}
 
 		// Pass on received command(s) and look for subcommands
-		arguments, err := requestCommands(db, command, args)
+		arguments, err := requestCommands(db, executor.Out(), command, args)
 		if err != nil {
 			return err
 		}
 
-		return execute(root, arguments)
+		return execute(executor, arguments)
 	}
 }
 
-func execute(root *cobra.Command, args []string) error {
+func execute(executor *cmdutil.Executor, args []string) error {
 	// Discard empty arguments as some commands will fail if we don't
 	// eg. file cat
 	filteredArgs := make([]string, 0, len(args))
@@ -126,11 +127,10 @@ func execute(root *cobra.Command, args []string) error {
 		}
 	}
 
-	root.SetArgs(filteredArgs)
-	return root.Execute()
+	return executor.Execute(filteredArgs)
 }
 
-func requestCommands(db *bolt.DB, root *cobra.Command, receivedCmds []string) ([]string, error) {
+func requestCommands(db *bolt.DB, w io.Writer, root *cobra.Command, receivedCmds []string) ([]string, error) {
 	commands, err := selectCommands(root)
 	if err != nil {
 		return nil, err
@@ -147,11 +147,11 @@ func requestCommands(db *bolt.DB, root *cobra.Command, receivedCmds []string) ([
 	if len(receivedCmds) > 0 {
 		args = append(receivedCmds, args...)
 	}
-	return requestName(db, args)
+	return requestName(db, w, args)
 }
 
 // args contains commands and flags.
-func requestName(db *bolt.DB, args []string) ([]string, error) {
+func requestName(db *bolt.DB, w io.Writer, args []string) ([]string, error) {
 	var (
 		name string
 		err  error
@@ -174,14 +174,14 @@ func requestName(db *bolt.DB, args []string) ([]string, error) {
 		name, err = selectManager(db)
 
 	case contains("file cat"), contains("file touch"):
-		names, err := fileMultiselect(db)
+		names, err := fileMultiselect(db, w)
 		if err != nil {
 			return nil, err
 		}
 		return append(args, names...), nil
 
 	case contains("file mv"):
-		names, err := fileMvNames(db)
+		names, err := fileMvNames(db, w)
 		if err != nil {
 			return nil, err
 		}
@@ -210,7 +210,7 @@ func requestName(db *bolt.DB, args []string) ([]string, error) {
 }
 
 // gotName is executed when the user already provided the name, commands and flags are requested only.
-func gotName(db *bolt.DB, root *cobra.Command, args []string) ([]string, error) {
+func gotName(db *bolt.DB, w io.Writer, root *cobra.Command, args []string) ([]string, error) {
 	var (
 		name  []string
 		flags []string
@@ -237,7 +237,7 @@ func gotName(db *bolt.DB, root *cobra.Command, args []string) ([]string, error)
 	}
 
 	if len(name) == 0 {
-		name, err = requestName(db, commands)
+		name, err = requestName(db, w, commands)
 		if err != nil {
 			return nil, err
 		}
@@ -8,6 +8,7 @@ import (
 	"github.com/GGP1/kure/db/entry"
 	"github.com/GGP1/kure/db/file"
 	"github.com/GGP1/kure/db/totp"
+	"github.com/GGP1/kure/db/wifi"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/pkg/errors"
@@ -100,6 +101,10 @@ func selectName(db *bolt.DB, commands []string) (string, error) {
 	case "file":
 		list, err = file.ListNames(db)
 
+	case "wifi":
+		list, err = wifi.ListNames(db)
+		message = "Choose a Wi-Fi network:"
+
 	case "ls", "copy", "edit", "rm":
 		list, err = entry.ListNames(db)
 		message = "Choose an entry:"
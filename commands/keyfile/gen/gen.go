@@ -0,0 +1,52 @@
+package gen
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/GGP1/kure/auth"
+	cmdutil "github.com/GGP1/kure/commands"
+
+	"github.com/spf13/cobra"
+)
+
+const example = `
+kure keyfile gen path/to/file.key`
+
+// NewCmd returns a new command.
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gen <path>",
+		Short: "Generate a key file",
+		Long: `Generate a key file.
+
+The file contains a 256-bit random key preceded by a checksum header, which lets Kure detect if it was modified. It's created with read-only permissions for the owner and it will never overwrite an existing file.
+
+Using files that change over time (photos, documents) as key files is discouraged as any modification makes the database inaccessible.`,
+		Example: example,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if strings.Join(args, "") == "" {
+				return cmdutil.ErrInvalidPath
+			}
+			return nil
+		},
+		RunE: runGen(),
+	}
+
+	return cmd
+}
+
+func runGen() cmdutil.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		path := strings.Join(args, " ")
+
+		if err := auth.GenerateKeyfile(path); err != nil {
+			return err
+		}
+
+		abs, _ := filepath.Abs(path)
+		fmt.Fprintln(cmd.OutOrStdout(), "Key file created at", abs)
+		return nil
+	}
+}
@@ -0,0 +1,54 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.key")
+
+	cmd := NewCmd()
+	cmd.SetArgs([]string{path})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Failed generating key file: %v", err)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Key file wasn't created: %v", err)
+	}
+}
+
+func TestGenErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.key")
+	if err := os.WriteFile(path, []byte("test"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		desc string
+		path string
+	}{
+		{
+			desc: "Invalid path",
+			path: "",
+		},
+		{
+			desc: "File already exists",
+			path: path,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cmd := NewCmd()
+			cmd.SetArgs([]string{tc.path})
+
+			if err := cmd.Execute(); err == nil {
+				t.Error("Expected an error and got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package keyfile
+
+import (
+	"github.com/GGP1/kure/commands/keyfile/gen"
+	"github.com/GGP1/kure/commands/keyfile/verify"
+
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+)
+
+const example = `
+kure keyfile (gen|verify)`
+
+// NewCmd returns a new command.
+func NewCmd(db *bolt.DB) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "keyfile",
+		Short:   "Key file operations",
+		Example: example,
+	}
+
+	cmd.AddCommand(gen.NewCmd(), verify.NewCmd(db))
+
+	return cmd
+}
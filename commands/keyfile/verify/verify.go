@@ -0,0 +1,54 @@
+package verify
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GGP1/kure/auth"
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/config"
+
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+)
+
+const example = `
+* Verify the key file specified in the configuration
+kure keyfile verify
+
+* Verify a key file
+kure keyfile verify path/to/file.key`
+
+// NewCmd returns a new command.
+func NewCmd(db *bolt.DB) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify [path]",
+		Short: "Verify a key file",
+		Long: `Verify that a key file still matches the one registered in the database.
+
+The master password is not required. If no path is passed, the one in the configuration file is used.`,
+		Example: example,
+		RunE:    runVerify(db),
+	}
+
+	return cmd
+}
+
+func runVerify(db *bolt.DB) cmdutil.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		path := strings.Join(args, " ")
+		if path == "" {
+			path = config.GetString("keyfile.path")
+		}
+		if path == "" {
+			return cmdutil.ErrInvalidPath
+		}
+
+		if err := auth.VerifyKeyfile(db, path); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(cmd.OutOrStdout(), "The key file matches the one registered")
+		return nil
+	}
+}
@@ -0,0 +1,56 @@
+package verify
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/GGP1/kure/auth"
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/config"
+	authDB "github.com/GGP1/kure/db/auth"
+)
+
+func TestVerifyErrors(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../../db/testdata/database")
+
+	path := filepath.Join(t.TempDir(), "test.key")
+	if err := auth.GenerateKeyfile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	params := authDB.Parameters{Iterations: 1, Memory: 1, Threads: 1}
+	if err := authDB.Register(db, params); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		desc       string
+		path       string
+		configPath string
+	}{
+		{
+			desc: "Invalid path",
+			path: "",
+		},
+		{
+			desc:       "Database without key file",
+			configPath: path,
+		},
+		{
+			desc: "Non-existent file",
+			path: "non-existent.key",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			config.Set("keyfile.path", tc.configPath)
+			cmd := NewCmd(db)
+			cmd.SetArgs([]string{tc.path})
+
+			if err := cmd.Execute(); err == nil {
+				t.Error("Expected an error and got nil")
+			}
+		})
+	}
+}
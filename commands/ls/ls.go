@@ -2,6 +2,7 @@ package ls
 
 import (
 	"fmt"
+	"io"
 	"path/filepath"
 	"strings"
 	"time"
@@ -11,7 +12,6 @@ import (
 	"github.com/GGP1/kure/db/entry"
 	"github.com/GGP1/kure/orderedmap"
 	"github.com/GGP1/kure/pb"
-	"github.com/GGP1/kure/tree"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -28,6 +28,9 @@ kure ls Sample -q
 * Filter by name
 kure ls Sample -f 
 
+* List the entries inside a folder
+kure ls work
+
 * List all
 kure ls`
 
@@ -44,16 +47,14 @@ func NewCmd(db *bolt.DB) *cobra.Command {
 		Short: "List entries",
 		Long: `List entries.
 
-Listing all the entries does not check for expired entries, this decision was taken to prevent high loads when the number of entries is elevated. Listing a single entry does notifies if it is expired.`,
+If the name is a folder, the entries inside it are listed.
+
+Listing all the entries does not check for expired entries, this decision was taken to prevent high loads when the number of entries is elevated. Listing a single entry does notifies if it is expired, use "kure audit" to find all the expired entries.`,
 		Aliases: []string{"entries", "list"},
 		Example: example,
 		Args:    cmdutil.MustExistLs(db, cmdutil.Entry),
 		PreRunE: auth.Login(db),
 		RunE:    runLs(db, &opts),
-		PostRun: func(cmd *cobra.Command, args []string) {
-			// Reset variables (session)
-			opts = lsOptions{}
-		},
 	}
 
 	f := cmd.Flags()
@@ -76,8 +77,7 @@ func runLs(db *bolt.DB, opts *lsOptions) cmdutil.RunEFunc {
 				return err
 			}
 
-			tree.Print(entries)
-			return nil
+			return cmdutil.PrintNames(cmd, entries)
 		}
 
 		// Filter by name
@@ -103,61 +103,103 @@ func runLs(db *bolt.DB, opts *lsOptions) cmdutil.RunEFunc {
 				return errors.New("no entries were found")
 			}
 
-			tree.Print(matches)
-			return nil
+			return cmdutil.PrintNames(cmd, matches)
+		}
+
+		// List a folder
+		entries, err := entry.ListNames(db)
+		if err != nil {
+			return err
+		}
+		if folder := folderEntries(entries, name); folder != nil {
+			return cmdutil.PrintNames(cmd, folder)
 		}
 
 		// List one
+		format, err := cmdutil.OutputFormat(cmd)
+		if err != nil {
+			return err
+		}
+
 		e, err := entry.Get(db, name)
 		if err != nil {
 			return err
 		}
 
+		if format != cmdutil.OutputDefault {
+			return cmdutil.WriteOutput(cmd.OutOrStdout(), format, entryOutput(name, e, opts.show))
+		}
+
 		if opts.qr {
-			if err := cmdutil.DisplayQRCode(e.Password); err != nil {
+			if err := cmdutil.DisplayQRCode(cmd.OutOrStdout(), e.Password); err != nil {
 				return err
 			}
 		}
 
-		printEntry(name, e, opts.show)
+		printEntry(cmd.OutOrStdout(), name, e, opts.show)
 		return nil
 	}
 }
 
-func printEntry(name string, e *pb.Entry, show bool) {
+// folderEntries returns the entries inside the folder passed, nil if name is an entry or
+// the folder is empty.
+func folderEntries(entries []string, name string) []string {
+	prefix := name + "/"
+	var folder []string
+	for _, e := range entries {
+		if e == name {
+			return nil
+		}
+		if strings.HasPrefix(e, prefix) {
+			folder = append(folder, e)
+		}
+	}
+	return folder
+}
+
+func entryOutput(name string, e *pb.Entry, show bool) *cmdutil.EntryOutput {
+	out := &cmdutil.EntryOutput{
+		Name:     name,
+		Username: e.Username,
+		URL:      e.URL,
+		Expires:  e.Expires,
+		Expired:  expired(e),
+		Notes:    e.Notes,
+	}
+	if show {
+		out.Password = &e.Password
+	}
+	return out
+}
+
+func printEntry(w io.Writer, name string, e *pb.Entry, show bool) {
 	if !show {
 		e.Password = "•••••••••••••••"
+		if e.PreviousPassword != "" {
+			e.PreviousPassword = "•••••••••••••••"
+		}
 	}
 
-	if expired(e.Expires) {
+	if expired(e) {
 		e.Expires = "EXPIRED"
 	}
 
 	mp := orderedmap.New()
 	mp.Set("Username", e.Username)
 	mp.Set("Password", e.Password)
+	if e.PreviousPassword != "" {
+		mp.Set("Previous password", e.PreviousPassword)
+	}
 	mp.Set("URL", e.URL)
 	mp.Set("Expires", e.Expires)
 	mp.Set("Notes", e.Notes)
 
 	box := cmdutil.BuildBox(name, mp)
-	fmt.Println("\n" + box)
+	fmt.Fprintln(w, "\n"+box)
 }
 
 // expired returns if the entry is expired or not.
-func expired(expires string) bool {
-	if expires == "Never" {
-		return false
-	}
-
-	// Error is always nil as "expires" field was already formatted before being saved
-	expiration, _ := time.Parse(time.RFC1123Z, expires)
-
-	// This never fails neither
-	now, _ := time.Parse(time.RFC1123Z, time.Now().Format(time.RFC1123Z))
-	if now.Sub(expiration) >= 0 {
-		return true
-	}
-
-	return false
+func expired(e *pb.Entry) bool {
+	expires, ok := cmdutil.EntryExpiration(e)
+	return ok && !expires.After(time.Now())
 }
@@ -1,7 +1,9 @@
 package ls
 
 import (
+	"bytes"
 	"testing"
+	"time"
 
 	cmdutil "github.com/GGP1/kure/commands"
 	"github.com/GGP1/kure/db/entry"
@@ -13,6 +15,7 @@ import (
 func TestLs(t *testing.T) {
 	db := cmdutil.SetContext(t, "../../db/testdata/database")
 	createEntry(t, db, "test", "testing")
+	createEntry(t, db, "folder/test", "testing")
 
 	cases := []struct {
 		desc   string
@@ -39,6 +42,10 @@ func TestLs(t *testing.T) {
 			desc: "List all",
 			name: "",
 		},
+		{
+			desc: "List folder",
+			name: "folder",
+		},
 		{
 			desc: "List one and hide",
 			name: "test",
@@ -110,6 +117,60 @@ func TestLsErrors(t *testing.T) {
 	}
 }
 
+func TestLsOutput(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+	createEntry(t, db, "test", "testing")
+
+	cases := []struct {
+		desc     string
+		args     []string
+		expected string
+	}{
+		{
+			desc:     "List one",
+			args:     []string{"test", "--output", "json"},
+			expected: "{\n  \"name\": \"test\",\n  \"username\": \"\",\n  \"url\": \"\",\n  \"expires\": \"Mon, 01 Jan 2021 15:04:05 -0700\",\n  \"expired\": true,\n  \"notes\": \"\"\n}\n",
+		},
+		{
+			desc:     "List one and show",
+			args:     []string{"test", "--output", "table", "-s"},
+			expected: "name\tusername\tpassword\turl\texpires\texpired\tnotes\ntest\t\ttesting\t\tMon, 01 Jan 2021 15:04:05 -0700\ttrue\t\n",
+		},
+		{
+			desc:     "List all",
+			args:     []string{"--output", "yaml"},
+			expected: "names:\n  - test\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			cmd := NewCmd(db)
+			cmdutil.AddOutputFlag(cmd)
+			cmd.SetOut(buf)
+			cmd.SetArgs(tc.args)
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := buf.String(); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+
+	t.Run("Invalid format", func(t *testing.T) {
+		cmd := NewCmd(db)
+		cmdutil.AddOutputFlag(cmd)
+		cmd.SetArgs([]string{"test", "--output", "xml"})
+		if err := cmd.Execute(); err == nil {
+			t.Error("Expected an error and got nil")
+		}
+	})
+}
+
 func TestQRCodeError(t *testing.T) {
 	db := cmdutil.SetContext(t, "../../db/testdata/database")
 	createEntry(t, db, "test", longSecret)
@@ -125,26 +186,35 @@ func TestQRCodeError(t *testing.T) {
 
 func TestCheckExpiration(t *testing.T) {
 	cases := []struct {
-		expiration string
-		expected   bool
+		desc     string
+		entry    *pb.Entry
+		expected bool
 	}{
 		{
-			expiration: "Mon, 02 Jan 2020 15:04:05 -0700",
-			expected:   true,
+			desc:     "Expired",
+			entry:    &pb.Entry{Expires: "Mon, 02 Jan 2020 15:04:05 -0700"},
+			expected: true,
+		},
+		{
+			desc:     "Never",
+			entry:    &pb.Entry{Expires: "Never"},
+			expected: false,
 		},
 		{
-			expiration: "Never",
-			expected:   false,
+			desc:     "Not expired",
+			entry:    &pb.Entry{Expires: "Mon, 02 Jan 2040 15:04:05 -0700"},
+			expected: false,
 		},
 		{
-			expiration: "Mon, 02 Jan 2040 15:04:05 -0700",
-			expected:   false,
+			desc:     "Timestamp",
+			entry:    &pb.Entry{Expires: "Mon, 02 Jan 2040 15:04:05 -0700", ExpiresAt: time.Now().Add(-time.Hour).Unix()},
+			expected: true,
 		},
 	}
 
 	for _, tc := range cases {
-		t.Run("Check expiration", func(t *testing.T) {
-			got := expired(tc.expiration)
+		t.Run(tc.desc, func(t *testing.T) {
+			got := expired(tc.entry)
 			if tc.expected != got {
 				t.Errorf("Expected %v, got %v", tc.expected, got)
 			}
@@ -152,10 +222,6 @@ func TestCheckExpiration(t *testing.T) {
 	}
 }
 
-func TestPostRun(t *testing.T) {
-	NewCmd(nil).PostRun(nil, nil)
-}
-
 func createEntry(t *testing.T, db *bolt.DB, name, password string) {
 	t.Helper()
 
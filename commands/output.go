@@ -0,0 +1,466 @@
+package cmdutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/GGP1/kure/tree"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats.
+const (
+	// OutputDefault prints trees and boxes meant to be read by humans.
+	OutputDefault = ""
+	// OutputJSON prints indented JSON objects.
+	OutputJSON = "json"
+	// OutputTable prints a header row followed by one row per element, values are tab-separated.
+	OutputTable = "table"
+	// OutputYAML prints YAML documents.
+	OutputYAML = "yaml"
+)
+
+// OutputFlag is the name of the flag used to select the output format.
+const OutputFlag = "output"
+
+// Output is implemented by the structures printed by the commands when an output format is selected.
+type Output interface {
+	// Header returns the name of the table columns.
+	Header() []string
+	// Rows returns the table values.
+	Rows() [][]string
+}
+
+// ListOutput contains the names of the records listed.
+type ListOutput struct {
+	Names []string `json:"names" yaml:"names"`
+}
+
+// Header implements Output.
+func (l *ListOutput) Header() []string { return []string{"name"} }
+
+// Rows implements Output.
+func (l *ListOutput) Rows() [][]string {
+	rows := make([][]string, 0, len(l.Names))
+	for _, name := range l.Names {
+		rows = append(rows, []string{name})
+	}
+	return rows
+}
+
+// EntryOutput contains an entry information, the password is nil unless it was requested.
+type EntryOutput struct {
+	Name     string  `json:"name" yaml:"name"`
+	Username string  `json:"username" yaml:"username"`
+	Password *string `json:"password,omitempty" yaml:"password,omitempty"`
+	URL      string  `json:"url" yaml:"url"`
+	Expires  string  `json:"expires" yaml:"expires"`
+	Expired  bool    `json:"expired" yaml:"expired"`
+	Notes    string  `json:"notes" yaml:"notes"`
+}
+
+// Header implements Output.
+func (e *EntryOutput) Header() []string {
+	if e.Password == nil {
+		return []string{"name", "username", "url", "expires", "expired", "notes"}
+	}
+	return []string{"name", "username", "password", "url", "expires", "expired", "notes"}
+}
+
+// Rows implements Output.
+func (e *EntryOutput) Rows() [][]string {
+	expired := strconv.FormatBool(e.Expired)
+	if e.Password == nil {
+		return [][]string{{e.Name, e.Username, e.URL, e.Expires, expired, e.Notes}}
+	}
+	return [][]string{{e.Name, e.Username, *e.Password, e.URL, e.Expires, expired, e.Notes}}
+}
+
+// BreachOutput contains the entries whose passwords were found in a breached passwords dump.
+type BreachOutput struct {
+	Checked  int           `json:"checked" yaml:"checked"`
+	Breached []BreachMatch `json:"breached" yaml:"breached"`
+}
+
+// BreachMatch is an entry whose password was found in a dump.
+type BreachMatch struct {
+	Name string `json:"name" yaml:"name"`
+	// Count is the number of times the password appears in the dump
+	Count int `json:"count" yaml:"count"`
+}
+
+// Header implements Output.
+func (b *BreachOutput) Header() []string { return []string{"name", "count"} }
+
+// Rows implements Output.
+func (b *BreachOutput) Rows() [][]string {
+	rows := make([][]string, 0, len(b.Breached))
+	for _, m := range b.Breached {
+		rows = append(rows, []string{m.Name, strconv.Itoa(m.Count)})
+	}
+	return rows
+}
+
+// CardOutput contains a card information, the number and security code are nil unless they were requested.
+type CardOutput struct {
+	Name         string  `json:"name" yaml:"name"`
+	Type         string  `json:"type" yaml:"type"`
+	Number       *string `json:"number,omitempty" yaml:"number,omitempty"`
+	SecurityCode *string `json:"security_code,omitempty" yaml:"security_code,omitempty"`
+	ExpireDate   string  `json:"expire_date" yaml:"expire_date"`
+	Notes        string  `json:"notes" yaml:"notes"`
+}
+
+// Header implements Output.
+func (c *CardOutput) Header() []string {
+	if c.Number == nil {
+		return []string{"name", "type", "expire_date", "notes"}
+	}
+	return []string{"name", "type", "number", "security_code", "expire_date", "notes"}
+}
+
+// Rows implements Output.
+func (c *CardOutput) Rows() [][]string {
+	if c.Number == nil {
+		return [][]string{{c.Name, c.Type, c.ExpireDate, c.Notes}}
+	}
+	return [][]string{{c.Name, c.Type, *c.Number, *c.SecurityCode, c.ExpireDate, c.Notes}}
+}
+
+// WifiOutput contains a Wi-Fi network information, the password is nil unless it was requested.
+type WifiOutput struct {
+	Name     string  `json:"name" yaml:"name"`
+	SSID     string  `json:"ssid" yaml:"ssid"`
+	Security string  `json:"security" yaml:"security"`
+	Password *string `json:"password,omitempty" yaml:"password,omitempty"`
+	Hidden   bool    `json:"hidden" yaml:"hidden"`
+	Notes    string  `json:"notes" yaml:"notes"`
+}
+
+// Header implements Output.
+func (w *WifiOutput) Header() []string {
+	if w.Password == nil {
+		return []string{"name", "ssid", "security", "hidden", "notes"}
+	}
+	return []string{"name", "ssid", "security", "password", "hidden", "notes"}
+}
+
+// Rows implements Output.
+func (w *WifiOutput) Rows() [][]string {
+	hidden := strconv.FormatBool(w.Hidden)
+	if w.Password == nil {
+		return [][]string{{w.Name, w.SSID, w.Security, hidden, w.Notes}}
+	}
+	return [][]string{{w.Name, w.SSID, w.Security, *w.Password, hidden, w.Notes}}
+}
+
+// FileOutput contains a file information, the content is never included.
+type FileOutput struct {
+	Name string `json:"name" yaml:"name"`
+	// Size in bytes
+	Size int64 `json:"size" yaml:"size"`
+	// CreatedAt and UpdatedAt are formatted using RFC 3339, UpdatedAt is empty if the file was never updated
+	CreatedAt string `json:"created_at" yaml:"created_at"`
+	UpdatedAt string `json:"updated_at" yaml:"updated_at"`
+}
+
+// Header implements Output.
+func (f *FileOutput) Header() []string {
+	return []string{"name", "size", "created_at", "updated_at"}
+}
+
+// Rows implements Output.
+func (f *FileOutput) Rows() [][]string {
+	return [][]string{{f.Name, strconv.FormatInt(f.Size, 10), f.CreatedAt, f.UpdatedAt}}
+}
+
+// TOTPOutput contains a two-factor authentication code.
+type TOTPOutput struct {
+	Name string `json:"name" yaml:"name"`
+	Code string `json:"code" yaml:"code"`
+}
+
+// Header implements Output.
+func (t *TOTPOutput) Header() []string { return []string{"name", "code"} }
+
+// Rows implements Output.
+func (t *TOTPOutput) Rows() [][]string { return [][]string{{t.Name, t.Code}} }
+
+// KeyInfoOutput contains a two-factor authentication setup key information.
+type KeyInfoOutput struct {
+	Name   string `json:"name" yaml:"name"`
+	URL    string `json:"url" yaml:"url"`
+	Key    string `json:"key" yaml:"key"`
+	Digits int32  `json:"digits" yaml:"digits"`
+}
+
+// Header implements Output.
+func (k *KeyInfoOutput) Header() []string { return []string{"name", "url", "key", "digits"} }
+
+// Rows implements Output.
+func (k *KeyInfoOutput) Rows() [][]string {
+	return [][]string{{k.Name, k.URL, k.Key, strconv.Itoa(int(k.Digits))}}
+}
+
+// SearchOutput contains the fields that matched a search query.
+type SearchOutput struct {
+	Matches []SearchMatch `json:"matches" yaml:"matches"`
+}
+
+// SearchMatch is a line of a record field that matched the query.
+type SearchMatch struct {
+	Type  string `json:"type" yaml:"type"`
+	Name  string `json:"name" yaml:"name"`
+	Field string `json:"field" yaml:"field"`
+	// Line number inside the field value, starting from 1
+	Line  int    `json:"line" yaml:"line"`
+	Value string `json:"value" yaml:"value"`
+	// Ranges contains the start and end byte offsets of the matches inside the value
+	Ranges [][]int `json:"ranges" yaml:"ranges,flow"`
+}
+
+// Header implements Output.
+func (s *SearchOutput) Header() []string {
+	return []string{"type", "name", "field", "line", "value"}
+}
+
+// Rows implements Output.
+func (s *SearchOutput) Rows() [][]string {
+	rows := make([][]string, 0, len(s.Matches))
+	for _, m := range s.Matches {
+		rows = append(rows, []string{m.Type, m.Name, m.Field, strconv.Itoa(m.Line), m.Value})
+	}
+	return rows
+}
+
+// AuditOutput contains the issues found in the entries passwords.
+type AuditOutput struct {
+	// Score is the percentage of entries without issues
+	Score      int           `json:"score" yaml:"score"`
+	Total      int           `json:"total" yaml:"total"`
+	Healthy    int           `json:"healthy" yaml:"healthy"`
+	UnknownAge int           `json:"unknown_age" yaml:"unknown_age"`
+	Weak       []AuditWeak   `json:"weak" yaml:"weak"`
+	Reused     [][]string    `json:"reused" yaml:"reused"`
+	Old        []AuditOld    `json:"old" yaml:"old"`
+	Expired    []AuditExpiry `json:"expired" yaml:"expired"`
+	Expiring   []AuditExpiry `json:"expiring" yaml:"expiring"`
+}
+
+// AuditWeak is an entry with a weak password.
+type AuditWeak struct {
+	Name     string   `json:"name" yaml:"name"`
+	Score    int      `json:"score" yaml:"score"`
+	Strength string   `json:"strength" yaml:"strength"`
+	Patterns []string `json:"patterns" yaml:"patterns"`
+}
+
+// AuditOld is an entry whose password wasn't changed in a long time.
+type AuditOld struct {
+	Name    string `json:"name" yaml:"name"`
+	AgeDays int    `json:"age_days" yaml:"age_days"`
+}
+
+// AuditExpiry is an entry that expired or is about to, the date is formatted using RFC 3339.
+type AuditExpiry struct {
+	Name    string `json:"name" yaml:"name"`
+	Expires string `json:"expires" yaml:"expires"`
+}
+
+// Header implements Output.
+func (a *AuditOutput) Header() []string { return []string{"name", "issue", "detail"} }
+
+// Rows implements Output, there is one row per issue found.
+func (a *AuditOutput) Rows() [][]string {
+	var rows [][]string
+	for _, w := range a.Weak {
+		rows = append(rows, []string{w.Name, "weak", w.Strength})
+	}
+	for _, names := range a.Reused {
+		for _, name := range names {
+			rows = append(rows, []string{name, "reused", strings.Join(names, ",")})
+		}
+	}
+	for _, o := range a.Old {
+		rows = append(rows, []string{o.Name, "old", strconv.Itoa(o.AgeDays)})
+	}
+	for _, e := range a.Expired {
+		rows = append(rows, []string{e.Name, "expired", e.Expires})
+	}
+	for _, e := range a.Expiring {
+		rows = append(rows, []string{e.Name, "expiring", e.Expires})
+	}
+	return rows
+}
+
+// ExpiringOutput contains the records that expired or are about to.
+type ExpiringOutput struct {
+	Records []ExpiringRecord `json:"records" yaml:"records"`
+}
+
+// ExpiringRecord is an entry or a card expiration, the date is formatted using RFC 3339.
+type ExpiringRecord struct {
+	Type    string `json:"type" yaml:"type"`
+	Name    string `json:"name" yaml:"name"`
+	Expires string `json:"expires" yaml:"expires"`
+	Expired bool   `json:"expired" yaml:"expired"`
+}
+
+// Header implements Output.
+func (e *ExpiringOutput) Header() []string { return []string{"type", "name", "expires", "expired"} }
+
+// Rows implements Output.
+func (e *ExpiringOutput) Rows() [][]string {
+	rows := make([][]string, 0, len(e.Records))
+	for _, r := range e.Records {
+		rows = append(rows, []string{r.Type, r.Name, r.Expires, strconv.FormatBool(r.Expired)})
+	}
+	return rows
+}
+
+// StatsOutput contains the number of records stored.
+type StatsOutput struct {
+	Cards   int `json:"cards" yaml:"cards"`
+	Entries int `json:"entries" yaml:"entries"`
+	Files   int `json:"files" yaml:"files"`
+	TOTPs   int `json:"totps" yaml:"totps"`
+	Wifis   int `json:"wifis" yaml:"wifis"`
+	Total   int `json:"total" yaml:"total"`
+}
+
+// Header implements Output.
+func (s *StatsOutput) Header() []string {
+	return []string{"cards", "entries", "files", "totps", "wifis", "total"}
+}
+
+// Rows implements Output.
+func (s *StatsOutput) Rows() [][]string {
+	return [][]string{{
+		strconv.Itoa(s.Cards), strconv.Itoa(s.Entries), strconv.Itoa(s.Files),
+		strconv.Itoa(s.TOTPs), strconv.Itoa(s.Wifis), strconv.Itoa(s.Total),
+	}}
+}
+
+// ConfigOutput contains the configuration file location and content.
+type ConfigOutput struct {
+	Path    string `json:"path" yaml:"path"`
+	Content string `json:"content" yaml:"content"`
+}
+
+// Header implements Output.
+func (c *ConfigOutput) Header() []string { return []string{"path", "content"} }
+
+// Rows implements Output.
+func (c *ConfigOutput) Rows() [][]string { return [][]string{{c.Path, c.Content}} }
+
+// ErrorOutput contains an error message.
+type ErrorOutput struct {
+	Error string `json:"error" yaml:"error"`
+}
+
+// Header implements Output.
+func (e *ErrorOutput) Header() []string { return []string{"error"} }
+
+// Rows implements Output.
+func (e *ErrorOutput) Rows() [][]string { return [][]string{{e.Error}} }
+
+// AddOutputFlag adds the flag used to select the output format to cmd and its subcommands.
+func AddOutputFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().String(OutputFlag, OutputDefault, "output format (json|yaml|table)")
+}
+
+// OutputFormat returns the output format selected, commands without the output flag use the default one.
+func OutputFormat(cmd *cobra.Command) (string, error) {
+	f := cmd.Flag(OutputFlag)
+	if f == nil {
+		return OutputDefault, nil
+	}
+
+	switch format := strings.ToLower(f.Value.String()); format {
+	case OutputDefault, OutputJSON, OutputTable, OutputYAML:
+		return format, nil
+	default:
+		return "", errors.Errorf("invalid output format %q, use json, yaml or table", f.Value.String())
+	}
+}
+
+// PrintNames prints the record names as a tree or in the output format selected.
+func PrintNames(cmd *cobra.Command, names []string) error {
+	format, err := OutputFormat(cmd)
+	if err != nil {
+		return err
+	}
+
+	if format == OutputDefault {
+		tree.Print(cmd.OutOrStdout(), names)
+		return nil
+	}
+
+	if names == nil {
+		names = []string{}
+	}
+	return WriteOutput(cmd.OutOrStdout(), format, &ListOutput{Names: names})
+}
+
+// PrintError prints the error in the output format selected, using plain text by default.
+func PrintError(cmd *cobra.Command, w io.Writer, err error) {
+	// Invalid formats fall back to plain text
+	format, _ := OutputFormat(cmd)
+	if format == OutputDefault {
+		fmt.Fprintln(w, "error:", err)
+		return
+	}
+
+	if wErr := WriteOutput(w, format, &ErrorOutput{Error: err.Error()}); wErr != nil {
+		fmt.Fprintln(w, "error:", err)
+	}
+}
+
+// WriteOutput writes v to w encoded in the format passed.
+func WriteOutput(w io.Writer, format string, v Output) error {
+	switch format {
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return errors.Wrap(enc.Encode(v), "encoding json")
+
+	case OutputYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return errors.Wrap(err, "encoding yaml")
+		}
+		return enc.Close()
+
+	case OutputTable:
+		var sb strings.Builder
+		writeRow(&sb, v.Header())
+		for _, row := range v.Rows() {
+			writeRow(&sb, row)
+		}
+		_, err := io.WriteString(w, sb.String())
+		return err
+
+	default:
+		return errors.Errorf("invalid output format %q, use json, yaml or table", format)
+	}
+}
+
+// tableReplacer escapes the characters that would break the table rows.
+var tableReplacer = strings.NewReplacer("\\", `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+func writeRow(sb *strings.Builder, values []string) {
+	for i, v := range values {
+		if i > 0 {
+			sb.WriteByte('\t')
+		}
+		sb.WriteString(tableReplacer.Replace(v))
+	}
+	sb.WriteByte('\n')
+}
@@ -0,0 +1,137 @@
+package cmdutil
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestWriteOutput(t *testing.T) {
+	password := "pass\tword"
+	entry := &EntryOutput{
+		Name:     "test",
+		Username: "user",
+		Password: &password,
+		Expires:  "Never",
+		Notes:    "first\nsecond",
+	}
+
+	cases := []struct {
+		desc     string
+		format   string
+		v        Output
+		expected string
+	}{
+		{
+			desc:     "JSON list",
+			format:   OutputJSON,
+			v:        &ListOutput{Names: []string{"a", "b"}},
+			expected: "{\n  \"names\": [\n    \"a\",\n    \"b\"\n  ]\n}\n",
+		},
+		{
+			desc:     "YAML list",
+			format:   OutputYAML,
+			v:        &ListOutput{Names: []string{"a", "b"}},
+			expected: "names:\n  - a\n  - b\n",
+		},
+		{
+			desc:     "Table list",
+			format:   OutputTable,
+			v:        &ListOutput{Names: []string{"a", "b"}},
+			expected: "name\na\nb\n",
+		},
+		{
+			desc:     "JSON hidden secret",
+			format:   OutputJSON,
+			v:        &EntryOutput{Name: "test", Expires: "Never"},
+			expected: "{\n  \"name\": \"test\",\n  \"username\": \"\",\n  \"url\": \"\",\n  \"expires\": \"Never\",\n  \"expired\": false,\n  \"notes\": \"\"\n}\n",
+		},
+		{
+			desc:     "Table escaping",
+			format:   OutputTable,
+			v:        entry,
+			expected: "name\tusername\tpassword\turl\texpires\texpired\tnotes\ntest\tuser\tpass\\tword\t\tNever\tfalse\tfirst\\nsecond\n",
+		},
+		{
+			desc:     "Table hidden secret",
+			format:   OutputTable,
+			v:        &CardOutput{Name: "card", Type: "debit"},
+			expected: "name\ttype\texpire_date\tnotes\ncard\tdebit\t\t\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := WriteOutput(buf, tc.format, tc.v); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := buf.String(); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+
+	t.Run("Invalid format", func(t *testing.T) {
+		if err := WriteOutput(new(bytes.Buffer), "xml", &ListOutput{}); err == nil {
+			t.Error("Expected an error and got nil")
+		}
+	})
+}
+
+func TestOutputFormat(t *testing.T) {
+	cmd := &cobra.Command{}
+	if format, err := OutputFormat(cmd); err != nil || format != OutputDefault {
+		t.Errorf("Expected the default format without the flag, got %q (%v)", format, err)
+	}
+
+	AddOutputFlag(cmd)
+	cmd.PersistentFlags().Set(OutputFlag, "JSON")
+	if format, err := OutputFormat(cmd); err != nil || format != OutputJSON {
+		t.Errorf("Expected %q, got %q (%v)", OutputJSON, format, err)
+	}
+
+	cmd.PersistentFlags().Set(OutputFlag, "xml")
+	if _, err := OutputFormat(cmd); err == nil {
+		t.Error("Expected an error and got nil")
+	}
+}
+
+func TestPrintNames(t *testing.T) {
+	cmd := &cobra.Command{}
+	AddOutputFlag(cmd)
+	cmd.PersistentFlags().Set(OutputFlag, OutputJSON)
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+
+	if err := PrintNames(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "{\n  \"names\": []\n}\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestPrintError(t *testing.T) {
+	cmd := &cobra.Command{}
+	AddOutputFlag(cmd)
+	err := errors.New("record not found")
+
+	buf := new(bytes.Buffer)
+	PrintError(cmd, buf, err)
+	if expected := "error: record not found\n"; buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+
+	cmd.PersistentFlags().Set(OutputFlag, OutputJSON)
+	buf.Reset()
+	PrintError(cmd, buf, err)
+	if expected := "{\n  \"error\": \"record not found\"\n}\n"; buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
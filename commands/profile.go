@@ -0,0 +1,446 @@
+package cmdutil
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GGP1/kure/config"
+
+	"github.com/GGP1/atoll"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ProfileFlag is the name of the flag used to select a generation profile.
+const ProfileFlag = "profile"
+
+// Default lengths, used when neither the flags nor the profile specify them.
+const (
+	defaultLength uint64 = 24
+	defaultWords  uint64 = 6
+)
+
+// maxAttempts is the number of secrets generated trying to meet the profile constraints.
+const maxAttempts = 100
+
+// GenProfile is a set of rules to generate passwords and passphrases. Profiles are stored in the
+// configuration file under "gen.profiles".
+type GenProfile struct {
+	Name string
+	// Password rules
+	Length  uint64
+	Levels  []int
+	Include string
+	Exclude string
+	Repeat  bool
+	// Passphrase rules
+	Words        uint64
+	Separator    string
+	List         string
+	IncludeWords []string
+	ExcludeWords []string
+	// Constraints on the generated secret length, zero means there is no limit
+	MinLength uint64
+	MaxLength uint64
+
+	// set contains the keys specified in the profile
+	set map[string]bool
+}
+
+// AddProfileFlag adds the flag used to select a generation profile.
+func AddProfileFlag(f *pflag.FlagSet) {
+	f.StringP(ProfileFlag, "p", "", "generation profile from the configuration file")
+}
+
+// UseProfile sets the generation flags that weren't used to the values of the profile selected.
+// If no profile was selected and the length wasn't specified, the default one is used.
+//
+// It returns nil if no profile was applied.
+func UseProfile(cmd *cobra.Command, phrase bool) (*GenProfile, error) {
+	f := cmd.Flags()
+	name, _ := f.GetString(ProfileFlag)
+	if name == "" && f.Changed("length") {
+		return nil, nil
+	}
+
+	p, err := LoadProfile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	values := p.passwordFlags()
+	if phrase {
+		values = p.phraseFlags()
+	}
+
+	for flag, value := range values {
+		if f.Changed(flag) {
+			continue
+		}
+		if err := f.Set(flag, value); err != nil {
+			return nil, errors.Wrapf(err, "profile %q", p.Name)
+		}
+	}
+
+	return p, nil
+}
+
+// LoadProfile returns the generation profile with the name passed. If the name is empty, the profile
+// set in "gen.default" is returned or, if there isn't one, the built-in default.
+func LoadProfile(name string) (*GenProfile, error) {
+	if name == "" {
+		name = config.GetString("gen.default")
+		if name == "" {
+			return &GenProfile{Name: "default", set: map[string]bool{}}, nil
+		}
+	}
+
+	// Profile names can't contain dots as they are used to separate the configuration keys
+	value := config.Get("gen.profiles." + name)
+	if strings.Contains(name, ".") || value == nil {
+		return nil, errors.Errorf("profile %q does not exist", name)
+	}
+
+	mp, err := cast.ToStringMapE(value)
+	if err != nil {
+		return nil, errors.Errorf("profile %q: invalid format", name)
+	}
+
+	return parseProfile(name, mp)
+}
+
+// Generate calls gen until the secret returned meets the profile constraints. The receiver may be nil.
+func (p *GenProfile) Generate(gen func() (string, error)) (string, error) {
+	if p == nil {
+		return gen()
+	}
+
+	var err error
+	for i := 0; i < maxAttempts; i++ {
+		var secret string
+		secret, err = gen()
+		if err != nil {
+			return "", err
+		}
+		if err = p.check(secret); err == nil {
+			return secret, nil
+		}
+	}
+
+	return "", err
+}
+
+// check verifies that the secret meets the profile constraints.
+func (p *GenProfile) check(secret string) error {
+	length := uint64(len([]rune(secret)))
+	if p.MinLength > 0 && length < p.MinLength {
+		return errors.Errorf("profile %q requires at least %d characters, the secret generated has %d", p.Name, p.MinLength, length)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		return errors.Errorf("profile %q allows up to %d characters, the secret generated has %d", p.Name, p.MaxLength, length)
+	}
+	return nil
+}
+
+// ParseRules parses the generation rules stored in an entry.
+func ParseRules(rules string) (*GenProfile, error) {
+	var mp map[string]interface{}
+	if err := json.Unmarshal([]byte(rules), &mp); err != nil {
+		return nil, errors.Wrap(err, "decoding generation rules")
+	}
+	return parseProfile("stored", mp)
+}
+
+// ProfileFromFlags returns a profile with the values of the generation flags, the length constraints
+// are taken from base if it's not nil.
+func ProfileFromFlags(f *pflag.FlagSet, phrase bool, base *GenProfile) *GenProfile {
+	p := &GenProfile{Name: "flags", set: make(map[string]bool)}
+	if base != nil {
+		p.Name = base.Name
+		p.MinLength, p.set["min_length"] = base.MinLength, base.set["min_length"]
+		p.MaxLength, p.set["max_length"] = base.MaxLength, base.set["max_length"]
+	}
+
+	length, _ := f.GetUint64("length")
+	if phrase {
+		p.Words = length
+		p.Separator, _ = f.GetString("separator")
+		p.List, _ = f.GetString("list")
+		p.IncludeWords, _ = f.GetStringSlice("include")
+		p.ExcludeWords, _ = f.GetStringSlice("exclude")
+		for _, key := range []string{"words", "separator", "list", "include_words", "exclude_words"} {
+			p.set[key] = true
+		}
+		return p
+	}
+
+	p.Length = length
+	p.Levels, _ = f.GetIntSlice("levels")
+	p.Include, _ = f.GetString("include")
+	p.Exclude, _ = f.GetString("exclude")
+	p.Repeat, _ = f.GetBool("repeat")
+	for _, key := range []string{"length", "levels", "include", "exclude", "repeat"} {
+		p.set[key] = true
+	}
+	return p
+}
+
+// IsPhrase returns whether the profile generates passphrases, that is, if it specifies a number of
+// words but not a password length.
+func (p *GenProfile) IsPhrase() bool {
+	return p.set["words"] && !p.set["length"]
+}
+
+// NewSecret generates a password, or a passphrase if IsPhrase is true, following the profile rules.
+func (p *GenProfile) NewSecret() (string, error) {
+	if p.IsPhrase() {
+		list, err := phraseList(p.List)
+		if err != nil {
+			return "", err
+		}
+
+		separator := " "
+		if p.set["separator"] {
+			separator = p.Separator
+		}
+
+		phrase := &atoll.Passphrase{
+			Length:    p.Words,
+			Separator: separator,
+			Include:   p.IncludeWords,
+			Exclude:   p.ExcludeWords,
+			List:      list,
+		}
+		return p.Generate(func() (string, error) { return atoll.NewSecret(phrase) })
+	}
+
+	lvls := p.Levels
+	if !p.set["levels"] {
+		lvls = []int{1, 2, 3, 4, 5}
+	}
+	levels := make([]atoll.Level, len(lvls))
+	for i, lvl := range lvls {
+		switch lvl {
+		case 1:
+			levels[i] = atoll.Lower
+		case 2:
+			levels[i] = atoll.Upper
+		case 3:
+			levels[i] = atoll.Digit
+		case 4:
+			levels[i] = atoll.Space
+		case 5:
+			levels[i] = atoll.Special
+
+		default:
+			return "", errors.Errorf("invalid level [%d]", lvl)
+		}
+	}
+
+	password := &atoll.Password{
+		Length:  p.length(),
+		Levels:  levels,
+		Include: p.Include,
+		Exclude: p.Exclude,
+		Repeat:  p.Repeat,
+	}
+	return p.Generate(func() (string, error) { return atoll.NewSecret(password) })
+}
+
+// Rules returns the profile encoded in JSON using the configuration keys, so it can be stored in an
+// entry and parsed with ParseRules.
+func (p *GenProfile) Rules() (string, error) {
+	mp := make(map[string]interface{}, len(p.set))
+	values := map[string]interface{}{
+		"length":        p.Length,
+		"levels":        p.Levels,
+		"include":       p.Include,
+		"exclude":       p.Exclude,
+		"repeat":        p.Repeat,
+		"words":         p.Words,
+		"separator":     p.Separator,
+		"list":          p.List,
+		"include_words": p.IncludeWords,
+		"exclude_words": p.ExcludeWords,
+		"min_length":    p.MinLength,
+		"max_length":    p.MaxLength,
+	}
+	for key, ok := range p.set {
+		if ok {
+			mp[key] = values[key]
+		}
+	}
+
+	rules, err := json.Marshal(mp)
+	if err != nil {
+		return "", errors.Wrap(err, "encoding generation rules")
+	}
+	return string(rules), nil
+}
+
+// length returns the password length, if the profile doesn't specify it the default one is used,
+// within the profile limits.
+func (p *GenProfile) length() uint64 {
+	length := defaultLength
+	switch {
+	case p.set["length"]:
+		length = p.Length
+	case p.MaxLength > 0 && length > p.MaxLength:
+		length = p.MaxLength
+	case length < p.MinLength:
+		length = p.MinLength
+	}
+	return length
+}
+
+// passwordFlags returns the values of the password generation flags specified in the profile, the
+// length is always included.
+func (p *GenProfile) passwordFlags() map[string]string {
+	flags := map[string]string{"length": strconv.FormatUint(p.length(), 10)}
+	if p.set["levels"] {
+		levels := make([]string, len(p.Levels))
+		for i, lvl := range p.Levels {
+			levels[i] = strconv.Itoa(lvl)
+		}
+		flags["levels"] = strings.Join(levels, ",")
+	}
+	if p.set["include"] {
+		flags["include"] = p.Include
+	}
+	if p.set["exclude"] {
+		flags["exclude"] = p.Exclude
+	}
+	if p.set["repeat"] {
+		flags["repeat"] = strconv.FormatBool(p.Repeat)
+	}
+	return flags
+}
+
+// phraseFlags returns the values of the passphrase generation flags specified in the profile, the
+// number of words is always included.
+func (p *GenProfile) phraseFlags() map[string]string {
+	flags := map[string]string{"length": strconv.FormatUint(defaultWords, 10)}
+	if p.set["words"] {
+		flags["length"] = strconv.FormatUint(p.Words, 10)
+	}
+	if p.set["separator"] {
+		flags["separator"] = p.Separator
+	}
+	if p.set["list"] {
+		flags["list"] = p.List
+	}
+	if p.set["include_words"] {
+		flags["include"] = strings.Join(p.IncludeWords, ",")
+	}
+	if p.set["exclude_words"] {
+		flags["exclude"] = strings.Join(p.ExcludeWords, ",")
+	}
+	return flags
+}
+
+// parseProfile parses and validates a profile from the configuration file.
+func parseProfile(name string, mp map[string]interface{}) (*GenProfile, error) {
+	p := &GenProfile{Name: name, set: make(map[string]bool, len(mp))}
+
+	// Sort the keys so errors are deterministic
+	keys := make([]string, 0, len(mp))
+	for k := range mp {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		v := mp[key]
+		var err error
+		switch key {
+		case "length":
+			p.Length, err = cast.ToUint64E(v)
+		case "levels":
+			p.Levels, err = cast.ToIntSliceE(v)
+		case "include":
+			p.Include, err = cast.ToStringE(v)
+		case "exclude":
+			p.Exclude, err = cast.ToStringE(v)
+		case "repeat":
+			p.Repeat, err = cast.ToBoolE(v)
+		case "words":
+			p.Words, err = cast.ToUint64E(v)
+		case "separator":
+			p.Separator, err = cast.ToStringE(v)
+		case "list":
+			p.List, err = cast.ToStringE(v)
+		case "include_words":
+			p.IncludeWords, err = cast.ToStringSliceE(v)
+		case "exclude_words":
+			p.ExcludeWords, err = cast.ToStringSliceE(v)
+		case "min_length":
+			p.MinLength, err = cast.ToUint64E(v)
+		case "max_length":
+			p.MaxLength, err = cast.ToUint64E(v)
+		default:
+			return nil, errors.Errorf("profile %q: unknown key %q", name, key)
+		}
+		if err != nil {
+			return nil, errors.Errorf("profile %q: invalid %s", name, key)
+		}
+		p.set[key] = true
+	}
+
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// validate verifies that the profile values are consistent with its constraints.
+func (p *GenProfile) validate() error {
+	if p.set["length"] && p.Length < 1 {
+		return errors.Errorf("profile %q: %v", p.Name, ErrInvalidLength)
+	}
+	if p.set["words"] && p.Words < 1 {
+		return errors.Errorf("profile %q: invalid number of words", p.Name)
+	}
+	for _, lvl := range p.Levels {
+		if lvl < 1 || lvl > 5 {
+			return errors.Errorf("profile %q: invalid level [%d]", p.Name, lvl)
+		}
+	}
+	if p.set["list"] {
+		if _, err := phraseList(p.List); err != nil {
+			return errors.Errorf("profile %q: %v", p.Name, err)
+		}
+	}
+	if p.MaxLength > 0 && p.MinLength > p.MaxLength {
+		return errors.Errorf("profile %q: min_length is higher than max_length", p.Name)
+	}
+	if p.set["length"] {
+		if p.MinLength > 0 && p.Length < p.MinLength {
+			return errors.Errorf("profile %q: length is lower than min_length", p.Name)
+		}
+		if p.MaxLength > 0 && p.Length > p.MaxLength {
+			return errors.Errorf("profile %q: length is higher than max_length", p.Name)
+		}
+	}
+	return nil
+}
+
+// phraseList returns the passphrase list with the name passed, WordList is used if it's empty.
+func phraseList(name string) (func(*atoll.Passphrase, int), error) {
+	switch strings.ToLower(strings.ReplaceAll(name, " ", "")) {
+	case "nolist", "no":
+		return atoll.NoList, nil
+
+	case "wordlist", "word", "":
+		return atoll.WordList, nil
+
+	case "syllablelist", "syllable":
+		return atoll.SyllableList, nil
+
+	default:
+		return nil, errors.Errorf("invalid list: %q", name)
+	}
+}
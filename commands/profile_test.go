@@ -0,0 +1,211 @@
+package cmdutil
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/GGP1/kure/config"
+
+	"github.com/spf13/cobra"
+)
+
+func TestLoadProfile(t *testing.T) {
+	config.Reset()
+	config.Set("gen.profiles.bank", map[string]interface{}{
+		"length":     16,
+		"levels":     []interface{}{1, 2, 3},
+		"exclude":    " ",
+		"max_length": 16,
+	})
+
+	got, err := LoadProfile("bank")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Length != 16 || got.MaxLength != 16 || got.Exclude != " " {
+		t.Errorf("Unexpected profile: %+v", got)
+	}
+	if !reflect.DeepEqual(got.Levels, []int{1, 2, 3}) {
+		t.Errorf("Expected levels [1 2 3], got %v", got.Levels)
+	}
+}
+
+func TestLoadDefaultProfile(t *testing.T) {
+	config.Reset()
+
+	got, err := LoadProfile("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "default" {
+		t.Errorf("Expected the built-in profile, got %+v", got)
+	}
+	if flags := got.passwordFlags(); flags["length"] != "24" {
+		t.Errorf("Expected length 24, got %s", flags["length"])
+	}
+	if flags := got.phraseFlags(); flags["length"] != "6" {
+		t.Errorf("Expected 6 words, got %s", flags["length"])
+	}
+
+	config.Set("gen.default", "short")
+	config.Set("gen.profiles.short", map[string]interface{}{"length": 8})
+	got, err = LoadProfile("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "short" || got.Length != 8 {
+		t.Errorf("Expected the configured default profile, got %+v", got)
+	}
+}
+
+func TestLoadProfileErrors(t *testing.T) {
+	cases := map[string]map[string]interface{}{
+		"unknown key":         {"lenght": 10},
+		"invalid length":      {"length": 0},
+		"invalid level":       {"levels": []int{1, 6}},
+		"invalid type":        {"repeat": "sometimes"},
+		"length over max":     {"length": 20, "max_length": 16},
+		"length under min":    {"length": 10, "min_length": 12},
+		"min higher than max": {"min_length": 20, "max_length": 16},
+	}
+
+	for desc, profile := range cases {
+		t.Run(desc, func(t *testing.T) {
+			config.Reset()
+			config.Set("gen.profiles.test", profile)
+			if _, err := LoadProfile("test"); err == nil {
+				t.Error("Expected an error and got nil")
+			}
+		})
+	}
+
+	t.Run("Does not exist", func(t *testing.T) {
+		config.Reset()
+		if _, err := LoadProfile("test"); err == nil {
+			t.Error("Expected an error and got nil")
+		}
+	})
+}
+
+func TestUseProfile(t *testing.T) {
+	config.Reset()
+	config.Set("gen.profiles.bank", map[string]interface{}{
+		"length":    16,
+		"levels":    []int{1, 3},
+		"repeat":    true,
+		"words":     4,
+		"separator": "-",
+	})
+
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		f := cmd.Flags()
+		f.Uint64("length", 0, "")
+		f.IntSlice("levels", []int{1, 2, 3, 4, 5}, "")
+		f.Bool("repeat", false, "")
+		f.String("separator", " ", "")
+		AddProfileFlag(f)
+		return cmd
+	}
+
+	t.Run("Password", func(t *testing.T) {
+		cmd := newCmd()
+		f := cmd.Flags()
+		f.Set(ProfileFlag, "bank")
+		f.Set("length", "14")
+
+		if _, err := UseProfile(cmd, false); err != nil {
+			t.Fatal(err)
+		}
+
+		// Flags used take precedence over the profile
+		if length, _ := f.GetUint64("length"); length != 14 {
+			t.Errorf("Expected length 14, got %d", length)
+		}
+		if levels, _ := f.GetIntSlice("levels"); !reflect.DeepEqual(levels, []int{1, 3}) {
+			t.Errorf("Expected levels [1 3], got %v", levels)
+		}
+		if repeat, _ := f.GetBool("repeat"); !repeat {
+			t.Error("Expected repeat to be true")
+		}
+	})
+
+	t.Run("Passphrase", func(t *testing.T) {
+		cmd := newCmd()
+		f := cmd.Flags()
+		f.Set(ProfileFlag, "bank")
+
+		if _, err := UseProfile(cmd, true); err != nil {
+			t.Fatal(err)
+		}
+
+		if length, _ := f.GetUint64("length"); length != 4 {
+			t.Errorf("Expected 4 words, got %d", length)
+		}
+		if separator, _ := f.GetString("separator"); separator != "-" {
+			t.Errorf("Expected separator %q, got %q", "-", separator)
+		}
+	})
+
+	t.Run("No profile", func(t *testing.T) {
+		cmd := newCmd()
+		cmd.Flags().Set("length", "10")
+
+		p, err := UseProfile(cmd, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != nil {
+			t.Errorf("Expected no profile to be applied, got %+v", p)
+		}
+	})
+}
+
+func TestProfileGenerate(t *testing.T) {
+	p := &GenProfile{Name: "test", MinLength: 4, MaxLength: 6}
+
+	secrets := []string{"ab", "abcdefgh", "abcde"}
+	i := 0
+	got, err := p.Generate(func() (string, error) {
+		s := secrets[i]
+		i++
+		return s, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "abcde" {
+		t.Errorf("Expected %q, got %q", "abcde", got)
+	}
+
+	if _, err := p.Generate(func() (string, error) { return "a", nil }); err == nil {
+		t.Error("Expected an error and got nil")
+	}
+
+	var nilProfile *GenProfile
+	if got, err := nilProfile.Generate(func() (string, error) { return "a", nil }); err != nil || got != "a" {
+		t.Errorf("Expected the secret to be returned, got %q (%v)", got, err)
+	}
+}
+
+func TestProfileDefaultLength(t *testing.T) {
+	cases := []struct {
+		desc     string
+		profile  *GenProfile
+		expected string
+	}{
+		{desc: "Default", profile: &GenProfile{}, expected: "24"},
+		{desc: "Maximum", profile: &GenProfile{MaxLength: 16}, expected: "16"},
+		{desc: "Minimum", profile: &GenProfile{MinLength: 32}, expected: "32"},
+		{desc: "Length", profile: &GenProfile{Length: 10, set: map[string]bool{"length": true}}, expected: "10"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := tc.profile.passwordFlags()["length"]; got != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
@@ -2,7 +2,6 @@ package restore
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/GGP1/kure/auth"
 	cmdutil "github.com/GGP1/kure/commands"
@@ -15,10 +14,14 @@ import (
 	bolt "go.etcd.io/bbolt"
 )
 
-var buckets = [][]byte{dbutil.CardBucket, dbutil.EntryBucket, dbutil.FileBucket, dbutil.TOTPBucket}
+type restoreOptions struct {
+	force bool
+}
 
 // NewCmd returns a new command.
 func NewCmd(db *bolt.DB) *cobra.Command {
+	opts := restoreOptions{}
+
 	cmd := &cobra.Command{
 		Use:   "restore",
 		Short: "Restore the database using new credentials",
@@ -26,16 +29,22 @@ func NewCmd(db *bolt.DB) *cobra.Command {
 
 Overwrite the registered credentials and re-encrypt every record with the new ones.
 
+New passwords weaker than the minimum score configured ("password.min_score") are rejected unless --force is used.
+
 Warning: this command is computationally expensive, it may cause memory (OOM) and CPU errors.`,
 		PreRunE: auth.Login(db),
-		RunE:    runRestore(db),
+		RunE:    runRestore(db, &opts),
 	}
 
+	cmd.Flags().BoolVar(&opts.force, "force", false, "accept a password weaker than the minimum score")
+
 	return cmd
 }
 
-func runRestore(db *bolt.DB) cmdutil.RunEFunc {
+func runRestore(db *bolt.DB, opts *restoreOptions) cmdutil.RunEFunc {
 	return func(cmd *cobra.Command, args []string) error {
+		// Buckets are read at execution time as they depend on the vault in use
+		buckets := dbutil.Buckets()
 		logs := make([]*log, 0, len(buckets))
 		for _, bucket := range buckets {
 			log, err := newLog(bucket)
@@ -52,10 +61,10 @@ func runRestore(db *bolt.DB) cmdutil.RunEFunc {
 		}
 
 		// Initialize registration and re-encrypt the records with the new credentials
-		if err := auth.Register(db, os.Stdin); err != nil {
+		if err := auth.Register(db, cmd.InOrStdin(), cmd.OutOrStdout(), opts.force); err != nil {
 			return err
 		}
-		fmt.Println("Re-encrypting records...")
+		fmt.Fprintln(cmd.OutOrStdout(), "Re-encrypting records...")
 
 		if err := readLogs(db, logs); err != nil {
 			return errors.Wrap(err, "recreating records")
@@ -23,7 +23,7 @@ func TestLogs(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	l, err := newLog(dbutil.EntryBucket)
+	l, err := newLog(dbutil.EntryBucket())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -60,7 +60,7 @@ func TestReadLogs(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	l, err := newLog(dbutil.CardBucket)
+	l, err := newLog(dbutil.CardBucket())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -84,7 +84,7 @@ func TestReadLogs(t *testing.T) {
 func TestWriteLogs(t *testing.T) {
 	db := cmdutil.SetContext(t, "../../db/testdata/database")
 
-	l, err := newLog(dbutil.EntryBucket)
+	l, err := newLog(dbutil.EntryBucket())
 	if err != nil {
 		t.Fatal(err)
 	}
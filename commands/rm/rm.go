@@ -49,11 +49,11 @@ func runRm(db *bolt.DB, r io.Reader) cmdutil.RunEFunc {
 				return err
 			}
 
-			fmt.Printf("\n%q removed\n", name)
+			fmt.Fprintf(cmd.OutOrStdout(), "\n%q removed\n", name)
 			return nil
 		}
 
-		fmt.Printf("Removing %q directory...\n", name)
+		fmt.Fprintf(cmd.OutOrStdout(), "Removing %q directory...\n", name)
 
 		entries, err := entry.ListNames(db)
 		if err != nil {
@@ -66,7 +66,7 @@ func runRm(db *bolt.DB, r io.Reader) cmdutil.RunEFunc {
 					return err
 				}
 
-				fmt.Println("Remove:", e)
+				fmt.Fprintln(cmd.OutOrStdout(), "Remove:", e)
 			}
 		}
 
@@ -2,35 +2,48 @@ package root
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"runtime/debug"
 
+	"github.com/GGP1/kure/auth"
+	cmdutil "github.com/GGP1/kure/commands"
 	tfa "github.com/GGP1/kure/commands/2fa"
 	"github.com/GGP1/kure/commands/add"
+	"github.com/GGP1/kure/commands/audit"
 	"github.com/GGP1/kure/commands/backup"
+	"github.com/GGP1/kure/commands/breach"
 	"github.com/GGP1/kure/commands/card"
 	"github.com/GGP1/kure/commands/clear"
 	"github.com/GGP1/kure/commands/config"
 	"github.com/GGP1/kure/commands/copy"
+	"github.com/GGP1/kure/commands/duress"
 	"github.com/GGP1/kure/commands/edit"
+	"github.com/GGP1/kure/commands/expiring"
 	"github.com/GGP1/kure/commands/export"
 	"github.com/GGP1/kure/commands/file"
 	"github.com/GGP1/kure/commands/gen"
 	importt "github.com/GGP1/kure/commands/import"
 	"github.com/GGP1/kure/commands/it"
+	"github.com/GGP1/kure/commands/keyfile"
 	"github.com/GGP1/kure/commands/ls"
 	"github.com/GGP1/kure/commands/restore"
 	"github.com/GGP1/kure/commands/rm"
+	"github.com/GGP1/kure/commands/rotate"
+	"github.com/GGP1/kure/commands/run"
+	"github.com/GGP1/kure/commands/search"
 	"github.com/GGP1/kure/commands/session"
 	"github.com/GGP1/kure/commands/stats"
+	"github.com/GGP1/kure/commands/wifi"
 
 	"github.com/spf13/cobra"
 	bolt "go.etcd.io/bbolt"
 )
 
-var (
-	version bool
-	cmd     = &cobra.Command{
+// New returns a new root command with all its subcommands, which read the user input from r.
+func New(db *bolt.DB, r io.Reader) *cobra.Command {
+	var version bool
+	cmd := &cobra.Command{
 		Use:           "kure",
 		Short:         "Kure ~ CLI password manager",
 		SilenceErrors: true,
@@ -46,44 +59,64 @@ var (
 			_ = cmd.Usage()
 		},
 	}
-)
+
+	cmd.Flags().BoolVarP(&version, "version", "v", false, "version for kure")
+	cmdutil.AddOutputFlag(cmd)
+	auth.AddAllowWeakFlag(cmd)
+	registerCmds(cmd, db, r)
+
+	return cmd
+}
 
 // DevCmd returns the root command with all its sub commands and without a database object.
 //
 // It should be used for documentation or testing purposes only.
 func DevCmd() *cobra.Command {
-	registerCmds(nil)
-	return cmd
+	return New(nil, os.Stdin)
 }
 
-// Execute adds all the subcommands to the root and executes it.
+// Execute creates the root command and executes it, the error returned is printed
+// to the standard error in the output format selected.
 func Execute(db *bolt.DB) error {
-	cmd.Flags().BoolVarP(&version, "version", "v", false, "version for kure")
-	registerCmds(db)
-
-	return cmd.Execute()
+	cmd, err := New(db, os.Stdin).ExecuteC()
+	if err != nil {
+		cmdutil.PrintError(cmd, os.Stderr, err)
+	}
+	return err
 }
 
 // registerCmds adds all the commands to the root.
-func registerCmds(db *bolt.DB) {
-	cmd.AddCommand(tfa.NewCmd(db))
-	cmd.AddCommand(add.NewCmd(db, os.Stdin))
+func registerCmds(cmd *cobra.Command, db *bolt.DB, r io.Reader) {
+	// Sessions, scripts and the interactive prompt execute each command on a new tree
+	newRoot := func(r io.Reader) *cobra.Command { return New(db, r) }
+
+	cmd.AddCommand(tfa.NewCmd(db, r))
+	cmd.AddCommand(add.NewCmd(db, r))
+	cmd.AddCommand(audit.NewCmd(db))
 	cmd.AddCommand(backup.NewCmd(db))
-	cmd.AddCommand(card.NewCmd(db))
+	cmd.AddCommand(breach.NewCmd(db))
+	cmd.AddCommand(card.NewCmd(db, r))
 	cmd.AddCommand(clear.NewCmd())
-	cmd.AddCommand(config.NewCmd(db, os.Stdin))
+	cmd.AddCommand(config.NewCmd(db, r))
 	cmd.AddCommand(copy.NewCmd(db))
-	cmd.AddCommand(edit.NewCmd(db))
+	cmd.AddCommand(duress.NewCmd(db, r))
+	cmd.AddCommand(edit.NewCmd(db, r))
+	cmd.AddCommand(expiring.NewCmd(db))
 	cmd.AddCommand(export.NewCmd(db))
-	cmd.AddCommand(file.NewCmd(db))
+	cmd.AddCommand(file.NewCmd(db, r))
 	cmd.AddCommand(gen.NewCmd())
 	cmd.AddCommand(importt.NewCmd(db))
-	cmd.AddCommand(it.NewCmd(db))
+	cmd.AddCommand(it.NewCmd(db, newRoot))
+	cmd.AddCommand(keyfile.NewCmd(db))
 	cmd.AddCommand(ls.NewCmd(db))
 	cmd.AddCommand(restore.NewCmd(db))
-	cmd.AddCommand(rm.NewCmd(db, os.Stdin))
-	cmd.AddCommand(session.NewCmd(db, os.Stdin))
+	cmd.AddCommand(rm.NewCmd(db, r))
+	cmd.AddCommand(rotate.NewCmd(db, r))
+	cmd.AddCommand(run.NewCmd(db, r, newRoot))
+	cmd.AddCommand(search.NewCmd(db))
+	cmd.AddCommand(session.NewCmd(db, r, newRoot))
 	cmd.AddCommand(stats.NewCmd(db))
+	cmd.AddCommand(wifi.NewCmd(db, r))
 }
 
 func printVersion() {
@@ -43,8 +43,11 @@ func TestHasExample(t *testing.T) {
 func TestRunnable(t *testing.T) {
 	cmd := root.DevCmd()
 	exceptions := map[string]struct{}{
+		"breach":     {},
 		"card":       {},
 		"file":       {},
+		"keyfile":    {},
+		"wifi":       {},
 		"completion": {},
 	}
 
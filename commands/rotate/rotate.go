@@ -0,0 +1,195 @@
+package rotate
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/GGP1/kure/auth"
+	"github.com/GGP1/kure/clip"
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/pb"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+)
+
+const example = `
+* Rotate an entry password using the rules it was generated with
+kure rotate Sample
+
+* Rotate all the entries inside a folder using a profile
+kure rotate Work/ -p bank
+
+* Ask whether the website accepted the new password before saving it
+kure rotate Sample --confirm`
+
+// genFlags are the flags used to generate the password, using any of them overrides the entry rules.
+var genFlags = []string{cmdutil.ProfileFlag, "length", "levels", "include", "exclude", "repeat"}
+
+type rotateOptions struct {
+	include, exclude string
+	levels           []int
+	length           uint64
+	repeat, confirm  bool
+	timeout          time.Duration
+}
+
+// NewCmd returns a new command.
+func NewCmd(db *bolt.DB, r io.Reader) *cobra.Command {
+	opts := rotateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "rotate <name|folder/>",
+		Short: "Regenerate an entry password",
+		Long: `Regenerate an entry password.
+
+The new password is generated using the rules the entry password was generated with. Entries without rules (custom or imported passwords) use the default profile. Use a profile or the generation flags to set new rules, they are stored in the entry for the next rotation.
+
+The old password is kept as the entry previous password, it can be seen with "kure ls <name> -s".
+
+When rotating a single entry the new password is copied to the clipboard. Use --confirm to copy it before saving it and answer whether the change on the website succeeded, the entry is left untouched otherwise.`,
+		Example: example,
+		Args:    cmdutil.MustExist(db, cmdutil.Entry, true),
+		PreRunE: auth.Login(db),
+		RunE:    runRotate(db, r, &opts),
+	}
+
+	f := cmd.Flags()
+	f.Uint64VarP(&opts.length, "length", "l", 0, "password length")
+	f.IntSliceVarP(&opts.levels, "levels", "L", []int{1, 2, 3, 4, 5}, "password levels")
+	f.StringVarP(&opts.include, "include", "i", "", "characters to include in the password")
+	f.StringVarP(&opts.exclude, "exclude", "e", "", "characters to exclude from the password")
+	f.BoolVarP(&opts.repeat, "repeat", "r", false, "allow character repetition")
+	f.BoolVar(&opts.confirm, "confirm", false, "ask whether the website change succeeded before saving")
+	f.DurationVarP(&opts.timeout, "timeout", "t", 0, "clipboard clearing timeout")
+	cmdutil.AddProfileFlag(f)
+
+	return cmd
+}
+
+func runRotate(db *bolt.DB, r io.Reader, opts *rotateOptions) cmdutil.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		name := strings.Join(args, " ")
+		name = cmdutil.NormalizeName(name, true)
+
+		names, err := entryNames(db, name)
+		if err != nil {
+			return err
+		}
+
+		// Rules selected by the user, they take precedence over the ones stored
+		var selected *cmdutil.GenProfile
+		for _, flag := range genFlags {
+			if cmd.Flags().Changed(flag) {
+				base, err := cmdutil.UseProfile(cmd, false)
+				if err != nil {
+					return err
+				}
+				selected = cmdutil.ProfileFromFlags(cmd.Flags(), false, base)
+				break
+			}
+		}
+
+		// Only a single password is copied to the clipboard, unless they are confirmed one by one
+		single := len(names) == 1
+		var last string
+		for _, name := range names {
+			e, err := entry.Get(db, name)
+			if err != nil {
+				return err
+			}
+
+			rules, err := entryRules(e, selected)
+			if err != nil {
+				return errors.Wrapf(err, "%q", name)
+			}
+
+			password, err := rules.NewSecret()
+			if err != nil {
+				return errors.Wrapf(err, "%q", name)
+			}
+
+			if opts.confirm {
+				if err := clip.Write(password); err != nil {
+					return errors.Wrap(err, "writing to clipboard")
+				}
+				msg := fmt.Sprintf("New %q password copied to the clipboard, was it changed successfully?", name)
+				if !cmdutil.Confirm(r, msg) {
+					fmt.Fprintf(cmd.OutOrStdout(), "%q was not rotated\n", name)
+					continue
+				}
+			}
+
+			if err := rotate(e, password, rules, time.Now()); err != nil {
+				return err
+			}
+			if err := entry.Update(db, name, e); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%q rotated\n", name)
+			last = password
+		}
+
+		if single && last != "" {
+			return cmdutil.WriteClipboard(cmd, opts.timeout, "Password", last)
+		}
+		if opts.confirm {
+			// Do not leave the last password generated in the clipboard
+			if err := clip.Clear(); err != nil {
+				return errors.Wrap(err, "clearing clipboard")
+			}
+		}
+		return nil
+	}
+}
+
+// entryNames returns the name passed or, if it's a folder, the entries inside it.
+func entryNames(db *bolt.DB, name string) ([]string, error) {
+	if !strings.HasSuffix(name, "/") {
+		return []string{name}, nil
+	}
+
+	entries, err := entry.ListNames(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if strings.HasPrefix(e, name) {
+			names = append(names, e)
+		}
+	}
+	return names, nil
+}
+
+// entryRules returns the rules used to generate the entry new password.
+func entryRules(e *pb.Entry, selected *cmdutil.GenProfile) (*cmdutil.GenProfile, error) {
+	if selected != nil {
+		return selected, nil
+	}
+	if e.GenRules != "" {
+		return cmdutil.ParseRules(e.GenRules)
+	}
+	return cmdutil.LoadProfile("")
+}
+
+// rotate replaces the entry password, keeping the old one as the previous password.
+func rotate(e *pb.Entry, password string, rules *cmdutil.GenProfile, now time.Time) error {
+	genRules, err := rules.Rules()
+	if err != nil {
+		return err
+	}
+
+	e.PreviousPassword = e.Password
+	e.Password = password
+	e.GenRules = genRules
+	e.PasswordUpdatedAt = now.Unix()
+	e.RotatedAt = now.Unix()
+	return nil
+}
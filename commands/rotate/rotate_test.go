@@ -0,0 +1,145 @@
+package rotate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/pb"
+
+	"github.com/atotto/clipboard"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestRotateFolder(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+	err := entry.Create(db,
+		&pb.Entry{Name: "work/rules", Password: "old rules", GenRules: `{"length":12,"levels":[1,3]}`},
+		&pb.Entry{Name: "work/custom", Password: "old custom"},
+		&pb.Entry{Name: "work/phrase", Password: "old phrase", GenRules: `{"words":4,"separator":"-"}`},
+		&pb.Entry{Name: "personal", Password: "untouched"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewCmd(db, nil)
+	cmd.SetArgs([]string{"work/"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	rules := get(t, db, "work/rules")
+	if len(rules.Password) != 12 || strings.Trim(rules.Password, "abcdefghijklmnopqrstuvwxyz0123456789") != "" {
+		t.Errorf("Password does not follow the stored rules: %q", rules.Password)
+	}
+	if rules.PreviousPassword != "old rules" {
+		t.Errorf("Expected previous password %q, got %q", "old rules", rules.PreviousPassword)
+	}
+	if rules.RotatedAt == 0 || rules.PasswordUpdatedAt != rules.RotatedAt {
+		t.Errorf("Expected the rotation date to be stamped, got %d", rules.RotatedAt)
+	}
+
+	custom := get(t, db, "work/custom")
+	if len(custom.Password) != 24 {
+		t.Errorf("Expected the default length to be used, got %q", custom.Password)
+	}
+	if custom.GenRules == "" {
+		t.Error("Expected the generation rules to be stored")
+	}
+
+	phrase := get(t, db, "work/phrase")
+	if words := strings.Split(phrase.Password, "-"); len(words) != 4 {
+		t.Errorf("Expected a passphrase of 4 words, got %q", phrase.Password)
+	}
+
+	if personal := get(t, db, "personal"); personal.Password != "untouched" || personal.PreviousPassword != "" {
+		t.Error("Expected entries outside the folder to be untouched")
+	}
+}
+
+func TestRotateFlags(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+	// Rotating more than one entry does not use the clipboard
+	err := entry.Create(db,
+		&pb.Entry{Name: "dir/test", Password: "old", GenRules: `{"length":12}`},
+		&pb.Entry{Name: "dir/test2", Password: "old"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewCmd(db, nil)
+	cmd.SetArgs([]string{"dir/", "-l", "30", "-L", "1,2"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	e := get(t, db, "dir/test")
+	if len(e.Password) != 30 {
+		t.Errorf("Expected the length passed to be used, got %q", e.Password)
+	}
+
+	rules, err := cmdutil.ParseRules(e.GenRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules.Length != 30 {
+		t.Errorf("Expected the new rules to be stored, got %q", e.GenRules)
+	}
+}
+
+func TestRotateNotConfirmed(t *testing.T) {
+	if clipboard.Unsupported {
+		t.Skip("No clipboard utilities available")
+	}
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+	if err := entry.Create(db, &pb.Entry{Name: "test", Password: "old"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewCmd(db, bytes.NewBufferString("n\n"))
+	cmd.SetArgs([]string{"test", "--confirm"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if e := get(t, db, "test"); e.Password != "old" || e.RotatedAt != 0 {
+		t.Error("Expected the entry to be untouched")
+	}
+}
+
+func TestRotate(t *testing.T) {
+	e := &pb.Entry{Name: "test", Password: "old"}
+	rules, err := cmdutil.ParseRules(`{"length":8}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	if err := rotate(e, "new", rules, now); err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Password != "new" || e.PreviousPassword != "old" {
+		t.Errorf("Expected password %q and previous %q, got %q and %q", "new", "old", e.Password, e.PreviousPassword)
+	}
+	if e.RotatedAt != now.Unix() || e.PasswordUpdatedAt != now.Unix() {
+		t.Errorf("Expected the dates to be %d, got %d and %d", now.Unix(), e.RotatedAt, e.PasswordUpdatedAt)
+	}
+	if e.GenRules != `{"length":8}` {
+		t.Errorf("Expected rules %q, got %q", `{"length":8}`, e.GenRules)
+	}
+}
+
+func get(t *testing.T, db *bolt.DB, name string) *pb.Entry {
+	t.Helper()
+	e, err := entry.Get(db, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
@@ -0,0 +1,94 @@
+package run
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/GGP1/kure/auth"
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/commands/session"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+)
+
+const example = `
+* Run a script
+kure run backup.kure
+
+* Run a script passing arguments
+kure run login.kure github
+
+* Print the commands without executing them
+kure run rotate.kure --dry-run`
+
+type runOptions struct {
+	dryRun  bool
+	timeout time.Duration
+}
+
+// NewCmd returns a new command.
+func NewCmd(db *bolt.DB, r io.Reader, newRoot cmdutil.NewRootFunc) *cobra.Command {
+	opts := runOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "run <file> [args]",
+		Short: "Run a script file",
+		Long: `Run a script file.
+
+Script files contain session commands, one per line, executed after providing the master password once. Lines support the same syntax as the session input (quotes, escapes, ";", "&&", "||" and comments) and scripts from the configuration file can be used by their aliases.
+
+Variables:
+• $1, $2, ..., $n or ${n} - arguments passed after the file path.
+• ${n:-default} - argument or default value if it's missing or empty.
+• $@ - all the arguments.
+• $name or ${name} - loop variable.
+
+Control flow:
+• if [command] ... else ... end - execute a block depending on whether the previous command (or the one passed) succeeded.
+• for name in <values> ... end - execute a block for each value, use $(ls-names [folder]) to iterate over the entries names.
+
+Failed commands are printed and the execution continues, the script fails if any of them did (if conditions are not taken into account). Syntax errors stop the script, reporting the line where they were found.
+
+With --dry-run, the commands are printed instead of being executed. Both branches of if blocks are printed as their conditions are not evaluated. Records are not read, loops over $(ls-names) are printed once with the variable shown as a placeholder (<name>).`,
+		Example: example,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("no script file specified")
+			}
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Records are not read in dry runs
+			if opts.dryRun {
+				return nil
+			}
+			return auth.Login(db)(cmd, args)
+		},
+		RunE: runRun(db, r, newRoot, &opts),
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&opts.dryRun, "dry-run", false, "print the commands instead of executing them")
+	f.DurationVarP(&opts.timeout, "timeout", "t", 0, "script timeout")
+
+	return cmd
+}
+
+func runRun(db *bolt.DB, r io.Reader, newRoot cmdutil.NewRootFunc, opts *runOptions) cmdutil.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		script, err := os.ReadFile(args[0])
+		if err != nil {
+			return errors.Wrap(err, "reading script")
+		}
+
+		executor := cmdutil.NewExecutor(newRoot, r, cmd.OutOrStdout(), cmd.ErrOrStderr())
+		return session.RunScript(executor, db, string(script), session.ScriptOptions{
+			Args:    args[1:],
+			DryRun:  opts.dryRun,
+			Timeout: opts.timeout,
+		})
+	}
+}
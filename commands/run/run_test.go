@@ -0,0 +1,84 @@
+package run
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/commands/ls"
+
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestRun(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+	path := filepath.Join(t.TempDir(), "test.kure")
+	script := "copy $1 -u\nif\n\tls $1\nend"
+	if err := os.WriteFile(path, []byte(script), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	out := new(bytes.Buffer)
+	cmd := NewCmd(db, &bytes.Buffer{}, newRoot(db))
+	cmd.SetOut(out)
+	cmd.SetArgs([]string{path, "github", "--dry-run"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Failed running script: %v", err)
+	}
+
+	expected := "copy github -u\nif\n\tls github\nend\n"
+	if got := out.String(); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+	path := filepath.Join(t.TempDir(), "invalid.kure")
+	if err := os.WriteFile(path, []byte("if\n\tls"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		desc string
+		args []string
+	}{
+		{
+			desc: "No file",
+			args: []string{},
+		},
+		{
+			desc: "Non-existent file",
+			args: []string{filepath.Join(t.TempDir(), "non-existent.kure")},
+		},
+		{
+			desc: "Invalid syntax",
+			args: []string{path, "--dry-run"},
+		},
+	}
+
+	cmd := NewCmd(db, &bytes.Buffer{}, newRoot(db))
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cmd.SetArgs(tc.args)
+			if err := cmd.Execute(); err == nil {
+				t.Error("Expected an error and got nil")
+			}
+		})
+	}
+}
+
+func newRoot(db *bolt.DB) cmdutil.NewRootFunc {
+	return func(r io.Reader) *cobra.Command {
+		root := &cobra.Command{Use: "kure", SilenceErrors: true, SilenceUsage: true}
+		root.AddCommand(ls.NewCmd(db))
+		return root
+	}
+}
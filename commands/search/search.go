@@ -0,0 +1,348 @@
+package search
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/GGP1/kure/auth"
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/card"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/db/file"
+	"github.com/GGP1/kure/db/totp"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+	"golang.org/x/term"
+)
+
+const example = `
+* Search a username in all the records
+kure search john@example.com
+
+* Search entries and files only
+kure search "recovery codes" -t entry,file
+
+* Use a regular expression
+kure search "^github\.com/" -r
+
+* Fuzzy search, characters must appear in the same order
+kure search gthb -z
+
+* Include card numbers and security codes
+kure search 4242 -t card --card-numbers`
+
+// Record types that can be searched.
+const (
+	entryType = "entry"
+	cardType  = "card"
+	fileType  = "file"
+	totpType  = "totp"
+)
+
+type searchOptions struct {
+	types       []string
+	regex       bool
+	fuzzy       bool
+	cardNumbers bool
+	maxSize     int64
+}
+
+// result is a field that matched the query.
+type result struct {
+	typ   string
+	name  string
+	field string
+	// line number inside the field value, starting from 1
+	line  int
+	value string
+	// ranges contains the start and end byte offsets of the matches in value
+	ranges [][2]int
+}
+
+// matcher returns the byte offsets of the matches found in s, nil if there are none.
+type matcher func(s string) [][2]int
+
+// NewCmd returns a new command.
+func NewCmd(db *bolt.DB) *cobra.Command {
+	opts := searchOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search records by their content",
+		Long: `Search records by their content.
+
+The query is case insensitive and it's matched against the following fields:
+• Entries: name, username, URL and notes. Passwords are never searched.
+• Cards: name, type, expire date and notes. Number and security code only with --card-numbers.
+• Files: name and content (text files smaller than --max-size only).
+• TOTP: name.
+
+By default the query is searched as a substring, use [-r regex] to use a regular expression or [-z fuzzy] to match the query characters in order but not necessarily next to each other.
+
+Every record is decrypted, the search may take some time if there are many of them.`,
+		Example: example,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(strings.Join(args, " ")) == "" {
+				return errors.New("no query specified")
+			}
+			return nil
+		},
+		PreRunE: auth.Login(db),
+		RunE:    runSearch(db, &opts),
+	}
+
+	f := cmd.Flags()
+	f.StringSliceVarP(&opts.types, "type", "t", []string{entryType, cardType, fileType, totpType}, "types of records to search (entry,card,file,totp)")
+	f.BoolVarP(&opts.regex, "regex", "r", false, "use the query as a regular expression")
+	f.BoolVarP(&opts.fuzzy, "fuzzy", "z", false, "use fuzzy matching")
+	f.BoolVar(&opts.cardNumbers, "card-numbers", false, "search card numbers and security codes")
+	f.Int64Var(&opts.maxSize, "max-size", 1<<20, "maximum size in bytes of the files whose content is searched")
+
+	return cmd
+}
+
+func runSearch(db *bolt.DB, opts *searchOptions) cmdutil.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		format, err := cmdutil.OutputFormat(cmd)
+		if err != nil {
+			return err
+		}
+
+		if opts.regex && opts.fuzzy {
+			return errors.New("regex and fuzzy matching can't be used at the same time")
+		}
+
+		match, err := newMatcher(strings.Join(args, " "), opts.regex, opts.fuzzy)
+		if err != nil {
+			return err
+		}
+
+		results, err := search(db, match, opts)
+		if err != nil {
+			return err
+		}
+
+		if format != cmdutil.OutputDefault {
+			return cmdutil.WriteOutput(cmd.OutOrStdout(), format, searchOutput(results))
+		}
+
+		if len(results) == 0 {
+			return errors.New("no matches were found")
+		}
+
+		printResults(cmd.OutOrStdout(), results, isTerminal(cmd.OutOrStdout()))
+		return nil
+	}
+}
+
+func newMatcher(query string, regex, fuzzy bool) (matcher, error) {
+	if fuzzy {
+		return fuzzyMatcher(query), nil
+	}
+
+	if !regex {
+		query = regexp.QuoteMeta(query)
+	}
+	re, err := regexp.Compile("(?i)" + query)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid regular expression")
+	}
+
+	return func(s string) [][2]int {
+		var ranges [][2]int
+		for _, loc := range re.FindAllStringIndex(s, -1) {
+			// Skip empty matches, they can't be highlighted
+			if loc[0] != loc[1] {
+				ranges = append(ranges, [2]int{loc[0], loc[1]})
+			}
+		}
+		return ranges
+	}, nil
+}
+
+// fuzzyMatcher matches the strings that contain all the query characters in the same order.
+func fuzzyMatcher(query string) matcher {
+	q := []rune(strings.ToLower(strings.Join(strings.Fields(query), "")))
+	return func(s string) [][2]int {
+		var ranges [][2]int
+		i := 0
+		for pos, r := range s {
+			if i == len(q) {
+				break
+			}
+			if unicode.ToLower(r) != q[i] {
+				continue
+			}
+
+			end := pos + utf8.RuneLen(r)
+			if n := len(ranges); n > 0 && ranges[n-1][1] == pos {
+				ranges[n-1][1] = end
+			} else {
+				ranges = append(ranges, [2]int{pos, end})
+			}
+			i++
+		}
+
+		if i < len(q) {
+			return nil
+		}
+		return ranges
+	}
+}
+
+func search(db *bolt.DB, match matcher, opts *searchOptions) ([]result, error) {
+	types := make([]string, 0, len(opts.types))
+	for _, t := range opts.types {
+		switch typ := strings.ToLower(strings.TrimSpace(t)); typ {
+		case entryType, cardType, fileType, totpType:
+			types = append(types, typ)
+		default:
+			return nil, errors.Errorf("invalid type %q, use entry, card, file or totp", t)
+		}
+	}
+
+	s := &searcher{match: match}
+	for _, t := range types {
+		switch t {
+		case entryType:
+			entries, err := entry.List(db)
+			if err != nil {
+				return nil, err
+			}
+			for _, e := range entries {
+				s.field(entryType, e.Name, "name", e.Name)
+				s.field(entryType, e.Name, "username", e.Username)
+				s.field(entryType, e.Name, "url", e.URL)
+				s.field(entryType, e.Name, "notes", e.Notes)
+			}
+
+		case cardType:
+			cards, err := card.List(db)
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range cards {
+				s.field(cardType, c.Name, "name", c.Name)
+				s.field(cardType, c.Name, "type", c.Type)
+				if opts.cardNumbers {
+					s.field(cardType, c.Name, "number", c.Number)
+					s.field(cardType, c.Name, "security_code", c.SecurityCode)
+				}
+				s.field(cardType, c.Name, "expire_date", c.ExpireDate)
+				s.field(cardType, c.Name, "notes", c.Notes)
+			}
+
+		case fileType:
+			files, err := file.List(db)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range files {
+				s.field(fileType, f.Name, "name", f.Name)
+				if f.Size <= opts.maxSize && isText(f.Content) {
+					s.field(fileType, f.Name, "content", string(f.Content))
+				}
+			}
+
+		case totpType:
+			names, err := totp.ListNames(db)
+			if err != nil {
+				return nil, err
+			}
+			for _, name := range names {
+				s.field(totpType, name, "name", name)
+			}
+		}
+	}
+
+	return s.results, nil
+}
+
+type searcher struct {
+	match   matcher
+	results []result
+}
+
+// field looks for matches in every line of the value.
+func (s *searcher) field(typ, name, field, value string) {
+	for i, line := range strings.Split(value, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if ranges := s.match(line); ranges != nil {
+			s.results = append(s.results, result{
+				typ:    typ,
+				name:   name,
+				field:  field,
+				line:   i + 1,
+				value:  line,
+				ranges: ranges,
+			})
+		}
+	}
+}
+
+// isText returns whether the content is valid UTF-8 text with no null bytes.
+func isText(content []byte) bool {
+	return utf8.Valid(content) && !strings.ContainsRune(string(content), 0)
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+func printResults(w io.Writer, results []result, color bool) {
+	for _, r := range results {
+		field := r.field
+		// Only notes and contents can have multiple lines
+		if field == "notes" || field == "content" {
+			field = fmt.Sprintf("%s:%d", r.field, r.line)
+		}
+
+		value := r.value
+		if color {
+			value = highlight(r.value, r.ranges)
+		}
+		fmt.Fprintf(w, "%s %s [%s] %s\n", r.typ, r.name, field, value)
+	}
+}
+
+// highlight surrounds the matches with terminal escape sequences to display them in bold and underlined.
+func highlight(s string, ranges [][2]int) string {
+	var sb strings.Builder
+	last := 0
+	for _, rg := range ranges {
+		sb.WriteString(s[last:rg[0]])
+		sb.WriteString("\x1b[1;4m")
+		sb.WriteString(s[rg[0]:rg[1]])
+		sb.WriteString("\x1b[0m")
+		last = rg[1]
+	}
+	sb.WriteString(s[last:])
+	return sb.String()
+}
+
+func searchOutput(results []result) *cmdutil.SearchOutput {
+	out := &cmdutil.SearchOutput{Matches: make([]cmdutil.SearchMatch, 0, len(results))}
+	for _, r := range results {
+		ranges := make([][]int, 0, len(r.ranges))
+		for _, rg := range r.ranges {
+			ranges = append(ranges, []int{rg[0], rg[1]})
+		}
+		out.Matches = append(out.Matches, cmdutil.SearchMatch{
+			Type:   r.typ,
+			Name:   r.name,
+			Field:  r.field,
+			Line:   r.line,
+			Value:  r.value,
+			Ranges: ranges,
+		})
+	}
+	return out
+}
@@ -0,0 +1,207 @@
+package search
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/card"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/db/file"
+	"github.com/GGP1/kure/db/totp"
+	"github.com/GGP1/kure/pb"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestSearch(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+	createRecords(t, db)
+
+	cases := []struct {
+		desc     string
+		args     []string
+		expected string
+	}{
+		{
+			desc:     "Substring",
+			args:     []string{"JOHN"},
+			expected: "entry github [username] john@example.com\n",
+		},
+		{
+			desc:     "Notes line",
+			args:     []string{"recovery"},
+			expected: "entry github [notes:2] Recovery codes in the safe\nfile codes.txt [content:1] recovery: 1234-5678\n",
+		},
+		{
+			desc:     "Type filter",
+			args:     []string{"recovery", "-t", "file"},
+			expected: "file codes.txt [content:1] recovery: 1234-5678\n",
+		},
+		{
+			desc:     "Regex",
+			args:     []string{"^git", "-r"},
+			expected: "entry github [name] github\nentry github [url] github.com\ntotp github [name] github\n",
+		},
+		{
+			desc:     "Fuzzy",
+			args:     []string{"gthb", "-z", "-t", "totp"},
+			expected: "totp github [name] github\n",
+		},
+		{
+			desc:     "Card numbers hidden",
+			args:     []string{"4242", "-t", "card,file"},
+			expected: "",
+		},
+		{
+			desc:     "Card numbers",
+			args:     []string{"4242", "-t", "card", "--card-numbers"},
+			expected: "card visa [number] 4242424242424242\n",
+		},
+		{
+			desc:     "File size limit",
+			args:     []string{"1234", "--max-size", "5"},
+			expected: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			cmd := NewCmd(db)
+			cmd.SetOut(buf)
+			cmd.SetArgs(tc.args)
+
+			err := cmd.Execute()
+			if tc.expected == "" {
+				if err == nil {
+					t.Errorf("Expected no matches, got %q", buf.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := buf.String(); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestSearchErrors(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+	cases := []struct {
+		desc string
+		args []string
+	}{
+		{
+			desc: "No query",
+			args: []string{},
+		},
+		{
+			desc: "Invalid type",
+			args: []string{"github", "-t", "entry,wifi"},
+		},
+		{
+			desc: "Invalid regex",
+			args: []string{"[git", "-r"},
+		},
+		{
+			desc: "Regex and fuzzy",
+			args: []string{"git", "-r", "-z"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cmd := NewCmd(db)
+			cmd.SetArgs(tc.args)
+			if err := cmd.Execute(); err == nil {
+				t.Error("Expected an error and got nil")
+			}
+		})
+	}
+}
+
+func TestSearchOutput(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+	createRecords(t, db)
+
+	buf := new(bytes.Buffer)
+	cmd := NewCmd(db)
+	cmdutil.AddOutputFlag(cmd)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"example", "--output", "table"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "type\tname\tfield\tline\tvalue\nentry\tgithub\tusername\t1\tjohn@example.com\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestFuzzyMatcher(t *testing.T) {
+	cases := []struct {
+		query    string
+		s        string
+		expected [][2]int
+	}{
+		{query: "gthb", s: "github", expected: [][2]int{{0, 1}, {2, 4}, {5, 6}}},
+		{query: "GH", s: "github", expected: [][2]int{{0, 1}, {3, 4}}},
+		{query: "ñd", s: "Ñandú", expected: [][2]int{{0, 2}, {4, 5}}},
+		{query: "bg", s: "github", expected: nil},
+	}
+
+	for _, tc := range cases {
+		got := fuzzyMatcher(tc.query)(tc.s)
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("%q in %q: expected %v, got %v", tc.query, tc.s, tc.expected, got)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	got := highlight("github", [][2]int{{0, 3}, {5, 6}})
+	expected := "\x1b[1;4mgit\x1b[0mhu\x1b[1;4mb\x1b[0m"
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func createRecords(t *testing.T, db *bolt.DB) {
+	t.Helper()
+
+	e := &pb.Entry{
+		Name:     "github",
+		Username: "john@example.com",
+		Password: "john1234",
+		URL:      "github.com",
+		Notes:    "Personal account\nRecovery codes in the safe",
+		Expires:  "Never",
+	}
+	if err := entry.Create(db, e); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &pb.Card{Name: "visa", Type: "Credit", Number: "4242424242424242", SecurityCode: "123"}
+	if err := card.Create(db, c); err != nil {
+		t.Fatal(err)
+	}
+
+	content := []byte("recovery: 1234-5678")
+	f := &pb.File{Name: "codes.txt", Content: content, Size: int64(len(content))}
+	if err := file.Create(db, f); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := totp.Create(db, &pb.TOTP{Name: "github", Raw: "IFGEWRKSIFJUMR2R", Digits: 6}); err != nil {
+		t.Fatal(err)
+	}
+}
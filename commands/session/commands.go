@@ -7,13 +7,20 @@ import (
 	"time"
 
 	"github.com/GGP1/kure/sig"
+
+	bolt "go.etcd.io/bbolt"
 )
 
 // params contains all commands' required parameters.
 type params struct {
-	in, out, outErr io.ReadWriter
+	in          io.Reader
+	out, outErr io.Writer
 
+	db      *bolt.DB
 	timeout *timeout
+	idle    *idle
+	editor  lineEditor
+	folder  *folder
 	args    []string
 }
 
@@ -24,12 +31,70 @@ var commands = map[string]func(params){
 		dump := ""
 		fmt.Fscanln(p.in, &dump)
 	},
+	"cd": func(p params) {
+		if len(p.args) > 1 {
+			fmt.Fprintln(p.outErr, "error: invalid arguments, use cd [folder]")
+			return
+		}
+
+		name := ""
+		if len(p.args) == 1 {
+			name = p.args[0]
+		}
+		if err := p.folder.cd(p.db, name); err != nil {
+			fmt.Fprintln(p.outErr, "error:", err)
+		}
+	},
 	"exit": func(_ params) {
 		sig.Signal.Kill()
 	},
 	"quit": func(_ params) {
 		sig.Signal.Kill()
 	},
+	"history": func(p params) {
+		if len(p.args) < 1 || p.args[0] != "-c" {
+			fmt.Fprintln(p.outErr, "error: invalid arguments, use history -c to clear it")
+			return
+		}
+		p.editor.ClearHistory()
+	},
+	"idle": func(p params) {
+		printIdle(p.out, p.idle.duration())
+	},
+	"idleadd": func(p params) {
+		if len(p.args) < 1 {
+			fmt.Fprintln(p.outErr, "error: invalid duration, use idleadd [duration]")
+			return
+		}
+
+		d, err := time.ParseDuration(p.args[0])
+		if err != nil {
+			fmt.Fprintf(p.outErr, "error: invalid duration %q\n", p.args[0])
+			return
+		}
+
+		p.idle.set(p.idle.duration() + d)
+		printIdle(p.out, p.idle.duration())
+	},
+	"idleset": func(p params) {
+		if len(p.args) < 1 {
+			fmt.Fprintln(p.outErr, "error: invalid duration, use idleset [duration]")
+			return
+		}
+
+		d, err := time.ParseDuration(p.args[0])
+		if err != nil {
+			fmt.Fprintf(p.outErr, "error: invalid duration %q\n", p.args[0])
+			return
+		}
+
+		p.idle.set(d)
+		printIdle(p.out, p.idle.duration())
+	},
+	"lock": func(p params) {
+		lock()
+		fmt.Fprintln(p.out, "Session locked")
+	},
 	"pwd": func(p params) {
 		dir, _ := os.Getwd()
 		fmt.Fprintln(p.out, dir)
@@ -105,7 +170,7 @@ var commands = map[string]func(params){
 
 // sessionCommand checks for any session command and returns a boolean representing
 // a "continue" in the loop where it was called.
-func sessionCommand(args []string, timeout *timeout) bool {
+func sessionCommand(args []string, s *state) bool {
 	// The arguments length will be zero only if the user input is "kure"
 	if len(args) == 0 {
 		return false
@@ -117,12 +182,24 @@ func sessionCommand(args []string, timeout *timeout) bool {
 	}
 
 	cmd(params{
-		in:      os.Stdin,
-		out:     os.Stdout,
-		outErr:  os.Stderr,
+		in:      s.executor.In(),
+		out:     s.executor.Out(),
+		outErr:  s.executor.Err(),
 		args:    args[1:],
-		timeout: timeout,
+		db:      s.db,
+		timeout: s.timeout,
+		idle:    s.idle,
+		editor:  s.editor,
+		folder:  s.folder,
 	})
 
 	return true
 }
+
+func printIdle(w io.Writer, d time.Duration) {
+	if d == 0 {
+		fmt.Fprintln(w, "The session has no idle timeout.")
+		return
+	}
+	fmt.Fprintln(w, "Idle timeout:", d)
+}
@@ -2,6 +2,7 @@ package session
 
 import (
 	"bytes"
+	"io"
 	"os"
 	"testing"
 	"time"
@@ -32,7 +33,7 @@ func TestSessionCommand(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
-			cont := sessionCommand(tc.args, &timeout{})
+			cont := sessionCommand(tc.args, testState(nil, new(bytes.Buffer), new(bytes.Buffer)))
 			if cont != tc.cont {
 				t.Errorf("Expected %v, got %v", tc.cont, cont)
 			}
@@ -47,6 +48,8 @@ func TestCommands(t *testing.T) {
 		desc        string
 		args        []string
 		timeout     *timeout
+		idle        *idle
+		editor      lineEditor
 		input       string
 		expectedOut string
 		expectedErr string
@@ -75,6 +78,61 @@ func TestCommands(t *testing.T) {
 			args:        []string{"sleep", "1", "ns"},
 			expectedErr: "error: invalid duration \"1\"\n",
 		},
+		{
+			desc:   "history",
+			args:   []string{"history", "-c"},
+			editor: &plainEditor{writer: io.Discard},
+		},
+		{
+			desc:        "history invalid arguments",
+			args:        []string{"history"},
+			expectedErr: "error: invalid arguments, use history -c to clear it\n",
+		},
+		{
+			desc:        "idle",
+			args:        []string{"idle"},
+			idle:        newIdle(5*time.Minute, nil),
+			expectedOut: "Idle timeout: 5m0s\n",
+		},
+		{
+			desc:        "No idle timeout",
+			args:        []string{"idle"},
+			idle:        newIdle(0, nil),
+			expectedOut: "The session has no idle timeout.\n",
+		},
+		{
+			desc:        "idleadd",
+			args:        []string{"idleadd", "1m"},
+			idle:        newIdle(5*time.Minute, nil),
+			expectedOut: "Idle timeout: 6m0s\n",
+		},
+		{
+			desc:        "idleadd disable",
+			args:        []string{"idleadd", "-10m"},
+			idle:        newIdle(5*time.Minute, nil),
+			expectedOut: "The session has no idle timeout.\n",
+		},
+		{
+			desc:        "idleadd invalid duration",
+			args:        []string{"idleadd", "m"},
+			expectedErr: "error: invalid duration \"m\"\n",
+		},
+		{
+			desc:        "idleset",
+			args:        []string{"idleset", "30s"},
+			idle:        newIdle(0, nil),
+			expectedOut: "Idle timeout: 30s\n",
+		},
+		{
+			desc:        "idleset no duration",
+			args:        []string{"idleset"},
+			expectedErr: "error: invalid duration, use idleset [duration]\n",
+		},
+		{
+			desc:        "lock",
+			args:        []string{"lock"},
+			expectedOut: "Session locked\n",
+		},
 		{
 			desc:        "pwd",
 			args:        []string{"pwd"},
@@ -134,6 +192,8 @@ func TestCommands(t *testing.T) {
 				outErr:  outErr,
 				args:    tc.args[1:],
 				timeout: tc.timeout,
+				idle:    tc.idle,
+				editor:  tc.editor,
 			}
 
 			cmd(params)
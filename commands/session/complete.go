@@ -0,0 +1,217 @@
+package session
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/GGP1/kure/db/card"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/db/file"
+	"github.com/GGP1/kure/db/totp"
+	"github.com/GGP1/kure/db/wifi"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	bolt "go.etcd.io/bbolt"
+)
+
+// recordLists maps the commands that take record names to the function listing them.
+var recordLists = map[string]func(*bolt.DB) ([]string, error){
+	"2fa":  totp.ListNames,
+	"card": card.ListNames,
+	"copy": entry.ListNames,
+	"edit": entry.ListNames,
+	"file": file.ListNames,
+	"ls":   entry.ListNames,
+	"rm":   entry.ListNames,
+	"wifi": wifi.ListNames,
+}
+
+// completer looks for the candidates to complete the word under the cursor.
+type completer struct {
+	root    *cobra.Command
+	db      *bolt.DB
+	folder  *folder
+	scripts map[string]string
+}
+
+// complete returns the line with the word under the cursor completed as much as possible
+// and the candidates found.
+func (c *completer) complete(line string, pos int) (string, int, []string) {
+	head := line[:pos]
+	// Complete only the last command of a sequence
+	for _, sep := range []string{"&&", "||", ";"} {
+		if i := strings.LastIndex(head, sep); i != -1 {
+			head = head[i+len(sep):]
+		}
+	}
+
+	words := strings.Fields(head)
+	current := ""
+	if len(words) > 0 && !strings.HasSuffix(head, " ") {
+		current = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+	if len(words) > 0 && words[0] == "kure" {
+		words = words[1:]
+	}
+
+	prefix := strings.TrimPrefix(current, quote)
+	candidates := filterPrefix(c.candidates(words, prefix), prefix)
+	if len(candidates) == 0 {
+		return line, pos, nil
+	}
+
+	completion := commonPrefix(candidates)
+	if len(candidates) == 1 {
+		if strings.Contains(completion, " ") {
+			completion = quote + completion + quote
+		}
+		completion += " "
+	} else if strings.HasPrefix(current, quote) {
+		completion = quote + completion
+	}
+
+	start := pos - len(current)
+	newLine := line[:start] + completion + line[pos:]
+	return newLine, start + len(completion), candidates
+}
+
+// candidates returns all the words that could follow the ones passed.
+func (c *completer) candidates(words []string, current string) []string {
+	if len(words) == 0 {
+		candidates := subcommands(c.root)
+		for name := range commands {
+			if name != "" {
+				candidates = append(candidates, name)
+			}
+		}
+		for alias := range c.scripts {
+			candidates = append(candidates, alias)
+		}
+		return candidates
+	}
+
+	if words[0] == "cd" {
+		return c.folders(current)
+	}
+
+	cmd, _, err := c.root.Find(words)
+	if err != nil || cmd == c.root {
+		return nil
+	}
+
+	if strings.HasPrefix(current, "-") {
+		return flags(cmd)
+	}
+
+	candidates := subcommands(cmd)
+	path := strings.Fields(cmd.CommandPath())
+	// Record names are listed only after the master password is entered
+	if listNames, ok := recordLists[path[1]]; ok && c.db != nil && !isLocked() {
+		names, err := listNames(c.db)
+		if err == nil {
+			candidates = append(candidates, c.relativeNames(names, current)...)
+		}
+	}
+	return candidates
+}
+
+// relativeNames returns the names relative to the current folder, or absolute if the word
+// being completed starts with a slash.
+func (c *completer) relativeNames(names []string, current string) []string {
+	if strings.HasPrefix(current, "/") {
+		absolute := make([]string, len(names))
+		for i, name := range names {
+			absolute[i] = "/" + name
+		}
+		return absolute
+	}
+	if c.folder == nil || c.folder.path == "" {
+		return names
+	}
+
+	prefix := c.folder.path + "/"
+	relative := make([]string, 0, len(names))
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) {
+			relative = append(relative, strings.TrimPrefix(name, prefix))
+		}
+	}
+	return relative
+}
+
+// folders returns the folders that contain entries.
+func (c *completer) folders(current string) []string {
+	if c.db == nil || isLocked() {
+		return nil
+	}
+	names, err := entry.ListNames(c.db)
+	if err != nil {
+		return nil
+	}
+
+	var folders []string
+	for _, name := range c.relativeNames(names, current) {
+		// Include every level so nested folders can be completed
+		for i, r := range name {
+			if r == '/' && i > 0 {
+				folders = append(folders, name[:i+1])
+			}
+		}
+	}
+	return folders
+}
+
+func subcommands(cmd *cobra.Command) []string {
+	names := make([]string, 0, len(cmd.Commands()))
+	for _, c := range cmd.Commands() {
+		if c.IsAvailableCommand() {
+			names = append(names, c.Name())
+		}
+	}
+	return names
+}
+
+func flags(cmd *cobra.Command) []string {
+	var names []string
+	add := func(f *pflag.Flag) {
+		if f.Hidden {
+			return
+		}
+		names = append(names, "--"+f.Name)
+		if f.Shorthand != "" {
+			names = append(names, "-"+f.Shorthand)
+		}
+	}
+	cmd.NonInheritedFlags().VisitAll(add)
+	cmd.InheritedFlags().VisitAll(add)
+	return names
+}
+
+// filterPrefix returns the unique words that start with prefix, sorted.
+func filterPrefix(words []string, prefix string) []string {
+	seen := make(map[string]struct{}, len(words))
+	filtered := make([]string, 0, len(words))
+	for _, w := range words {
+		if _, ok := seen[w]; ok || !strings.HasPrefix(w, prefix) {
+			continue
+		}
+		seen[w] = struct{}{}
+		filtered = append(filtered, w)
+	}
+	sort.Strings(filtered)
+	return filtered
+}
+
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, w := range words[1:] {
+		for !strings.HasPrefix(w, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
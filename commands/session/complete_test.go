@@ -0,0 +1,249 @@
+package session
+
+import (
+	"reflect"
+	"testing"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/db/totp"
+	"github.com/GGP1/kure/pb"
+
+	"github.com/spf13/cobra"
+)
+
+func TestComplete(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+	for _, name := range []string{"github", "gitlab", "google/mail", "my entry"} {
+		if err := entry.Create(db, &pb.Entry{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := totp.Create(db, &pb.TOTP{Name: "twitter"}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &completer{
+		root:    mockRoot(),
+		db:      db,
+		scripts: map[string]string{"login": "copy $1"},
+	}
+
+	cases := []struct {
+		desc       string
+		line       string
+		expected   string
+		candidates []string
+	}{
+		{
+			desc:       "Command",
+			line:       "cop",
+			expected:   "copy ",
+			candidates: []string{"copy"},
+		},
+		{
+			desc:       "Script alias",
+			line:       "log",
+			expected:   "login ",
+			candidates: []string{"login"},
+		},
+		{
+			desc:       "Session command",
+			line:       "idles",
+			expected:   "idleset ",
+			candidates: []string{"idleset"},
+		},
+		{
+			desc:       "Common prefix",
+			line:       "copy g",
+			expected:   "copy g",
+			candidates: []string{"github", "gitlab", "google/mail"},
+		},
+		{
+			desc:       "Partial common prefix",
+			line:       "copy gi",
+			expected:   "copy git",
+			candidates: []string{"github", "gitlab"},
+		},
+		{
+			desc:       "Record name",
+			line:       "copy gith",
+			expected:   "copy github ",
+			candidates: []string{"github"},
+		},
+		{
+			desc:       "Record name with spaces",
+			line:       "copy my",
+			expected:   `copy "my entry" `,
+			candidates: []string{"my entry"},
+		},
+		{
+			desc:       "TOTP name",
+			line:       "2fa tw",
+			expected:   "2fa twitter ",
+			candidates: []string{"twitter"},
+		},
+		{
+			desc:       "Subcommand",
+			line:       "file c",
+			expected:   "file cat ",
+			candidates: []string{"cat"},
+		},
+		{
+			desc:       "Flags",
+			line:       "copy github -",
+			expected:   "copy github -",
+			candidates: []string{"--timeout", "--username", "-t", "-u"},
+		},
+		{
+			desc:       "Long flag",
+			line:       "copy github --user",
+			expected:   "copy github --username ",
+			candidates: []string{"--username"},
+		},
+		{
+			desc:       "Sequence",
+			line:       "ls && cop",
+			expected:   "ls && copy ",
+			candidates: []string{"copy"},
+		},
+		{
+			desc:       "Mixed operators",
+			line:       "ls && stats || ls; cop",
+			expected:   "ls && stats || ls; copy ",
+			candidates: []string{"copy"},
+		},
+		{
+			desc:       "Kure prefix",
+			line:       "kure cop",
+			expected:   "kure copy ",
+			candidates: []string{"copy"},
+		},
+		{
+			desc:     "No candidates",
+			line:     "copy x",
+			expected: "copy x",
+		},
+		{
+			desc:     "Unknown command",
+			line:     "jump g",
+			expected: "jump g",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, pos, candidates := c.complete(tc.line, len(tc.line))
+			if got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+			if pos != len(tc.expected) {
+				t.Errorf("Expected position %d, got %d", len(tc.expected), pos)
+			}
+			if !reflect.DeepEqual(candidates, tc.candidates) {
+				t.Errorf("Expected candidates %v, got %v", tc.candidates, candidates)
+			}
+		})
+	}
+}
+
+func TestCompleteLocked(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+	if err := entry.Create(db, &pb.Entry{Name: "work/github"}); err != nil {
+		t.Fatal(err)
+	}
+	lock()
+
+	c := &completer{root: mockRoot(), db: db}
+	for _, line := range []string{"copy w", "cd w"} {
+		if _, _, candidates := c.complete(line, len(line)); len(candidates) != 0 {
+			t.Errorf("%q: expected no candidates while locked, got %v", line, candidates)
+		}
+	}
+}
+
+func TestCompleteMiddle(t *testing.T) {
+	c := &completer{root: mockRoot()}
+
+	line := "cop github"
+	got, pos, _ := c.complete(line, 3)
+	expected := "copy  github"
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+	if pos != 5 {
+		t.Errorf("Expected position 5, got %d", pos)
+	}
+}
+
+func TestCompleteFolder(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+	for _, name := range []string{"github", "work/aws/prod", "work/gcp"} {
+		if err := entry.Create(db, &pb.Entry{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := &completer{root: mockRoot(), db: db, folder: &folder{path: "work"}}
+
+	cases := []struct {
+		desc       string
+		line       string
+		expected   string
+		candidates []string
+	}{
+		{
+			desc:       "Relative name",
+			line:       "copy g",
+			expected:   "copy gcp ",
+			candidates: []string{"gcp"},
+		},
+		{
+			desc:       "Absolute name",
+			line:       "copy /gi",
+			expected:   "copy /github ",
+			candidates: []string{"/github"},
+		},
+		{
+			desc:       "Folder",
+			line:       "cd a",
+			expected:   "cd aws/ ",
+			candidates: []string{"aws/"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, pos, candidates := c.complete(tc.line, len(tc.line))
+			if got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+			if pos != len(got) {
+				t.Errorf("Expected position %d, got %d", len(got), pos)
+			}
+			if !reflect.DeepEqual(candidates, tc.candidates) {
+				t.Errorf("Expected candidates %q, got %q", tc.candidates, candidates)
+			}
+		})
+	}
+}
+
+func mockRoot() *cobra.Command {
+	run := func(cmd *cobra.Command, args []string) {}
+	root := &cobra.Command{Use: "kure"}
+
+	copy := &cobra.Command{Use: "copy", Run: run}
+	copy.Flags().BoolP("username", "u", false, "")
+	copy.Flags().DurationP("timeout", "t", 0, "")
+
+	file := &cobra.Command{Use: "file"}
+	file.AddCommand(&cobra.Command{Use: "cat", Run: run}, &cobra.Command{Use: "add", Run: run})
+
+	tfaImport := &cobra.Command{Use: "import", Run: run}
+	tfaImport.Flags().StringP("path", "p", "", "")
+	tfa := &cobra.Command{Use: "2fa", Run: run}
+	tfa.AddCommand(tfaImport)
+
+	root.AddCommand(copy, file, tfa, &cobra.Command{Use: "ls", Run: run})
+	return root
+}
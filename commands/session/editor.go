@@ -0,0 +1,181 @@
+package session
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/GGP1/kure/sig"
+
+	"golang.org/x/term"
+)
+
+// lineEditor reads the session input line by line.
+type lineEditor interface {
+	// ReadLine prints the prompt and returns the line entered by the user.
+	ReadLine() (string, error)
+	// Notify prints a message without breaking the line being edited.
+	Notify(message string)
+	// ClearHistory removes all the lines from the history.
+	ClearHistory()
+	// SetPrompt changes the text printed before reading a line.
+	SetPrompt(prompt string)
+	// Close restores the terminal to its previous state.
+	Close() error
+}
+
+// newLineEditor returns a terminal line editor if r is a terminal and a plain reader otherwise,
+// the prompt and the notifications are written to w.
+func newLineEditor(r io.Reader, w io.Writer, prompt string, c *completer) lineEditor {
+	if f, ok := r.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		return newTerminalEditor(f, w, int(f.Fd()), prompt, c)
+	}
+	return &plainEditor{reader: bufio.NewReader(r), writer: w, prompt: prompt}
+}
+
+// terminalEditor supports cursor movement, history and tab completion.
+//
+// The history is kept in memory only and it's never written to disk.
+type terminalEditor struct {
+	rw        io.ReadWriter
+	completer *completer
+	t         *term.Terminal
+	oldState  *term.State
+	prompt    string
+	mu        sync.Mutex
+	// fd is the terminal file descriptor, it's -1 if rw isn't a terminal
+	fd int
+	// lastTab is used to show the candidates when tab is pressed twice
+	lastTab string
+}
+
+func newTerminalEditor(r io.Reader, w io.Writer, fd int, prompt string, c *completer) *terminalEditor {
+	e := &terminalEditor{
+		rw: struct {
+			io.Reader
+			io.Writer
+		}{r, w},
+		completer: c,
+		prompt:    prompt,
+		fd:        fd,
+	}
+	e.t = e.newTerminal()
+	if fd >= 0 {
+		// Restore the terminal in case the session is closed while reading
+		sig.Signal.AddCleanup(e.Close)
+	}
+	return e
+}
+
+func (e *terminalEditor) newTerminal() *term.Terminal {
+	t := term.NewTerminal(e.rw, e.prompt+" ")
+	t.AutoCompleteCallback = e.autoComplete
+	if e.fd >= 0 {
+		if width, height, err := term.GetSize(e.fd); err == nil {
+			t.SetSize(width, height)
+		}
+	}
+	return t
+}
+
+func (e *terminalEditor) ReadLine() (string, error) {
+	e.mu.Lock()
+	t := e.t
+	e.mu.Unlock()
+
+	if e.fd >= 0 {
+		oldState, err := term.MakeRaw(e.fd)
+		if err != nil {
+			return "", err
+		}
+		e.mu.Lock()
+		e.oldState = oldState
+		e.mu.Unlock()
+		defer e.Close()
+	}
+
+	return t.ReadLine()
+}
+
+func (e *terminalEditor) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.oldState == nil {
+		return nil
+	}
+	err := term.Restore(e.fd, e.oldState)
+	e.oldState = nil
+	return err
+}
+
+func (e *terminalEditor) Notify(message string) {
+	e.mu.Lock()
+	t := e.t
+	e.mu.Unlock()
+	t.Write([]byte(message))
+}
+
+func (e *terminalEditor) ClearHistory() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	// The terminal doesn't expose its history, replace it with a new one
+	e.t = e.newTerminal()
+}
+
+func (e *terminalEditor) SetPrompt(prompt string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.prompt = prompt
+	e.t.SetPrompt(prompt + " ")
+}
+
+// autoComplete completes the word under the cursor when tab is pressed, if there are
+// multiple candidates and tab is pressed twice they are listed.
+func (e *terminalEditor) autoComplete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' || e.completer == nil {
+		e.lastTab = ""
+		return "", 0, false
+	}
+
+	newLine, newPos, candidates := e.completer.complete(line, pos)
+	if len(candidates) > 1 && newLine == line {
+		if e.lastTab == line {
+			// Write once the terminal is released, it repaints the prompt and the line after the message
+			go e.Notify(strings.Join(candidates, "  ") + "\n")
+		}
+		e.lastTab = line
+	}
+
+	return newLine, newPos, true
+}
+
+// plainEditor is used when the input isn't a terminal.
+type plainEditor struct {
+	reader *bufio.Reader
+	writer io.Writer
+	prompt string
+}
+
+func (e *plainEditor) ReadLine() (string, error) {
+	fmt.Fprintf(e.writer, "%s ", e.prompt)
+	text, _, err := e.reader.ReadLine()
+	return string(text), err
+}
+
+func (e *plainEditor) Notify(message string) {
+	fmt.Fprintf(e.writer, "\n%s%s ", message, e.prompt)
+}
+
+func (e *plainEditor) ClearHistory() {}
+
+func (e *plainEditor) SetPrompt(prompt string) {
+	e.prompt = prompt
+}
+
+func (e *plainEditor) Close() error {
+	return nil
+}
@@ -0,0 +1,103 @@
+package session
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestTerminalEditor(t *testing.T) {
+	cases := []struct {
+		desc     string
+		input    string
+		expected string
+	}{
+		{
+			desc:     "Line",
+			input:    "ls -s\r",
+			expected: "ls -s",
+		},
+		{
+			desc:     "Cursor movement",
+			input:    "ls\x1b[D\x1b[Dxx\r", // Left arrow twice
+			expected: "xxls",
+		},
+		{
+			desc:     "Tab completion",
+			input:    "cop\tgithub\r",
+			expected: "copy github",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			c := &completer{root: mockRoot()}
+			e := newTerminalEditor(strings.NewReader(tc.input), new(bytes.Buffer), -1, "kure:~ $", c)
+
+			got, err := e.ReadLine()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestTerminalEditorHistory(t *testing.T) {
+	// The last line is read after clearing the history
+	input := &chunkReader{chunks: []string{"ls\r", "copy\r", "\x1b[A\x1b[A\r", "stats\r", "\x1b[A\r", "\x1b[A\r"}}
+	e := newTerminalEditor(input, new(bytes.Buffer), -1, "kure:~ $", nil)
+
+	expected := []string{"ls", "copy", "ls", "stats"}
+	for _, exp := range expected {
+		got, err := e.ReadLine()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != exp {
+			t.Errorf("Expected %q, got %q", exp, got)
+		}
+	}
+
+	e.ClearHistory()
+	got, err := e.ReadLine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "" {
+		t.Errorf("Expected the history to be empty, got %q", got)
+	}
+}
+
+func TestPlainEditor(t *testing.T) {
+	e := newLineEditor(strings.NewReader("ls\n"), io.Discard, "kure:~ $", nil)
+	if _, ok := e.(*plainEditor); !ok {
+		t.Fatalf("Expected a plain editor, got %T", e)
+	}
+
+	got, err := e.ReadLine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "ls" {
+		t.Errorf("Expected %q, got %q", "ls", got)
+	}
+}
+
+// chunkReader returns one chunk on every call to Read, like a terminal does with each line.
+type chunkReader struct {
+	chunks []string
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.chunks[0])
+	r.chunks = r.chunks[1:]
+	return n, nil
+}
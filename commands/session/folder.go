@@ -0,0 +1,234 @@
+package session
+
+import (
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/GGP1/kure/db/card"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/db/file"
+	"github.com/GGP1/kure/db/totp"
+	"github.com/GGP1/kure/db/wifi"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	bolt "go.etcd.io/bbolt"
+)
+
+// relativeCmds contains the commands whose record name arguments are resolved relative to
+// the current folder.
+var relativeCmds = map[string]struct{}{
+	"2fa":  {},
+	"copy": {},
+	"edit": {},
+	"file": {},
+	"ls":   {},
+	"rm":   {},
+}
+
+// multiNameCmds contains the commands that take multiple record names, the others join
+// their arguments into a single name.
+var multiNameCmds = map[string]struct{}{
+	"file cat":   {},
+	"file mv":    {},
+	"file touch": {},
+}
+
+// nameless contains the subcommands of relative commands whose arguments aren't record names.
+var nameless = map[string]struct{}{
+	"2fa import": {},
+}
+
+// folder is the session current folder.
+type folder struct {
+	editor lineEditor
+	// prefix is the text that precedes the commands, "~" is replaced with the folder path
+	prefix string
+	// path is empty at the root and doesn't contain leading or trailing slashes
+	path string
+}
+
+// cd changes the current folder, an empty name or "~" moves to the root.
+func (f *folder) cd(db *bolt.DB, name string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "~" {
+		name = "/"
+	}
+
+	p := strings.TrimSuffix(joinPath(f.path, name), "/")
+	if name == "" {
+		p = ""
+	}
+	if p != "" {
+		exists, err := folderExists(db, p)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return errors.Errorf("folder %q does not exist", p)
+		}
+	}
+
+	f.path = p
+	if f.editor != nil {
+		f.editor.SetPrompt(f.prompt())
+	}
+	return nil
+}
+
+// prompt returns the prefix with the current folder.
+func (f *folder) prompt() string {
+	if f.path == "" {
+		return f.prefix
+	}
+	return strings.Replace(f.prefix, "~", f.path, 1)
+}
+
+// resolve returns the arguments with the record names relative to the current folder.
+//
+// Names starting with a slash are absolute.
+func (f *folder) resolve(root *cobra.Command, args []string) []string {
+	if f == nil || f.path == "" || len(args) == 0 {
+		return args
+	}
+	if _, ok := relativeCmds[args[0]]; !ok {
+		return args
+	}
+
+	resolved := make([]string, len(args))
+	copy(resolved, args)
+
+	cmd := root
+	var cmdPath []string
+	positional := 0
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "--" {
+			for j := i + 1; j < len(args); j++ {
+				resolved[j] = f.resolveName(cmdPath, positional, args[j])
+				positional++
+			}
+			break
+		}
+
+		if strings.HasPrefix(arg, "-") && len(arg) > 1 {
+			if takesValue(cmd, arg) {
+				i++ // Skip the flag value
+			}
+			continue
+		}
+
+		if positional == 0 {
+			if sub := subcommand(cmd, arg); sub != nil {
+				cmd = sub
+				cmdPath = append(cmdPath, sub.Name())
+				continue
+			}
+		}
+
+		resolved[i] = f.resolveName(cmdPath, positional, arg)
+		positional++
+	}
+
+	if _, ok := nameless[strings.Join(cmdPath, " ")]; ok {
+		return args
+	}
+
+	if positional == 0 && strings.Join(cmdPath, " ") == "ls" {
+		// List the current folder
+		resolved = append(resolved, f.path)
+	}
+
+	return resolved
+}
+
+func (f *folder) resolveName(cmdPath []string, positional int, name string) string {
+	if _, ok := multiNameCmds[strings.Join(cmdPath, " ")]; !ok && positional > 0 {
+		// Only the first argument is part of the name when it's split by spaces
+		return name
+	}
+	return joinPath(f.path, name)
+}
+
+// joinPath returns the name relative to dir unless it starts with a slash. The
+// result doesn't go above the root and has no leading slash.
+func joinPath(dir, name string) string {
+	if strings.HasPrefix(name, "/") {
+		dir = ""
+	}
+
+	p := strings.TrimPrefix(path.Join("/", dir, name), "/")
+	if strings.HasSuffix(name, "/") && p != "" {
+		p += "/"
+	}
+	return p
+}
+
+// folderExists returns whether there is any record inside the folder.
+func folderExists(db *bolt.DB, folder string) (bool, error) {
+	prefix := folder + "/"
+	for _, listNames := range []func(*bolt.DB) ([]string, error){
+		entry.ListNames, card.ListNames, file.ListNames, totp.ListNames, wifi.ListNames,
+	} {
+		names, err := listNames(db)
+		if err != nil {
+			return false, err
+		}
+		for _, name := range names {
+			if strings.HasPrefix(name, prefix) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// subcommand returns the cmd subcommand with the name or alias passed, nil if it doesn't exist.
+func subcommand(cmd *cobra.Command, name string) *cobra.Command {
+	for _, c := range cmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// takesValue returns whether the flag argument is followed by its value.
+func takesValue(cmd *cobra.Command, arg string) bool {
+	lookup := func(name string, short bool) *pflag.Flag {
+		if short {
+			if f := cmd.Flags().ShorthandLookup(name); f != nil {
+				return f
+			}
+			return cmd.InheritedFlags().ShorthandLookup(name)
+		}
+		if f := cmd.Flags().Lookup(name); f != nil {
+			return f
+		}
+		return cmd.InheritedFlags().Lookup(name)
+	}
+
+	if strings.HasPrefix(arg, "--") {
+		if strings.Contains(arg, "=") {
+			return false
+		}
+		f := lookup(arg[2:], false)
+		return f != nil && f.NoOptDefVal == ""
+	}
+
+	// Shorthands may be combined (-abc), a flag with a value takes the rest of the argument
+	shorthands := arg[1:]
+	for i, r := range shorthands {
+		if r >= utf8.RuneSelf {
+			return false
+		}
+		f := lookup(string(r), true)
+		if f != nil && f.NoOptDefVal == "" {
+			return i == len(shorthands)-1
+		}
+	}
+	return false
+}
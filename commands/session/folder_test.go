@@ -0,0 +1,167 @@
+package session
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/pb"
+)
+
+func TestJoinPath(t *testing.T) {
+	cases := []struct {
+		dir      string
+		name     string
+		expected string
+	}{
+		{dir: "", name: "github", expected: "github"},
+		{dir: "work", name: "github", expected: "work/github"},
+		{dir: "work/aws", name: "../gcp", expected: "work/gcp"},
+		{dir: "work", name: "../../..", expected: ""},
+		{dir: "work", name: "/personal/github", expected: "personal/github"},
+		{dir: "work", name: "./aws/", expected: "work/aws/"},
+		{dir: "work", name: "", expected: "work"},
+	}
+
+	for _, tc := range cases {
+		if got := joinPath(tc.dir, tc.name); got != tc.expected {
+			t.Errorf("joinPath(%q, %q): expected %q, got %q", tc.dir, tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	f := &folder{path: "work"}
+	root := mockRoot()
+
+	cases := []struct {
+		desc     string
+		args     []string
+		expected []string
+	}{
+		{
+			desc:     "Name",
+			args:     []string{"copy", "github"},
+			expected: []string{"copy", "work/github"},
+		},
+		{
+			desc:     "Absolute name",
+			args:     []string{"copy", "/github"},
+			expected: []string{"copy", "github"},
+		},
+		{
+			desc:     "Flags",
+			args:     []string{"copy", "-t", "5s", "-u", "github"},
+			expected: []string{"copy", "-t", "5s", "-u", "work/github"},
+		},
+		{
+			desc:     "Combined shorthands",
+			args:     []string{"copy", "-ut", "5s", "github"},
+			expected: []string{"copy", "-ut", "5s", "work/github"},
+		},
+		{
+			desc:     "Flag with equal sign",
+			args:     []string{"copy", "--timeout=5s", "github"},
+			expected: []string{"copy", "--timeout=5s", "work/github"},
+		},
+		{
+			desc:     "Name with spaces",
+			args:     []string{"copy", "my", "entry"},
+			expected: []string{"copy", "work/my", "entry"},
+		},
+		{
+			desc:     "Multiple names",
+			args:     []string{"file", "cat", "a.txt", "../b.txt"},
+			expected: []string{"file", "cat", "work/a.txt", "b.txt"},
+		},
+		{
+			desc:     "Subcommand",
+			args:     []string{"file", "add", "a.txt"},
+			expected: []string{"file", "add", "work/a.txt"},
+		},
+		{
+			desc:     "2FA name",
+			args:     []string{"2fa", "github"},
+			expected: []string{"2fa", "work/github"},
+		},
+		{
+			desc:     "Arguments that aren't names",
+			args:     []string{"2fa", "import", "aegis", "-p", "x.json"},
+			expected: []string{"2fa", "import", "aegis", "-p", "x.json"},
+		},
+		{
+			desc:     "List current folder",
+			args:     []string{"ls"},
+			expected: []string{"ls", "work"},
+		},
+		{
+			desc:     "Unsupported command",
+			args:     []string{"stats", "github"},
+			expected: []string{"stats", "github"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := f.resolve(root, tc.args)
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+
+	t.Run("Root", func(t *testing.T) {
+		args := []string{"copy", "github"}
+		got := (&folder{}).resolve(root, args)
+		if !reflect.DeepEqual(got, args) {
+			t.Errorf("Expected %q, got %q", args, got)
+		}
+	})
+}
+
+func TestCd(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+	if err := entry.Create(db, &pb.Entry{Name: "work/aws/prod"}, &pb.Entry{Name: "work/gcp"}); err != nil {
+		t.Fatal(err)
+	}
+
+	editor := &plainEditor{writer: io.Discard}
+	f := &folder{prefix: "kure:~ $", editor: editor}
+
+	steps := []struct {
+		name     string
+		expected string
+		prompt   string
+		fail     bool
+	}{
+		{name: "work", expected: "work", prompt: "kure:work $"},
+		{name: "AWS/", expected: "work/aws", prompt: "kure:work/aws $"},
+		{name: "../gcp", expected: "work/aws", fail: true},
+		{name: "..", expected: "work", prompt: "kure:work $"},
+		{name: "/work/aws", expected: "work/aws", prompt: "kure:work/aws $"},
+		{name: "non-existent", expected: "work/aws", fail: true},
+		{name: "/work", expected: "work", prompt: "kure:work $"},
+		{name: "~", expected: "", prompt: "kure:~ $"},
+		{name: "work", expected: "work", prompt: "kure:work $"},
+		{name: "", expected: "", prompt: "kure:~ $"},
+	}
+
+	for _, s := range steps {
+		err := f.cd(db, s.name)
+		if s.fail && err == nil {
+			t.Errorf("cd %q: expected an error and got nil", s.name)
+		}
+		if !s.fail && err != nil {
+			t.Errorf("cd %q: %v", s.name, err)
+		}
+
+		if f.path != s.expected {
+			t.Errorf("cd %q: expected path %q, got %q", s.name, s.expected, f.path)
+		}
+		if !s.fail && editor.prompt != s.prompt {
+			t.Errorf("cd %q: expected prompt %q, got %q", s.name, s.prompt, editor.prompt)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package session
+
+import (
+	"sync"
+	"time"
+
+	"github.com/GGP1/kure/config"
+)
+
+// idle locks the session after a period of inactivity.
+//
+// Locking wipes the master password from the configuration, it's requested again
+// before executing the next command.
+type idle struct {
+	timer  *time.Timer
+	onLock func()
+	t      time.Duration
+	mu     sync.Mutex
+	// busy is true while a command is being executed
+	busy bool
+}
+
+func newIdle(t time.Duration, onLock func()) *idle {
+	return &idle{t: t, onLock: onLock}
+}
+
+// start begins the countdown, it's a no-op if the idle timeout is disabled.
+func (i *idle) start() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.busy = false
+	if i.t <= 0 || isLocked() {
+		return
+	}
+
+	if i.timer == nil {
+		i.timer = time.AfterFunc(i.t, i.expire)
+		return
+	}
+	i.timer.Reset(i.t)
+}
+
+// stop pauses the countdown so the session isn't locked while a command is running.
+func (i *idle) stop() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.busy = true
+	if i.timer != nil {
+		i.timer.Stop()
+	}
+}
+
+// set changes the idle timeout, zero disables it. The countdown starts again
+// once the current command finishes.
+func (i *idle) set(d time.Duration) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if d < 0 {
+		d = 0
+	}
+	i.t = d
+}
+
+func (i *idle) duration() time.Duration {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.t
+}
+
+func (i *idle) expire() {
+	i.mu.Lock()
+	// The timer may fire right before being stopped
+	if i.busy {
+		i.mu.Unlock()
+		return
+	}
+	lock()
+	i.mu.Unlock()
+
+	if i.onLock != nil {
+		i.onLock()
+	}
+}
+
+// lock wipes the credentials from the configuration. The vault in use is kept so nothing reads
+// another vault before the master password is entered again.
+func lock() {
+	config.Set("auth", map[string]interface{}{
+		"vault": config.GetUint32("auth.vault"),
+		"decoy": config.GetBool("auth.decoy"),
+	})
+}
+
+// isLocked returns whether the master password must be requested before executing a command.
+func isLocked() bool {
+	return !config.IsSet("auth.password")
+}
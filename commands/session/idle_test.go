@@ -0,0 +1,105 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GGP1/kure/config"
+	dbutil "github.com/GGP1/kure/db"
+
+	"github.com/awnumar/memguard"
+)
+
+func TestIdleLock(t *testing.T) {
+	setAuth()
+
+	locked := make(chan struct{})
+	idle := newIdle(time.Millisecond, func() { close(locked) })
+	idle.start()
+
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the session to be locked")
+	}
+
+	if !isLocked() {
+		t.Error("Expected the authentication parameters to be wiped")
+	}
+}
+
+func TestLockKeepsVault(t *testing.T) {
+	setAuth()
+	config.Set("auth.vault", uint32(1))
+	config.Set("auth.decoy", true)
+
+	lock()
+
+	if !isLocked() {
+		t.Error("Expected the password to be wiped")
+	}
+	if got := dbutil.VaultInUse(); got != 1 {
+		t.Errorf("Expected the vault in use to be kept, got %d", got)
+	}
+	if !config.GetBool("auth.decoy") {
+		t.Error("Expected the decoy flag to be kept")
+	}
+}
+
+func TestIdleBusy(t *testing.T) {
+	setAuth()
+
+	idle := newIdle(time.Millisecond, func() { t.Error("The session was locked while busy") })
+	idle.start()
+	idle.stop()
+	// Simulate the timer firing right before being stopped
+	idle.expire()
+	time.Sleep(5 * time.Millisecond)
+
+	if isLocked() {
+		t.Error("Expected the session to be unlocked")
+	}
+}
+
+func TestIdleDisabled(t *testing.T) {
+	setAuth()
+
+	idle := newIdle(0, nil)
+	idle.start()
+	idle.set(-time.Second)
+
+	if idle.timer != nil {
+		t.Error("Expected the timer not to be started")
+	}
+	if d := idle.duration(); d != 0 {
+		t.Errorf("Expected 0, got %v", d)
+	}
+}
+
+func TestRequiresLogin(t *testing.T) {
+	cases := map[string]bool{
+		"":     false,
+		"exit": false,
+		"quit": false,
+		"ls":   true,
+		"lock": true,
+	}
+
+	for arg, expected := range cases {
+		if got := requiresLogin([]command{{args: []string{arg}}}); got != expected {
+			t.Errorf("%q: expected %v, got %v", arg, expected, got)
+		}
+	}
+
+	cmds := []command{{args: []string{"exit"}}, {args: []string{"ls"}, op: opOr}}
+	if !requiresLogin(cmds) {
+		t.Error("Expected a sequence with a kure command to require login")
+	}
+}
+
+func setAuth() {
+	config.Reset()
+	config.Set("auth", map[string]interface{}{
+		"password": memguard.NewEnclave([]byte("1")),
+	})
+}
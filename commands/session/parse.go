@@ -0,0 +1,374 @@
+package session
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// operator decides whether a command is executed depending on the result of the previous one.
+type operator int
+
+const (
+	// opNone is used by the first command
+	opNone operator = iota
+	// opSeq (;) executes the command regardless of the previous result
+	opSeq
+	// opAnd (&&) executes the command only if the previous one succeeded
+	opAnd
+	// opOr (||) executes the command only if the previous one failed
+	opOr
+)
+
+func (o operator) String() string {
+	switch o {
+	case opSeq:
+		return ";"
+	case opAnd:
+		return "&&"
+	case opOr:
+		return "||"
+	default:
+		return ""
+	}
+}
+
+// command contains the arguments of a command and the operator that precedes it.
+type command struct {
+	args []string
+	// script contains the commands of a script alias, they are executed as a single command
+	script []command
+	op     operator
+}
+
+// parseError is returned when the input has invalid syntax.
+type parseError struct {
+	msg string
+	// col is the one-based position of the character that caused the error
+	col int
+}
+
+func (e *parseError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.col, e.msg)
+}
+
+// parse splits the input into a list of commands.
+//
+// Words are separated by spaces and may be quoted with single quotes (taken literally) or
+// double quotes (where \", \\ and \$ are escaped). Outside quotes, a backslash escapes
+// the next character and a word starting with # comments out the rest of the line.
+// Commands are separated by ;, && and ||.
+func parse(input string) ([]command, error) {
+	p := &parser{input: []rune(input)}
+	return p.parse()
+}
+
+// parseScript is like parse but it also replaces the script variables with the arguments
+// passed: $N is the Nth argument, ${N} is the same, ${N:-default} uses default if the
+// argument is missing or empty and $@ expands to all the arguments.
+func parseScript(script string, args []string) ([]command, error) {
+	p := &parser{input: []rune(script), args: args, expand: true}
+	return p.parse()
+}
+
+// parseWithVars is like parseScript but it also replaces named variables ($name or ${name})
+// with the values in vars, using an undefined variable is an error.
+func parseWithVars(input string, args []string, vars map[string]string) ([]command, error) {
+	p := &parser{input: []rune(input), args: args, vars: vars, expand: true}
+	return p.parse()
+}
+
+type parser struct {
+	word  strings.Builder
+	input []rune
+	args  []string
+	vars  map[string]string
+	cmds  []command
+	cur   command
+	pos   int
+	// inWord is true if a word has been started, even if it's empty ("")
+	inWord bool
+	// hasWords is true if the current command contains any word, even if it expanded to nothing
+	hasWords bool
+	expand   bool
+}
+
+func (p *parser) parse() ([]command, error) {
+	var lastOpCol int
+
+	for p.pos < len(p.input) {
+		r := p.input[p.pos]
+		col := p.pos + 1
+
+		switch {
+		case r == ' ' || r == '\t':
+			p.flushWord()
+			p.pos++
+
+		case r == '#' && !p.inWord:
+			// Comment, skip the rest of the input
+			p.pos = len(p.input)
+
+		case r == ';':
+			if err := p.endCommand(opSeq, col); err != nil {
+				return nil, err
+			}
+			lastOpCol = col
+			p.pos++
+
+		case r == '&' || r == '|':
+			if p.pos+1 >= len(p.input) || p.input[p.pos+1] != r {
+				return nil, &parseError{msg: fmt.Sprintf("unexpected %q", r), col: col}
+			}
+			op := opAnd
+			if r == '|' {
+				op = opOr
+			}
+			if err := p.endCommand(op, col); err != nil {
+				return nil, err
+			}
+			lastOpCol = col
+			p.pos += 2
+
+		case r == '\'':
+			if err := p.singleQuotes(); err != nil {
+				return nil, err
+			}
+
+		case r == '"':
+			if err := p.doubleQuotes(); err != nil {
+				return nil, err
+			}
+
+		case r == '\\':
+			if p.pos+1 >= len(p.input) {
+				return nil, &parseError{msg: "unfinished escape", col: col}
+			}
+			p.write(p.input[p.pos+1])
+			p.pos += 2
+
+		case r == '$' && p.expand:
+			if err := p.variable(false); err != nil {
+				return nil, err
+			}
+
+		default:
+			p.write(r)
+			p.pos++
+		}
+	}
+
+	p.flushWord()
+	if p.hasWords {
+		p.cmds = append(p.cmds, p.cur)
+	} else if p.cur.op == opAnd || p.cur.op == opOr {
+		return nil, &parseError{msg: fmt.Sprintf("missing command after %q", p.cur.op.String()), col: lastOpCol}
+	}
+
+	return p.cmds, nil
+}
+
+func (p *parser) write(r rune) {
+	p.word.WriteRune(r)
+	p.inWord = true
+	p.hasWords = true
+}
+
+func (p *parser) flushWord() {
+	if !p.inWord {
+		return
+	}
+	p.cur.args = append(p.cur.args, p.word.String())
+	p.word.Reset()
+	p.inWord = false
+}
+
+func (p *parser) endCommand(next operator, col int) error {
+	p.flushWord()
+	if !p.hasWords {
+		return &parseError{msg: fmt.Sprintf("unexpected %q", next.String()), col: col}
+	}
+
+	p.cmds = append(p.cmds, p.cur)
+	p.cur = command{op: next}
+	p.hasWords = false
+	return nil
+}
+
+func (p *parser) singleQuotes() error {
+	start := p.pos
+	end := -1
+	for i := p.pos + 1; i < len(p.input); i++ {
+		if p.input[i] == '\'' {
+			end = i
+			break
+		}
+	}
+	if end == -1 {
+		return &parseError{msg: "unterminated single quote", col: start + 1}
+	}
+
+	p.inWord = true
+	p.hasWords = true
+	p.word.WriteString(string(p.input[start+1 : end]))
+	p.pos = end + 1
+	return nil
+}
+
+func (p *parser) doubleQuotes() error {
+	start := p.pos
+	p.inWord = true
+	p.hasWords = true
+	p.pos++
+
+	for p.pos < len(p.input) {
+		r := p.input[p.pos]
+		switch {
+		case r == '"':
+			p.pos++
+			return nil
+
+		case r == '\\' && p.pos+1 < len(p.input) && strings.ContainsRune(`"\$`, p.input[p.pos+1]):
+			p.word.WriteRune(p.input[p.pos+1])
+			p.pos += 2
+
+		case r == '$' && p.expand:
+			if err := p.variable(true); err != nil {
+				return err
+			}
+
+		default:
+			p.word.WriteRune(r)
+			p.pos++
+		}
+	}
+
+	return &parseError{msg: "unterminated double quote", col: start + 1}
+}
+
+// variable replaces the variable at the current position with its value.
+func (p *parser) variable(quoted bool) error {
+	col := p.pos + 1
+	p.pos++ // Skip $
+	if p.pos >= len(p.input) {
+		p.write('$')
+		return nil
+	}
+
+	switch r := p.input[p.pos]; {
+	case r == '@':
+		p.pos++
+		p.hasWords = true
+		if quoted {
+			p.word.WriteString(strings.Join(p.args, " "))
+			return nil
+		}
+		// Each argument is a separate word
+		for i, arg := range p.args {
+			if i > 0 {
+				p.flushWord()
+			}
+			p.word.WriteString(arg)
+			p.inWord = true
+		}
+		return nil
+
+	case r >= '0' && r <= '9':
+		start := p.pos
+		for p.pos < len(p.input) && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+			p.pos++
+		}
+		n, _ := strconv.Atoi(string(p.input[start:p.pos]))
+		p.writeValue(p.arg(n))
+		return nil
+
+	case r == '{':
+		end := -1
+		for i := p.pos; i < len(p.input); i++ {
+			if p.input[i] == '}' {
+				end = i
+				break
+			}
+		}
+		if end == -1 {
+			return &parseError{msg: "unterminated variable", col: col}
+		}
+
+		content := string(p.input[p.pos+1 : end])
+		name, def, hasDefault := strings.Cut(content, ":-")
+		var value string
+		if n, err := strconv.Atoi(name); err == nil && n >= 0 {
+			value = p.arg(n)
+		} else if p.vars != nil && isName(name) {
+			v, ok := p.vars[name]
+			if !ok && !hasDefault {
+				return &parseError{msg: fmt.Sprintf("undefined variable %q", name), col: col}
+			}
+			value = v
+		} else {
+			return &parseError{msg: fmt.Sprintf("invalid variable %q", "${"+content+"}"), col: col}
+		}
+
+		if value == "" && hasDefault {
+			value = def
+		}
+		p.writeValue(value)
+		p.pos = end + 1
+		return nil
+
+	case p.vars != nil && isNameStart(r):
+		start := p.pos
+		for p.pos < len(p.input) && isNameChar(p.input[p.pos]) {
+			p.pos++
+		}
+		name := string(p.input[start:p.pos])
+		value, ok := p.vars[name]
+		if !ok {
+			return &parseError{msg: fmt.Sprintf("undefined variable %q", name), col: col}
+		}
+		p.writeValue(value)
+		return nil
+
+	default:
+		// Not a variable
+		p.write('$')
+		return nil
+	}
+}
+
+// writeValue adds a variable value to the current word. Empty values outside quotes don't
+// start a new word.
+func (p *parser) writeValue(value string) {
+	p.hasWords = true
+	if value == "" {
+		return
+	}
+	p.word.WriteString(value)
+	p.inWord = true
+}
+
+// arg returns the nth argument (one-based), $0 and missing arguments are empty.
+func (p *parser) arg(n int) string {
+	if n < 1 || n > len(p.args) {
+		return ""
+	}
+	return p.args[n-1]
+}
+
+// isName returns whether s is a valid variable name.
+func isName(s string) bool {
+	for i, r := range s {
+		if !isNameChar(r) || (i == 0 && !isNameStart(r)) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isNameStart(r rune) bool {
+	return r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+}
+
+func isNameChar(r rune) bool {
+	return isNameStart(r) || ('0' <= r && r <= '9')
+}
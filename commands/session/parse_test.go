@@ -0,0 +1,410 @@
+package session
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		desc     string
+		input    string
+		expected []command
+	}{
+		{
+			desc:     "Empty",
+			input:    "",
+			expected: nil,
+		},
+		{
+			desc:     "Spaces only",
+			input:    "   \t ",
+			expected: nil,
+		},
+		{
+			desc:  "Single command",
+			input: "copy github",
+			expected: []command{
+				{args: []string{"copy", "github"}},
+			},
+		},
+		{
+			desc:  "Multiple spaces",
+			input: "  copy   github  ",
+			expected: []command{
+				{args: []string{"copy", "github"}},
+			},
+		},
+		{
+			desc:  "Double quotes",
+			input: `copy "my github" -u`,
+			expected: []command{
+				{args: []string{"copy", "my github", "-u"}},
+			},
+		},
+		{
+			desc:  "Double quotes escapes",
+			input: `echo "a \"b\" \\ \$1 \n"`,
+			expected: []command{
+				{args: []string{"echo", `a "b" \ $1 \n`}},
+			},
+		},
+		{
+			desc:  "Single quotes",
+			input: `echo 'a "b" \ $1'`,
+			expected: []command{
+				{args: []string{"echo", `a "b" \ $1`}},
+			},
+		},
+		{
+			desc:  "Empty quotes",
+			input: `echo "" ''`,
+			expected: []command{
+				{args: []string{"echo", "", ""}},
+			},
+		},
+		{
+			desc:  "Quotes inside a word",
+			input: `copy my" "git'hub'`,
+			expected: []command{
+				{args: []string{"copy", "my github"}},
+			},
+		},
+		{
+			desc:  "Escape outside quotes",
+			input: `copy my\ github \&\& \;`,
+			expected: []command{
+				{args: []string{"copy", "my github", "&&", ";"}},
+			},
+		},
+		{
+			desc:  "Variables are not expanded",
+			input: "copy $1 ${2}",
+			expected: []command{
+				{args: []string{"copy", "$1", "${2}"}},
+			},
+		},
+		{
+			desc:  "And",
+			input: "copy github && ls",
+			expected: []command{
+				{args: []string{"copy", "github"}},
+				{args: []string{"ls"}, op: opAnd},
+			},
+		},
+		{
+			desc:  "Operators without spaces",
+			input: "ls&&stats||pwd;timeout",
+			expected: []command{
+				{args: []string{"ls"}},
+				{args: []string{"stats"}, op: opAnd},
+				{args: []string{"pwd"}, op: opOr},
+				{args: []string{"timeout"}, op: opSeq},
+			},
+		},
+		{
+			desc:  "Operators inside quotes",
+			input: `copy "a && b; c || d"`,
+			expected: []command{
+				{args: []string{"copy", "a && b; c || d"}},
+			},
+		},
+		{
+			desc:  "Trailing semicolon",
+			input: "ls;",
+			expected: []command{
+				{args: []string{"ls"}},
+			},
+		},
+		{
+			desc:  "Comment",
+			input: "ls # && rm github",
+			expected: []command{
+				{args: []string{"ls"}},
+			},
+		},
+		{
+			desc:     "Comment only",
+			input:    "# ls",
+			expected: nil,
+		},
+		{
+			desc:  "Hash inside a word",
+			input: "copy c#",
+			expected: []command{
+				{args: []string{"copy", "c#"}},
+			},
+		},
+		{
+			desc:  "Unicode",
+			input: `copy "ñandú" 'café'`,
+			expected: []command{
+				{args: []string{"copy", "ñandú", "café"}},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := parse(tc.input)
+			if err != nil {
+				t.Fatalf("Failed parsing input: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Expected %#v, got %#v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		desc     string
+		input    string
+		expected string
+	}{
+		{
+			desc:     "Leading operator",
+			input:    "&& ls",
+			expected: `column 1: unexpected "&&"`,
+		},
+		{
+			desc:     "Double operator",
+			input:    "ls && || stats",
+			expected: `column 7: unexpected "||"`,
+		},
+		{
+			desc:     "Leading semicolon",
+			input:    " ; ls",
+			expected: `column 2: unexpected ";"`,
+		},
+		{
+			desc:     "Trailing and",
+			input:    "ls &&",
+			expected: `column 4: missing command after "&&"`,
+		},
+		{
+			desc:     "Trailing or",
+			input:    "ls || ",
+			expected: `column 4: missing command after "||"`,
+		},
+		{
+			desc:     "Single ampersand",
+			input:    "ls & stats",
+			expected: `column 4: unexpected '&'`,
+		},
+		{
+			desc:     "Single pipe",
+			input:    "ls | stats",
+			expected: `column 4: unexpected '|'`,
+		},
+		{
+			desc:     "Unterminated single quote",
+			input:    "copy 'github",
+			expected: "column 6: unterminated single quote",
+		},
+		{
+			desc:     "Unterminated double quote",
+			input:    `copy "git\"hub`,
+			expected: "column 6: unterminated double quote",
+		},
+		{
+			desc:     "Unfinished escape",
+			input:    `copy github\`,
+			expected: "column 12: unfinished escape",
+		},
+		{
+			desc:     "Unicode column",
+			input:    `copy ñandú "x`,
+			expected: "column 12: unterminated double quote",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := parse(tc.input)
+			if err == nil {
+				t.Fatal("Expected an error and got nil")
+			}
+
+			if err.Error() != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestParseScript(t *testing.T) {
+	args := []string{"a", "b c", "3", "4", "5", "6", "7", "8", "9", "ten"}
+
+	cases := []struct {
+		desc     string
+		script   string
+		args     []string
+		expected []command
+	}{
+		{
+			desc:   "Positional",
+			script: "copy -u $1 && copy $1",
+			args:   []string{"github"},
+			expected: []command{
+				{args: []string{"copy", "-u", "github"}},
+				{args: []string{"copy", "github"}, op: opAnd},
+			},
+		},
+		{
+			desc:   "Multiple digits",
+			script: "echo $1 $10 $10",
+			args:   args,
+			expected: []command{
+				{args: []string{"echo", "a", "ten", "ten"}},
+			},
+		},
+		{
+			desc:   "Braces",
+			script: "echo ${1}0 ${10}",
+			args:   args,
+			expected: []command{
+				{args: []string{"echo", "a0", "ten"}},
+			},
+		},
+		{
+			desc:   "Argument with spaces",
+			script: "copy $2",
+			args:   args,
+			expected: []command{
+				{args: []string{"copy", "b c"}},
+			},
+		},
+		{
+			desc:   "Default",
+			script: "copy ${1:-github} ${2:-my default}",
+			args:   []string{"gitlab"},
+			expected: []command{
+				{args: []string{"copy", "gitlab", "my default"}},
+			},
+		},
+		{
+			desc:   "Default with empty argument",
+			script: "copy ${1:-github}",
+			args:   []string{""},
+			expected: []command{
+				{args: []string{"copy", "github"}},
+			},
+		},
+		{
+			desc:   "Missing argument",
+			script: "copy $1 $2 -u",
+			args:   []string{"github"},
+			expected: []command{
+				{args: []string{"copy", "github", "-u"}},
+			},
+		},
+		{
+			desc:   "Missing argument quoted",
+			script: `copy "$2"`,
+			args:   []string{"github"},
+			expected: []command{
+				{args: []string{"copy", ""}},
+			},
+		},
+		{
+			desc:   "All arguments",
+			script: "ls $@",
+			args:   []string{"a", "b c"},
+			expected: []command{
+				{args: []string{"ls", "a", "b c"}},
+			},
+		},
+		{
+			desc:   "All arguments quoted",
+			script: `echo "$@"`,
+			args:   []string{"a", "b c"},
+			expected: []command{
+				{args: []string{"echo", "a b c"}},
+			},
+		},
+		{
+			desc:   "All arguments without arguments",
+			script: "ls $@",
+			expected: []command{
+				{args: []string{"ls"}},
+			},
+		},
+		{
+			desc:   "Values aren't parsed",
+			script: "copy $1",
+			args:   []string{"a && rm b"},
+			expected: []command{
+				{args: []string{"copy", "a && rm b"}},
+			},
+		},
+		{
+			desc:   "Escaped variable",
+			script: `echo \$1 '$1' "\$1"`,
+			args:   []string{"a"},
+			expected: []command{
+				{args: []string{"echo", "$1", "$1", "$1"}},
+			},
+		},
+		{
+			desc:   "Not a variable",
+			script: "echo $ $a",
+			args:   []string{"a"},
+			expected: []command{
+				{args: []string{"echo", "$", "$a"}},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := parseScript(tc.script, tc.args)
+			if err != nil {
+				t.Fatalf("Failed parsing script: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Expected %#v, got %#v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseScriptErrors(t *testing.T) {
+	cases := []struct {
+		desc     string
+		script   string
+		expected string
+	}{
+		{
+			desc:     "Unterminated variable",
+			script:   "copy ${1",
+			expected: "column 6: unterminated variable",
+		},
+		{
+			desc:     "Invalid variable",
+			script:   "copy ${name}",
+			expected: `column 6: invalid variable "${name}"`,
+		},
+		{
+			desc:     "Empty variable",
+			script:   `copy "${}"`,
+			expected: `column 7: invalid variable "${}"`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := parseScript(tc.script, nil)
+			if err == nil {
+				t.Fatal("Expected an error and got nil")
+			}
+
+			if err.Error() != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, err.Error())
+			}
+		})
+	}
+}
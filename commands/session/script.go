@@ -0,0 +1,476 @@
+package session
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/config"
+	"github.com/GGP1/kure/db/entry"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	forRegexp = regexp.MustCompile(`^for\s+([A-Za-z_][A-Za-z0-9_]*)\s+in(?:\s+(.*))?$`)
+
+	errScriptTimeout = errors.New("script timeout reached")
+)
+
+// ScriptOptions contains the options used to run a script file.
+type ScriptOptions struct {
+	// Args are the values of the positional variables ($1, $2, ..., $n)
+	Args []string
+	// DryRun prints the commands instead of executing them
+	DryRun  bool
+	Timeout time.Duration
+}
+
+type statementKind int
+
+const (
+	stmtCommand statementKind = iota
+	stmtIf
+	stmtFor
+)
+
+// statement is a single command or a block of a script file.
+type statement struct {
+	// text is the command, the if condition (may be empty) or the for loop values
+	text     string
+	name     string
+	body     []statement
+	elseBody []statement
+	kind     statementKind
+	line     int
+}
+
+// RunScript executes the script file content using the session commands and the executor passed.
+//
+// Failed commands are printed and the execution continues, only syntax errors and the timeout stop it.
+// An error is returned if any of the commands failed.
+func RunScript(executor *cmdutil.Executor, db *bolt.DB, script string, opts ScriptOptions) error {
+	stmts, err := parseFile(script)
+	if err != nil {
+		return err
+	}
+
+	timeout := &timeout{
+		t:     opts.Timeout,
+		start: time.Now(),
+		timer: time.NewTimer(opts.Timeout),
+	}
+	if timeout.t == 0 {
+		if !timeout.timer.Stop() {
+			<-timeout.timer.C
+		}
+	}
+
+	in := &interpreter{
+		db:      db,
+		args:    opts.Args,
+		vars:    make(map[string]string),
+		scripts: config.GetStringMapString("session.scripts"),
+		dryRun:  opts.DryRun,
+		stop:    make(chan struct{}),
+		state: &state{
+			db:       db,
+			executor: executor,
+			root:     executor.Root(),
+			timeout:  timeout,
+			idle:     newIdle(0, nil),
+			editor:   &plainEditor{reader: bufio.NewReader(executor.In()), writer: executor.Out()},
+			folder:   &folder{},
+		},
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- in.exec(stmts) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return err
+		}
+	case <-timeout.timer.C:
+		// Wait for the command being executed to finish so the script is not left running
+		close(in.stop)
+		<-done
+		return errScriptTimeout
+	}
+
+	if in.failed > 0 {
+		return errors.Errorf("%d command(s) failed", in.failed)
+	}
+	return nil
+}
+
+// parseFile returns the statements of a script file.
+func parseFile(script string) ([]statement, error) {
+	p := &fileParser{lines: strings.Split(script, "\n")}
+	stmts, end, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	if end != "" {
+		return nil, errors.Errorf("line %d: unexpected %q", p.pos, end)
+	}
+	return stmts, nil
+}
+
+type fileParser struct {
+	lines []string
+	// pos is the number of lines read
+	pos int
+}
+
+// block reads statements until the end of the file or an "else" or "end" keyword, which is returned.
+func (p *fileParser) block() ([]statement, string, error) {
+	var stmts []statement
+
+	for p.pos < len(p.lines) {
+		text := strings.TrimSpace(strings.TrimSuffix(p.lines[p.pos], "\r"))
+		p.pos++
+		line := p.pos
+
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		keyword := strings.Fields(text)[0]
+		switch keyword {
+		case "else", "end":
+			if text != keyword {
+				return nil, "", errors.Errorf("line %d: unexpected text after %q", line, keyword)
+			}
+			return stmts, keyword, nil
+
+		case "if":
+			st := statement{kind: stmtIf, line: line, text: strings.TrimSpace(text[len(keyword):])}
+			body, end, err := p.block()
+			if err != nil {
+				return nil, "", err
+			}
+			st.body = body
+
+			if end == "else" {
+				st.elseBody, end, err = p.block()
+				if err != nil {
+					return nil, "", err
+				}
+			}
+			if end != "end" {
+				return nil, "", errors.Errorf("line %d: missing end for if", line)
+			}
+			stmts = append(stmts, st)
+
+		case "for":
+			m := forRegexp.FindStringSubmatch(text)
+			if m == nil {
+				return nil, "", errors.Errorf("line %d: invalid loop, use for <name> in <values>", line)
+			}
+			st := statement{kind: stmtFor, line: line, name: m[1], text: m[2]}
+			body, end, err := p.block()
+			if err != nil {
+				return nil, "", err
+			}
+			if end != "end" {
+				return nil, "", errors.Errorf("line %d: missing end for loop", line)
+			}
+			st.body = body
+			stmts = append(stmts, st)
+
+		default:
+			stmts = append(stmts, statement{kind: stmtCommand, line: line, text: text})
+		}
+	}
+
+	return stmts, "", nil
+}
+
+type interpreter struct {
+	db      *bolt.DB
+	state   *state
+	vars    map[string]string
+	scripts map[string]string
+	// stop is closed when the script must not execute more statements
+	stop chan struct{}
+	// err is the result of the last command executed
+	err  error
+	args []string
+	// failed is the number of commands that failed, if conditions are not included
+	failed int
+	// indent is the depth of the if blocks printed in dry runs
+	indent int
+	dryRun bool
+}
+
+func (in *interpreter) exec(stmts []statement) error {
+	for _, st := range stmts {
+		select {
+		case <-in.stop:
+			return errScriptTimeout
+		default:
+		}
+
+		var err error
+		switch st.kind {
+		case stmtCommand:
+			err = in.command(st.line, st.text)
+			if in.err != nil {
+				in.failed++
+			}
+
+		case stmtIf:
+			if in.dryRun {
+				err = in.printIf(st)
+				break
+			}
+			if st.text != "" {
+				err = in.command(st.line, st.text)
+			}
+			if err == nil {
+				if in.err == nil {
+					err = in.exec(st.body)
+				} else {
+					err = in.exec(st.elseBody)
+				}
+			}
+
+		case stmtFor:
+			err = in.loop(st)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// command executes a line of commands, only syntax errors are returned.
+func (in *interpreter) command(line int, text string) error {
+	cmds, err := in.parse(line, text)
+	if err != nil {
+		return err
+	}
+
+	if in.dryRun {
+		if len(cmds) > 0 {
+			in.print(formatCommands(cmds))
+		}
+		in.err = nil
+		return nil
+	}
+
+	in.err = execute(cmds, in.state)
+	if in.err != nil {
+		fmt.Fprintf(in.state.executor.Err(), "error: line %d: %v\n", line, in.err)
+	}
+	return nil
+}
+
+// printIf prints both branches of an if statement as the result of the condition is unknown in dry runs.
+func (in *interpreter) printIf(st statement) error {
+	cond := "if"
+	if st.text != "" {
+		cmds, err := in.parse(st.line, st.text)
+		if err != nil {
+			return err
+		}
+		if len(cmds) > 0 {
+			cond += " " + formatCommands(cmds)
+		}
+	}
+
+	in.print(cond)
+	if err := in.printBlock(st.body); err != nil {
+		return err
+	}
+	if st.elseBody != nil {
+		in.print("else")
+		if err := in.printBlock(st.elseBody); err != nil {
+			return err
+		}
+	}
+	in.print("end")
+	return nil
+}
+
+func (in *interpreter) printBlock(stmts []statement) error {
+	in.indent++
+	defer func() { in.indent-- }()
+	return in.exec(stmts)
+}
+
+func (in *interpreter) print(text string) {
+	fmt.Fprintln(in.state.executor.Out(), strings.Repeat("\t", in.indent)+text)
+}
+
+// parse returns the commands of a line with the variables replaced and the scripts expanded.
+func (in *interpreter) parse(line int, text string) ([]command, error) {
+	cmds, err := parseWithVars(text, in.args, in.vars)
+	if err != nil {
+		return nil, errors.Wrapf(err, "line %d", line)
+	}
+	cmds, err = expandScripts(cmds, in.scripts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "line %d", line)
+	}
+	return cmds, nil
+}
+
+func (in *interpreter) loop(st statement) error {
+	// Records are not read in dry runs, the loop is printed with a placeholder in place of the names
+	if in.dryRun && strings.HasPrefix(st.text, "$(") {
+		return in.printLoop(st)
+	}
+
+	values, err := in.values(st.text)
+	if err != nil {
+		return errors.Wrapf(err, "line %d", st.line)
+	}
+
+	prev, ok := in.vars[st.name]
+	for _, v := range values {
+		in.vars[st.name] = v
+		if err := in.exec(st.body); err != nil {
+			return err
+		}
+	}
+
+	if ok {
+		in.vars[st.name] = prev
+	} else {
+		delete(in.vars, st.name)
+	}
+	return nil
+}
+
+// printLoop prints a loop over a command substitution without executing it, the variable is
+// replaced by "<name>" in the body.
+func (in *interpreter) printLoop(st statement) error {
+	sub, err := in.substitution(st.text)
+	if err != nil {
+		return errors.Wrapf(err, "line %d", st.line)
+	}
+
+	in.print(fmt.Sprintf("for %s in $(%s)", st.name, formatCommands([]command{sub})))
+
+	prev, ok := in.vars[st.name]
+	in.vars[st.name] = "<" + st.name + ">"
+	if err := in.printBlock(st.body); err != nil {
+		return err
+	}
+	if ok {
+		in.vars[st.name] = prev
+	} else {
+		delete(in.vars, st.name)
+	}
+
+	in.print("end")
+	return nil
+}
+
+// values returns the words a loop iterates over.
+func (in *interpreter) values(text string) ([]string, error) {
+	if strings.HasPrefix(text, "$(") {
+		sub, err := in.substitution(text)
+		if err != nil {
+			return nil, err
+		}
+		return listNames(in.db, sub.args[1:])
+	}
+
+	cmds, err := parseWithVars(text, in.args, in.vars)
+	if err != nil {
+		return nil, err
+	}
+	switch len(cmds) {
+	case 0:
+		return nil, nil
+	case 1:
+		return cmds[0].args, nil
+	default:
+		return nil, errors.New("loop values must not contain operators")
+	}
+}
+
+// substitution parses the command substitution passed, only "ls-names [folder]" is supported.
+func (in *interpreter) substitution(text string) (command, error) {
+	if !strings.HasSuffix(text, ")") {
+		return command{}, errors.New("unterminated command substitution")
+	}
+
+	cmds, err := parseWithVars(text[2:len(text)-1], in.args, in.vars)
+	if err != nil {
+		return command{}, err
+	}
+	if len(cmds) != 1 || len(cmds[0].args) == 0 || cmds[0].args[0] != "ls-names" {
+		return command{}, errors.New(`only "ls-names [folder]" can be used in a command substitution`)
+	}
+	if len(cmds[0].args) > 2 {
+		return command{}, errors.New("ls-names accepts only one folder")
+	}
+	return cmds[0], nil
+}
+
+// listNames returns the names of the entries inside the folder passed, or all of them
+// if none is specified.
+func listNames(db *bolt.DB, args []string) ([]string, error) {
+	if len(args) > 1 {
+		return nil, errors.New("ls-names accepts only one folder")
+	}
+
+	names, err := entry.ListNames(db)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return names, nil
+	}
+
+	folder := strings.Trim(strings.ToLower(strings.TrimSpace(args[0])), "/")
+	if folder == "" {
+		return names, nil
+	}
+
+	prefix := folder + "/"
+	filtered := make([]string, 0, len(names))
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) {
+			filtered = append(filtered, name)
+		}
+	}
+	return filtered, nil
+}
+
+// formatCommands returns the commands as they would be typed in a session, the commands of
+// scripts are grouped in parentheses.
+func formatCommands(cmds []command) string {
+	var sb strings.Builder
+	for i, c := range cmds {
+		if i > 0 {
+			sb.WriteString(" " + c.op.String() + " ")
+		}
+		if c.script != nil {
+			sb.WriteString("(" + formatCommands(c.script) + ")")
+			continue
+		}
+		for j, arg := range c.args {
+			if j > 0 {
+				sb.WriteByte(' ')
+			}
+			if arg == "" || strings.ContainsAny(arg, " \t\"'\\$#;&|") {
+				arg = fmt.Sprintf("%q", arg)
+			}
+			sb.WriteString(arg)
+		}
+	}
+	return sb.String()
+}
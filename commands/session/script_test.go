@@ -0,0 +1,286 @@
+package session
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/entry"
+	"github.com/GGP1/kure/pb"
+)
+
+func TestParseFile(t *testing.T) {
+	script := `#!/usr/bin/env kure run
+# Comment
+copy $1 -u
+
+if
+	ls $1
+else
+	ls
+end
+for name in $(ls-names work/)
+	if copy $name
+		sleep 1s
+	end
+end`
+
+	expected := []statement{
+		{kind: stmtCommand, line: 3, text: "copy $1 -u"},
+		{
+			kind:     stmtIf,
+			line:     5,
+			body:     []statement{{kind: stmtCommand, line: 6, text: "ls $1"}},
+			elseBody: []statement{{kind: stmtCommand, line: 8, text: "ls"}},
+		},
+		{
+			kind: stmtFor,
+			line: 10,
+			name: "name",
+			text: "$(ls-names work/)",
+			body: []statement{
+				{
+					kind: stmtIf,
+					line: 11,
+					text: "copy $name",
+					body: []statement{{kind: stmtCommand, line: 12, text: "sleep 1s"}},
+				},
+			},
+		},
+	}
+
+	got, err := parseFile(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, got)
+	}
+}
+
+func TestParseFileErrors(t *testing.T) {
+	cases := []struct {
+		desc     string
+		script   string
+		expected string
+	}{
+		{
+			desc:     "Missing end",
+			script:   "ls\nif\n\tls",
+			expected: "line 2: missing end for if",
+		},
+		{
+			desc:     "Missing loop end",
+			script:   "for n in a b\n\tls $n",
+			expected: "line 1: missing end for loop",
+		},
+		{
+			desc:     "Else without if",
+			script:   "ls\nelse",
+			expected: `line 2: unexpected "else"`,
+		},
+		{
+			desc:     "End without block",
+			script:   "end",
+			expected: `line 1: unexpected "end"`,
+		},
+		{
+			desc:     "Else inside loop",
+			script:   "for n in a\nelse\nend",
+			expected: "line 1: missing end for loop",
+		},
+		{
+			desc:     "Text after end",
+			script:   "if\nend if",
+			expected: `line 2: unexpected text after "end"`,
+		},
+		{
+			desc:     "Invalid loop",
+			script:   "for 1 in a\nend",
+			expected: "line 1: invalid loop, use for <name> in <values>",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := parseFile(tc.script)
+			if err == nil {
+				t.Fatal("Expected an error and got nil")
+			}
+
+			if err.Error() != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestRunScriptDryRun(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+	if err := entry.Create(db, &pb.Entry{Name: "work/aws"}, &pb.Entry{Name: "work/gcp"}, &pb.Entry{Name: "workshop"}, &pb.Entry{Name: "home"}); err != nil {
+		t.Fatal(err)
+	}
+
+	script := `copy "$1" -u && copy ${2:-gitlab}
+if
+	ls
+else
+	ls -s
+end
+for name in $(ls-names work)
+	rm $name
+end
+for n in a "b c"
+	echo $n $@
+end
+if stats
+	if
+		ls
+	end
+end`
+	expected := `copy "my github" -u && copy gitlab
+if
+	ls
+else
+	ls -s
+end
+for name in $(ls-names work)
+	rm <name>
+end
+echo a "my github"
+echo "b c" "my github"
+if stats
+	if
+		ls
+	end
+end
+`
+
+	out := new(bytes.Buffer)
+	executor := testState(db, out, new(bytes.Buffer)).executor
+
+	opts := ScriptOptions{Args: []string{"my github"}, DryRun: true}
+	if err := RunScript(executor, db, script, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := out.String(); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestRunScript(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+
+	script := `ls non-existent
+if
+	ttset 2h
+else
+	ttset 1h
+end
+if stats
+	ttadd 1h
+end`
+
+	stmts, err := parseFile(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := &interpreter{
+		db:    db,
+		vars:  make(map[string]string),
+		state: testState(db, new(bytes.Buffer), new(bytes.Buffer)),
+	}
+	if err := in.exec(stmts); err != nil {
+		t.Fatal(err)
+	}
+
+	if in.state.timeout.t != 2*time.Hour {
+		t.Errorf("Expected timeout to be 2h, got %v", in.state.timeout.t)
+	}
+}
+
+func TestRunScriptErrors(t *testing.T) {
+	cases := []struct {
+		desc     string
+		script   string
+		expected string
+	}{
+		{
+			desc:     "Undefined variable",
+			script:   "ls\nls $name",
+			expected: `line 2: column 4: undefined variable "name"`,
+		},
+		{
+			desc:     "Invalid command substitution",
+			script:   "for n in $(ls)\nend",
+			expected: `line 1: only "ls-names [folder]" can be used in a command substitution`,
+		},
+		{
+			desc:     "Unterminated command substitution",
+			script:   "for n in $(ls-names\nend",
+			expected: "line 1: unterminated command substitution",
+		},
+		{
+			desc:     "Operators in loop values",
+			script:   "for n in a && b\nend",
+			expected: "line 1: loop values must not contain operators",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			executor := testState(nil, new(bytes.Buffer), new(bytes.Buffer)).executor
+			err := RunScript(executor, nil, tc.script, ScriptOptions{DryRun: true})
+			if err == nil {
+				t.Fatal("Expected an error and got nil")
+			}
+
+			if err.Error() != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestRunScriptFailed(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+	executor := testState(db, new(bytes.Buffer), new(bytes.Buffer)).executor
+
+	script := `if ls non-existent
+	stats
+end
+ls non-existent
+stats`
+	err := RunScript(executor, db, script, ScriptOptions{})
+	if err == nil {
+		t.Fatal("Expected an error and got nil")
+	}
+
+	expected := "1 command(s) failed"
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestScriptTimeout(t *testing.T) {
+	executor := testState(nil, new(bytes.Buffer), new(bytes.Buffer)).executor
+	opts := ScriptOptions{Timeout: time.Millisecond}
+
+	done := make(chan error, 1)
+	// The second command would block the test if the script kept running after the timeout
+	go func() { done <- RunScript(executor, nil, "sleep 20ms\nsleep 1h", opts) }()
+
+	select {
+	case err := <-done:
+		if err != errScriptTimeout {
+			t.Errorf("Expected %v, got %v", errScriptTimeout, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("The script kept running after the timeout")
+	}
+}
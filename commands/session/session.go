@@ -1,12 +1,9 @@
 package session
 
 import (
-	"bufio"
 	"fmt"
 	"io"
-	"os"
 	"runtime"
-	"strings"
 	"time"
 
 	"github.com/GGP1/kure/auth"
@@ -14,8 +11,8 @@ import (
 	"github.com/GGP1/kure/config"
 	"github.com/GGP1/kure/sig"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	"github.com/spf13/pflag"
 	bolt "go.etcd.io/bbolt"
 )
 
@@ -26,12 +23,28 @@ const (
 kure session -p $
 
 * Run a session for 1 hour
-kure session -t 1h`
+kure session -t 1h
+
+* Run a session that locks after 5 minutes of inactivity
+kure session -i 5m`
 )
 
 type sessionOptions struct {
 	prefix  string
 	timeout time.Duration
+	idle    time.Duration
+}
+
+// state contains the session elements that session commands can modify.
+type state struct {
+	db       *bolt.DB
+	executor *cmdutil.Executor
+	// root is used to inspect the commands, they are executed with the executor
+	root    *cobra.Command
+	timeout *timeout
+	idle    *idle
+	editor  lineEditor
+	folder  *folder
 }
 
 type timeout struct {
@@ -41,7 +54,7 @@ type timeout struct {
 }
 
 // NewCmd returns a new command.
-func NewCmd(db *bolt.DB, r io.Reader) *cobra.Command {
+func NewCmd(db *bolt.DB, r io.Reader, newRoot cmdutil.NewRootFunc) *cobra.Command {
 	opts := sessionOptions{}
 
 	cmd := &cobra.Command{
@@ -49,13 +62,25 @@ func NewCmd(db *bolt.DB, r io.Reader) *cobra.Command {
 		Short: "Run a session",
 		Long: `Sessions let you do multiple operations by providing the master password once. 
 		
-They support running scripts using the logical AND (&&) operator and executing pre-defined ones from the configuration file by using their aliases.
+They support running command sequences (;), conditional commands (&& and ||), quoting and escaping arguments, comments (#) and executing pre-defined scripts from the configuration file by using their aliases.
 
 During a session, the master password is encrypted and stored inside a protected buffer.
 
+When running on a terminal, the input can be edited with the arrow keys, previous commands are accessed with the up and down arrows and commands, flags, record names and scripts are completed by pressing tab (twice to list the candidates). The history is kept in memory only, it's never written to disk.
+
+The idle timeout locks the session after a period without input: the master password is wiped from memory and requested again before the next command, without closing the session.
+
+Use cd to move between folders, record names passed to the 2fa, copy, edit, file, ls and rm commands are resolved relative to the current folder unless they start with "/". The "~" in the prefix is replaced with the current folder.
+
 Session commands:
 • block - block execution (to be manually unlocked).
+• cd [folder] - change the current folder (.. moves to the parent, / or no folder to the root).
 • exit|quit|Ctrl+C - close the session.
+• history -c - clear the commands history.
+• idle - show the idle timeout.
+• idleadd [duration] - increase/decrease the idle timeout.
+• idleset [duration] - set a new idle timeout.
+• lock - lock the session.
 • pwd - show current directory.
 • timeout - show time left.
 • ttadd [duration] - increase/decrease timeout.
@@ -63,17 +88,18 @@ Session commands:
 • sleep [duration] - sleep for x time.`,
 		Example: example,
 		PreRunE: auth.Login(db),
-		RunE:    runSession(r, &opts),
+		RunE:    runSession(db, r, newRoot, &opts),
 	}
 
 	f := cmd.Flags()
 	f.StringVarP(&opts.prefix, "prefix", "p", "kure:~ $", "text that precedes your commands")
 	f.DurationVarP(&opts.timeout, "timeout", "t", 0, "session timeout")
+	f.DurationVarP(&opts.idle, "idle", "i", 0, "lock the session after this time without input")
 
 	return cmd
 }
 
-func runSession(r io.Reader, opts *sessionOptions) cmdutil.RunEFunc {
+func runSession(db *bolt.DB, r io.Reader, newRoot cmdutil.NewRootFunc, opts *sessionOptions) cmdutil.RunEFunc {
 	return func(cmd *cobra.Command, args []string) error {
 		// Use config values if they are set and the flag wasn't used
 		if p := "session.prefix"; config.IsSet(p) && !cmd.Flags().Changed("prefix") {
@@ -82,6 +108,9 @@ func runSession(r io.Reader, opts *sessionOptions) cmdutil.RunEFunc {
 		if t := "session.timeout"; config.IsSet(t) && !cmd.Flags().Changed("timeout") {
 			opts.timeout = config.GetDuration(t)
 		}
+		if i := "session.idle"; config.IsSet(i) && !cmd.Flags().Changed("idle") {
+			opts.idle = config.GetDuration(i)
+		}
 
 		timeout := &timeout{
 			t:     opts.timeout,
@@ -89,7 +118,29 @@ func runSession(r io.Reader, opts *sessionOptions) cmdutil.RunEFunc {
 			timer: time.NewTimer(opts.timeout),
 		}
 
-		go startSession(cmd, r, opts.prefix, timeout)
+		// The configuration is populated on start and changes inside the session won't have effect until restart.
+		scripts := config.GetStringMapString("session.scripts")
+		executor := cmdutil.NewExecutor(newRoot, r, cmd.OutOrStdout(), cmd.ErrOrStderr())
+		root := executor.Root()
+		folder := &folder{prefix: opts.prefix}
+		completer := &completer{root: root, db: db, scripts: scripts, folder: folder}
+		editor := newLineEditor(r, executor.Out(), opts.prefix, completer)
+		defer editor.Close()
+		folder.editor = editor
+
+		s := &state{
+			db:       db,
+			executor: executor,
+			root:     root,
+			timeout:  timeout,
+			editor:   editor,
+			folder:   folder,
+			idle: newIdle(opts.idle, func() {
+				editor.Notify("Session locked due to inactivity\n")
+			}),
+		}
+
+		go startSession(cmd, scripts, s)
 
 		if timeout.t == 0 {
 			if !timeout.timer.Stop() {
@@ -102,165 +153,137 @@ func runSession(r io.Reader, opts *sessionOptions) cmdutil.RunEFunc {
 	}
 }
 
-func startSession(cmd *cobra.Command, r io.Reader, prefix string, timeout *timeout) {
-	reader := bufio.NewReader(r)
-	root := cmd.Root()
-	// The configuration is populated on start and changes inside the session won't have effect until restart.
-	scripts := config.GetStringMapString("session.scripts")
+func startSession(cmd *cobra.Command, scripts map[string]string, s *state) {
+	errOut := s.executor.Err()
 
 	for {
 		// Force a garbage collection so the memory used by argon2 isn't reserved
 		// for us by the system while sleeping
 		runtime.GC()
-		fmt.Printf("%s ", prefix)
 
-		text, _, err := reader.ReadLine()
+		s.idle.start()
+		textStr, err := s.editor.ReadLine()
+		s.idle.stop()
 		if err != nil {
 			if err == io.EOF {
 				sig.Signal.Kill()
 			}
-			fmt.Fprintln(os.Stderr, "error:", err)
+			fmt.Fprintln(errOut, "error:", err)
 			continue
 		}
 
-		textStr := string(text)
-		args := strings.Split(textStr, " ")
-		if strings.Contains(textStr, quote) {
-			args = parseDoubleQuotes(args)
+		cmds, err := parse(textStr)
+		if err != nil {
+			fmt.Fprintln(errOut, "error:", err)
+			continue
 		}
 
-		script, ok := scripts[args[0]]
-		if ok {
-			script = fillScript(args[1:], script)
-			args = strings.Split(script, " ")
+		cmds, err = expandScripts(cmds, scripts)
+		if err != nil {
+			fmt.Fprintln(errOut, "error:", err)
+			continue
 		}
 
-		if err := execute(root, args, timeout); err != nil {
-			fmt.Fprintln(os.Stderr, "error:", err)
+		if isLocked() && requiresLogin(cmds) {
+			// Ask for the master password again, PreRunE is auth.Login
+			if err := cmd.PreRunE(cmd, nil); err != nil {
+				fmt.Fprintln(errOut, "error:", err)
+				continue
+			}
+		}
+
+		if err := execute(cmds, s); err != nil {
+			fmt.Fprintln(errOut, "error:", err)
 		}
 	}
 }
 
-// cleanup resets signal cleanups and sets all flags as unchanged to keep using default values.
+// execute runs the commands taking into account the operators between them.
 //
-// It also sets the help flag internal variable to false in case it's used.
-func cleanup(cmd *cobra.Command) {
-	sig.Signal.ResetCleanups()
-	cmd.LocalFlags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
-	cmd.Flags().Set("help", "false")
-}
-
-func execute(root *cobra.Command, args []string, timeout *timeout) error {
-	cmds := parseCmds(args)
-
-	for _, args := range cmds {
-		if len(args) == 0 {
+// The error of the last command executed is returned, previous ones are printed.
+func execute(cmds []command, s *state) error {
+	var err error
+	for _, c := range cmds {
+		if (c.op == opAnd && err != nil) || (c.op == opOr && err == nil) {
 			continue
 		}
-		if args[0] == "kure" {
-			args = args[1:]
-		}
-
-		cont := sessionCommand(args, timeout)
-		if cont {
-			continue
+		if err != nil {
+			fmt.Fprintln(s.executor.Err(), "error:", err)
 		}
-
-		root.SetArgs(args)
-		subCmd, _, _ := root.Find(args)
-		if subCmd.Name() == "session" {
+		if c.script != nil {
+			err = execute(c.script, s)
 			continue
 		}
-
-		if err := root.Execute(); err != nil {
-			if subCmd.PostRun != nil {
-				// Force PostRun to reset options variables (as it isn't executed on failure)
-				subCmd.PostRun(nil, nil)
-			}
-			return err
-		}
-
-		cleanup(subCmd)
+		err = run(c.args, s)
 	}
-	return nil
+	return err
 }
 
-// fillScript replaces any argument placeholder in the script with the user input.
-func fillScript(args []string, script string) string {
-	if !strings.ContainsRune(script, '$') {
-		return script
+func run(args []string, s *state) error {
+	if len(args) == 0 {
+		return nil
+	}
+	if args[0] == "kure" {
+		args = args[1:]
+	}
+
+	if sessionCommand(args, s) {
+		return nil
 	}
 
-	n := 1 // Start from $1 like bash
-	for _, arg := range args {
-		placeholder := fmt.Sprintf("$%d", n)
-		script = strings.ReplaceAll(script, placeholder, arg)
-		n++
+	args = s.folder.resolve(s.root, args)
+	if subCmd, _, _ := s.root.Find(args); subCmd.Name() == "session" {
+		return nil
 	}
 
-	return script
+	return s.executor.Execute(args)
 }
 
-// Given
-// 		"ls && copy github && 2fa"
-// return
-// 		[{"ls"}, {"copy", "github"}, {"2fa"}].
-func parseCmds(args []string) [][]string {
-	// The underlying array will grow only if the script has multiple "&&" in a row
-	ampersands := make([]int, 0, len(args)/2)
-	for i, a := range args {
-		if a == "&&" {
-			// Store the indices of the ampersands
-			ampersands = append(ampersands, i)
+// expandScripts sets the commands of the script aliases, the script takes the place of the alias
+// and its result is the one of its last command.
+func expandScripts(cmds []command, scripts map[string]string) ([]command, error) {
+	expanded := make([]command, 0, len(cmds))
+	for _, c := range cmds {
+		if len(c.args) == 0 {
+			continue
 		}
-	}
 
-	// Pass on the args received if no ampersand was found
-	if len(ampersands) == 0 {
-		return [][]string{args}
-	}
+		script, ok := scripts[c.args[0]]
+		if !ok {
+			expanded = append(expanded, c)
+			continue
+		}
 
-	group := make([][]string, 0, len(ampersands)+1)
-	lastIdx := 0
-	// Append len(ampersands) commands to the group
-	for _, idx := range ampersands {
-		group = append(group, args[lastIdx:idx])
-		lastIdx = idx + 1 // Add one to skip the ampersand
+		scriptCmds, err := parseScript(script, c.args[1:])
+		if err != nil {
+			return nil, errors.Wrapf(err, "script %q", c.args[0])
+		}
+		if len(scriptCmds) == 0 {
+			continue
+		}
+		c.script = scriptCmds
+		expanded = append(expanded, c)
 	}
-
-	// Append the last command
-	group = append(group, args[lastIdx:])
-
-	return group
+	return expanded, nil
 }
 
-// parseDoubleQuotes joins two arguments enclosed by doublequotes.
-//
-// Given
-// 		[]string{"file", "touch", "\"file", "with", "spaces\""}
-// return
-// 		[]string{"file", "touch", "file with spaces"}
-func parseDoubleQuotes(args []string) []string {
-	for i := 0; i < len(args); i++ {
-		arg := args[i]
-		if strings.HasPrefix(arg, quote) {
-			if strings.HasSuffix(arg, quote) {
-				args[i] = strings.Trim(arg, quote)
-				continue
-			}
-
-			for j := i + 1; j < len(args); j++ {
-				if strings.HasSuffix(args[j], quote) {
-					// Join enclosed words, store the sequence where the first one was
-					// and remove the others from the slice
-					words := strings.Join(args[i:j+1], " ")
-					args[i] = strings.Trim(words, quote)
-					args = append(args[:i+1], args[j+1:]...)
-					i = j - 1
-					break
-				}
+// requiresLogin returns whether the input must wait for the session to be unlocked.
+func requiresLogin(cmds []command) bool {
+	for _, c := range cmds {
+		if c.script != nil {
+			if requiresLogin(c.script) {
+				return true
 			}
+			continue
+		}
+		if len(c.args) == 0 {
+			continue
+		}
+		switch c.args[0] {
+		case "", "exit", "quit":
+		default:
+			return true
 		}
 	}
-	return args
+	return false
 }
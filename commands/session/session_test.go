@@ -2,12 +2,19 @@ package session
 
 import (
 	"bytes"
-	"strconv"
+	"io"
+	"reflect"
 	"testing"
+	"time"
 
 	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/commands/ls"
+	"github.com/GGP1/kure/commands/stats"
 	"github.com/GGP1/kure/config"
+
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
 )
 
 func TestExecute(t *testing.T) {
@@ -18,162 +25,184 @@ func TestExecute(t *testing.T) {
 	config.Set("session.scripts", scripts)
 
 	cases := []struct {
-		desc string
-		args []string
+		desc  string
+		input string
 	}{
 		{
-			desc: "Kure command",
-			args: []string{"kure", "stats"},
+			desc:  "Kure command",
+			input: "kure stats",
+		},
+		{
+			desc:  "Session command",
+			input: "pwd",
+		},
+		{
+			desc:  "Kure help",
+			input: "kure",
+		},
+		{
+			desc:  "No command",
+			input: "",
 		},
 		{
-			desc: "Session command",
-			args: []string{"pwd"},
+			desc:  "Sequence",
+			input: "pwd; stats",
 		},
 		{
-			desc: "Kure help",
-			args: []string{"kure"},
+			desc:  "Skip after failure",
+			input: "ls non-existent && ls non-existent || stats",
 		},
 		{
-			desc: "No command",
-			args: []string{},
+			desc:  "Skip after success",
+			input: "stats || ls non-existent",
 		},
 	}
 
-	cmd := NewCmd(db, &bytes.Buffer{})
-	cmd.RunE = nil
-	root := cmd.Root()
+	s := testState(db, new(bytes.Buffer), new(bytes.Buffer))
 
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
-			if err := execute(root, tc.args, &timeout{}); err != nil {
+			cmds, err := parse(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := execute(cmds, s); err != nil {
 				t.Errorf("Failed executing command: %v", err)
 			}
 		})
 	}
 }
 
-func TestCleanup(t *testing.T) {
-	cmd := &cobra.Command{Use: "test"}
-	cmd.Flags().Bool("testing", false, "")
-	cmd.Flag("testing").Changed = true
-	cleanup(cmd)
+func TestExpandScripts(t *testing.T) {
+	scripts := map[string]string{
+		"login": "copy -u $1 && copy $1",
+		"show":  "ls ${1:-github}",
+	}
 
-	changed := cmd.Flag("testing").Changed
-	if changed {
-		t.Errorf("Expected false and got %t", changed)
+	cmds, err := parse("pwd || login gitlab; show")
+	if err != nil {
+		t.Fatal(err)
 	}
-}
 
-func TestFillScript(t *testing.T) {
-	cases := []struct {
-		desc     string
-		script   string
-		expected string
-		args     []string
-	}{
-		{
-			desc:     "No arguments",
-			script:   "edit test && rm test",
-			args:     []string{"no_args"},
-			expected: "edit test && rm test",
-		},
+	got, err := expandScripts(cmds, scripts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []command{
+		{args: []string{"pwd"}},
 		{
-			desc:     "One argument",
-			script:   "2fa $1 && ls -q $1",
-			args:     []string{"test"},
-			expected: "2fa test && ls -q test",
+			args: []string{"login", "gitlab"},
+			script: []command{
+				{args: []string{"copy", "-u", "gitlab"}},
+				{args: []string{"copy", "gitlab"}, op: opAnd},
+			},
+			op: opOr,
 		},
 		{
-			desc:     "Two arguments",
-			script:   "file cat $1 && copy $2",
-			args:     []string{"notes/test.txt", "testing"},
-			expected: "file cat notes/test.txt && copy testing",
+			args:   []string{"show"},
+			script: []command{{args: []string{"ls", "github"}}},
+			op:     opSeq,
 		},
 	}
-
-	for _, tc := range cases {
-		t.Run(tc.desc, func(t *testing.T) {
-			got := fillScript(tc.args, tc.script)
-			if got != tc.expected {
-				t.Errorf("Expected %s, got %s", tc.expected, got)
-			}
-		})
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, got)
 	}
 }
 
-func TestParseCmds(t *testing.T) {
+func TestExecuteScript(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+	scripts := map[string]string{"timeout": "ttset 1h; ttset 2h"}
+
 	cases := []struct {
 		desc     string
-		args     []string
-		expected [][]string
+		input    string
+		expected time.Duration
 	}{
 		{
-			desc:     "Single command",
-			args:     []string{"kure", "ls"},
-			expected: [][]string{{"kure", "ls"}},
+			desc:     "Skipped",
+			input:    "ls non-existent && timeout",
+			expected: 0,
 		},
 		{
-			desc:     "Two commands",
-			args:     []string{"kure", "ls", "&&", "copy", "test"},
-			expected: [][]string{{"kure", "ls"}, {"copy", "test"}},
+			desc:     "Executed",
+			input:    "ls non-existent || timeout",
+			expected: 2 * time.Hour,
 		},
 		{
-			desc:     "Three commands",
-			args:     []string{"stats", "&&", "gen", "-l", "15", "&&", "config", "argon2"},
-			expected: [][]string{{"stats"}, {"gen", "-l", "15"}, {"config", "argon2"}},
+			desc:     "Script result",
+			input:    "timeout && ttadd 1h",
+			expected: 3 * time.Hour,
 		},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
-			got := parseCmds(tc.args)
-
-			for i := 0; i < len(tc.expected); i++ {
-				for j := 0; j < len(tc.expected[i]); j++ {
-					if got[i][j] != tc.expected[i][j] {
-						t.Errorf("Expected %#v, got %#v", tc.expected[i], got[i])
-					}
-				}
+			s := testState(db, new(bytes.Buffer), new(bytes.Buffer))
+			cmds, err := parse(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cmds, err = expandScripts(cmds, scripts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			execute(cmds, s)
+
+			if s.timeout.t != tc.expected {
+				t.Errorf("Expected timeout to be %v, got %v", tc.expected, s.timeout.t)
 			}
 		})
 	}
 }
 
-func TestParseDoubleQuotes(t *testing.T) {
-	cases := []struct {
-		args     []string
-		expected []string
-	}{
-		{
-			args:     []string{"file", "touch", "\"file", "with", "spaces\""},
-			expected: []string{"file", "touch", "file with spaces"},
-		},
-		{
-			args:     []string{"rm", "one", "\"two", "three"},
-			expected: []string{"rm", "one", "\"two", "three"},
-		},
-		{
-			args:     []string{"\"test\""},
-			expected: []string{"test"},
-		},
-		{
-			args:     []string{"\"file\"", "\"the", "wind\"", "\"is", "actually", "\"rocking\""},
-			expected: []string{"file", "the wind", "is actually \"rocking"},
-		},
-		{
-			args:     []string{"\"a\"", "\"little bit", "\"more\"", "testing\""},
-			expected: []string{"a", "little bit \"more", "testing\""},
-		},
+func TestExpandScriptsError(t *testing.T) {
+	scripts := map[string]string{"invalid": "copy ${x}"}
+	cmds := []command{{args: []string{"invalid"}}}
+
+	_, err := expandScripts(cmds, scripts)
+	if err == nil {
+		t.Fatal("Expected an error and got nil")
 	}
 
-	for i, tc := range cases {
-		t.Run(strconv.Itoa(i), func(t *testing.T) {
-			got := parseDoubleQuotes(tc.args)
-			for i := 0; i < len(tc.expected); i++ {
-				if got[i] != tc.expected[i] {
-					t.Errorf("Expected %#v, got %#v", tc.expected, got)
-				}
-			}
+	expected := `script "invalid": column 6: invalid variable "${x}"`
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestNestedSession(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../db/testdata/database")
+	s := testState(db, new(bytes.Buffer), new(bytes.Buffer))
+
+	if err := run([]string{"session"}, s); err != nil {
+		t.Errorf("Expected nested sessions to be skipped, got %v", err)
+	}
+}
+
+// testState returns a session state whose executor has the ls, session and stats commands.
+func testState(db *bolt.DB, out, errOut io.Writer) *state {
+	newRoot := func(r io.Reader) *cobra.Command {
+		root := &cobra.Command{Use: "kure", SilenceErrors: true, SilenceUsage: true}
+		root.AddCommand(ls.NewCmd(db), stats.NewCmd(db), &cobra.Command{
+			Use: "session",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return errors.New("nested session")
+			},
 		})
+		return root
+	}
+	executor := cmdutil.NewExecutor(newRoot, new(bytes.Buffer), out, errOut)
+
+	return &state{
+		db:       db,
+		executor: executor,
+		root:     executor.Root(),
+		timeout:  &timeout{timer: time.NewTimer(time.Hour)},
+		idle:     newIdle(0, nil),
+		editor:   &plainEditor{writer: io.Discard},
+		folder:   &folder{},
 	}
 }
@@ -36,22 +36,39 @@ func runStats(db *bolt.DB) cmdutil.RunEFunc {
 		}
 		defer tx.Rollback()
 
-		nCards := tx.Bucket(dbutil.CardBucket).Stats().KeyN
-		nEntries := tx.Bucket(dbutil.EntryBucket).Stats().KeyN
-		nFiles := tx.Bucket(dbutil.FileBucket).Stats().KeyN
-		nTOTPs := tx.Bucket(dbutil.TOTPBucket).Stats().KeyN
-		total := nCards + nEntries + nFiles + nTOTPs
+		nCards := tx.Bucket(dbutil.CardBucket()).Stats().KeyN
+		nEntries := tx.Bucket(dbutil.EntryBucket()).Stats().KeyN
+		nFiles := tx.Bucket(dbutil.FileBucket()).Stats().KeyN
+		nTOTPs := tx.Bucket(dbutil.TOTPBucket()).Stats().KeyN
+		nWifis := tx.Bucket(dbutil.WifiBucket()).Stats().KeyN
+		total := nCards + nEntries + nFiles + nTOTPs + nWifis
 
-		fmt.Printf(`
+		format, err := cmdutil.OutputFormat(cmd)
+		if err != nil {
+			return err
+		}
+		if format != cmdutil.OutputDefault {
+			return cmdutil.WriteOutput(cmd.OutOrStdout(), format, &cmdutil.StatsOutput{
+				Cards:   nCards,
+				Entries: nEntries,
+				Files:   nFiles,
+				TOTPs:   nTOTPs,
+				Wifis:   nWifis,
+				Total:   total,
+			})
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), `
      STATISTICS
 ────────────────────
 Number of cards: %d
 Number of entries: %d
 Number of files: %d
 Number of TOTPs: %d
+Number of Wi-Fi networks: %d
 
 Total elements: %d
-`, nCards, nEntries, nFiles, nTOTPs, total)
+`, nCards, nEntries, nFiles, nTOTPs, nWifis, total)
 
 		return nil
 	}
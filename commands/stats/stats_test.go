@@ -1,6 +1,8 @@
 package stats
 
 import (
+	"bytes"
+	"encoding/json"
 	"testing"
 
 	cmdutil "github.com/GGP1/kure/commands"
@@ -16,6 +18,25 @@ func TestStats(t *testing.T) {
 		}
 	})
 
+	t.Run("JSON", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		cmd := NewCmd(db)
+		cmdutil.AddOutputFlag(cmd)
+		cmd.SetOut(buf)
+		cmd.SetArgs([]string{"--output", "json"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+
+		var stats cmdutil.StatsOutput
+		if err := json.Unmarshal(buf.Bytes(), &stats); err != nil {
+			t.Fatal(err)
+		}
+		if stats.Total != stats.Cards+stats.Entries+stats.Files+stats.TOTPs+stats.Wifis {
+			t.Errorf("Invalid total: %+v", stats)
+		}
+	})
+
 	t.Run("Database connection closed", func(t *testing.T) {
 		db.Close()
 
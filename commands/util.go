@@ -4,24 +4,27 @@ import (
 	"bufio"
 	"bytes"
 	"crypto/rand"
+	"encoding/base32"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/GGP1/kure/clip"
 	"github.com/GGP1/kure/config"
 	dbutil "github.com/GGP1/kure/db"
 	"github.com/GGP1/kure/db/card"
 	"github.com/GGP1/kure/db/entry"
 	"github.com/GGP1/kure/db/file"
 	"github.com/GGP1/kure/db/totp"
+	"github.com/GGP1/kure/db/wifi"
 	"github.com/GGP1/kure/orderedmap"
 	"github.com/GGP1/kure/sig"
 
-	"github.com/atotto/clipboard"
 	"github.com/awnumar/memguard"
 	"github.com/pkg/errors"
 	"github.com/skip2/go-qrcode"
@@ -47,6 +50,8 @@ const (
 	File
 	// TOTP object
 	TOTP
+	// Wifi object
+	Wifi
 
 	// Box
 	hBar       = "─"
@@ -205,8 +210,8 @@ func Confirm(r io.Reader, message string) bool {
 	}
 }
 
-// DisplayQRCode creates a qr code with the password provided and writes it to the terminal.
-func DisplayQRCode(secret string) error {
+// DisplayQRCode creates a qr code with the password provided and writes it to w.
+func DisplayQRCode(w io.Writer, secret string) error {
 	if len([]rune(secret)) > 1273 {
 		return errors.New("secret too long to encode to QR code, maximum is 1273")
 	}
@@ -216,7 +221,7 @@ func DisplayQRCode(secret string) error {
 		return errors.Wrap(err, "creating QR code")
 	}
 
-	fmt.Println(qr.ToSmallString(false))
+	fmt.Fprintln(w, qr.ToSmallString(false))
 	return nil
 }
 
@@ -258,24 +263,15 @@ func Exists(db *bolt.DB, name string, obj object) error {
 
 // FmtExpires returns expires formatted.
 func FmtExpires(expires string) (string, error) {
-	switch strings.ToLower(expires) {
-	case "never", "", " ", "0", "0s":
-		return "Never", nil
-
-	default:
-		expires = strings.ReplaceAll(expires, "-", "/")
-
-		// If the first format fails, try the second
-		exp, err := time.Parse("02/01/2006", expires)
-		if err != nil {
-			exp, err = time.Parse("2006/01/02", expires)
-			if err != nil {
-				return "", errors.New("\"expires\" field has an invalid format. Valid formats: d/m/y or y/m/d")
-			}
-		}
+	exp, err := parseExpires(expires)
+	if err != nil {
+		return "", err
+	}
 
-		return exp.Format(time.RFC1123Z), nil
+	if exp.IsZero() {
+		return "Never", nil
 	}
+	return exp.Format(time.RFC1123Z), nil
 }
 
 // MustExist returns an error if a record does not exist or if the name is invalid.
@@ -322,7 +318,7 @@ func MustExist(db *bolt.DB, obj object, allowDir ...bool) cobra.PositionalArgs {
 }
 
 // MustExistLs is like MustExist but it doesn't fail if
-// there are no arguments or if the user is using the filter flag.
+// there are no arguments, the name is a folder or if the user is using the filter flag.
 func MustExistLs(db *bolt.DB, obj object) cobra.PositionalArgs {
 	return func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 || cmd.Flags().Changed("filter") {
@@ -335,6 +331,18 @@ func MustExistLs(db *bolt.DB, obj object) cobra.PositionalArgs {
 			return nil
 		}
 
+		// Folders are listed as well
+		records, _, err := listNames(db, obj)
+		if err != nil {
+			return err
+		}
+		prefix := NormalizeName(strings.Join(args, " ")) + "/"
+		for _, record := range records {
+			if strings.HasPrefix(record, prefix) {
+				return nil
+			}
+		}
+
 		// Pass on cmd and args
 		return MustExist(db, obj)(cmd, args)
 	}
@@ -373,6 +381,19 @@ func NormalizeName(name string, allowDir ...bool) string {
 	return strings.ToLower(strings.TrimSpace(name))
 }
 
+// NormalizeTOTPKey removes the spaces of a base32 TOTP key and uppercases and pads it,
+// it fails if the key is not valid base32.
+func NormalizeTOTPKey(key string) (string, error) {
+	key = strings.ReplaceAll(key, " ", "")
+	key += strings.Repeat("=", -len(key)&7)
+	key = strings.ToUpper(key)
+
+	if _, err := base32.StdEncoding.DecodeString(key); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
 // Scanln scans a single line and returns the input.
 func Scanln(r *bufio.Reader, field string) string {
 	fmt.Printf("%s: ", field)
@@ -420,6 +441,11 @@ func SelectEditor() string {
 	return "vim"
 }
 
+var (
+	muted    *os.File
+	muteOnce sync.Once
+)
+
 // SetContext sets up the testing environment.
 //
 // It uses t.Cleanup() to close the database connection after the test and
@@ -442,8 +468,7 @@ func SetContext(t testing.TB, path string) *bolt.DB {
 	config.Set("auth", auth)
 
 	db.Update(func(tx *bolt.Tx) error {
-		buckets := [][]byte{dbutil.CardBucket, dbutil.EntryBucket, dbutil.FileBucket, dbutil.TOTPBucket}
-		for _, bucket := range buckets {
+		for _, bucket := range dbutil.Buckets() {
 			// Ignore errors on purpose
 			tx.DeleteBucket(bucket)
 			tx.CreateBucketIfNotExists(bucket)
@@ -451,8 +476,10 @@ func SetContext(t testing.TB, path string) *bolt.DB {
 		return nil
 	})
 
-	os.Stdout = os.NewFile(0, "") // Mute stdout
-	os.Stderr = os.NewFile(0, "") // Mute stderr
+	// Mute stdout and stderr, the same file is reused as discarded ones close the descriptor when collected
+	muteOnce.Do(func() { muted = os.NewFile(0, "") })
+	os.Stdout = muted
+	os.Stderr = muted
 	t.Cleanup(func() {
 		if err := db.Close(); err != nil {
 			t.Fatalf("Failed closing database: %v", err)
@@ -492,11 +519,13 @@ func WatchFile(filename string, done chan struct{}, errCh chan error) {
 // WriteClipboard writes the content to the clipboard and deletes it after
 // "t" if "t" is higher than 0 or if there is a default timeout set in the configuration.
 // Otherwise it does nothing.
+//
+// The clipboard is only cleared if nothing else was copied in the meantime.
 func WriteClipboard(cmd *cobra.Command, t time.Duration, field, content string) error {
-	if err := clipboard.WriteAll(content); err != nil {
+	if err := clip.Write(content); err != nil {
 		return errors.Wrap(err, "writing to clipboard")
 	}
-	fmt.Println(field, "copied to clipboard")
+	fmt.Fprintln(cmd.OutOrStdout(), field, "copied to clipboard")
 	memguard.WipeBytes([]byte(content))
 
 	// Use the config value if it's specified and the timeout flag wasn't used
@@ -506,9 +535,7 @@ func WriteClipboard(cmd *cobra.Command, t time.Duration, field, content string)
 	}
 
 	if t > 0 {
-		sig.Signal.AddCleanup(func() error { return clipboard.WriteAll("") })
-		<-time.After(t)
-		clipboard.WriteAll("")
+		return clip.ClearAfter(t, content)
 	}
 
 	return nil
@@ -575,6 +602,10 @@ func listNames(db *bolt.DB, obj object) ([]string, string, error) {
 	case TOTP:
 		objType = "TOTP"
 		records, err = totp.ListNames(db)
+
+	case Wifi:
+		objType = "Wi-Fi network"
+		records, err = wifi.ListNames(db)
 	}
 	if err != nil {
 		return nil, "", err
@@ -3,6 +3,7 @@ package cmdutil
 import (
 	"bufio"
 	"bytes"
+	"io"
 	"os"
 	"testing"
 	"time"
@@ -65,7 +66,7 @@ func TestConfirm(t *testing.T) {
 
 func TestDisplayQRCode(t *testing.T) {
 	os.Stdout = os.NewFile(0, "") // Mute stdout
-	if err := DisplayQRCode("secret"); err != nil {
+	if err := DisplayQRCode(io.Discard, "secret"); err != nil {
 		t.Errorf("Failed displaying QR code: %v", err)
 	}
 }
@@ -82,7 +83,7 @@ func TestDisplayQRCodeErrors(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
 			os.Stdout = os.NewFile(0, "") // Mute stdout
-			if err := DisplayQRCode(tc.secret); err == nil {
+			if err := DisplayQRCode(io.Discard, tc.secret); err == nil {
 				t.Error("Expected an error and got nil")
 			}
 		})
@@ -451,6 +452,42 @@ func TestNormalizeName(t *testing.T) {
 	}
 }
 
+func TestNormalizeTOTPKey(t *testing.T) {
+	cases := []struct {
+		desc     string
+		key      string
+		expected string
+	}{
+		{
+			desc:     "Spaces and lowercase",
+			key:      "jbsw y3dp ehpk 3pxp",
+			expected: "JBSWY3DPEHPK3PXP",
+		},
+		{
+			desc:     "Padding",
+			key:      "JBSWY3DPEHPK3PX",
+			expected: "JBSWY3DPEHPK3PX=",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := NormalizeTOTPKey(tc.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+
+	if _, err := NormalizeTOTPKey("invalid!"); err == nil {
+		t.Error("Expected an error and got nil")
+	}
+}
+
 func TestScanln(t *testing.T) {
 	cases := []struct {
 		desc     string
@@ -0,0 +1,68 @@
+package cmdutil
+
+import (
+	"strings"
+
+	"github.com/GGP1/kure/pb"
+
+	"github.com/pkg/errors"
+)
+
+// Wi-Fi security types.
+const (
+	WifiWPA  = "WPA"
+	WifiWPA2 = "WPA2"
+	WifiWPA3 = "WPA3"
+	WifiWEP  = "WEP"
+	WifiNone = "none"
+)
+
+// wifiEscaper escapes the characters with a special meaning in WIFI: payloads.
+var wifiEscaper = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, `:`, `\:`, `"`, `\"`)
+
+// ParseWifiSecurity returns the security type in its canonical form, WPA2 is used if it's empty.
+func ParseWifiSecurity(security string) (string, error) {
+	switch strings.ToUpper(strings.TrimSpace(security)) {
+	case "", WifiWPA2:
+		return WifiWPA2, nil
+	case WifiWPA:
+		return WifiWPA, nil
+	case WifiWPA3:
+		return WifiWPA3, nil
+	case WifiWEP:
+		return WifiWEP, nil
+	case "NONE", "NOPASS", "OPEN":
+		return WifiNone, nil
+	default:
+		return "", errors.Errorf("invalid security %q, expected WPA, WPA2, WPA3, WEP or none", security)
+	}
+}
+
+// WifiPayload returns the network in the "WIFI:" format, which phones read from QR codes to join it.
+//
+// Example: WIFI:T:WPA;S:Home;P:secret;;
+func WifiPayload(w *pb.Wifi) string {
+	var sb strings.Builder
+	sb.WriteString("WIFI:")
+
+	switch w.Security {
+	case WifiWEP:
+		sb.WriteString("T:WEP;")
+	case WifiNone:
+		sb.WriteString("T:nopass;")
+	default:
+		// WPA2 and WPA3 networks are advertised as WPA as well
+		sb.WriteString("T:WPA;")
+	}
+
+	sb.WriteString("S:" + wifiEscaper.Replace(w.Ssid) + ";")
+	if w.Security != WifiNone {
+		sb.WriteString("P:" + wifiEscaper.Replace(w.Password) + ";")
+	}
+	if w.Hidden {
+		sb.WriteString("H:true;")
+	}
+	sb.WriteString(";")
+
+	return sb.String()
+}
@@ -0,0 +1,91 @@
+package add
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/GGP1/kure/auth"
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/wifi"
+	"github.com/GGP1/kure/pb"
+
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+)
+
+const example = `
+* Add a new Wi-Fi network
+kure wifi add Home`
+
+// NewCmd returns a new command.
+func NewCmd(db *bolt.DB, r io.Reader) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add a Wi-Fi network",
+		Long: `Add a Wi-Fi network.
+
+The SSID defaults to the last part of the name, keeping its case, and the security to WPA2. Supported security types are WPA, WPA2, WPA3, WEP and none.`,
+		Aliases: []string{"create", "new"},
+		Example: example,
+		Args:    cmdutil.MustNotExist(db, cmdutil.Wifi),
+		PreRunE: auth.Login(db),
+		RunE:    runAdd(db, r),
+	}
+
+	return cmd
+}
+
+func runAdd(db *bolt.DB, r io.Reader) cmdutil.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		name := strings.Join(args, " ")
+		// Names are lowercased but SSIDs are case sensitive, take the default one from the argument
+		ssid := path.Base(strings.Trim(strings.TrimSpace(name), "/"))
+		name = cmdutil.NormalizeName(name)
+
+		w, err := input(name, ssid, r)
+		if err != nil {
+			return err
+		}
+
+		if err := wifi.Create(db, w); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "\n%q added\n", name)
+		return nil
+	}
+}
+
+// input requests the network information, defaultSSID is used when the SSID is left blank.
+func input(name, defaultSSID string, r io.Reader) (*pb.Wifi, error) {
+	reader := bufio.NewReader(r)
+
+	ssid := cmdutil.Scanln(reader, fmt.Sprintf("SSID [%s]", defaultSSID))
+	if ssid == "" {
+		ssid = defaultSSID
+	}
+
+	security, err := cmdutil.ParseWifiSecurity(cmdutil.Scanln(reader, "Security [WPA2]"))
+	if err != nil {
+		return nil, err
+	}
+
+	var password string
+	if security != cmdutil.WifiNone {
+		password = cmdutil.Scanln(reader, "Password")
+	}
+
+	hidden := strings.ToLower(cmdutil.Scanln(reader, "Hidden (y/N)"))
+
+	return &pb.Wifi{
+		Name:     name,
+		Ssid:     ssid,
+		Security: security,
+		Password: password,
+		Hidden:   hidden == "y" || hidden == "yes",
+		Notes:    cmdutil.Scanlns(reader, "Notes"),
+	}, nil
+}
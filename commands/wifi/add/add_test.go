@@ -0,0 +1,126 @@
+package add
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/wifi"
+	"github.com/GGP1/kure/pb"
+)
+
+func TestAdd(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../../db/testdata/database")
+
+	buf := bytes.NewBufferString("\nwpa3\nsecret\ny\nnotes<\n")
+	cmd := NewCmd(db, buf)
+	cmd.SetArgs([]string{"Home/Router"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := wifi.Get(db, "home/router")
+	if err != nil {
+		t.Fatalf("Wi-Fi network wasn't created correctly: %v", err)
+	}
+	if got.Ssid != "Router" || got.Security != cmdutil.WifiWPA3 || !got.Hidden {
+		t.Errorf("Unexpected network: %v", got)
+	}
+}
+
+func TestAddErrors(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../../db/testdata/database")
+
+	if err := wifi.Create(db, &pb.Wifi{Name: "test"}); err != nil {
+		t.Fatalf("Failed creating the Wi-Fi network: %v", err)
+	}
+
+	cases := []struct {
+		desc  string
+		name  string
+		input string
+	}{
+		{
+			desc:  "Already exists",
+			name:  "test",
+			input: "ssid\nWPA2\npassword\nn\nnotes<\n",
+		},
+		{
+			desc:  "Invalid name",
+			name:  "",
+			input: "ssid\nWPA2\npassword\nn\nnotes<\n",
+		},
+		{
+			desc:  "Invalid security",
+			name:  "new",
+			input: "ssid\nWPA9\npassword\nn\nnotes<\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cmd := NewCmd(db, bytes.NewBufferString(tc.input))
+			cmd.SetArgs([]string{tc.name})
+
+			if err := cmd.Execute(); err == nil {
+				t.Error("Expected an error and got nil")
+			}
+		})
+	}
+}
+
+func TestInput(t *testing.T) {
+	cases := []struct {
+		desc     string
+		input    string
+		expected *pb.Wifi
+	}{
+		{
+			desc:  "WPA2",
+			input: "Home 5G\n\nsecret\n\nnotes<",
+			expected: &pb.Wifi{
+				Name:     "test",
+				Ssid:     "Home 5G",
+				Security: cmdutil.WifiWPA2,
+				Password: "secret",
+				Notes:    "notes",
+			},
+		},
+		{
+			desc:  "Default SSID",
+			input: "\n\nsecret\n\nnotes<",
+			expected: &pb.Wifi{
+				Name:     "test",
+				Ssid:     "Test",
+				Security: cmdutil.WifiWPA2,
+				Password: "secret",
+				Notes:    "notes",
+			},
+		},
+		{
+			desc:  "Open",
+			input: "Guests\nnone\nyes\nnotes<",
+			expected: &pb.Wifi{
+				Name:     "test",
+				Ssid:     "Guests",
+				Security: cmdutil.WifiNone,
+				Hidden:   true,
+				Notes:    "notes",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := input("test", "Test", bytes.NewBufferString(tc.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(tc.expected, got) {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
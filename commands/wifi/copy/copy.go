@@ -0,0 +1,70 @@
+package copy
+
+import (
+	"strings"
+	"time"
+
+	"github.com/GGP1/kure/auth"
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/wifi"
+
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+)
+
+const example = `
+* Copy the password
+kure wifi copy Home
+
+* Copy the SSID
+kure wifi copy Home -s
+
+* Copy and clean after 30s
+kure wifi copy Home -t 30s`
+
+type copyOptions struct {
+	ssid    bool
+	timeout time.Duration
+}
+
+// NewCmd returns a new command.
+func NewCmd(db *bolt.DB) *cobra.Command {
+	opts := copyOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "copy <name>",
+		Short:   "Copy Wi-Fi network password or SSID",
+		Aliases: []string{"cp"},
+		Example: example,
+		Args:    cmdutil.MustExist(db, cmdutil.Wifi),
+		PreRunE: auth.Login(db),
+		RunE:    runCopy(db, &opts),
+	}
+
+	f := cmd.Flags()
+	f.BoolVarP(&opts.ssid, "ssid", "s", false, "copy the network SSID")
+	f.DurationVarP(&opts.timeout, "timeout", "t", 0, "clipboard clearing timeout")
+
+	return cmd
+}
+
+func runCopy(db *bolt.DB, opts *copyOptions) cmdutil.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		name := strings.Join(args, " ")
+		name = cmdutil.NormalizeName(name)
+
+		w, err := wifi.Get(db, name)
+		if err != nil {
+			return err
+		}
+
+		field := "Password"
+		copy := w.Password
+		if opts.ssid {
+			field = "SSID"
+			copy = w.Ssid
+		}
+
+		return cmdutil.WriteClipboard(cmd, opts.timeout, field, copy)
+	}
+}
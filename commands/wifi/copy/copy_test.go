@@ -0,0 +1,92 @@
+package copy
+
+import (
+	"testing"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/config"
+	"github.com/GGP1/kure/db/wifi"
+	"github.com/GGP1/kure/pb"
+
+	"github.com/atotto/clipboard"
+)
+
+func TestCopy(t *testing.T) {
+	if clipboard.Unsupported {
+		t.Skip("No clipboard utilities available")
+	}
+	db := cmdutil.SetContext(t, "../../../db/testdata/database")
+
+	if err := wifi.Create(db, &pb.Wifi{Name: "test", Ssid: "Home", Password: "secret"}); err != nil {
+		t.Fatalf("Failed creating the Wi-Fi network: %v", err)
+	}
+
+	cases := []struct {
+		desc    string
+		name    string
+		ssid    string
+		timeout string
+	}{
+		{
+			desc: "Copy password",
+			name: "test",
+			ssid: "false",
+		},
+		{
+			desc: "Copy SSID",
+			name: "test",
+			ssid: "true",
+		},
+		{
+			desc:    "Copy w/Timeout",
+			name:    "test",
+			timeout: "1ns",
+		},
+	}
+
+	cmd := NewCmd(db)
+	f := cmd.Flags()
+	config.Set("clipboard.timeout", "1ns") // Set default
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cmd.SetArgs([]string{tc.name})
+			f.Set("timeout", tc.timeout)
+			f.Set("ssid", tc.ssid)
+
+			if err := cmd.Execute(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCopyErrors(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../../db/testdata/database")
+
+	cases := []struct {
+		desc string
+		name string
+	}{
+		{
+			desc: "Invalid name",
+			name: "",
+		},
+		{
+			desc: "Non existent network",
+			name: "non-existent",
+		},
+	}
+
+	cmd := NewCmd(db)
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cmd.SetArgs([]string{tc.name})
+
+			if err := cmd.Execute(); err == nil {
+				t.Error("Expected an error and got nil")
+			}
+		})
+	}
+}
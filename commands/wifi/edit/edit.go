@@ -0,0 +1,235 @@
+package edit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/GGP1/kure/auth"
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/wifi"
+	"github.com/GGP1/kure/pb"
+	"github.com/GGP1/kure/sig"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+)
+
+const example = `
+* Edit using the standard input
+kure wifi edit Home
+
+* Edit using the text editor
+kure wifi edit Home -i`
+
+type editOptions struct {
+	interactive bool
+}
+
+// NewCmd returns a new command.
+func NewCmd(db *bolt.DB, r io.Reader) *cobra.Command {
+	opts := editOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "edit <name>",
+		Short: "Edit a Wi-Fi network",
+		Long: `Edit a Wi-Fi network.
+
+If the name is edited, Kure will remove the old network and create one with the new name.`,
+		Example: example,
+		Args:    cmdutil.MustExist(db, cmdutil.Wifi),
+		PreRunE: auth.Login(db),
+		RunE:    runEdit(db, r, &opts),
+	}
+
+	cmd.Flags().BoolVarP(&opts.interactive, "it", "i", false, "use the text editor")
+
+	return cmd
+}
+
+func runEdit(db *bolt.DB, r io.Reader, opts *editOptions) cmdutil.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		name := strings.Join(args, " ")
+		name = cmdutil.NormalizeName(name)
+
+		oldWifi, err := wifi.Get(db, name)
+		if err != nil {
+			return err
+		}
+
+		if opts.interactive {
+			return useTextEditor(db, cmd.OutOrStdout(), oldWifi)
+		}
+
+		return useStdin(db, r, cmd.OutOrStdout(), oldWifi)
+	}
+}
+
+func createTempFile(w *pb.Wifi) (string, error) {
+	f, err := os.CreateTemp("", "*.json")
+	if err != nil {
+		return "", errors.Wrap(err, "creating temporary file")
+	}
+
+	content, err := json.MarshalIndent(w, "", "  ")
+	if err != nil {
+		return "", errors.Wrap(err, "encoding Wi-Fi network")
+	}
+
+	if _, err := f.Write(content); err != nil {
+		return "", errors.Wrap(err, "writing temporary file")
+	}
+
+	if err := f.Close(); err != nil {
+		return "", errors.Wrap(err, "closing temporary file")
+	}
+
+	return f.Name(), nil
+}
+
+// readTmpFile reads the modified file and formats the Wi-Fi network.
+func readTmpFile(filename string) (*pb.Wifi, error) {
+	var w pb.Wifi
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading file")
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(f).Decode(&w); err != nil {
+		return nil, errors.Wrap(err, "decoding file")
+	}
+
+	return &w, nil
+}
+
+// updateWifi takes the name of the Wi-Fi network that's being edited to check if the name was
+// changed. If it was, it will remove the old one.
+func updateWifi(db *bolt.DB, out io.Writer, name string, w *pb.Wifi) error {
+	if w.Name == "" {
+		return cmdutil.ErrInvalidName
+	}
+
+	security, err := cmdutil.ParseWifiSecurity(w.Security)
+	if err != nil {
+		return err
+	}
+	w.Security = security
+	if w.Security == cmdutil.WifiNone {
+		w.Password = ""
+	}
+
+	name = cmdutil.NormalizeName(name)
+	w.Name = cmdutil.NormalizeName(w.Name)
+
+	if err := wifi.Update(db, name, w); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, w.Name, "updated")
+	return nil
+}
+
+func useStdin(db *bolt.DB, r io.Reader, out io.Writer, oldWifi *pb.Wifi) error {
+	fmt.Fprintln(out, "Type '-' to clear the field or leave blank to use the current value")
+	reader := bufio.NewReader(r)
+
+	scanln := func(field, value string) string {
+		input := cmdutil.Scanln(reader, fmt.Sprintf("%s [%s]", field, value))
+		if input == "-" {
+			return ""
+		} else if input != "" {
+			return input
+		}
+		return value
+	}
+
+	newWifi := &pb.Wifi{
+		Name:     scanln("Name", oldWifi.Name),
+		Ssid:     scanln("SSID", oldWifi.Ssid),
+		Security: scanln("Security", oldWifi.Security),
+		Password: scanln("Password", oldWifi.Password),
+	}
+
+	hidden := strings.ToLower(scanln("Hidden (y/n)", yesNo(oldWifi.Hidden)))
+	newWifi.Hidden = hidden == "y" || hidden == "yes"
+
+	notes := cmdutil.Scanlns(reader, fmt.Sprintf("Notes [%s]", oldWifi.Notes))
+	if notes == "" {
+		notes = oldWifi.Notes
+	} else if notes == "-" {
+		notes = ""
+	}
+	newWifi.Notes = notes
+
+	return updateWifi(db, out, oldWifi.Name, newWifi)
+}
+
+func useTextEditor(db *bolt.DB, out io.Writer, oldWifi *pb.Wifi) error {
+	editor := cmdutil.SelectEditor()
+	bin, err := exec.LookPath(editor)
+	if err != nil {
+		return errors.Errorf("executable %q not found", editor)
+	}
+
+	filename, err := createTempFile(oldWifi)
+	if err != nil {
+		return err
+	}
+
+	sig.Signal.AddCleanup(func() error { return cmdutil.Erase(filename) })
+	defer cmdutil.Erase(filename)
+
+	// Open the temporary file with the selected text editor
+	edit := exec.Command(bin, filename)
+	edit.Stdin = os.Stdin
+	edit.Stdout = os.Stdout
+
+	if err := edit.Start(); err != nil {
+		return errors.Wrapf(err, "running %s", editor)
+	}
+
+	done := make(chan struct{}, 1)
+	errCh := make(chan error, 1)
+	go cmdutil.WatchFile(filename, done, errCh)
+
+	// Block until an event is received or an error occurs
+	select {
+	case <-done:
+	case err := <-errCh:
+		return err
+	}
+
+	if err := edit.Wait(); err != nil {
+		return err
+	}
+
+	newWifi, err := readTmpFile(filename)
+	if err != nil {
+		return err
+	}
+
+	rmTabs := func(old string) string {
+		return strings.ReplaceAll(old, "\t", "")
+	}
+	newWifi.Name = rmTabs(newWifi.Name)
+	newWifi.Ssid = rmTabs(newWifi.Ssid)
+	newWifi.Security = rmTabs(newWifi.Security)
+	newWifi.Password = rmTabs(newWifi.Password)
+	newWifi.Notes = rmTabs(newWifi.Notes)
+
+	return updateWifi(db, out, oldWifi.Name, newWifi)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "y"
+	}
+	return "n"
+}
@@ -0,0 +1,198 @@
+package edit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/config"
+	"github.com/GGP1/kure/db/wifi"
+	"github.com/GGP1/kure/pb"
+
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestEditErrors(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../../db/testdata/database")
+	createWifi(t, db, "test")
+
+	cases := []struct {
+		desc string
+		name string
+		it   string
+		set  func()
+	}{
+		{
+			desc: "Invalid name",
+			name: "",
+			set:  func() {},
+		},
+		{
+			desc: "Non-existent network",
+			name: "non-existent",
+			it:   "true",
+			set: func() {
+				config.Set("editor", "non-existent")
+			},
+		},
+	}
+
+	cmd := NewCmd(db, nil)
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			tc.set()
+			cmd.SetArgs([]string{tc.name})
+			cmd.Flags().Set("it", tc.it)
+
+			if err := cmd.Execute(); err == nil {
+				t.Error("Expected an error and got nil")
+			}
+		})
+	}
+}
+
+func TestCreateTempFile(t *testing.T) {
+	w := &pb.Wifi{Name: "test-create-file", Ssid: "Home", Hidden: true}
+	filename, err := createTempFile(w)
+	if err != nil {
+		t.Fatalf("Failed creating the file: %v", err)
+	}
+	defer os.Remove(filename)
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Errorf("Failed reading the file: %v", err)
+	}
+
+	var got pb.Wifi
+	if err := json.Unmarshal(content, &got); err != nil {
+		t.Errorf("Failed decoding the file: %v", err)
+	}
+
+	if !reflect.DeepEqual(w, &got) {
+		t.Error("Expected Wi-Fi networks to be deep equal")
+	}
+}
+
+func TestReadTmpFile(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		if _, err := readTmpFile("testdata/test_read.json"); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		cases := []struct {
+			desc     string
+			filename string
+		}{
+			{
+				desc:     "Does not exists",
+				filename: "testdata/does_not_exists.json",
+			},
+			{
+				desc:     "EOF",
+				filename: "testdata/test_read_EOF.json",
+			},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.desc, func(t *testing.T) {
+				if _, err := readTmpFile(tc.filename); err == nil {
+					t.Error("Expected an error and got nil")
+				}
+			})
+		}
+	})
+}
+
+func TestUpdateWifi(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../../db/testdata/database")
+	name := "test_update"
+	createWifi(t, db, name)
+
+	newName := "new_name"
+	newWifi := &pb.Wifi{
+		Name:     newName,
+		Ssid:     "Guests",
+		Security: "none",
+		Password: "discarded",
+	}
+
+	if err := updateWifi(db, io.Discard, name, newWifi); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := wifi.Get(db, newName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Security != cmdutil.WifiNone || got.Password != "" {
+		t.Errorf("Expected an open network without password, got %v", got)
+	}
+	if _, err := wifi.Get(db, name); err == nil {
+		t.Error("Expected the old network to be removed")
+	}
+
+	t.Run("Invalid name", func(t *testing.T) {
+		newWifi.Name = ""
+		if err := updateWifi(db, io.Discard, "fail", newWifi); err == nil {
+			t.Error("Expected an error and got nil")
+		}
+	})
+
+	t.Run("Invalid security", func(t *testing.T) {
+		newWifi.Name = newName
+		newWifi.Security = "WPA9"
+		if err := updateWifi(db, io.Discard, newName, newWifi); err == nil {
+			t.Error("Expected an error and got nil")
+		}
+	})
+}
+
+func TestUseStdin(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../../db/testdata/database")
+
+	oldWifi := &pb.Wifi{
+		Name:     "test",
+		Ssid:     "Home",
+		Security: cmdutil.WifiWPA2,
+		Password: "secret",
+		Hidden:   true,
+		Notes:    "test\nnotes",
+	}
+
+	buf := bytes.NewBufferString("\n\nwpa3\n\nn\n-<\n")
+
+	if err := useStdin(db, buf, io.Discard, oldWifi); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := wifi.Get(db, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &pb.Wifi{
+		Name:     "test",
+		Ssid:     "Home",
+		Security: cmdutil.WifiWPA3,
+		Password: "secret",
+	}
+	if !proto.Equal(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func createWifi(t *testing.T, db *bolt.DB, name string) {
+	t.Helper()
+	if err := wifi.Create(db, &pb.Wifi{Name: name}); err != nil {
+		t.Fatalf("Failed creating the Wi-Fi network: %v", err)
+	}
+}
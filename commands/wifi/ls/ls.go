@@ -0,0 +1,157 @@
+package ls
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/GGP1/kure/auth"
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/wifi"
+	"github.com/GGP1/kure/orderedmap"
+	"github.com/GGP1/kure/pb"
+
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+)
+
+const example = `
+* List one and show the QR code to join the network from a phone
+kure wifi ls Home -q
+
+* List one and show the password
+kure wifi ls Home -s
+
+* Filter by name
+kure wifi ls Ho* -f
+
+* List all
+kure wifi ls`
+
+type lsOptions struct {
+	filter, qr, show bool
+}
+
+// NewCmd returns a new command.
+func NewCmd(db *bolt.DB) *cobra.Command {
+	opts := lsOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "ls <name>",
+		Short: "List Wi-Fi networks",
+		Long: `List Wi-Fi networks.
+
+The QR code contains the network in the standard "WIFI:" format, phones can join it by scanning the code with the camera.`,
+		Example: example,
+		Args:    cmdutil.MustExistLs(db, cmdutil.Wifi),
+		PreRunE: auth.Login(db),
+		RunE:    runLs(db, &opts),
+	}
+
+	f := cmd.Flags()
+	f.BoolVarP(&opts.filter, "filter", "f", false, "filter by name")
+	f.BoolVarP(&opts.qr, "qr", "q", false, "show the QR code to join the network on the terminal")
+	f.BoolVarP(&opts.show, "show", "s", false, "show the network password")
+
+	return cmd
+}
+
+func runLs(db *bolt.DB, opts *lsOptions) cmdutil.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		name := strings.Join(args, " ")
+		name = cmdutil.NormalizeName(name)
+
+		// List all
+		if name == "" {
+			wifis, err := wifi.ListNames(db)
+			if err != nil {
+				return err
+			}
+
+			return cmdutil.PrintNames(cmd, wifis)
+		}
+
+		// Filter by name
+		if opts.filter {
+			wifis, err := wifi.ListNames(db)
+			if err != nil {
+				return err
+			}
+
+			var matches []string
+			for _, w := range wifis {
+				matched, err := filepath.Match(name, w)
+				if err != nil {
+					return err
+				}
+
+				if matched {
+					matches = append(matches, w)
+				}
+			}
+
+			if len(matches) == 0 {
+				return errors.New("no Wi-Fi networks were found")
+			}
+
+			return cmdutil.PrintNames(cmd, matches)
+		}
+
+		// List one
+		format, err := cmdutil.OutputFormat(cmd)
+		if err != nil {
+			return err
+		}
+
+		w, err := wifi.Get(db, name)
+		if err != nil {
+			return err
+		}
+
+		if format != cmdutil.OutputDefault {
+			return cmdutil.WriteOutput(cmd.OutOrStdout(), format, wifiOutput(name, w, opts.show))
+		}
+
+		if opts.qr {
+			if err := cmdutil.DisplayQRCode(cmd.OutOrStdout(), cmdutil.WifiPayload(w)); err != nil {
+				return err
+			}
+		}
+
+		printWifi(cmd.OutOrStdout(), name, w, opts.show)
+		return nil
+	}
+}
+
+func wifiOutput(name string, w *pb.Wifi, show bool) *cmdutil.WifiOutput {
+	out := &cmdutil.WifiOutput{
+		Name:     name,
+		SSID:     w.Ssid,
+		Security: w.Security,
+		Hidden:   w.Hidden,
+		Notes:    w.Notes,
+	}
+	if show {
+		out.Password = &w.Password
+	}
+	return out
+}
+
+func printWifi(out io.Writer, name string, w *pb.Wifi, show bool) {
+	if !show && w.Password != "" {
+		w.Password = "•••••••••••••••"
+	}
+
+	mp := orderedmap.New()
+	mp.Set("SSID", w.Ssid)
+	mp.Set("Security", w.Security)
+	mp.Set("Password", w.Password)
+	mp.Set("Hidden", strconv.FormatBool(w.Hidden))
+	mp.Set("Notes", w.Notes)
+
+	box := cmdutil.BuildBox(name, mp)
+	fmt.Fprintln(out, "\n"+box)
+}
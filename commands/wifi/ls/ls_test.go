@@ -0,0 +1,149 @@
+package ls
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/wifi"
+	"github.com/GGP1/kure/pb"
+)
+
+func TestLs(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../../db/testdata/database")
+
+	if err := wifi.Create(db, &pb.Wifi{
+		Name:     "test",
+		Ssid:     "Home",
+		Security: cmdutil.WifiWPA2,
+		Password: "secret",
+	}); err != nil {
+		t.Fatalf("Failed creating the Wi-Fi network: %v", err)
+	}
+
+	cases := []struct {
+		desc   string
+		name   string
+		filter string
+		show   string
+		qr     string
+	}{
+		{
+			desc: "List one",
+			name: "test",
+		},
+		{
+			desc: "List one and show qr",
+			name: "test",
+			qr:   "true",
+		},
+		{
+			desc:   "Filter by name",
+			name:   "te*",
+			filter: "true",
+		},
+		{
+			desc: "List all",
+			name: "",
+		},
+		{
+			desc: "List one and show",
+			name: "test",
+			show: "true",
+		},
+	}
+
+	cmd := NewCmd(db)
+	f := cmd.Flags()
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cmd.SetArgs([]string{tc.name})
+			f.Set("filter", tc.filter)
+			f.Set("show", tc.show)
+			f.Set("qr", tc.qr)
+
+			if err := cmd.Execute(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestLsOutput(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../../db/testdata/database")
+
+	if err := wifi.Create(db, &pb.Wifi{Name: "test", Ssid: "Home", Password: "secret"}); err != nil {
+		t.Fatalf("Failed creating the Wi-Fi network: %v", err)
+	}
+
+	for _, show := range []bool{false, true} {
+		buf := new(bytes.Buffer)
+		cmd := NewCmd(db)
+		cmdutil.AddOutputFlag(cmd)
+		cmd.SetOut(buf)
+		args := []string{"test", "--output", "json"}
+		if show {
+			args = append(args, "-s")
+		}
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+
+		var got cmdutil.WifiOutput
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.SSID != "Home" {
+			t.Errorf("Expected SSID %q, got %q", "Home", got.SSID)
+		}
+		if show != (got.Password != nil) {
+			t.Errorf("Expected the password to be included only when requested (show: %t)", show)
+		}
+	}
+}
+
+func TestLsErrors(t *testing.T) {
+	db := cmdutil.SetContext(t, "../../../db/testdata/database")
+
+	if err := wifi.Create(db, &pb.Wifi{Name: "test"}); err != nil {
+		t.Fatalf("Failed creating the Wi-Fi network: %v", err)
+	}
+
+	cases := []struct {
+		desc   string
+		name   string
+		filter string
+	}{
+		{
+			desc:   "Wi-Fi network does not exist",
+			name:   "non-existent",
+			filter: "false",
+		},
+		{
+			desc:   "No networks found",
+			name:   "non-existent",
+			filter: "true",
+		},
+		{
+			desc:   "Filter syntax error",
+			name:   "[error",
+			filter: "true",
+		},
+	}
+
+	cmd := NewCmd(db)
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cmd.SetArgs([]string{tc.name})
+			cmd.Flags().Set("filter", tc.filter)
+
+			if err := cmd.Execute(); err == nil {
+				t.Error("Expected an error and got nil")
+			}
+		})
+	}
+}
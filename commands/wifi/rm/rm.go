@@ -0,0 +1,74 @@
+package rm
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/GGP1/kure/auth"
+	cmdutil "github.com/GGP1/kure/commands"
+	"github.com/GGP1/kure/db/wifi"
+
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+)
+
+const example = `
+* Remove a Wi-Fi network
+kure wifi rm Home
+
+* Remove a directory
+kure wifi rm Office/`
+
+// NewCmd returns the a new command.
+func NewCmd(db *bolt.DB, r io.Reader) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rm <name>",
+		Short:   "Remove a Wi-Fi network or directory",
+		Example: example,
+		Args:    cmdutil.MustExist(db, cmdutil.Wifi, true),
+		PreRunE: auth.Login(db),
+		RunE:    runRm(db, r),
+	}
+
+	return cmd
+}
+
+func runRm(db *bolt.DB, r io.Reader) cmdutil.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		name := strings.Join(args, " ")
+		name = cmdutil.NormalizeName(name, true)
+
+		if !cmdutil.Confirm(r, "Are you sure you want to proceed?") {
+			return nil
+		}
+
+		// Remove a single network
+		if !strings.HasSuffix(name, "/") {
+			if err := wifi.Remove(db, name); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "\n%q removed\n", name)
+			return nil
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Removing %q directory...\n", name)
+
+		wifis, err := wifi.ListNames(db)
+		if err != nil {
+			return err
+		}
+
+		for _, w := range wifis {
+			if strings.HasPrefix(w, name) {
+				if err := wifi.Remove(db, w); err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), "Remove:", w)
+			}
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,30 @@
+package wifi
+
+import (
+	"io"
+
+	wadd "github.com/GGP1/kure/commands/wifi/add"
+	wcopy "github.com/GGP1/kure/commands/wifi/copy"
+	wedit "github.com/GGP1/kure/commands/wifi/edit"
+	wls "github.com/GGP1/kure/commands/wifi/ls"
+	wrm "github.com/GGP1/kure/commands/wifi/rm"
+
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+)
+
+const example = `
+kure wifi (add|copy|edit|ls|rm)`
+
+// NewCmd returns a new command.
+func NewCmd(db *bolt.DB, r io.Reader) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "wifi",
+		Short:   "Wi-Fi network operations",
+		Example: example,
+	}
+
+	cmd.AddCommand(wadd.NewCmd(db, r), wcopy.NewCmd(db), wedit.NewCmd(db, r), wls.NewCmd(db), wrm.NewCmd(db, r))
+
+	return cmd
+}
@@ -0,0 +1,70 @@
+package cmdutil
+
+import (
+	"testing"
+
+	"github.com/GGP1/kure/pb"
+)
+
+func TestParseWifiSecurity(t *testing.T) {
+	cases := map[string]string{
+		"":      WifiWPA2,
+		"wpa":   WifiWPA,
+		"WPA2":  WifiWPA2,
+		" wpa3": WifiWPA3,
+		"wep":   WifiWEP,
+		"None":  WifiNone,
+		"open":  WifiNone,
+	}
+
+	for input, expected := range cases {
+		got, err := ParseWifiSecurity(input)
+		if err != nil {
+			t.Errorf("%q: %v", input, err)
+		}
+		if got != expected {
+			t.Errorf("%q: expected %q, got %q", input, expected, got)
+		}
+	}
+
+	if _, err := ParseWifiSecurity("WPA4"); err == nil {
+		t.Error("Expected an error and got nil")
+	}
+}
+
+func TestWifiPayload(t *testing.T) {
+	cases := []struct {
+		desc     string
+		wifi     *pb.Wifi
+		expected string
+	}{
+		{
+			desc:     "WPA2",
+			wifi:     &pb.Wifi{Ssid: "Home", Security: WifiWPA2, Password: "secret"},
+			expected: "WIFI:T:WPA;S:Home;P:secret;;",
+		},
+		{
+			desc:     "WEP hidden",
+			wifi:     &pb.Wifi{Ssid: "Office", Security: WifiWEP, Password: "secret", Hidden: true},
+			expected: "WIFI:T:WEP;S:Office;P:secret;H:true;;",
+		},
+		{
+			desc:     "Open",
+			wifi:     &pb.Wifi{Ssid: "Guests", Security: WifiNone, Password: "ignored"},
+			expected: "WIFI:T:nopass;S:Guests;;",
+		},
+		{
+			desc:     "Escape special characters",
+			wifi:     &pb.Wifi{Ssid: `"My;Wi-Fi"`, Security: WifiWPA3, Password: `a:b,c\d`},
+			expected: `WIFI:T:WPA;S:\"My\;Wi-Fi\";P:a\:b\,c\\d;;`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := WifiPayload(tc.wifi); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
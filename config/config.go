@@ -87,6 +87,11 @@ func GetEnclave(key string) *memguard.Enclave {
 	return v.(*memguard.Enclave)
 }
 
+// GetBool returns a boolean from the config map.
+func GetBool(key string) bool {
+	return cast.ToBool(config.Get(key))
+}
+
 // GetDuration returns a duration from the config map.
 func GetDuration(key string) time.Duration {
 	return cast.ToDuration(config.Get(key))
@@ -130,13 +135,15 @@ func Set(key string, value interface{}) {
 // SetDefaults populates the config map with the default values.
 func SetDefaults(dbPath string) {
 	var defaults = map[string]interface{}{
-		"clipboard.timeout": "0s",
-		"database.path":     dbPath,
-		"editor":            "vim",
-		"keyfile.path":      "",
-		"session.prefix":    "kure:~ $",
-		"session.scripts":   map[string]string{},
-		"session.timeout":   "0s",
+		"clipboard.backend":  "auto",
+		"clipboard.timeout":  "0s",
+		"database.path":      dbPath,
+		"editor":             "vim",
+		"keyfile.path":       "",
+		"password.min_score": 2,
+		"session.prefix":     "kure:~ $",
+		"session.scripts":    map[string]string{},
+		"session.timeout":    "0s",
 	}
 
 	for k, v := range defaults {
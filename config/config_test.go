@@ -134,6 +134,17 @@ func TestGetEnclave(t *testing.T) {
 	})
 }
 
+func TestGetBool(t *testing.T) {
+	key := "test"
+	config.mp = map[string]interface{}{
+		key: true,
+	}
+
+	if !GetBool(key) {
+		t.Error("Expected true and got false")
+	}
+}
+
 func TestGetDuration(t *testing.T) {
 	key := "test"
 	expected := time.Duration(10)
@@ -189,6 +200,7 @@ func TestGetUint32(t *testing.T) {
 
 func TestSetDefaults(t *testing.T) {
 	defaults := map[string]string{
+		"clipboard.backend": "auto",
 		"clipboard.timeout": "0s",
 		"database.path":     "test",
 		"editor":            "vim",
@@ -1,8 +1,15 @@
 package auth
 
 import (
+	"bytes"
 	"crypto/rand"
 	"encoding/binary"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/GGP1/kure/crypt"
 	dbutil "github.com/GGP1/kure/db"
@@ -11,28 +18,112 @@ import (
 	bolt "go.etcd.io/bbolt"
 )
 
+var authBucket = []byte("kure_auth")
+
+// Vault keys prefixes, the vault number is appended to them.
+const (
+	// authKeyPrefix is the key we are trying to decrypt on every Login
+	authKeyPrefix = "key_"
+	iterKeyPrefix = "iterations_"
+	memKeyPrefix  = "memory_"
+	thKeyPrefix   = "threads_"
+	// keyfileKeyPrefix will exist only if the vault uses a key file, its value is the key file fingerprint
+	keyfileKeyPrefix = "keyfile_"
+)
+
+// KeySize is the length of the authentication keys before being encrypted.
+const KeySize = 32
+
+// The decoy vaults keys end with decoyMarker followed by a byte containing their flags, both are only
+// visible after decrypting them.
+var decoyMarker = make([]byte, KeySize/2-1)
+
+// destroyFlag is set in the decoy keys of the vaults that destroy the other one when they are opened.
+const destroyFlag byte = 1
+
+// legacyKeyfile is the value stored by versions that didn't save the key file fingerprint.
+var legacyKeyfile = []byte("1")
+
+// keyfileSaltKey holds the random salt used to derive the key file fingerprints, each database has
+// its own so they can't be compared across databases.
+var keyfileSaltKey = []byte("keyfile_salt")
+
+const keyfileSaltSize = 32
+
+// legacyRecordsKey exists while the records moved to a vault by Upgrade weren't migrated yet, which
+// requires decrypting them.
+var legacyRecordsKey = []byte("legacy_records")
+
+// Vaults being destroyed in the background, Close() waits for them to finish.
 var (
-	authBucket = []byte("kure_auth")
-	// authKey is the key we are trying to decrypt on every Login
-	authKey = []byte("key")
-	// keyfileKey will exist only if the user uses a keyfile
-	keyfileKey = []byte("keyfile")
-	iterKey    = []byte("iterations")
-	memKey     = []byte("memory")
-	thKey      = []byte("threads")
+	pending    sync.WaitGroup
+	pendingMu  sync.Mutex
+	pendingErr error
 )
 
+// destroyed is set to 1 when a vault is destroyed and the database must be compacted when closing it,
+// it should be always accessed atomically.
+var destroyed int32
+
+// Layout of the databases created before the vaults existed.
+var (
+	legacySlot = slot{
+		key:        []byte("key"),
+		iterations: []byte("iterations"),
+		memory:     []byte("memory"),
+		threads:    []byte("threads"),
+		// Both vaults shared the key file until each one got its own
+		keyfile: []byte("keyfile"),
+	}
+	legacyBuckets = [][]byte{
+		[]byte("kure_card"),
+		[]byte("kure_entry"),
+		[]byte("kure_file"),
+		[]byte("kure_totp"),
+		[]byte("kure_wifi"),
+	}
+)
+
+// slot contains the keys under which a vault's parameters are stored.
+type slot struct {
+	key        []byte
+	iterations []byte
+	memory     []byte
+	threads    []byte
+	keyfile    []byte
+}
+
+// vaultSlot returns the keys of the vault passed.
+func vaultSlot(v dbutil.Vault) slot {
+	n := strconv.FormatUint(uint64(v), 10)
+	return slot{
+		key:        []byte(authKeyPrefix + n),
+		iterations: []byte(iterKeyPrefix + n),
+		memory:     []byte(memKeyPrefix + n),
+		threads:    []byte(thKeyPrefix + n),
+		keyfile:    []byte(keyfileKeyPrefix + n),
+	}
+}
+
 // Parameters contains all the information needed for logging in.
 type Parameters struct {
-	AuthKey    []byte
-	Iterations uint32
-	Memory     uint32
-	Threads    uint32
-	UseKeyfile bool
+	AuthKey            []byte
+	KeyfileFingerprint []byte
+	Iterations         uint32
+	Memory             uint32
+	Threads            uint32
+	UseKeyfile         bool
+	// Vault is the vault the parameters belong to
+	Vault dbutil.Vault
+	// Decoy is true if the vault is a decoy one, it's only known after decrypting the key
+	Decoy bool
+	// Destroy is true if opening the decoy vault destroys the other one, it's only known after
+	// decrypting the key
+	Destroy bool
 }
 
-// GetParameters returns the authentication parameters.
-func GetParameters(db *bolt.DB) (Parameters, error) {
+// GetParameters returns the authentication parameters of the vault passed.
+func GetParameters(db *bolt.DB, v dbutil.Vault) (Parameters, error) {
 	tx, err := db.Begin(false)
 	if err != nil {
 		return Parameters{}, err
@@ -41,45 +132,401 @@ func GetParameters(db *bolt.DB) (Parameters, error) {
 
 	b := tx.Bucket(authBucket)
 	if b == nil {
-		return Parameters{}, nil
+		return Parameters{Vault: v}, nil
 	}
 
-	params := make(map[string][]byte, 5)
-	_ = b.ForEach(func(k, v []byte) error {
-		params[string(k)] = v
-		return nil
-	})
-
+	s := vaultSlot(v)
 	// Key file will be used only if it isn't nil
 	useKeyfile := false
-	if _, ok := params[string(keyfileKey)]; ok {
+	var fingerprint []byte
+	if value := b.Get(s.keyfile); value != nil {
 		useKeyfile = true
+		if !bytes.Equal(value, legacyKeyfile) {
+			fingerprint = append([]byte(nil), value...)
+		}
+	}
+
+	var authKey []byte
+	if key := b.Get(s.key); key != nil {
+		authKey = append([]byte(nil), key...)
 	}
 
 	return Parameters{
-		AuthKey:    params[string(authKey)],
-		Iterations: binary.BigEndian.Uint32(params[string(iterKey)]),
-		Memory:     binary.BigEndian.Uint32(params[string(memKey)]),
-		Threads:    binary.BigEndian.Uint32(params[string(thKey)]),
-		UseKeyfile: useKeyfile,
+		AuthKey:            authKey,
+		KeyfileFingerprint: fingerprint,
+		Iterations:         getUint32(b, s.iterations),
+		Memory:             getUint32(b, s.memory),
+		Threads:            getUint32(b, s.threads),
+		UseKeyfile:         useKeyfile,
+		Vault:              v,
 	}, nil
 }
 
-// Register creates all the buckets, saves the authentication key and the argon2 parameters used.
+// Register creates the vaults buckets, saves the authentication key and the argon2 parameters used
+// by the vault passed in params.
+//
+// If the other vault doesn't have a key yet, it's given random bytes and the same parameters so
+// the database looks the same with or without a duress password.
 func Register(db *bolt.DB, params Parameters) error {
 	return db.Update(func(tx *bolt.Tx) error {
 		// Create all the buckets except auth, it will be created in setParameters()
-		buckets := [][]byte{dbutil.CardBucket, dbutil.EntryBucket, dbutil.FileBucket, dbutil.TOTPBucket}
-		for _, bucket := range buckets {
-			if _, err := tx.CreateBucketIfNotExists([]byte(bucket)); err != nil {
-				return errors.Wrapf(err, "creating %q bucket", bucket)
+		for _, v := range dbutil.Vaults {
+			if err := createBuckets(tx, v.Buckets()); err != nil {
+				return err
+			}
+		}
+
+		if err := setParameters(tx, params); err != nil {
+			return err
+		}
+
+		b := tx.Bucket(authBucket)
+		other := vaultSlot(params.Vault.Other())
+		if b.Get(other.key) != nil {
+			return nil
+		}
+
+		if err := putArgon2Params(b, other, params); err != nil {
+			return err
+		}
+		if err := putKeyfile(b, other, params); err != nil {
+			return err
+		}
+		return putRandomBytes(b, other.key, len(b.Get(vaultSlot(params.Vault).key)))
+	})
+}
+
+// RandomVault returns one of the vaults at random, the location of the real vault must not be
+// predictable.
+func RandomVault() (dbutil.Vault, error) {
+	b := make([]byte, 1)
+	if _, err := rand.Read(b); err != nil {
+		return 0, errors.Wrap(err, "generating random bytes")
+	}
+	return dbutil.Vaults[int(b[0])%len(dbutil.Vaults)], nil
+}
+
+// RegisterDecoy creates an empty decoy vault in the vault passed in params and saves its authentication
+// key, encrypted with the password set in the configuration, and the argon2 parameters used.
+//
+// If params.Destroy is true, opening the decoy vault destroys the other one. The flag is stored inside
+// the encrypted key so it can't be seen without the duress password.
+//
+// The vault previous records are overwritten with random bytes and deleted.
+func RegisterDecoy(db *bolt.DB, params Parameters) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		if err := wipeBuckets(tx, params.Vault.Buckets()); err != nil {
+			return err
+		}
+		if err := resetBuckets(tx, params.Vault.Buckets()); err != nil {
+			return err
+		}
+
+		b, err := tx.CreateBucketIfNotExists(authBucket)
+		if err != nil {
+			return errors.Wrap(err, "creating auth bucket")
+		}
+
+		s := vaultSlot(params.Vault)
+		if err := putArgon2Params(b, s, params); err != nil {
+			return err
+		}
+		if err := putKeyfile(b, s, params); err != nil {
+			return err
+		}
+
+		return putAuthKey(b, s.key, true, params.Destroy)
+	})
+}
+
+// IsDecoyKey returns whether the decrypted authentication key belongs to a decoy vault.
+func IsDecoyKey(key []byte) bool {
+	if len(key) != KeySize {
+		return false
+	}
+	flags := key[KeySize-1]
+	return bytes.Equal(key[KeySize-1-len(decoyMarker):KeySize-1], decoyMarker) && flags&^destroyFlag == 0
+}
+
+// IsDestroyKey returns whether the decrypted authentication key belongs to a decoy vault that destroys
+// the other one when it's opened.
+func IsDestroyKey(key []byte) bool {
+	return IsDecoyKey(key) && key[KeySize-1]&destroyFlag != 0
+}
+
+// DestroyVault overwrites the records of the vault passed with random bytes, deletes them and
+// overwrites its authentication key so it can't be opened anymore.
+//
+// The key keeps its length so the database layout doesn't change. bbolt doesn't zero the pages it
+// frees, the previous records are discarded from the file when it's compacted by Close().
+func DestroyVault(db *bolt.DB, v dbutil.Vault) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		return destroyVault(tx, v)
+	})
+}
+
+// DestroyVaultInBackground destroys the vault passed without waiting for it to finish, so the time it
+// takes can't be told from the caller's. Close() waits for it and returns its error, if any.
+func DestroyVaultInBackground(db *bolt.DB, v dbutil.Vault) {
+	pending.Add(1)
+	go func() {
+		defer pending.Done()
+		if err := DestroyVault(db, v); err != nil {
+			pendingMu.Lock()
+			pendingErr = err
+			pendingMu.Unlock()
+		}
+	}()
+}
+
+// Wait blocks until the vaults being destroyed in the background are destroyed and returns the last
+// error encountered, if any.
+func Wait() error {
+	pending.Wait()
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+	return pendingErr
+}
+
+// OpenVault prepares the vault passed to be used after logging in: it creates the buckets missing,
+// databases registered before a record type was introduced lack them, and stores the key file
+// fingerprint if it's not nil.
+//
+// The database is written only if there is something to change, logging in doesn't modify it otherwise.
+func OpenVault(db *bolt.DB, v dbutil.Vault, fingerprint []byte) error {
+	missing := false
+	_ = db.View(func(tx *bolt.Tx) error {
+		for _, vault := range dbutil.Vaults {
+			for _, bucket := range vault.Buckets() {
+				if tx.Bucket(bucket) == nil {
+					missing = true
+				}
+			}
+		}
+		return nil
+	})
+	if !missing && fingerprint == nil {
+		return nil
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(authBucket)
+		if b == nil {
+			return errors.New("the auth bucket does not exist")
+		}
+
+		for _, vault := range dbutil.Vaults {
+			if err := createBuckets(tx, vault.Buckets()); err != nil {
+				return err
+			}
+		}
+
+		if fingerprint != nil {
+			if err := b.Put(vaultSlot(v).keyfile, fingerprint); err != nil {
+				return errors.Wrap(err, "saving key file fingerprint")
+			}
+		}
+		return nil
+	})
+}
+
+// KeyfileSalt returns the salt used to derive the key file fingerprints, it's nil if the database
+// wasn't upgraded yet.
+func KeyfileSalt(db *bolt.DB) ([]byte, error) {
+	var salt []byte
+	err := db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(authBucket); b != nil {
+			if value := b.Get(keyfileSaltKey); value != nil {
+				salt = append([]byte(nil), value...)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "reading key file salt")
+	}
+	return salt, nil
+}
+
+// Close waits for the vaults being destroyed in the background, closes the database and, if a vault
+// was destroyed, compacts it.
+//
+// The compacted copy replaces the database file and the previous one is overwritten with random bytes,
+// so the records destroyed can't be recovered from the pages bbolt freed.
+func Close(db *bolt.DB) error {
+	destroyErr := Wait()
+
+	path := db.Path()
+	if err := db.Close(); err != nil {
+		return errors.Wrap(err, "closing the database")
+	}
+
+	if atomic.LoadInt32(&destroyed) == 1 {
+		if err := compact(path); err != nil {
+			return err
+		}
+	}
+	return destroyErr
+}
+
+// Upgrade migrates the databases created with a previous layout:
+//
+// The records and parameters of databases created before the vaults existed are moved to a random
+// vault, the other one is given random bytes as its key.
+//
+// The key file value shared by both vaults is copied to each of them.
+//
+// Databases without a key file salt get one. The fingerprints stored without it are discarded, the
+// salted ones are stored on the next login.
+func Upgrade(db *bolt.DB) error {
+	var legacy, sharedKeyfile bool
+	unsalted := true
+	_ = db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(authBucket); b != nil {
+			legacy = b.Get(legacySlot.key) != nil
+			sharedKeyfile = b.Get(legacySlot.keyfile) != nil
+			unsalted = b.Get(keyfileSaltKey) == nil
+		}
+		return nil
+	})
+	if !legacy && !sharedKeyfile && !unsalted {
+		return nil
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(authBucket)
+		if err != nil {
+			return errors.Wrap(err, "creating auth bucket")
+		}
+
+		if legacy {
+			if err := moveToVault(tx); err != nil {
+				return err
+			}
+		}
+		if sharedKeyfile {
+			if err := splitKeyfile(b); err != nil {
+				return err
 			}
 		}
+		if unsalted {
+			return saltKeyfiles(b)
+		}
+		return nil
+	})
+}
 
-		return setParameters(tx, params)
+// MigrateRecords calls migrate if the records were stored before the vaults existed and weren't
+// migrated yet, it must be called once logged in as the records are encrypted.
+//
+// Those records are moved to a vault on the first login, before a duress password can be registered,
+// so the vault opened is always the one containing them.
+func MigrateRecords(db *bolt.DB, migrate func(db *bolt.DB) error) error {
+	pending := false
+	_ = db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(authBucket); b != nil {
+			pending = b.Get(legacyRecordsKey) != nil
+		}
+		return nil
+	})
+	if !pending {
+		return nil
+	}
+
+	if err := migrate(db); err != nil {
+		return errors.Wrap(err, "migrating records")
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(authBucket).Delete(legacyRecordsKey); err != nil {
+			return errors.Wrap(err, "deleting legacy records mark")
+		}
+		return nil
 	})
 }
 
+// moveToVault moves the records and parameters stored before the vaults existed to a random vault,
+// the other one is given random bytes as its key.
+func moveToVault(tx *bolt.Tx) error {
+	v, err := RandomVault()
+	if err != nil {
+		return err
+	}
+
+	for i, bucket := range v.Buckets() {
+		if err := moveBucket(tx, legacyBuckets[i], bucket); err != nil {
+			return err
+		}
+	}
+	if err := createBuckets(tx, v.Other().Buckets()); err != nil {
+		return err
+	}
+
+	b := tx.Bucket(authBucket)
+	s, other := vaultSlot(v), vaultSlot(v.Other())
+	keys := [][3][]byte{
+		{legacySlot.iterations, s.iterations, other.iterations},
+		{legacySlot.memory, s.memory, other.memory},
+		{legacySlot.threads, s.threads, other.threads},
+	}
+	for _, k := range keys {
+		value := append([]byte(nil), b.Get(k[0])...)
+		if err := b.Put(k[1], value); err != nil {
+			return errors.Wrapf(err, "saving %q", k[1])
+		}
+		if err := b.Put(k[2], value); err != nil {
+			return errors.Wrapf(err, "saving %q", k[2])
+		}
+		if err := b.Delete(k[0]); err != nil {
+			return errors.Wrapf(err, "deleting %q", k[0])
+		}
+	}
+
+	key := append([]byte(nil), b.Get(legacySlot.key)...)
+	if err := b.Put(s.key, key); err != nil {
+		return errors.Wrap(err, "saving auth key")
+	}
+	if err := b.Delete(legacySlot.key); err != nil {
+		return errors.Wrap(err, "deleting auth key")
+	}
+
+	if err := b.Put(legacyRecordsKey, []byte{1}); err != nil {
+		return errors.Wrap(err, "saving legacy records mark")
+	}
+
+	return putRandomBytes(b, other.key, len(key))
+}
+
+// splitKeyfile copies the key file value shared by both vaults to each of them and deletes it.
+func splitKeyfile(b *bolt.Bucket) error {
+	value := append([]byte(nil), b.Get(legacySlot.keyfile)...)
+	for _, v := range dbutil.Vaults {
+		if err := b.Put(vaultSlot(v).keyfile, value); err != nil {
+			return errors.Wrap(err, "saving key file value")
+		}
+	}
+
+	if err := b.Delete(legacySlot.keyfile); err != nil {
+		return errors.Wrap(err, "deleting key file value")
+	}
+	return nil
+}
+
+// saltKeyfiles generates the key file salt and replaces the fingerprints stored without it.
+func saltKeyfiles(b *bolt.Bucket) error {
+	for _, v := range dbutil.Vaults {
+		keyfile := vaultSlot(v).keyfile
+		if b.Get(keyfile) == nil {
+			continue
+		}
+		if err := b.Put(keyfile, legacyKeyfile); err != nil {
+			return errors.Wrap(err, "discarding key file fingerprint")
+		}
+	}
+
+	return putRandomBytes(b, keyfileSaltKey, keyfileSaltSize)
+}
+
 // setParameters creates the auth bucket and sets parameters.
 //
 // The transaction shouldn't be closed as it's already handled by Register().
@@ -89,7 +536,37 @@ func setParameters(tx *bolt.Tx, params Parameters) error {
 		return errors.Wrap(err, "creating auth bucket")
 	}
 
-	// Argon2
+	s := vaultSlot(params.Vault)
+	if err := putArgon2Params(b, s, params); err != nil {
+		return err
+	}
+
+	if err := putKeyfile(b, s, params); err != nil {
+		return err
+	}
+
+	return putAuthKey(b, s.key, params.Decoy, params.Destroy)
+}
+
+// destroyVault overwrites the vault key and records with random bytes and deletes the latter.
+func destroyVault(tx *bolt.Tx, v dbutil.Vault) error {
+	b := tx.Bucket(authBucket)
+	if b == nil {
+		return errors.New("the auth bucket does not exist")
+	}
+
+	key := vaultSlot(v).key
+	if err := putRandomBytes(b, key, len(b.Get(key))); err != nil {
+		return err
+	}
+
+	if err := wipeBuckets(tx, v.Buckets()); err != nil {
+		return err
+	}
+	return resetBuckets(tx, v.Buckets())
+}
+
+func putArgon2Params(b *bolt.Bucket, s slot, params Parameters) error {
 	i := make([]byte, 4)
 	m := make([]byte, 4)
 	t := make([]byte, 4)
@@ -97,44 +574,226 @@ func setParameters(tx *bolt.Tx, params Parameters) error {
 	binary.BigEndian.PutUint32(m, params.Memory)
 	binary.BigEndian.PutUint32(t, params.Threads)
 
-	if err := b.Put(iterKey, i); err != nil {
+	if err := b.Put(s.iterations, i); err != nil {
 		return errors.Wrap(err, "saving iterations")
 	}
 
-	if err := b.Put(memKey, m); err != nil {
+	if err := b.Put(s.memory, m); err != nil {
 		return errors.Wrap(err, "saving memory")
 	}
 
-	if err := b.Put(thKey, t); err != nil {
+	if err := b.Put(s.threads, t); err != nil {
 		return errors.Wrap(err, "saving threads")
 	}
 
-	// Keyfile
-	if params.UseKeyfile {
-		if err := b.Put(keyfileKey, []byte("1")); err != nil {
-			return errors.Wrap(err, "saving key file value")
-		}
-	} else {
+	return nil
+}
+
+// putKeyfile stores the key file fingerprint in the slot passed if params.UseKeyfile is true, otherwise
+// it deletes it.
+//
+// Every vault has its own key file value, so changing it doesn't lock the other vault out.
+func putKeyfile(b *bolt.Bucket, s slot, params Parameters) error {
+	if !params.UseKeyfile {
 		// Does not fail if the key doesn't exist
-		if err := b.Delete(keyfileKey); err != nil {
+		if err := b.Delete(s.keyfile); err != nil {
 			return errors.Wrap(err, "deleting key file value")
 		}
+		return nil
 	}
 
-	// Auth key
-	key := make([]byte, 32)
+	value := params.KeyfileFingerprint
+	if value == nil {
+		value = legacyKeyfile
+	}
+	if err := b.Put(s.keyfile, value); err != nil {
+		return errors.Wrap(err, "saving key file value")
+	}
+	return nil
+}
+
+// putAuthKey generates a random key, encrypts it and stores it under name. Decoy keys end with
+// decoyMarker and their flags, which can only be seen by those who know the password.
+func putAuthKey(b *bolt.Bucket, name []byte, decoy, destroy bool) error {
+	key := make([]byte, KeySize)
 	if _, err := rand.Read(key); err != nil {
 		return errors.Wrap(err, "generating key")
 	}
+	if decoy {
+		copy(key[KeySize-1-len(decoyMarker):], decoyMarker)
+		key[KeySize-1] = 0
+		if destroy {
+			key[KeySize-1] = destroyFlag
+		}
+	}
 
 	encKey, err := crypt.Encrypt(key)
 	if err != nil {
 		return err
 	}
 
-	if err := b.Put(authKey, encKey); err != nil {
+	if err := b.Put(name, encKey); err != nil {
 		return errors.Wrap(err, "saving auth key")
 	}
 
 	return nil
 }
+
+// putRandomBytes stores size random bytes under name, they are indistinguishable from an encrypted key.
+func putRandomBytes(b *bolt.Bucket, name []byte, size int) error {
+	garbage := make([]byte, size)
+	if _, err := rand.Read(garbage); err != nil {
+		return errors.Wrap(err, "generating random bytes")
+	}
+
+	if err := b.Put(name, garbage); err != nil {
+		return errors.Wrapf(err, "overwriting %q", name)
+	}
+	return nil
+}
+
+// getUint32 returns the number stored under key, or zero if it doesn't exist.
+func getUint32(b *bolt.Bucket, key []byte) uint32 {
+	value := b.Get(key)
+	if len(value) < 4 {
+		return 0
+	}
+	return binary.BigEndian.Uint32(value)
+}
+
+// createBuckets creates the buckets passed that do not exist.
+func createBuckets(tx *bolt.Tx, buckets [][]byte) error {
+	for _, bucket := range buckets {
+		if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+			return errors.Wrapf(err, "creating %q bucket", bucket)
+		}
+	}
+	return nil
+}
+
+// wipeBuckets overwrites the values stored in the buckets passed with random bytes of the same length.
+//
+// If any value is overwritten, the database is compacted by Close().
+func wipeBuckets(tx *bolt.Tx, buckets [][]byte) error {
+	for _, bucket := range buckets {
+		b := tx.Bucket(bucket)
+		if b == nil {
+			continue
+		}
+
+		// Values can't be modified while iterating
+		var keys [][]byte
+		sizes := make(map[string]int)
+		_ = b.ForEach(func(k, v []byte) error {
+			keys = append(keys, append([]byte(nil), k...))
+			sizes[string(k)] = len(v)
+			return nil
+		})
+
+		for _, k := range keys {
+			if err := putRandomBytes(b, k, sizes[string(k)]); err != nil {
+				return errors.Wrapf(err, "wiping %q bucket", bucket)
+			}
+			atomic.StoreInt32(&destroyed, 1)
+		}
+	}
+	return nil
+}
+
+// resetBuckets deletes the buckets passed, if they exist, and creates them again.
+func resetBuckets(tx *bolt.Tx, buckets [][]byte) error {
+	for _, bucket := range buckets {
+		if err := tx.DeleteBucket(bucket); err != nil && err != bolt.ErrBucketNotFound {
+			return errors.Wrapf(err, "deleting %q bucket", bucket)
+		}
+		if _, err := tx.CreateBucket(bucket); err != nil {
+			return errors.Wrapf(err, "creating %q bucket", bucket)
+		}
+	}
+	return nil
+}
+
+// compact writes a compacted copy of the database to a temporary file, replaces the database with it
+// and overwrites the previous file contents with random bytes.
+func compact(path string) error {
+	tmp := path + ".tmp"
+	// Remove the file left by a compaction that didn't finish, if any
+	if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "removing temporary file")
+	}
+
+	src, err := bolt.Open(path, 0600, &bolt.Options{ReadOnly: true, Timeout: time.Second})
+	if err != nil {
+		return errors.Wrap(err, "opening the database")
+	}
+	defer src.Close()
+
+	dst, err := bolt.Open(tmp, 0600, nil)
+	if err != nil {
+		return errors.Wrap(err, "creating temporary file")
+	}
+
+	if err := bolt.Compact(dst, src, 0); err != nil {
+		dst.Close()
+		os.Remove(tmp)
+		return errors.Wrap(err, "compacting the database")
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmp)
+		return errors.Wrap(err, "closing temporary file")
+	}
+	if err := src.Close(); err != nil {
+		return errors.Wrap(err, "closing the database")
+	}
+
+	// Keep the previous file open to overwrite it once it's replaced, if the process stops in between
+	// the database is still readable
+	old, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return errors.Wrap(err, "opening the database file")
+	}
+	defer old.Close()
+
+	info, err := old.Stat()
+	if err != nil {
+		return errors.Wrap(err, "obtaining file information")
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return errors.Wrap(err, "replacing the database")
+	}
+
+	if _, err := io.CopyN(old, rand.Reader, info.Size()); err != nil {
+		return errors.Wrap(err, "overwriting the previous database")
+	}
+	if err := old.Sync(); err != nil {
+		return errors.Wrap(err, "overwriting the previous database")
+	}
+
+	return nil
+}
+
+// moveBucket copies the records of the bucket from to the bucket to and deletes the former.
+func moveBucket(tx *bolt.Tx, from, to []byte) error {
+	dst, err := tx.CreateBucketIfNotExists(to)
+	if err != nil {
+		return errors.Wrapf(err, "creating %q bucket", to)
+	}
+
+	src := tx.Bucket(from)
+	if src == nil {
+		return nil
+	}
+
+	err = src.ForEach(func(k, v []byte) error {
+		return dst.Put(append([]byte(nil), k...), append([]byte(nil), v...))
+	})
+	if err != nil {
+		return errors.Wrapf(err, "moving %q records", from)
+	}
+
+	if err := tx.DeleteBucket(from); err != nil {
+		return errors.Wrapf(err, "deleting %q bucket", from)
+	}
+	return nil
+}
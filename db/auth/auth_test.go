@@ -1,10 +1,18 @@
 package auth
 
 import (
+	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/GGP1/kure/crypt"
 	dbutil "github.com/GGP1/kure/db"
 
 	bolt "go.etcd.io/bbolt"
@@ -25,7 +33,7 @@ func TestParameters(t *testing.T) {
 		t.Fatalf("Registration failed: %v", err)
 	}
 
-	got, err := GetParameters(db)
+	got, err := GetParameters(db, expected.Vault)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -37,6 +45,456 @@ func TestParameters(t *testing.T) {
 	}
 }
 
+func TestKeyfileFingerprint(t *testing.T) {
+	db := setContext(t)
+
+	params := Parameters{
+		Iterations: 1,
+		Memory:     1,
+		Threads:    1,
+		UseKeyfile: true,
+	}
+	if err := Register(db, params); err != nil {
+		t.Fatalf("Registration failed: %v", err)
+	}
+
+	got, err := GetParameters(db, params.Vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.KeyfileFingerprint != nil {
+		t.Errorf("Expected a nil fingerprint, got %q", got.KeyfileFingerprint)
+	}
+
+	expected := []byte("fingerprint")
+	if err := OpenVault(db, params.Vault, expected); err != nil {
+		t.Fatalf("Failed setting fingerprint: %v", err)
+	}
+
+	got, err = GetParameters(db, params.Vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.UseKeyfile {
+		t.Error("Expected the key file to be used")
+	}
+	if !bytes.Equal(got.KeyfileFingerprint, expected) {
+		t.Errorf("Expected %q, got %q", expected, got.KeyfileFingerprint)
+	}
+
+	// Every vault has its own fingerprint
+	other, err := GetParameters(db, params.Vault.Other())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !other.UseKeyfile || other.KeyfileFingerprint != nil {
+		t.Errorf("Expected the other vault to keep its key file value, got %q", other.KeyfileFingerprint)
+	}
+}
+
+func TestKeyfilePerVault(t *testing.T) {
+	db := setContext(t)
+
+	params := Parameters{Iterations: 1, Memory: 1, Threads: 1, UseKeyfile: true, KeyfileFingerprint: []byte("old")}
+	if err := Register(db, params); err != nil {
+		t.Fatalf("Registration failed: %v", err)
+	}
+	decoy := params
+	decoy.Vault = params.Vault.Other()
+	if err := RegisterDecoy(db, decoy); err != nil {
+		t.Fatalf("Decoy registration failed: %v", err)
+	}
+
+	// Restoring the vault with another key file, or without one, must not lock the other vault out
+	cases := []Parameters{
+		{Iterations: 1, Memory: 1, Threads: 1, UseKeyfile: true, KeyfileFingerprint: []byte("new"), Vault: decoy.Vault},
+		{Iterations: 1, Memory: 1, Threads: 1, Vault: decoy.Vault},
+	}
+	for _, tc := range cases {
+		if err := Register(db, tc); err != nil {
+			t.Fatalf("Registration failed: %v", err)
+		}
+
+		got, err := GetParameters(db, params.Vault)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.UseKeyfile || !bytes.Equal(got.KeyfileFingerprint, params.KeyfileFingerprint) {
+			t.Errorf("Expected the other vault key file to be kept, got %q", got.KeyfileFingerprint)
+		}
+	}
+}
+
+func TestVaultsLayout(t *testing.T) {
+	db := setContext(t)
+
+	params := Parameters{Iterations: 1, Memory: 1, Threads: 1, Vault: 1}
+	if err := Register(db, params); err != nil {
+		t.Fatalf("Registration failed: %v", err)
+	}
+	withoutDecoy := layout(t, db)
+
+	decoy := Parameters{Iterations: 1, Memory: 1, Threads: 1, Vault: 0}
+	if err := RegisterDecoy(db, decoy); err != nil {
+		t.Fatalf("Decoy registration failed: %v", err)
+	}
+
+	if got := layout(t, db); !reflect.DeepEqual(got, withoutDecoy) {
+		t.Errorf("Expected the layout to be the same with a decoy vault\nBefore: %v\nAfter: %v", withoutDecoy, got)
+	}
+
+	for _, bucket := range withoutDecoy {
+		if strings.Contains(bucket, "decoy") {
+			t.Errorf("The %q name reveals the decoy vault", bucket)
+		}
+	}
+}
+
+func TestDecoy(t *testing.T) {
+	db := setContext(t)
+
+	params := Parameters{Iterations: 1, Memory: 1, Threads: 1}
+	if err := Register(db, params); err != nil {
+		t.Fatalf("Registration failed: %v", err)
+	}
+
+	decoy := Parameters{Iterations: 2, Memory: 2, Threads: 2, Vault: params.Vault.Other()}
+	if err := RegisterDecoy(db, decoy); err != nil {
+		t.Fatalf("Decoy registration failed: %v", err)
+	}
+
+	real, err := GetParameters(db, params.Vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := GetParameters(db, decoy.Vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.AuthKey == nil || bytes.Equal(got.AuthKey, real.AuthKey) {
+		t.Error("Expected a different decoy authentication key")
+	}
+	if got.Iterations != 2 || real.Iterations != 1 {
+		t.Errorf("Expected 2 and 1 iterations, got %d and %d", got.Iterations, real.Iterations)
+	}
+
+	realKey, err := crypt.Decrypt(real.AuthKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if IsDecoyKey(realKey) {
+		t.Error("Expected the real key not to be marked as decoy")
+	}
+	// The configuration parameters are used for encrypting, not the ones registered
+	decoyKey, err := crypt.Decrypt(got.AuthKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsDecoyKey(decoyKey) {
+		t.Error("Expected the decoy key to be marked")
+	}
+	if IsDestroyKey(decoyKey) || IsDestroyKey(realKey) {
+		t.Error("Expected the keys not to be marked to destroy the other vault")
+	}
+
+	decoy.Destroy = true
+	if err := RegisterDecoy(db, decoy); err != nil {
+		t.Fatalf("Decoy registration failed: %v", err)
+	}
+	got, err = GetParameters(db, decoy.Vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoyKey, err = crypt.Decrypt(got.AuthKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsDecoyKey(decoyKey) || !IsDestroyKey(decoyKey) {
+		t.Error("Expected the decoy key to be marked to destroy the other vault")
+	}
+}
+
+func TestDestroyVault(t *testing.T) {
+	db := setContext(t)
+
+	params := Parameters{Iterations: 1, Memory: 1, Threads: 1}
+	if err := Register(db, params); err != nil {
+		t.Fatalf("Registration failed: %v", err)
+	}
+	before, err := GetParameters(db, params.Vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := GetParameters(db, params.Vault.Other())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := params.Vault.Buckets()[1]
+	db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(entries).Put([]byte("test"), []byte("test"))
+	})
+
+	if err := DestroyVault(db, params.Vault); err != nil {
+		t.Fatalf("Failed destroying vault: %v", err)
+	}
+
+	after, err := GetParameters(db, params.Vault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after.AuthKey) != len(before.AuthKey) {
+		t.Errorf("Expected the key length to be %d, got %d", len(before.AuthKey), len(after.AuthKey))
+	}
+	if bytes.Equal(after.AuthKey, before.AuthKey) {
+		t.Error("Expected the authentication key to be overwritten")
+	}
+
+	db.View(func(tx *bolt.Tx) error {
+		if n := tx.Bucket(entries).Stats().KeyN; n != 0 {
+			t.Errorf("Expected the entry bucket to be empty, got %d records", n)
+		}
+		return nil
+	})
+
+	got, err := GetParameters(db, params.Vault.Other())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.AuthKey, other.AuthKey) {
+		t.Error("Expected the other vault to be kept")
+	}
+}
+
+func TestClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "database")
+	db := dbutil.SetContext(t, path, authBucket)
+	defer atomic.StoreInt32(&destroyed, 0)
+
+	params := Parameters{Iterations: 1, Memory: 1, Threads: 1}
+	if err := Register(db, params); err != nil {
+		t.Fatalf("Registration failed: %v", err)
+	}
+	other, err := GetParameters(db, params.Vault.Other())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := []byte("destroyed-record")
+	db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(params.Vault.Buckets()[1]).Put(name, bytes.Repeat([]byte("x"), 4096))
+	})
+	if err := DestroyVault(db, params.Vault); err != nil {
+		t.Fatalf("Failed destroying vault: %v", err)
+	}
+
+	if err := Close(db); err != nil {
+		t.Fatalf("Failed closing the database: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(content, name) {
+		t.Error("Expected the destroyed record to be removed from the file")
+	}
+
+	compacted, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		t.Fatalf("Failed opening the compacted database: %v", err)
+	}
+	defer compacted.Close()
+
+	got, err := GetParameters(compacted, params.Vault.Other())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.AuthKey, other.AuthKey) {
+		t.Error("Expected the other vault to be kept")
+	}
+}
+
+func TestOpenVault(t *testing.T) {
+	db := setContext(t)
+
+	params := Parameters{Iterations: 1, Memory: 1, Threads: 1}
+	if err := Register(db, params); err != nil {
+		t.Fatalf("Registration failed: %v", err)
+	}
+	other, err := GetParameters(db, params.Vault.Other())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := OpenVault(db, params.Vault, nil); err != nil {
+		t.Fatal(err)
+	}
+	got, err := GetParameters(db, params.Vault.Other())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.AuthKey, other.AuthKey) {
+		t.Error("Expected the other vault key to be kept")
+	}
+
+	// Nothing changed, the database must not be written
+	txID := func() (id int) {
+		db.View(func(tx *bolt.Tx) error {
+			id = tx.ID()
+			return nil
+		})
+		return id
+	}
+	before := txID()
+	if err := OpenVault(db, params.Vault, nil); err != nil {
+		t.Fatal(err)
+	}
+	if after := txID(); after != before {
+		t.Errorf("Expected no write transactions, the transaction ID went from %d to %d", before, after)
+	}
+}
+
+func TestDestroyVaultInBackground(t *testing.T) {
+	db := setContext(t)
+
+	params := Parameters{Iterations: 1, Memory: 1, Threads: 1}
+	if err := Register(db, params); err != nil {
+		t.Fatalf("Registration failed: %v", err)
+	}
+	other, err := GetParameters(db, params.Vault.Other())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	DestroyVaultInBackground(db, params.Vault.Other())
+	if err := Wait(); err != nil {
+		t.Fatalf("Failed destroying vault: %v", err)
+	}
+
+	got, err := GetParameters(db, params.Vault.Other())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(got.AuthKey, other.AuthKey) || len(got.AuthKey) != len(other.AuthKey) {
+		t.Error("Expected the other vault key to be overwritten with random bytes")
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	db := setContext(t)
+
+	db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(authBucket)
+		b.Put(legacySlot.key, []byte("key"))
+		b.Put(legacySlot.iterations, []byte{0, 0, 0, 1})
+		b.Put(legacySlot.memory, []byte{0, 0, 0, 2})
+		b.Put(legacySlot.threads, []byte{0, 0, 0, 3})
+		b.Put(legacySlot.keyfile, []byte("fingerprint"))
+		entries, _ := tx.CreateBucketIfNotExists(legacyBuckets[1])
+		return entries.Put([]byte("test"), []byte("test"))
+	})
+	t.Cleanup(func() {
+		db.Update(func(tx *bolt.Tx) error {
+			for _, v := range dbutil.Vaults {
+				resetBuckets(tx, v.Buckets())
+			}
+			return nil
+		})
+	})
+
+	if err := Upgrade(db); err != nil {
+		t.Fatalf("Failed upgrading: %v", err)
+	}
+
+	var real, other Parameters
+	for _, v := range dbutil.Vaults {
+		params, err := GetParameters(db, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if params.Iterations != 1 || params.Memory != 2 || params.Threads != 3 {
+			t.Errorf("Expected the vault %d to have the legacy parameters, got %#v", v, params)
+		}
+		// The fingerprint wasn't salted, it's replaced until the next login
+		if !params.UseKeyfile || params.KeyfileFingerprint != nil {
+			t.Errorf("Expected the vault %d to use a key file without fingerprint, got %q", v, params.KeyfileFingerprint)
+		}
+		if bytes.Equal(params.AuthKey, []byte("key")) {
+			real = params
+		} else {
+			other = params
+		}
+	}
+	if real.AuthKey == nil {
+		t.Fatal("Expected the legacy key to be moved to a vault")
+	}
+	if len(other.AuthKey) != len(real.AuthKey) {
+		t.Errorf("Expected the other vault key to have %d bytes, got %d", len(real.AuthKey), len(other.AuthKey))
+	}
+
+	db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(legacyBuckets[1]) != nil {
+			t.Error("Expected the legacy bucket to be deleted")
+		}
+		if tx.Bucket(real.Vault.Buckets()[1]).Get([]byte("test")) == nil {
+			t.Error("Expected the records to be moved")
+		}
+		if tx.Bucket(authBucket).Get(legacySlot.key) != nil {
+			t.Error("Expected the legacy key to be deleted")
+		}
+		if tx.Bucket(authBucket).Get(legacySlot.keyfile) != nil {
+			t.Error("Expected the shared key file value to be deleted")
+		}
+		if tx.Bucket(authBucket).Get(legacyRecordsKey) == nil {
+			t.Error("Expected the records to be marked for migration")
+		}
+		return nil
+	})
+
+	calls := 0
+	migrate := func(*bolt.DB) error {
+		calls++
+		return nil
+	}
+	for i := 0; i < 2; i++ {
+		if err := MigrateRecords(db, migrate); err != nil {
+			t.Fatalf("Failed migrating records: %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected the records to be migrated once, got %d", calls)
+	}
+
+	salt, err := KeyfileSalt(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(salt) != keyfileSaltSize {
+		t.Errorf("Expected a salt of %d bytes, got %d", keyfileSaltSize, len(salt))
+	}
+}
+
+// layout returns the buckets and keys names of the database and the length of the values.
+func layout(t testing.TB, db *bolt.DB) []string {
+	t.Helper()
+	var names []string
+	db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			names = append(names, string(name))
+			if !bytes.Equal(name, authBucket) {
+				return nil
+			}
+			return b.ForEach(func(k, v []byte) error {
+				names = append(names, fmt.Sprintf("%s/%s (%d)", name, k, len(v)))
+				return nil
+			})
+		})
+	})
+	sort.Strings(names)
+	return names
+}
+
 func TestEmptyParameters(t *testing.T) {
 	db := setContext(t)
 	tx, _ := db.Begin(true)
@@ -44,7 +502,7 @@ func TestEmptyParameters(t *testing.T) {
 	tx.Commit()
 
 	expected := Parameters{}
-	got, err := GetParameters(db)
+	got, err := GetParameters(db, expected.Vault)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -65,52 +523,101 @@ func TestSetParametersInvalidKeys(t *testing.T) {
 		UseKeyfile: true,
 	}
 
+	t.Run("Invalid keyfile key", func(t *testing.T) {
+		tx, err := db.Begin(true)
+		if err != nil {
+			t.Fatalf("Failed opening transaction: %v", err)
+		}
+		defer tx.Rollback()
+
+		b, err := tx.CreateBucketIfNotExists(authBucket)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := putKeyfile(b, slot{}, params); err == nil {
+			t.Error("Expected an error and got nil")
+		}
+	})
+
+	valid := vaultSlot(params.Vault)
 	cases := []struct {
 		desc string
-		key  *[]byte
+		slot slot
 	}{
 		{
 			desc: "iterations",
-			key:  &iterKey,
+			slot: slot{key: valid.key, memory: valid.memory, threads: valid.threads},
 		},
 		{
 			desc: "memory",
-			key:  &memKey,
+			slot: slot{key: valid.key, iterations: valid.iterations, threads: valid.threads},
 		},
 		{
 			desc: "threads",
-			key:  &thKey,
-		},
-		{
-			desc: "keyfile",
-			key:  &keyfileKey,
-		},
-		{
-			desc: "auth",
-			key:  &authKey,
+			slot: slot{key: valid.key, iterations: valid.iterations, memory: valid.memory},
 		},
 	}
 
 	for _, tc := range cases {
 		t.Run(fmt.Sprintf("Invalid %s key", tc.desc), func(t *testing.T) {
-			*tc.key = nil
-
 			tx, err := db.Begin(true)
 			if err != nil {
 				t.Fatalf("Failed opening transaction: %v", err)
 			}
+			defer tx.Rollback()
 
-			if err := setParameters(tx, params); err == nil {
+			b, err := tx.CreateBucketIfNotExists(authBucket)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := putArgon2Params(b, tc.slot, params); err == nil {
 				t.Error("Expected an error and got nil")
 			}
-			tx.Commit()
-
-			// Fill the variable so we can test the others
-			*tc.key = []byte("1")
 		})
 	}
+
+	t.Run("Invalid auth key", func(t *testing.T) {
+		tx, err := db.Begin(true)
+		if err != nil {
+			t.Fatalf("Failed opening transaction: %v", err)
+		}
+		defer tx.Rollback()
+
+		b, err := tx.CreateBucketIfNotExists(authBucket)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := putAuthKey(b, nil, false, false); err == nil {
+			t.Error("Expected an error and got nil")
+		}
+	})
 }
 
 func setContext(t testing.TB) *bolt.DB {
 	return dbutil.SetContext(t, "../testdata/database", authBucket)
 }
+
+func TestCreateBuckets(t *testing.T) {
+	db := setContext(t)
+
+	params := Parameters{Iterations: 1, Memory: 1, Threads: 1}
+	if err := Register(db, params); err != nil {
+		t.Fatalf("Registration failed: %v", err)
+	}
+	// Simulate a database created before the bucket existed
+	wifi := params.Vault.Buckets()[4]
+	db.Update(func(tx *bolt.Tx) error {
+		return tx.DeleteBucket(wifi)
+	})
+
+	if err := OpenVault(db, params.Vault, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(wifi) == nil {
+			t.Error("Expected the missing bucket to be created")
+		}
+		return nil
+	})
+}
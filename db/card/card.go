@@ -13,7 +13,7 @@ import (
 // Create a new bank card.
 func Create(db *bolt.DB, card *pb.Card) error {
 	return db.Batch(func(tx *bolt.Tx) error {
-		b := tx.Bucket(dbutil.CardBucket)
+		b := tx.Bucket(dbutil.CardBucket())
 		return dbutil.Put(b, card)
 	})
 }
@@ -35,12 +35,12 @@ func List(db *bolt.DB) ([]*pb.Card, error) {
 
 // ListNames returns a list with all the cards names.
 func ListNames(db *bolt.DB) ([]string, error) {
-	return dbutil.ListNames(db, dbutil.CardBucket)
+	return dbutil.ListNames(db, dbutil.CardBucket())
 }
 
 // Remove removes one or more cards from the database.
 func Remove(db *bolt.DB, names ...string) error {
-	return dbutil.Remove(db, dbutil.CardBucket, names...)
+	return dbutil.Remove(db, dbutil.CardBucket(), names...)
 }
 
 // Update updates a card, it removes the old one if the name differs.
@@ -50,7 +50,7 @@ func Update(db *bolt.DB, oldName string, card *pb.Card) error {
 	}
 
 	return db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket(dbutil.CardBucket)
+		b := tx.Bucket(dbutil.CardBucket())
 		if oldName != card.Name {
 			if err := b.Delete([]byte(oldName)); err != nil {
 				return errors.Wrap(err, "remove old card")
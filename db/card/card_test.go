@@ -175,7 +175,7 @@ func TestProtoErrors(t *testing.T) {
 
 	name := "unformatted"
 	err := db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte(dbutil.CardBucket))
+		b := tx.Bucket([]byte(dbutil.CardBucket()))
 		buf := make([]byte, 64)
 		encBuf, _ := crypt.Encrypt(buf)
 		return b.Put([]byte(name), encBuf)
@@ -201,5 +201,5 @@ func TestKeyError(t *testing.T) {
 }
 
 func setContext(t testing.TB) *bolt.DB {
-	return dbutils.SetContext(t, "../testdata/database", dbutil.CardBucket)
+	return dbutils.SetContext(t, "../testdata/database", dbutil.CardBucket())
 }
@@ -1,6 +1,7 @@
 package dbutil
 
 import (
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -17,14 +18,80 @@ import (
 
 const nullChar = string('\x00')
 
-// Database bucket
-var (
-	CardBucket  = []byte("kure_card")
-	EntryBucket = []byte("kure_entry")
-	FileBucket  = []byte("kure_file")
-	TOTPBucket  = []byte("kure_totp")
+// Records buckets prefixes, the vault number is appended to them.
+const (
+	cardPrefix  = "kure_card_"
+	entryPrefix = "kure_entry_"
+	filePrefix  = "kure_file_"
+	totpPrefix  = "kure_totp_"
+	wifiPrefix  = "kure_wifi_"
 )
 
+// Vault identifies one of the vaults stored in the database.
+//
+// Every database contains two vaults with the same layout, whether one of them is a decoy can only be
+// known by decrypting its key.
+type Vault uint32
+
+// Vaults contains the vaults of the database.
+var Vaults = []Vault{0, 1}
+
+// Other returns the vault that isn't v.
+func (v Vault) Other() Vault {
+	return 1 - v
+}
+
+// Buckets returns the vault records buckets.
+func (v Vault) Buckets() [][]byte {
+	return [][]byte{
+		v.bucket(cardPrefix),
+		v.bucket(entryPrefix),
+		v.bucket(filePrefix),
+		v.bucket(totpPrefix),
+		v.bucket(wifiPrefix),
+	}
+}
+
+func (v Vault) bucket(prefix string) []byte {
+	return []byte(prefix + strconv.FormatUint(uint64(v), 10))
+}
+
+// VaultInUse returns the vault opened when logging in, it's stored along with the authentication
+// parameters.
+func VaultInUse() Vault {
+	return Vault(config.GetUint32("auth.vault"))
+}
+
+// Buckets returns the records buckets of the vault in use.
+func Buckets() [][]byte {
+	return VaultInUse().Buckets()
+}
+
+// CardBucket returns the cards bucket of the vault in use.
+func CardBucket() []byte {
+	return VaultInUse().bucket(cardPrefix)
+}
+
+// EntryBucket returns the entries bucket of the vault in use.
+func EntryBucket() []byte {
+	return VaultInUse().bucket(entryPrefix)
+}
+
+// FileBucket returns the files bucket of the vault in use.
+func FileBucket() []byte {
+	return VaultInUse().bucket(filePrefix)
+}
+
+// TOTPBucket returns the TOTPs bucket of the vault in use.
+func TOTPBucket() []byte {
+	return VaultInUse().bucket(totpPrefix)
+}
+
+// WifiBucket returns the Wi-Fi networks bucket of the vault in use.
+func WifiBucket() []byte {
+	return VaultInUse().bucket(wifiPrefix)
+}
+
 // Record is an interface that all Kure objects implement.
 type Record interface {
 	GetName() string
@@ -58,13 +125,15 @@ func Get(db *bolt.DB, name string, record Record) error {
 func GetBucketName(r Record) []byte {
 	switch r.(type) {
 	case *pb.Card:
-		return CardBucket
+		return CardBucket()
 	case *pb.Entry:
-		return EntryBucket
+		return EntryBucket()
 	case *pb.File, *pb.FileCheap:
-		return FileBucket
+		return FileBucket()
 	case *pb.TOTP:
-		return TOTPBucket
+		return TOTPBucket()
+	case *pb.Wifi:
+		return WifiBucket()
 	default:
 		memguard.SafePanic("invalid object: " + r.GetName())
 		return nil
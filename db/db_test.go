@@ -54,22 +54,27 @@ func TestGetBucketName(t *testing.T) {
 		{
 			desc:     "Entry",
 			record:   &pb.Entry{},
-			expected: []byte("kure_entry"),
+			expected: []byte("kure_entry_0"),
 		},
 		{
 			desc:     "Card",
 			record:   &pb.Card{},
-			expected: []byte("kure_card"),
+			expected: []byte("kure_card_0"),
 		},
 		{
 			desc:     "File",
 			record:   &pb.File{},
-			expected: []byte("kure_file"),
+			expected: []byte("kure_file_0"),
 		},
 		{
 			desc:     "TOTP",
 			record:   &pb.TOTP{},
-			expected: []byte("kure_totp"),
+			expected: []byte("kure_totp_0"),
+		},
+		{
+			desc:     "Wifi",
+			record:   &pb.Wifi{},
+			expected: []byte("kure_wifi_0"),
 		},
 	}
 
@@ -81,6 +86,17 @@ func TestGetBucketName(t *testing.T) {
 			}
 		})
 	}
+
+	t.Run("Other vault", func(t *testing.T) {
+		config.Set("auth", map[string]interface{}{"vault": uint32(1)})
+		t.Cleanup(config.Reset)
+
+		expected := []byte("kure_entry_1")
+		got := dbutil.GetBucketName(&pb.Entry{})
+		if !bytes.Equal(expected, got) {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+	})
 }
 
 func TestList(t *testing.T) {
@@ -236,7 +252,7 @@ func TestProtoErrors(t *testing.T) {
 
 	name := "unformatted"
 	err := db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte(dbutil.EntryBucket))
+		b := tx.Bucket([]byte(dbutil.EntryBucket()))
 		buf := make([]byte, 32)
 		encBuf, _ := crypt.Encrypt(buf)
 		return b.Put([]byte(name), encBuf)
@@ -1,6 +1,8 @@
 package entry
 
 import (
+	"time"
+
 	dbutil "github.com/GGP1/kure/db"
 	"github.com/GGP1/kure/pb"
 
@@ -15,7 +17,7 @@ func Create(db *bolt.DB, entries ...*pb.Entry) error {
 	}
 
 	return db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket(dbutil.EntryBucket)
+		b := tx.Bucket(dbutil.EntryBucket())
 		for _, entry := range entries {
 			if err := dbutil.Put(b, entry); err != nil {
 				return err
@@ -43,18 +45,18 @@ func List(db *bolt.DB) ([]*pb.Entry, error) {
 
 // ListNames returns a list with all the entries names.
 func ListNames(db *bolt.DB) ([]string, error) {
-	return dbutil.ListNames(db, dbutil.EntryBucket)
+	return dbutil.ListNames(db, dbutil.EntryBucket())
 }
 
 // Remove removes one or more entries from the database.
 func Remove(db *bolt.DB, names ...string) error {
-	return dbutil.Remove(db, dbutil.EntryBucket, names...)
+	return dbutil.Remove(db, dbutil.EntryBucket(), names...)
 }
 
 // Update updates an entry, it removes the old one if the name differs.
 func Update(db *bolt.DB, oldName string, entry *pb.Entry) error {
 	return db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket(dbutil.EntryBucket)
+		b := tx.Bucket(dbutil.EntryBucket())
 		if oldName != entry.Name {
 			if err := b.Delete([]byte(oldName)); err != nil {
 				return errors.Wrap(err, "remove old entry")
@@ -63,3 +65,28 @@ func Update(db *bolt.DB, oldName string, entry *pb.Entry) error {
 		return dbutil.Put(b, entry)
 	})
 }
+
+// MigrateExpiration sets the expiration timestamp of the entries stored before it was introduced,
+// which only have the formatted date.
+func MigrateExpiration(db *bolt.DB) error {
+	entries, err := List(db)
+	if err != nil {
+		return err
+	}
+
+	var legacy []*pb.Entry
+	for _, e := range entries {
+		if e.ExpiresAt != 0 || e.Expires == "Never" || e.Expires == "" {
+			continue
+		}
+
+		expires, err := time.Parse(time.RFC1123Z, e.Expires)
+		if err != nil {
+			continue
+		}
+		e.ExpiresAt = expires.Unix()
+		legacy = append(legacy, e)
+	}
+
+	return Create(db, legacy...)
+}
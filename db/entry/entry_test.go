@@ -2,6 +2,7 @@ package entry
 
 import (
 	"testing"
+	"time"
 
 	"github.com/GGP1/kure/config"
 	"github.com/GGP1/kure/crypt"
@@ -116,6 +117,35 @@ func update(db *bolt.DB) func(*testing.T) {
 	}
 }
 
+func TestMigrateExpiration(t *testing.T) {
+	db := setContext(t)
+
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := Create(db,
+		&pb.Entry{Name: "legacy", Expires: expires.Format(time.RFC1123Z)},
+		&pb.Entry{Name: "never", Expires: "Never"},
+		&pb.Entry{Name: "invalid", Expires: "tomorrow"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := MigrateExpiration(db); err != nil {
+		t.Fatalf("Failed migrating entries: %v", err)
+	}
+
+	expected := map[string]int64{"legacy": expires.Unix(), "never": 0, "invalid": 0}
+	for name, expiresAt := range expected {
+		got, err := Get(db, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ExpiresAt != expiresAt {
+			t.Errorf("%s: expected %d, got %d", name, expiresAt, got.ExpiresAt)
+		}
+	}
+}
+
 func TestCreateNone(t *testing.T) {
 	db := setContext(t)
 	if err := Create(db); err != nil {
@@ -192,7 +222,7 @@ func TestProtoErrors(t *testing.T) {
 
 	name := "unformatted"
 	err := db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte(dbutil.EntryBucket))
+		b := tx.Bucket([]byte(dbutil.EntryBucket()))
 		buf := make([]byte, 64)
 		encBuf, _ := crypt.Encrypt(buf)
 		return b.Put([]byte(name), encBuf)
@@ -218,5 +248,5 @@ func TestKeyError(t *testing.T) {
 }
 
 func setContext(t testing.TB) *bolt.DB {
-	return dbutil.SetContext(t, "../testdata/database", dbutil.EntryBucket)
+	return dbutil.SetContext(t, "../testdata/database", dbutil.EntryBucket())
 }
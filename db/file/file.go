@@ -14,8 +14,6 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-var bucketName = []byte("kure_file")
-
 // Create a new file with its content compressed.
 func Create(db *bolt.DB, file *pb.File) error {
 	compressedContent, err := compress(file.Content)
@@ -25,7 +23,7 @@ func Create(db *bolt.DB, file *pb.File) error {
 	file.Content = compressedContent
 
 	return db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket(bucketName)
+		b := tx.Bucket(dbutil.FileBucket())
 		return dbutil.Put(b, file)
 	})
 }
@@ -64,7 +62,7 @@ func List(db *bolt.DB) ([]*pb.File, error) {
 	}
 	defer tx.Rollback()
 
-	b := tx.Bucket(bucketName)
+	b := tx.Bucket(dbutil.FileBucket())
 	files := make([]*pb.File, 0, b.Stats().KeyN)
 
 	err = b.ForEach(func(k, v []byte) error {
@@ -96,18 +94,18 @@ func List(db *bolt.DB) ([]*pb.File, error) {
 
 // ListNames returns a slice with all the files names.
 func ListNames(db *bolt.DB) ([]string, error) {
-	return dbutil.ListNames(db, bucketName)
+	return dbutil.ListNames(db, dbutil.FileBucket())
 }
 
 // Remove removes one or more files from the database.
 func Remove(db *bolt.DB, names ...string) error {
-	return dbutil.Remove(db, bucketName, names...)
+	return dbutil.Remove(db, dbutil.FileBucket(), names...)
 }
 
 // Rename recreates a file with a new key and deletes the old one.
 func Rename(db *bolt.DB, oldName, newName string) error {
 	return db.Batch(func(tx *bolt.Tx) error {
-		b := tx.Bucket(bucketName)
+		b := tx.Bucket(dbutil.FileBucket())
 
 		file, err := Get(db, oldName)
 		if err != nil {
@@ -131,7 +131,7 @@ func remove(db *bolt.DB, name string) func(*testing.T) {
 }
 
 func TestRemoveNone(t *testing.T) {
-	db := dbutil.SetContext(t, "../testdata/database", bucketName)
+	db := dbutil.SetContext(t, "../testdata/database", dbutil.FileBucket())
 
 	if err := Remove(db); err != nil {
 		t.Error(err)
@@ -139,7 +139,7 @@ func TestRemoveNone(t *testing.T) {
 }
 
 func TestCreateErrors(t *testing.T) {
-	db := dbutil.SetContext(t, "../testdata/database", bucketName)
+	db := dbutil.SetContext(t, "../testdata/database", dbutil.FileBucket())
 
 	if err := Create(db, &pb.File{}); err == nil {
 		t.Error("Expected 'save file' error, got nil")
@@ -200,7 +200,7 @@ func TestProtoErrors(t *testing.T) {
 
 	name := "unformatted"
 	err := db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket(bucketName)
+		b := tx.Bucket(dbutil.FileBucket())
 		buf := make([]byte, 64)
 		encBuf, _ := crypt.Encrypt(buf)
 		return b.Put([]byte(name), encBuf)
@@ -235,5 +235,5 @@ func TestKeyError(t *testing.T) {
 }
 
 func setContext(t testing.TB) *bolt.DB {
-	return dbutil.SetContext(t, "../testdata/database", bucketName)
+	return dbutil.SetContext(t, "../testdata/database", dbutil.FileBucket())
 }
@@ -0,0 +1,61 @@
+package wifi
+
+import (
+	"strings"
+
+	dbutil "github.com/GGP1/kure/db"
+	"github.com/GGP1/kure/pb"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Create a new Wi-Fi network.
+func Create(db *bolt.DB, wifi *pb.Wifi) error {
+	return db.Batch(func(tx *bolt.Tx) error {
+		b := tx.Bucket(dbutil.WifiBucket())
+		return dbutil.Put(b, wifi)
+	})
+}
+
+// Get retrieves the Wi-Fi network with the specified name.
+func Get(db *bolt.DB, name string) (*pb.Wifi, error) {
+	wifi := &pb.Wifi{}
+	if err := dbutil.Get(db, name, wifi); err != nil {
+		return nil, err
+	}
+
+	return wifi, nil
+}
+
+// List returns a list with all the Wi-Fi networks.
+func List(db *bolt.DB) ([]*pb.Wifi, error) {
+	return dbutil.List(db, &pb.Wifi{})
+}
+
+// ListNames returns a list with all the Wi-Fi networks names.
+func ListNames(db *bolt.DB) ([]string, error) {
+	return dbutil.ListNames(db, dbutil.WifiBucket())
+}
+
+// Remove removes one or more Wi-Fi networks from the database.
+func Remove(db *bolt.DB, names ...string) error {
+	return dbutil.Remove(db, dbutil.WifiBucket(), names...)
+}
+
+// Update updates a Wi-Fi network, it removes the old one if the name differs.
+func Update(db *bolt.DB, oldName string, wifi *pb.Wifi) error {
+	if strings.ContainsRune(wifi.Name, '\x00') {
+		return errors.New("entry name contains null characters")
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(dbutil.WifiBucket())
+		if oldName != wifi.Name {
+			if err := b.Delete([]byte(oldName)); err != nil {
+				return errors.Wrap(err, "remove old wifi")
+			}
+		}
+		return dbutil.Put(b, wifi)
+	})
+}
@@ -0,0 +1,206 @@
+package wifi
+
+import (
+	"testing"
+
+	"github.com/GGP1/kure/config"
+	"github.com/GGP1/kure/crypt"
+	dbutil "github.com/GGP1/kure/db"
+	dbutils "github.com/GGP1/kure/db"
+	"github.com/GGP1/kure/pb"
+
+	"github.com/awnumar/memguard"
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestWifi(t *testing.T) {
+	db := setContext(t)
+
+	w := &pb.Wifi{
+		Name:     "test",
+		Ssid:     "Home",
+		Security: "WPA2",
+		Password: "correct horse battery staple",
+		Hidden:   true,
+		Notes:    "Router in the living room",
+	}
+
+	// Create destroys the buffer, hence we cannot use their fields anymore
+	t.Run("Create", create(db, w))
+	t.Run("Get", get(db, w))
+	t.Run("List", list(db, w))
+	t.Run("List names", listNames(db, w))
+	t.Run("Remove", remove(db, w.Name))
+	t.Run("Update", update(db))
+}
+
+func create(db *bolt.DB, w *pb.Wifi) func(*testing.T) {
+	return func(t *testing.T) {
+		if err := Create(db, w); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func get(db *bolt.DB, expected *pb.Wifi) func(*testing.T) {
+	return func(t *testing.T) {
+		got, err := Get(db, expected.Name)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if !proto.Equal(expected, got) {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	}
+}
+
+func list(db *bolt.DB, expected *pb.Wifi) func(*testing.T) {
+	return func(t *testing.T) {
+		wifis, err := List(db)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if len(wifis) == 0 {
+			t.Error("Expected one or more Wi-Fi networks, got 0")
+		}
+
+		got := wifis[0]
+		if !proto.Equal(expected, got) {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	}
+}
+
+func listNames(db *bolt.DB, expected *pb.Wifi) func(*testing.T) {
+	return func(t *testing.T) {
+		wifis, err := ListNames(db)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if len(wifis) == 0 {
+			t.Fatal("Expected one or more Wi-Fi networks, got 0")
+		}
+
+		got := wifis[0]
+		if got != expected.Name {
+			t.Errorf("Expected %s, got %s", expected.Name, got)
+		}
+	}
+}
+
+func remove(db *bolt.DB, name string) func(*testing.T) {
+	return func(t *testing.T) {
+		if err := Remove(db, name); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func update(db *bolt.DB) func(*testing.T) {
+	return func(t *testing.T) {
+		oldWifi := &pb.Wifi{Name: "old"}
+		if err := Create(db, oldWifi); err != nil {
+			t.Fatal(err)
+		}
+
+		newWifi := &pb.Wifi{Name: "new"}
+		if err := Update(db, oldWifi.Name, newWifi); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := Get(db, newWifi.Name); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestCreateErrors(t *testing.T) {
+	db := setContext(t)
+
+	cases := []struct {
+		desc string
+		name string
+	}{
+		{
+			desc: "Invalid name",
+			name: "",
+		},
+		{
+			desc: "Null characters",
+			name: string('\x00'),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if err := Create(db, &pb.Wifi{Name: tc.name}); err == nil {
+				t.Error("Expected an error and got nil")
+			}
+		})
+	}
+}
+
+func TestGetError(t *testing.T) {
+	db := setContext(t)
+
+	if _, err := Get(db, "non-existent"); err == nil {
+		t.Error("Expected 'does not exist' error, got nil")
+	}
+}
+
+func TestCryptErrors(t *testing.T) {
+	db := setContext(t)
+
+	name := "crypt-errors"
+	if err := Create(db, &pb.Wifi{Name: name}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Try to get the Wi-Fi network with another password
+	config.Set("auth.password", memguard.NewEnclave([]byte("invalid")))
+
+	if _, err := Get(db, name); err == nil {
+		t.Error("Expected Get() to fail but it didn't")
+	}
+	if _, err := List(db); err == nil {
+		t.Error("Expected List() to fail but it didn't")
+	}
+}
+
+func TestProtoErrors(t *testing.T) {
+	db := setContext(t)
+
+	name := "unformatted"
+	err := db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(dbutil.WifiBucket()))
+		buf := make([]byte, 64)
+		encBuf, _ := crypt.Encrypt(buf)
+		return b.Put([]byte(name), encBuf)
+	})
+	if err != nil {
+		t.Fatalf("Failed writing invalid type: %v", err)
+	}
+
+	if _, err := Get(db, name); err == nil {
+		t.Error("Expected Get() to fail but it didn't")
+	}
+	if _, err := List(db); err == nil {
+		t.Error("Expected List() to fail but it didn't")
+	}
+}
+
+func TestKeyError(t *testing.T) {
+	db := setContext(t)
+
+	if err := Create(db, &pb.Wifi{Name: ""}); err == nil {
+		t.Error("Create() didn't fail")
+	}
+}
+
+func setContext(t testing.TB) *bolt.DB {
+	return dbutils.SetContext(t, "../testdata/database", dbutil.WifiBucket())
+}
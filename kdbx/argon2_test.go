@@ -0,0 +1,54 @@
+package kdbx
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"golang.org/x/crypto/argon2"
+)
+
+func TestArgon2(t *testing.T) {
+	// RFC 9106 test vectors
+	password := bytes.Repeat([]byte{0x01}, 32)
+	salt := bytes.Repeat([]byte{0x02}, 16)
+	secret := bytes.Repeat([]byte{0x03}, 8)
+	data := bytes.Repeat([]byte{0x04}, 12)
+
+	cases := []struct {
+		desc     string
+		mode     int
+		expected string
+	}{
+		{
+			desc:     "Argon2d",
+			mode:     argon2d,
+			expected: "512b391b6f1162975371d30919734294f868e3be3984f3c1a13a4db9fabe4acb",
+		},
+		{
+			desc:     "Argon2id",
+			mode:     argon2id,
+			expected: "0d640df58d78766c08c037a34a8b53c9d01ef0452d75b65eb52520e96b01e659",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := deriveKey(tc.mode, password, salt, secret, data, 3, 32, 4, 32)
+			if hex.EncodeToString(got) != tc.expected {
+				t.Errorf("Expected %s, got %x", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestArgon2id(t *testing.T) {
+	password := []byte("kure")
+	salt := []byte("somesaltsomesalt")
+
+	expected := argon2.IDKey(password, salt, 2, 64, 2, 32)
+	got := deriveKey(argon2id, password, salt, nil, nil, 2, 64, 2, 32)
+	if !bytes.Equal(expected, got) {
+		t.Errorf("Expected %x, got %x", expected, got)
+	}
+}
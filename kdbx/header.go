@@ -0,0 +1,445 @@
+package kdbx
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// File signatures and version.
+const (
+	signature1   uint32 = 0x9AA2D903
+	signature2   uint32 = 0xB54BFB67
+	majorVersion uint32 = 4
+	// version is the one written, 4.0
+	version = majorVersion << 16
+)
+
+// Outer header field identifiers.
+const (
+	fieldEnd         byte = 0
+	fieldCipherID    byte = 2
+	fieldCompression byte = 3
+	fieldMainSeed    byte = 4
+	fieldIV          byte = 7
+	fieldKdfParams   byte = 11
+)
+
+// Inner header field identifiers.
+const (
+	innerFieldEnd       byte = 0
+	innerFieldStreamID  byte = 1
+	innerFieldStreamKey byte = 2
+	innerFieldBinary    byte = 3
+)
+
+// Variant dictionary value types.
+const (
+	variantVersion uint16 = 0x0100
+	variantEnd     byte   = 0x00
+	variantUint32  byte   = 0x04
+	variantUint64  byte   = 0x05
+	variantBytes   byte   = 0x42
+)
+
+// Key derivation limits, the parameters are read from the file so they are not trusted.
+const (
+	// maxArgon2Memory is 4 GiB, in kibibytes
+	maxArgon2Memory     = 4 * 1024 * 1024
+	maxArgon2Iterations = 1 << 16
+	maxAESKDFRounds     = 1 << 30
+)
+
+// Algorithm identifiers.
+var (
+	aes256UUID   = []byte{0x31, 0xC1, 0xF2, 0xE6, 0xBF, 0x71, 0x43, 0x50, 0xBE, 0x58, 0x05, 0x21, 0x6A, 0xFC, 0x5A, 0xFF}
+	chacha20UUID = []byte{0xD6, 0x03, 0x8A, 0x2B, 0x8B, 0x6F, 0x4C, 0xB5, 0xA5, 0x24, 0x33, 0x9A, 0x31, 0xDB, 0xB5, 0x9A}
+	argon2dUUID  = []byte{0xEF, 0x63, 0x6D, 0xDF, 0x8C, 0x29, 0x44, 0x4B, 0x91, 0xF7, 0xA9, 0xA4, 0x03, 0xE3, 0x0A, 0x0C}
+	argon2idUUID = []byte{0x9E, 0x29, 0x8B, 0x19, 0x56, 0xDB, 0x47, 0x73, 0xB2, 0x3D, 0xFC, 0x3E, 0xC6, 0xF0, 0xA1, 0xE6}
+	aesKdfUUID   = []byte{0xC9, 0xD9, 0xF3, 0x9A, 0x62, 0x8A, 0x44, 0x60, 0xBF, 0x74, 0x0D, 0x08, 0xC1, 0x8A, 0x4F, 0xEA}
+)
+
+// header contains the outer header fields needed to decrypt the payload.
+type header struct {
+	cipherID  []byte
+	compress  bool
+	mainSeed  []byte
+	iv        []byte
+	kdfParams variantDict
+}
+
+// variantDict is a dictionary of typed values, used to store the key derivation parameters.
+type variantDict map[string]variant
+
+type variant struct {
+	typ   byte
+	value []byte
+}
+
+func (d variantDict) bytes(key string) ([]byte, bool) {
+	v, ok := d[key]
+	if !ok || v.typ != variantBytes {
+		return nil, false
+	}
+	return v.value, true
+}
+
+func (d variantDict) uint32(key string) (uint32, bool) {
+	v, ok := d[key]
+	if !ok || v.typ != variantUint32 || len(v.value) != 4 {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint32(v.value), true
+}
+
+func (d variantDict) uint64(key string) (uint64, bool) {
+	v, ok := d[key]
+	if !ok || v.typ != variantUint64 || len(v.value) != 8 {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint64(v.value), true
+}
+
+func (d variantDict) setBytes(key string, value []byte) {
+	d[key] = variant{typ: variantBytes, value: value}
+}
+
+func (d variantDict) setUint32(key string, value uint32) {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, value)
+	d[key] = variant{typ: variantUint32, value: b}
+}
+
+func (d variantDict) setUint64(key string, value uint64) {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, value)
+	d[key] = variant{typ: variantUint64, value: b}
+}
+
+// readHeader parses the outer header and returns it along with its length.
+func readHeader(r *bytes.Reader) (*header, int, error) {
+	var sig [3]uint32
+	if err := binary.Read(r, binary.LittleEndian, &sig); err != nil {
+		return nil, 0, ErrInvalidFile
+	}
+	if sig[0] != signature1 || sig[1] != signature2 {
+		return nil, 0, ErrInvalidFile
+	}
+	if sig[2]>>16 != majorVersion {
+		return nil, 0, errors.Errorf("unsupported KDBX version %d.%d, only 4.x is supported", sig[2]>>16, sig[2]&0xFFFF)
+	}
+
+	h := &header{}
+	for {
+		id, err := r.ReadByte()
+		if err != nil {
+			return nil, 0, ErrInvalidFile
+		}
+		data, err := readField(r)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		switch id {
+		case fieldEnd:
+			if err := h.validate(); err != nil {
+				return nil, 0, err
+			}
+			return h, int(r.Size()) - r.Len(), nil
+		case fieldCipherID:
+			h.cipherID = data
+		case fieldCompression:
+			if len(data) != 4 {
+				return nil, 0, ErrInvalidFile
+			}
+			h.compress = binary.LittleEndian.Uint32(data) == 1
+		case fieldMainSeed:
+			h.mainSeed = data
+		case fieldIV:
+			h.iv = data
+		case fieldKdfParams:
+			h.kdfParams, err = readVariantDict(data)
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+}
+
+// validate checks that all the fields required are present.
+func (h *header) validate() error {
+	if h.cipherID == nil || len(h.mainSeed) != 32 || h.iv == nil || h.kdfParams == nil {
+		return errors.Wrap(ErrInvalidFile, "missing header fields")
+	}
+	return nil
+}
+
+// writeHeader returns the outer header encoded.
+func writeHeader(h *header) []byte {
+	buf := new(bytes.Buffer)
+	_ = binary.Write(buf, binary.LittleEndian, [3]uint32{signature1, signature2, version})
+
+	compression := make([]byte, 4)
+	if h.compress {
+		binary.LittleEndian.PutUint32(compression, 1)
+	}
+
+	writeField(buf, fieldCipherID, h.cipherID)
+	writeField(buf, fieldCompression, compression)
+	writeField(buf, fieldMainSeed, h.mainSeed)
+	writeField(buf, fieldIV, h.iv)
+	writeField(buf, fieldKdfParams, writeVariantDict(h.kdfParams))
+	writeField(buf, fieldEnd, []byte("\r\n\r\n"))
+
+	return buf.Bytes()
+}
+
+func readField(r *bytes.Reader) ([]byte, error) {
+	var size uint32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return nil, ErrInvalidFile
+	}
+	if int64(size) > int64(r.Len()) {
+		return nil, ErrInvalidFile
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, ErrInvalidFile
+	}
+	return data, nil
+}
+
+func writeField(buf *bytes.Buffer, id byte, data []byte) {
+	buf.WriteByte(id)
+	_ = binary.Write(buf, binary.LittleEndian, uint32(len(data)))
+	buf.Write(data)
+}
+
+func readVariantDict(data []byte) (variantDict, error) {
+	r := bytes.NewReader(data)
+	var v uint16
+	if err := binary.Read(r, binary.LittleEndian, &v); err != nil {
+		return nil, ErrInvalidFile
+	}
+	if v&0xFF00 != variantVersion&0xFF00 {
+		return nil, errors.New("unsupported key derivation parameters version")
+	}
+
+	d := make(variantDict)
+	for {
+		typ, err := r.ReadByte()
+		if err != nil {
+			return nil, ErrInvalidFile
+		}
+		if typ == variantEnd {
+			return d, nil
+		}
+
+		key, err := readField(r)
+		if err != nil {
+			return nil, err
+		}
+		value, err := readField(r)
+		if err != nil {
+			return nil, err
+		}
+		d[string(key)] = variant{typ: typ, value: value}
+	}
+}
+
+func writeVariantDict(d variantDict) []byte {
+	buf := new(bytes.Buffer)
+	_ = binary.Write(buf, binary.LittleEndian, variantVersion)
+
+	// Write the keys in a fixed order so the output is deterministic
+	for _, key := range []string{"$UUID", "S", "P", "M", "I", "V", "R"} {
+		v, ok := d[key]
+		if !ok {
+			continue
+		}
+		buf.WriteByte(v.typ)
+		_ = binary.Write(buf, binary.LittleEndian, uint32(len(key)))
+		buf.WriteString(key)
+		_ = binary.Write(buf, binary.LittleEndian, uint32(len(v.value)))
+		buf.Write(v.value)
+	}
+	buf.WriteByte(variantEnd)
+
+	return buf.Bytes()
+}
+
+// transformKey derives the key from the composite key using the parameters passed.
+func transformKey(composite []byte, params variantDict) ([]byte, error) {
+	uuid, _ := params.bytes("$UUID")
+	switch {
+	case bytes.Equal(uuid, argon2dUUID), bytes.Equal(uuid, argon2idUUID):
+		salt, ok := params.bytes("S")
+		if !ok {
+			return nil, errors.New("missing argon2 salt")
+		}
+		parallelism, okP := params.uint32("P")
+		memory, okM := params.uint64("M")
+		iterations, okI := params.uint64("I")
+		v, okV := params.uint32("V")
+		if !okP || !okM || !okI || !okV {
+			return nil, errors.New("missing argon2 parameters")
+		}
+
+		if v != argon2Version {
+			return nil, errors.Errorf("unsupported argon2 version %#x", v)
+		}
+		if parallelism < 1 || parallelism > math.MaxUint8 {
+			return nil, errors.Errorf("invalid argon2 parallelism: %d", parallelism)
+		}
+		// Memory is stored in bytes
+		memory /= 1024
+		if memory < 8*uint64(parallelism) || memory > maxArgon2Memory {
+			return nil, errors.Errorf("invalid argon2 memory: %d KiB", memory)
+		}
+		if iterations < 1 || iterations > maxArgon2Iterations {
+			return nil, errors.Errorf("invalid argon2 iterations: %d", iterations)
+		}
+
+		mode := argon2d
+		if bytes.Equal(uuid, argon2idUUID) {
+			mode = argon2id
+		}
+		key := deriveKey(mode, composite, salt, nil, nil, uint32(iterations), uint32(memory), uint8(parallelism), 32)
+		return key, nil
+
+	case bytes.Equal(uuid, aesKdfUUID):
+		seed, ok := params.bytes("S")
+		if !ok || len(seed) != 32 {
+			return nil, errors.New("invalid AES-KDF seed")
+		}
+		rounds, ok := params.uint64("R")
+		if !ok || rounds > maxAESKDFRounds {
+			return nil, errors.Errorf("invalid AES-KDF rounds: %d", rounds)
+		}
+
+		block, err := aes.NewCipher(seed)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating cipher")
+		}
+		key := make([]byte, len(composite))
+		copy(key, composite)
+		for i := uint64(0); i < rounds; i++ {
+			block.Encrypt(key[:16], key[:16])
+			block.Encrypt(key[16:], key[16:])
+		}
+		sum := sha256.Sum256(key)
+		return sum[:], nil
+
+	default:
+		return nil, errors.New("unsupported key derivation function")
+	}
+}
+
+// deriveKeys returns the payload encryption key and the HMAC base key.
+func deriveKeys(password, mainSeed []byte, params variantDict) (encKey, hmacKey []byte, err error) {
+	pwdHash := sha256.Sum256(password)
+	composite := sha256.Sum256(pwdHash[:])
+
+	transformed, err := transformKey(composite[:], params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	h := sha256.New()
+	h.Write(mainSeed)
+	h.Write(transformed)
+	encKey = h.Sum(nil)
+
+	h512 := sha512.New()
+	h512.Write(mainSeed)
+	h512.Write(transformed)
+	h512.Write([]byte{1})
+	hmacKey = h512.Sum(nil)
+
+	return encKey, hmacKey, nil
+}
+
+// blockHMAC returns the HMAC of the data with the key of the block index passed.
+func blockHMAC(hmacKey []byte, index uint64, data []byte) []byte {
+	var idx [8]byte
+	binary.LittleEndian.PutUint64(idx[:], index)
+
+	h := sha512.New()
+	h.Write(idx[:])
+	h.Write(hmacKey)
+
+	mac := hmac.New(sha256.New, h.Sum(nil))
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// readBlocks verifies and concatenates the blocks of the HMAC block stream.
+func readBlocks(r *bytes.Reader, hmacKey []byte) ([]byte, error) {
+	var payload []byte
+	for index := uint64(0); ; index++ {
+		mac := make([]byte, 32)
+		if _, err := io.ReadFull(r, mac); err != nil {
+			return nil, ErrInvalidFile
+		}
+		var size uint32
+		if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+			return nil, ErrInvalidFile
+		}
+		if int64(size) > int64(r.Len()) {
+			return nil, ErrInvalidFile
+		}
+
+		// The HMAC covers the index, the size and the data
+		data := make([]byte, 12+size)
+		binary.LittleEndian.PutUint64(data, index)
+		binary.LittleEndian.PutUint32(data[8:], size)
+		if _, err := io.ReadFull(r, data[12:]); err != nil {
+			return nil, ErrInvalidFile
+		}
+
+		if !hmac.Equal(mac, blockHMAC(hmacKey, index, data)) {
+			return nil, errors.Wrap(ErrInvalidFile, "block authentication failed")
+		}
+		if size == 0 {
+			return payload, nil
+		}
+		payload = append(payload, data[12:]...)
+	}
+}
+
+// writeBlocks splits the payload into authenticated blocks.
+func writeBlocks(w io.Writer, payload []byte, hmacKey []byte) error {
+	const blockSize = 1 << 20
+	buf := new(bytes.Buffer)
+
+	for index := uint64(0); ; index++ {
+		n := len(payload)
+		if n > blockSize {
+			n = blockSize
+		}
+
+		data := make([]byte, 12+n)
+		binary.LittleEndian.PutUint64(data, index)
+		binary.LittleEndian.PutUint32(data[8:], uint32(n))
+		copy(data[12:], payload[:n])
+		payload = payload[n:]
+
+		buf.Write(blockHMAC(hmacKey, index, data))
+		buf.Write(data[8:])
+
+		// The last block is empty
+		if n == 0 {
+			break
+		}
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,487 @@
+// Package kdbx reads and writes KeePass databases in the KDBX 4 format.
+//
+// Only password protected databases are supported, key files and other key providers are not.
+package kdbx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/chacha20"
+	"golang.org/x/crypto/salsa20/salsa"
+)
+
+// Inner random stream identifiers.
+const (
+	salsa20StreamID  uint32 = 2
+	chacha20StreamID uint32 = 3
+)
+
+var (
+	// ErrInvalidFile is returned when the file is not a KDBX database or it's corrupted.
+	ErrInvalidFile = errors.New("invalid KDBX file")
+	// ErrInvalidCredentials is returned when the password does not match the database one.
+	ErrInvalidCredentials = errors.New("invalid credentials or corrupted database")
+)
+
+// Cipher is the algorithm used to encrypt the database.
+type Cipher int
+
+// Ciphers supported.
+const (
+	AES256 Cipher = iota
+	ChaCha20
+)
+
+// KDF is the function used to derive the encryption key from the password.
+type KDF int
+
+// Key derivation functions supported.
+const (
+	Argon2d KDF = iota
+	Argon2id
+	AESKDF
+)
+
+// Options are the parameters used to write a database.
+type Options struct {
+	Cipher Cipher
+	KDF    KDF
+	// Iterations is the number of argon2 passes or AES-KDF rounds
+	Iterations uint64
+	// Memory used by argon2, in kibibytes
+	Memory uint64
+	// Parallelism is the number of argon2 threads
+	Parallelism uint32
+	Compress    bool
+}
+
+// DefaultOptions returns the parameters KeePass uses by default.
+func DefaultOptions() Options {
+	return Options{
+		Cipher:      AES256,
+		KDF:         Argon2d,
+		Iterations:  2,
+		Memory:      64 * 1024,
+		Parallelism: 2,
+		Compress:    true,
+	}
+}
+
+// Database is a KeePass database.
+type Database struct {
+	// Name is the database name, used as the root group name as well
+	Name string
+	Root Group
+}
+
+// Group is a set of entries and subgroups.
+type Group struct {
+	Name    string
+	Groups  []Group
+	Entries []Entry
+}
+
+// Entry is a KeePass entry, its title, username, password, url and notes are stored as fields.
+type Entry struct {
+	Fields      []Field
+	Attachments []Attachment
+	Expires     bool
+	ExpiryTime  time.Time
+}
+
+// Field is an entry string field.
+type Field struct {
+	Key   string
+	Value string
+	// Protected fields are encrypted in memory by KeePass
+	Protected bool
+}
+
+// Attachment is a file attached to an entry.
+type Attachment struct {
+	Name string
+	Data []byte
+}
+
+// Standard field keys.
+const (
+	TitleField    = "Title"
+	UserNameField = "UserName"
+	PasswordField = "Password"
+	URLField      = "URL"
+	NotesField    = "Notes"
+)
+
+// Get returns the value of the field with the key passed, an empty string if it doesn't exist.
+func (e Entry) Get(key string) string {
+	for _, f := range e.Fields {
+		if f.Key == key {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Read decrypts and parses the database read from r.
+//
+// Entries and groups inside the recycle bin are skipped.
+func Read(r io.Reader, password []byte) (*Database, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading database")
+	}
+
+	reader := bytes.NewReader(data)
+	h, size, err := readHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	headerData := data[:size]
+
+	var hash, mac [32]byte
+	if _, err := io.ReadFull(reader, hash[:]); err != nil {
+		return nil, ErrInvalidFile
+	}
+	if _, err := io.ReadFull(reader, mac[:]); err != nil {
+		return nil, ErrInvalidFile
+	}
+	if sum := sha256.Sum256(headerData); !hmac.Equal(sum[:], hash[:]) {
+		return nil, errors.Wrap(ErrInvalidFile, "header checksum mismatch")
+	}
+
+	encKey, hmacKey, err := deriveKeys(password, h.mainSeed, h.kdfParams)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(mac[:], blockHMAC(hmacKey, ^uint64(0), headerData)) {
+		return nil, ErrInvalidCredentials
+	}
+
+	payload, err := readBlocks(reader, hmacKey)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err = decrypt(h, encKey, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.compress {
+		gr, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, errors.Wrap(err, "decompressing payload")
+		}
+		payload, err = io.ReadAll(gr)
+		if err != nil {
+			return nil, errors.Wrap(err, "decompressing payload")
+		}
+	}
+
+	stream, attachments, doc, err := readInnerHeader(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseXML(doc, stream, attachments)
+}
+
+// Write encrypts the database with the password and options passed and writes it to w.
+func Write(w io.Writer, db *Database, password []byte, opts Options) error {
+	h, err := newHeader(opts)
+	if err != nil {
+		return err
+	}
+	headerData := writeHeader(h)
+
+	encKey, hmacKey, err := deriveKeys(password, h.mainSeed, h.kdfParams)
+	if err != nil {
+		return err
+	}
+
+	streamKey, err := randomBytes(64)
+	if err != nil {
+		return err
+	}
+	stream, err := newInnerStream(chacha20StreamID, streamKey)
+	if err != nil {
+		return err
+	}
+
+	doc, attachments, err := buildXML(db, stream)
+	if err != nil {
+		return err
+	}
+
+	payload := new(bytes.Buffer)
+	writeInnerHeader(payload, streamKey, attachments)
+	payload.Write(doc)
+
+	plaintext := payload.Bytes()
+	if h.compress {
+		buf := new(bytes.Buffer)
+		gw := gzip.NewWriter(buf)
+		if _, err := gw.Write(plaintext); err != nil {
+			return errors.Wrap(err, "compressing payload")
+		}
+		if err := gw.Close(); err != nil {
+			return errors.Wrap(err, "compressing payload")
+		}
+		plaintext = buf.Bytes()
+	}
+
+	ciphertext, err := encrypt(h, encKey, plaintext)
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256(headerData)
+	out := new(bytes.Buffer)
+	out.Write(headerData)
+	out.Write(hash[:])
+	out.Write(blockHMAC(hmacKey, ^uint64(0), headerData))
+	if err := writeBlocks(out, ciphertext, hmacKey); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(out.Bytes()); err != nil {
+		return errors.Wrap(err, "writing database")
+	}
+	return nil
+}
+
+// newHeader generates the header seeds and sets the parameters from the options.
+func newHeader(opts Options) (*header, error) {
+	h := &header{compress: opts.Compress, kdfParams: make(variantDict)}
+
+	var err error
+	h.mainSeed, err = randomBytes(32)
+	if err != nil {
+		return nil, err
+	}
+
+	switch opts.Cipher {
+	case AES256:
+		h.cipherID = aes256UUID
+		h.iv, err = randomBytes(aes.BlockSize)
+	case ChaCha20:
+		h.cipherID = chacha20UUID
+		h.iv, err = randomBytes(chacha20.NonceSize)
+	default:
+		return nil, errors.New("invalid cipher")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seed, err := randomBytes(32)
+	if err != nil {
+		return nil, err
+	}
+
+	switch opts.KDF {
+	case Argon2d, Argon2id:
+		uuid := argon2dUUID
+		if opts.KDF == Argon2id {
+			uuid = argon2idUUID
+		}
+		h.kdfParams.setBytes("$UUID", uuid)
+		h.kdfParams.setBytes("S", seed)
+		h.kdfParams.setUint32("P", opts.Parallelism)
+		h.kdfParams.setUint64("M", opts.Memory*1024)
+		h.kdfParams.setUint64("I", opts.Iterations)
+		h.kdfParams.setUint32("V", argon2Version)
+	case AESKDF:
+		h.kdfParams.setBytes("$UUID", aesKdfUUID)
+		h.kdfParams.setBytes("S", seed)
+		h.kdfParams.setUint64("R", opts.Iterations)
+	default:
+		return nil, errors.New("invalid key derivation function")
+	}
+
+	return h, nil
+}
+
+func decrypt(h *header, key, ciphertext []byte) ([]byte, error) {
+	switch {
+	case bytes.Equal(h.cipherID, aes256UUID):
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating cipher")
+		}
+		if len(h.iv) != aes.BlockSize || len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+			return nil, ErrInvalidFile
+		}
+		plaintext := make([]byte, len(ciphertext))
+		cipher.NewCBCDecrypter(block, h.iv).CryptBlocks(plaintext, ciphertext)
+
+		// Remove PKCS#7 padding
+		pad := int(plaintext[len(plaintext)-1])
+		if pad == 0 || pad > aes.BlockSize {
+			return nil, ErrInvalidFile
+		}
+		for _, b := range plaintext[len(plaintext)-pad:] {
+			if int(b) != pad {
+				return nil, ErrInvalidFile
+			}
+		}
+		return plaintext[:len(plaintext)-pad], nil
+
+	case bytes.Equal(h.cipherID, chacha20UUID):
+		c, err := chacha20.NewUnauthenticatedCipher(key, h.iv)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating cipher")
+		}
+		plaintext := make([]byte, len(ciphertext))
+		c.XORKeyStream(plaintext, ciphertext)
+		return plaintext, nil
+
+	default:
+		return nil, errors.New("unsupported cipher")
+	}
+}
+
+func encrypt(h *header, key, plaintext []byte) ([]byte, error) {
+	switch {
+	case bytes.Equal(h.cipherID, aes256UUID):
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating cipher")
+		}
+		pad := aes.BlockSize - len(plaintext)%aes.BlockSize
+		padded := make([]byte, len(plaintext)+pad)
+		copy(padded, plaintext)
+		for i := len(plaintext); i < len(padded); i++ {
+			padded[i] = byte(pad)
+		}
+		cipher.NewCBCEncrypter(block, h.iv).CryptBlocks(padded, padded)
+		return padded, nil
+
+	case bytes.Equal(h.cipherID, chacha20UUID):
+		c, err := chacha20.NewUnauthenticatedCipher(key, h.iv)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating cipher")
+		}
+		ciphertext := make([]byte, len(plaintext))
+		c.XORKeyStream(ciphertext, plaintext)
+		return ciphertext, nil
+
+	default:
+		return nil, errors.New("unsupported cipher")
+	}
+}
+
+// readInnerHeader parses the inner header and returns the protected values stream,
+// the attachments and the XML document that follows it.
+func readInnerHeader(payload []byte) (cipher.Stream, [][]byte, []byte, error) {
+	r := bytes.NewReader(payload)
+	var (
+		streamID    uint32
+		streamKey   []byte
+		attachments [][]byte
+	)
+
+	for {
+		id, err := r.ReadByte()
+		if err != nil {
+			return nil, nil, nil, ErrInvalidFile
+		}
+		data, err := readField(r)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		switch id {
+		case innerFieldEnd:
+			stream, err := newInnerStream(streamID, streamKey)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			return stream, attachments, payload[len(payload)-r.Len():], nil
+		case innerFieldStreamID:
+			if len(data) != 4 {
+				return nil, nil, nil, ErrInvalidFile
+			}
+			streamID = binary.LittleEndian.Uint32(data)
+		case innerFieldStreamKey:
+			streamKey = data
+		case innerFieldBinary:
+			// The first byte contains the binary flags
+			if len(data) == 0 {
+				return nil, nil, nil, ErrInvalidFile
+			}
+			attachments = append(attachments, data[1:])
+		}
+	}
+}
+
+func writeInnerHeader(buf *bytes.Buffer, streamKey []byte, attachments [][]byte) {
+	id := make([]byte, 4)
+	binary.LittleEndian.PutUint32(id, chacha20StreamID)
+
+	writeField(buf, innerFieldStreamID, id)
+	writeField(buf, innerFieldStreamKey, streamKey)
+	for _, a := range attachments {
+		writeField(buf, innerFieldBinary, append([]byte{0}, a...))
+	}
+	writeField(buf, innerFieldEnd, nil)
+}
+
+// newInnerStream returns the stream used to encrypt the protected values.
+func newInnerStream(id uint32, key []byte) (cipher.Stream, error) {
+	switch id {
+	case chacha20StreamID:
+		h := sha512.Sum512(key)
+		c, err := chacha20.NewUnauthenticatedCipher(h[:32], h[32:44])
+		if err != nil {
+			return nil, errors.Wrap(err, "creating inner stream")
+		}
+		return c, nil
+	case salsa20StreamID:
+		s := &salsa20Stream{key: sha256.Sum256(key)}
+		copy(s.counter[:8], []byte{0xE8, 0x30, 0x09, 0x4B, 0x97, 0x20, 0x5D, 0x2A})
+		return s, nil
+	default:
+		return nil, errors.Errorf("unsupported inner stream %d", id)
+	}
+}
+
+// salsa20Stream is a Salsa20 cipher.Stream, used by databases converted from KDBX 3.
+type salsa20Stream struct {
+	key     [32]byte
+	counter [16]byte
+	block   [64]byte
+	used    int
+}
+
+func (s *salsa20Stream) XORKeyStream(dst, src []byte) {
+	for i := range src {
+		if s.used == 0 || s.used == len(s.block) {
+			var zero [64]byte
+			salsa.XORKeyStream(s.block[:], zero[:], &s.counter, &s.key)
+			binary.LittleEndian.PutUint64(s.counter[8:], binary.LittleEndian.Uint64(s.counter[8:])+1)
+			s.used = 0
+		}
+		dst[i] = src[i] ^ s.block[s.used]
+		s.used++
+	}
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, errors.Wrap(err, "generating random bytes")
+	}
+	return b, nil
+}
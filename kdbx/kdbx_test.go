@@ -0,0 +1,282 @@
+package kdbx
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/salsa20"
+)
+
+func TestReadWrite(t *testing.T) {
+	expected := testDatabase()
+	password := []byte("kure")
+
+	cases := []struct {
+		desc string
+		opts Options
+	}{
+		{
+			desc: "AES-256 Argon2d",
+			opts: Options{Cipher: AES256, KDF: Argon2d, Iterations: 1, Memory: 64, Parallelism: 2, Compress: true},
+		},
+		{
+			desc: "ChaCha20 Argon2id",
+			opts: Options{Cipher: ChaCha20, KDF: Argon2id, Iterations: 2, Memory: 32, Parallelism: 1, Compress: true},
+		},
+		{
+			desc: "AES-256 AES-KDF uncompressed",
+			opts: Options{Cipher: AES256, KDF: AESKDF, Iterations: 100},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := Write(buf, expected, password, tc.opts); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Read(buf, password)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(expected, got) {
+				t.Errorf("Expected %+v, got %+v", expected, got)
+			}
+		})
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	opts := Options{Cipher: ChaCha20, KDF: Argon2d, Iterations: 1, Memory: 32, Parallelism: 1}
+	buf := new(bytes.Buffer)
+	if err := Write(buf, testDatabase(), []byte("kure"), opts); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	t.Run("Invalid password", func(t *testing.T) {
+		if _, err := Read(bytes.NewReader(data), []byte("invalid")); err != ErrInvalidCredentials {
+			t.Errorf("Expected %v, got %v", ErrInvalidCredentials, err)
+		}
+	})
+
+	t.Run("Tampered payload", func(t *testing.T) {
+		tampered := append([]byte(nil), data...)
+		tampered[len(tampered)-50] ^= 1
+		if _, err := Read(bytes.NewReader(tampered), []byte("kure")); err == nil {
+			t.Error("Expected an error and got nil")
+		}
+	})
+
+	t.Run("Invalid signature", func(t *testing.T) {
+		if _, err := Read(bytes.NewReader([]byte("not a database")), []byte("kure")); err != ErrInvalidFile {
+			t.Errorf("Expected %v, got %v", ErrInvalidFile, err)
+		}
+	})
+
+	t.Run("Unsupported version", func(t *testing.T) {
+		v3 := append([]byte(nil), data...)
+		v3[10] = 3
+		if _, err := Read(bytes.NewReader(v3), []byte("kure")); err == nil {
+			t.Error("Expected an error and got nil")
+		}
+	})
+}
+
+func TestParseXML(t *testing.T) {
+	key := []byte("inner stream key")
+	stream, err := newInnerStream(chacha20StreamID, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Protected values are encrypted in document order, including the ones in the history
+	protect := func(s string) string {
+		out := make([]byte, len(s))
+		stream.XORKeyStream(out, []byte(s))
+		return base64.StdEncoding.EncodeToString(out)
+	}
+	password, old := protect("secret"), protect("old")
+
+	doc := `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<KeePassFile>
+	<Meta>
+		<DatabaseName>Test</DatabaseName>
+		<RecycleBinEnabled>True</RecycleBinEnabled>
+		<RecycleBinUUID>AAAAAAAAAAAAAAAAAAAAAQ==</RecycleBinUUID>
+	</Meta>
+	<Root>
+		<Group>
+			<UUID>AAAAAAAAAAAAAAAAAAAAAA==</UUID>
+			<Name>Root</Name>
+			<Entry>
+				<Times>
+					<ExpiryTime>2030-01-02T03:04:05Z</ExpiryTime>
+					<Expires>True</Expires>
+				</Times>
+				<String><Key>Title</Key><Value>Legacy</Value></String>
+				<String><Key>Password</Key><Value Protected="True">` + password + `</Value></String>
+				<History>
+					<Entry>
+						<String><Key>Password</Key><Value Protected="True">` + old + `</Value></String>
+					</Entry>
+				</History>
+			</Entry>
+			<Group>
+				<UUID>AAAAAAAAAAAAAAAAAAAAAQ==</UUID>
+				<Name>Recycle Bin</Name>
+				<Entry>
+					<String><Key>Title</Key><Value>Deleted</Value></String>
+				</Entry>
+			</Group>
+		</Group>
+	</Root>
+</KeePassFile>`
+
+	stream, err = newInnerStream(chacha20StreamID, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parseXML([]byte(doc), stream, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &Database{
+		Name: "Test",
+		Root: Group{
+			Name: "Root",
+			Entries: []Entry{
+				{
+					Fields: []Field{
+						{Key: TitleField, Value: "Legacy"},
+						{Key: PasswordField, Value: "secret", Protected: true},
+					},
+					Expires:    true,
+					ExpiryTime: time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestSalsa20Stream(t *testing.T) {
+	key := []byte("inner stream key")
+	input := bytes.Repeat([]byte("kure"), 50)
+
+	k := sha256.Sum256(key)
+	expected := make([]byte, len(input))
+	salsa20.XORKeyStream(expected, input, []byte{0xE8, 0x30, 0x09, 0x4B, 0x97, 0x20, 0x5D, 0x2A}, &k)
+
+	stream, err := newInnerStream(salsa20StreamID, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Process the input in chunks that don't match the block size
+	got := make([]byte, len(input))
+	for i := 0; i < len(input); i += 30 {
+		end := i + 30
+		if end > len(input) {
+			end = len(input)
+		}
+		stream.XORKeyStream(got[i:end], input[i:end])
+	}
+
+	if !bytes.Equal(expected, got) {
+		t.Errorf("Expected %x, got %x", expected, got)
+	}
+}
+
+func testDatabase() *Database {
+	return &Database{
+		Name: "Test",
+		Root: Group{
+			Name: "Test",
+			Entries: []Entry{
+				{
+					Fields: []Field{
+						{Key: TitleField, Value: "github"},
+						{Key: UserNameField, Value: "gopher"},
+						{Key: PasswordField, Value: "p4ss<&>word", Protected: true},
+						{Key: URLField, Value: "https://github.com"},
+						{Key: NotesField, Value: "multiline\nnotes"},
+						{Key: "otp", Value: "otpauth://totp/github?secret=JBSWY3DPEHPK3PXP&period=30&digits=6", Protected: true},
+						{Key: "Recovery code", Value: "1234"},
+					},
+					Attachments: []Attachment{{Name: "recovery.txt", Data: []byte("codes")}},
+					Expires:     true,
+					ExpiryTime:  time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+				},
+				{
+					Fields: []Field{
+						{Key: TitleField, Value: "empty"},
+						{Key: PasswordField, Value: "", Protected: true},
+					},
+				},
+			},
+			Groups: []Group{
+				{
+					Name: "Work",
+					Groups: []Group{
+						{
+							Name: "Servers",
+							Entries: []Entry{
+								{Fields: []Field{{Key: TitleField, Value: "ssh"}, {Key: PasswordField, Value: "ssh-pass", Protected: true}}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestTransformKeyErrors(t *testing.T) {
+	composite := make([]byte, 32)
+	seed := make([]byte, 32)
+
+	argon2Params := func(memory, iterations uint64) variantDict {
+		params := make(variantDict)
+		params.setBytes("$UUID", argon2dUUID)
+		params.setBytes("S", seed)
+		params.setUint32("P", 1)
+		params.setUint64("M", memory*1024)
+		params.setUint64("I", iterations)
+		params.setUint32("V", argon2Version)
+		return params
+	}
+
+	missingMemory := argon2Params(32, 1)
+	delete(missingMemory, "M")
+
+	aesParams := make(variantDict)
+	aesParams.setBytes("$UUID", aesKdfUUID)
+	aesParams.setBytes("S", seed)
+	aesParams.setUint64("R", maxAESKDFRounds+1)
+
+	cases := []struct {
+		desc   string
+		params variantDict
+	}{
+		{desc: "Missing argon2 memory", params: missingMemory},
+		{desc: "Argon2 memory too high", params: argon2Params(maxArgon2Memory+1, 1)},
+		{desc: "Argon2 iterations too high", params: argon2Params(32, maxArgon2Iterations+1)},
+		{desc: "AES-KDF rounds too high", params: aesParams},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if _, err := transformKey(composite, tc.params); err == nil {
+				t.Error("Expected an error and got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,376 @@
+package kdbx
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Seconds between 0001-01-01 and the Unix epoch, KDBX 4 times are counted from the former.
+const epochOffset = 62135596800
+
+type xmlFile struct {
+	XMLName xml.Name `xml:"KeePassFile"`
+	Meta    xmlMeta  `xml:"Meta"`
+	Root    xmlRoot  `xml:"Root"`
+}
+
+type xmlMeta struct {
+	Generator         string  `xml:"Generator"`
+	DatabaseName      string  `xml:"DatabaseName"`
+	RecycleBinEnabled xmlBool `xml:"RecycleBinEnabled"`
+	RecycleBinUUID    string  `xml:"RecycleBinUUID"`
+}
+
+type xmlRoot struct {
+	Group xmlGroup `xml:"Group"`
+}
+
+type xmlGroup struct {
+	UUID    string     `xml:"UUID"`
+	Name    string     `xml:"Name"`
+	IconID  int        `xml:"IconID"`
+	Times   xmlTimes   `xml:"Times"`
+	Entries []xmlEntry `xml:"Entry"`
+	Groups  []xmlGroup `xml:"Group"`
+}
+
+type xmlEntry struct {
+	UUID     string      `xml:"UUID"`
+	IconID   int         `xml:"IconID"`
+	Times    xmlTimes    `xml:"Times"`
+	Strings  []xmlString `xml:"String"`
+	Binaries []xmlBinary `xml:"Binary"`
+}
+
+type xmlTimes struct {
+	CreationTime         xmlTime `xml:"CreationTime"`
+	LastModificationTime xmlTime `xml:"LastModificationTime"`
+	LastAccessTime       xmlTime `xml:"LastAccessTime"`
+	ExpiryTime           xmlTime `xml:"ExpiryTime"`
+	Expires              xmlBool `xml:"Expires"`
+	UsageCount           int     `xml:"UsageCount"`
+	LocationChanged      xmlTime `xml:"LocationChanged"`
+}
+
+type xmlString struct {
+	Key   string   `xml:"Key"`
+	Value xmlValue `xml:"Value"`
+}
+
+type xmlValue struct {
+	Protected xmlBool `xml:"Protected,attr,omitempty"`
+	Value     string  `xml:",chardata"`
+}
+
+type xmlBinary struct {
+	Key   string `xml:"Key"`
+	Value struct {
+		Ref int `xml:"Ref,attr"`
+	} `xml:"Value"`
+}
+
+// xmlBool is a boolean formatted as KeePass expects it.
+type xmlBool bool
+
+func (b xmlBool) MarshalText() ([]byte, error) {
+	if b {
+		return []byte("True"), nil
+	}
+	return []byte("False"), nil
+}
+
+func (b *xmlBool) UnmarshalText(text []byte) error {
+	*b = xmlBool(strings.EqualFold(string(text), "true"))
+	return nil
+}
+
+// xmlTime is a time encoded in base64 as the number of seconds since 0001-01-01,
+// times in the ISO 8601 format used by KDBX 3 are accepted as well.
+type xmlTime time.Time
+
+func (t xmlTime) MarshalText() ([]byte, error) {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, uint64(time.Time(t).Unix()+epochOffset))
+	return []byte(base64.StdEncoding.EncodeToString(b)), nil
+}
+
+func (t *xmlTime) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		return nil
+	}
+	if bytes.ContainsRune(text, ':') {
+		parsed, err := time.Parse(time.RFC3339, string(text))
+		if err != nil {
+			return errors.Wrap(err, "parsing time")
+		}
+		*t = xmlTime(parsed.UTC())
+		return nil
+	}
+
+	b, err := base64.StdEncoding.DecodeString(string(text))
+	if err != nil || len(b) != 8 {
+		return errors.New("invalid time")
+	}
+	seconds := int64(binary.LittleEndian.Uint64(b)) - epochOffset
+	*t = xmlTime(time.Unix(seconds, 0).UTC())
+	return nil
+}
+
+// parseXML decrypts the protected values of the document and converts it to a database.
+func parseXML(doc []byte, stream cipher.Stream, attachments [][]byte) (*Database, error) {
+	doc, err := transformProtected(doc, func(value string) (string, error) {
+		ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil {
+			return "", errors.Wrap(err, "decoding protected value")
+		}
+		plaintext := make([]byte, len(ciphertext))
+		stream.XORKeyStream(plaintext, ciphertext)
+		return string(plaintext), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var file xmlFile
+	if err := xml.Unmarshal(doc, &file); err != nil {
+		return nil, errors.Wrap(err, "parsing XML")
+	}
+
+	recycleBin := ""
+	if file.Meta.RecycleBinEnabled {
+		recycleBin = file.Meta.RecycleBinUUID
+	}
+
+	root, err := fromXMLGroup(file.Root.Group, attachments, recycleBin)
+	if err != nil {
+		return nil, err
+	}
+
+	name := file.Meta.DatabaseName
+	if name == "" {
+		name = root.Name
+	}
+	return &Database{Name: name, Root: root}, nil
+}
+
+func fromXMLGroup(g xmlGroup, attachments [][]byte, recycleBin string) (Group, error) {
+	group := Group{Name: g.Name}
+
+	for _, e := range g.Entries {
+		entry := Entry{Expires: bool(e.Times.Expires)}
+		if entry.Expires {
+			entry.ExpiryTime = time.Time(e.Times.ExpiryTime)
+		}
+		for _, s := range e.Strings {
+			entry.Fields = append(entry.Fields, Field{
+				Key:       s.Key,
+				Value:     s.Value.Value,
+				Protected: bool(s.Value.Protected),
+			})
+		}
+		for _, b := range e.Binaries {
+			if b.Value.Ref < 0 || b.Value.Ref >= len(attachments) {
+				return Group{}, errors.Errorf("invalid attachment reference %d", b.Value.Ref)
+			}
+			entry.Attachments = append(entry.Attachments, Attachment{Name: b.Key, Data: attachments[b.Value.Ref]})
+		}
+		group.Entries = append(group.Entries, entry)
+	}
+
+	for _, sub := range g.Groups {
+		if recycleBin != "" && sub.UUID == recycleBin {
+			continue
+		}
+		subgroup, err := fromXMLGroup(sub, attachments, recycleBin)
+		if err != nil {
+			return Group{}, err
+		}
+		group.Groups = append(group.Groups, subgroup)
+	}
+
+	return group, nil
+}
+
+// buildXML converts the database to an XML document with its protected values encrypted,
+// it returns the attachments that must be stored in the inner header as well.
+func buildXML(db *Database, stream cipher.Stream) ([]byte, [][]byte, error) {
+	var attachments [][]byte
+	now := xmlTime(time.Now().UTC())
+
+	root, err := toXMLGroup(db.Root, db.Name, now, &attachments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file := xmlFile{
+		Meta: xmlMeta{
+			Generator:    "Kure",
+			DatabaseName: db.Name,
+		},
+		Root: xmlRoot{Group: root},
+	}
+
+	doc, err := xml.MarshalIndent(file, "", "\t")
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "encoding XML")
+	}
+
+	doc, err = transformProtected(doc, func(value string) (string, error) {
+		ciphertext := make([]byte, len(value))
+		stream.XORKeyStream(ciphertext, []byte(value))
+		return base64.StdEncoding.EncodeToString(ciphertext), nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return append([]byte(xml.Header), doc...), attachments, nil
+}
+
+func toXMLGroup(g Group, name string, now xmlTime, attachments *[][]byte) (xmlGroup, error) {
+	uuid, err := newUUID()
+	if err != nil {
+		return xmlGroup{}, err
+	}
+	if g.Name != "" {
+		name = g.Name
+	}
+
+	group := xmlGroup{
+		UUID:   uuid,
+		Name:   name,
+		IconID: 48,
+		Times:  newTimes(now),
+	}
+
+	for _, e := range g.Entries {
+		uuid, err := newUUID()
+		if err != nil {
+			return xmlGroup{}, err
+		}
+
+		entry := xmlEntry{UUID: uuid, Times: newTimes(now)}
+		if e.Expires {
+			entry.Times.Expires = true
+			entry.Times.ExpiryTime = xmlTime(e.ExpiryTime)
+		}
+		for _, f := range e.Fields {
+			entry.Strings = append(entry.Strings, xmlString{
+				Key:   f.Key,
+				Value: xmlValue{Value: f.Value, Protected: xmlBool(f.Protected)},
+			})
+		}
+		for _, a := range e.Attachments {
+			b := xmlBinary{Key: a.Name}
+			b.Value.Ref = len(*attachments)
+			*attachments = append(*attachments, a.Data)
+			entry.Binaries = append(entry.Binaries, b)
+		}
+		group.Entries = append(group.Entries, entry)
+	}
+
+	for _, sub := range g.Groups {
+		subgroup, err := toXMLGroup(sub, "", now, attachments)
+		if err != nil {
+			return xmlGroup{}, err
+		}
+		group.Groups = append(group.Groups, subgroup)
+	}
+
+	return group, nil
+}
+
+func newTimes(now xmlTime) xmlTimes {
+	return xmlTimes{
+		CreationTime:         now,
+		LastModificationTime: now,
+		LastAccessTime:       now,
+		ExpiryTime:           now,
+		LocationChanged:      now,
+	}
+}
+
+func newUUID() (string, error) {
+	uuid, err := randomBytes(16)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(uuid), nil
+}
+
+// transformProtected applies fn to the content of the values marked as protected.
+//
+// The inner stream is shared by all the values, so they must be processed in the order
+// they appear in the document.
+func transformProtected(doc []byte, fn func(value string) (string, error)) ([]byte, error) {
+	dec := xml.NewDecoder(bytes.NewReader(doc))
+	buf := new(bytes.Buffer)
+	enc := xml.NewEncoder(buf)
+
+	protected := false
+	var value strings.Builder
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing XML")
+		}
+
+		switch t := tok.(type) {
+		case xml.ProcInst:
+			// The declaration is written again if needed
+			continue
+		case xml.StartElement:
+			if t.Name.Local == "Value" && isProtected(t.Attr) {
+				protected = true
+				value.Reset()
+			}
+		case xml.CharData:
+			if protected {
+				value.Write(t)
+				continue
+			}
+		case xml.EndElement:
+			if protected {
+				protected = false
+				result, err := fn(value.String())
+				if err != nil {
+					return nil, err
+				}
+				if err := enc.EncodeToken(xml.CharData(result)); err != nil {
+					return nil, errors.Wrap(err, "encoding XML")
+				}
+			}
+		}
+
+		if err := enc.EncodeToken(xml.CopyToken(tok)); err != nil {
+			return nil, errors.Wrap(err, "encoding XML")
+		}
+	}
+
+	if err := enc.Flush(); err != nil {
+		return nil, errors.Wrap(err, "encoding XML")
+	}
+	return buf.Bytes(), nil
+}
+
+func isProtected(attrs []xml.Attr) bool {
+	for _, a := range attrs {
+		if a.Name.Local == "Protected" {
+			v, _ := strconv.ParseBool(a.Value)
+			return v
+		}
+	}
+	return false
+}
@@ -7,8 +7,10 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/GGP1/kure/clip"
 	"github.com/GGP1/kure/commands/root"
 	"github.com/GGP1/kure/config"
+	authDB "github.com/GGP1/kure/db/auth"
 	"github.com/GGP1/kure/sig"
 
 	"github.com/awnumar/memguard"
@@ -25,6 +27,15 @@ func main() {
 		log.Fatalf("couldn't initialize the configuration: %v", err)
 	}
 
+	// Started by a previous execution to clear the clipboard, the database must not be opened
+	if clip.IsClearer() {
+		if err := clip.RunClearer(os.Stdin); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+	clip.EnableDetach()
+
 	dbPath := filepath.Clean(config.GetString("database.path"))
 	db, err := bolt.Open(dbPath, 0600, &bolt.Options{Timeout: 200 * time.Millisecond})
 	if err != nil {
@@ -32,14 +43,17 @@ func main() {
 	}
 
 	// Listen for a signal to release resources and delete sensitive information
-	sig.Signal.Listen(db)
+	closeDB := func() error { return authDB.Close(db) }
+	sig.Signal.Listen(closeDB)
 
 	if err := root.Execute(db); err != nil {
-		fmt.Fprintln(os.Stderr, "error:", err)
-		db.Close()
+		closeDB()
 		memguard.SafeExit(1)
 	}
 
-	db.Close()
+	if err := closeDB(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		memguard.SafeExit(1)
+	}
 	memguard.SafeExit(0)
 }
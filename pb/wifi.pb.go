@@ -0,0 +1,196 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.25.0
+// 	protoc        v3.13.0
+// source: wifi.proto
+
+package pb
+
+import (
+	proto "github.com/golang/protobuf/proto"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+// Wifi represents a wireless network.
+type Wifi struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name"`
+	Ssid string `protobuf:"bytes,2,opt,name=ssid,proto3" json:"ssid"`
+	// WPA, WPA2, WPA3, WEP or none.
+	Security string `protobuf:"bytes,3,opt,name=security,proto3" json:"security"`
+	Password string `protobuf:"bytes,4,opt,name=password,proto3" json:"password"`
+	Hidden   bool   `protobuf:"varint,5,opt,name=hidden,proto3" json:"hidden"`
+	Notes    string `protobuf:"bytes,6,opt,name=notes,proto3" json:"notes"`
+}
+
+func (x *Wifi) Reset() {
+	*x = Wifi{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_wifi_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Wifi) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Wifi) ProtoMessage() {}
+
+func (x *Wifi) ProtoReflect() protoreflect.Message {
+	mi := &file_wifi_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Wifi.ProtoReflect.Descriptor instead.
+func (*Wifi) Descriptor() ([]byte, []int) {
+	return file_wifi_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Wifi) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Wifi) GetSsid() string {
+	if x != nil {
+		return x.Ssid
+	}
+	return ""
+}
+
+func (x *Wifi) GetSecurity() string {
+	if x != nil {
+		return x.Security
+	}
+	return ""
+}
+
+func (x *Wifi) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *Wifi) GetHidden() bool {
+	if x != nil {
+		return x.Hidden
+	}
+	return false
+}
+
+func (x *Wifi) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+var File_wifi_proto protoreflect.FileDescriptor
+
+var file_wifi_proto_rawDesc = []byte{
+	0x0a, 0x0a, 0x77, 0x69, 0x66, 0x69, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62,
+	0x22, 0x94, 0x01, 0x0a, 0x04, 0x57, 0x69, 0x66, 0x69, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x73, 0x73, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x73, 0x69,
+	0x64, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x12, 0x1a, 0x0a,
+	0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x69, 0x64,
+	0x64, 0x65, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x68, 0x69, 0x64, 0x64, 0x65,
+	0x6e, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x42, 0x19, 0x5a, 0x17, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x47, 0x47, 0x50, 0x31, 0x2f, 0x6b, 0x75, 0x72, 0x65, 0x2f,
+	0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_wifi_proto_rawDescOnce sync.Once
+	file_wifi_proto_rawDescData = file_wifi_proto_rawDesc
+)
+
+func file_wifi_proto_rawDescGZIP() []byte {
+	file_wifi_proto_rawDescOnce.Do(func() {
+		file_wifi_proto_rawDescData = protoimpl.X.CompressGZIP(file_wifi_proto_rawDescData)
+	})
+	return file_wifi_proto_rawDescData
+}
+
+var file_wifi_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_wifi_proto_goTypes = []interface{}{
+	(*Wifi)(nil), // 0: pb.Wifi
+}
+var file_wifi_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_wifi_proto_init() }
+func file_wifi_proto_init() {
+	if File_wifi_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_wifi_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Wifi); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_wifi_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_wifi_proto_goTypes,
+		DependencyIndexes: file_wifi_proto_depIdxs,
+		MessageInfos:      file_wifi_proto_msgTypes,
+	}.Build()
+	File_wifi_proto = out.File
+	file_wifi_proto_rawDesc = nil
+	file_wifi_proto_goTypes = nil
+	file_wifi_proto_depIdxs = nil
+}
@@ -9,7 +9,6 @@ import (
 	"syscall"
 
 	"github.com/awnumar/memguard"
-	bolt "go.etcd.io/bbolt"
 )
 
 // Signal is the element used to handle interruptions.
@@ -61,10 +60,11 @@ func (s *sig) Kill() {
 // Listen listens for a signal to release resources, delete any sensitive information
 // and exit safely. It should be called only once and in the main file.
 //
-// If keepAlive is true it won't exit. Cleanup functions are executed in any case.
+// If keepAlive is true it won't exit. Cleanup functions are executed in any case, closeDB
+// only before exiting.
 //
-// db.Close() will block waiting for open transactions to finish before closing.
-func (s *sig) Listen(db *bolt.DB) {
+// closeDB should block waiting for open transactions to finish before closing, as db.Close() does.
+func (s *sig) Listen(closeDB func() error) {
 	// interrupt gets updated on each call to Listen
 	s.interrupt = make(chan os.Signal, 1)
 	signal.Notify(s.interrupt, os.Interrupt, syscall.SIGHUP, syscall.SIGTERM)
@@ -81,16 +81,31 @@ func (s *sig) Listen(db *bolt.DB) {
 		if atomic.LoadInt32(&s.keepAlive) == 1 {
 			// Reset keep alive state
 			atomic.StoreInt32(&s.keepAlive, 0)
-			s.Listen(db)
+			s.Listen(closeDB)
 			return
 		}
 
-		db.Close()
+		if err := closeDB(); err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
 		fmt.Println("\nExiting...")
 		memguard.SafeExit(0)
 	}()
 }
 
+// ScopeCleanups returns a function that removes the cleanups added after the call, the ones
+// registered before are kept.
+//
+// Example: discard the cleanups of a command executed inside a session once it finishes.
+func (s *sig) ScopeCleanups() func() {
+	n := len(s.cleanups)
+	return func() {
+		if len(s.cleanups) > n {
+			s.cleanups = s.cleanups[:n]
+		}
+	}
+}
+
 // ResetCleanups empties cleanup functions.
 func (s *sig) ResetCleanups() {
 	s.cleanups = nil
@@ -23,6 +23,21 @@ func TestAddCleanup(t *testing.T) {
 	}
 }
 
+func TestScopeCleanups(t *testing.T) {
+	Signal.ResetCleanups()
+	defer Signal.ResetCleanups()
+
+	Signal.AddCleanup(func() error { return nil })
+	removeCleanups := Signal.ScopeCleanups()
+	Signal.AddCleanup(func() error { return nil })
+	Signal.AddCleanup(func() error { return nil })
+	removeCleanups()
+
+	if len(Signal.cleanups) != 1 {
+		t.Errorf("Expected 1 cleanup function, got %d", len(Signal.cleanups))
+	}
+}
+
 func TestKeepAlive(t *testing.T) {
 	Signal.KeepAlive()
 	if Signal.keepAlive != 1 {
@@ -39,6 +54,6 @@ func TestListenKeepAlive(t *testing.T) {
 	}
 	defer db.Close()
 
-	Signal.Listen(db)
+	Signal.Listen(db.Close)
 	Signal.Interrupt()
 }
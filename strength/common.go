@@ -0,0 +1,75 @@
+package strength
+
+// commonPasswords contains the base words of the most used passwords, digits and symbols
+// appended to them are handled separately.
+var commonPasswords = map[string]struct{}{
+	"abc":           {},
+	"access":        {},
+	"admin":         {},
+	"administrator": {},
+	"alexander":     {},
+	"andrew":        {},
+	"angel":         {},
+	"ashley":        {},
+	"azerty":        {},
+	"bailey":        {},
+	"baseball":      {},
+	"batman":        {},
+	"buster":        {},
+	"charlie":       {},
+	"cheese":        {},
+	"chocolate":     {},
+	"computer":      {},
+	"cookie":        {},
+	"daniel":        {},
+	"dragon":        {},
+	"flower":        {},
+	"football":      {},
+	"freedom":       {},
+	"ginger":        {},
+	"hello":         {},
+	"hockey":        {},
+	"hunter":        {},
+	"iloveyou":      {},
+	"jennifer":      {},
+	"jessica":       {},
+	"jordan":        {},
+	"killer":        {},
+	"letmein":       {},
+	"login":         {},
+	"love":          {},
+	"lovely":        {},
+	"maggie":        {},
+	"master":        {},
+	"matrix":        {},
+	"michael":       {},
+	"michelle":      {},
+	"monkey":        {},
+	"mustang":       {},
+	"nicole":        {},
+	"pass":          {},
+	"passw":         {},
+	"passwd":        {},
+	"password":      {},
+	"pepper":        {},
+	"princess":      {},
+	"qazwsx":        {},
+	"qwerty":        {},
+	"qwertyuiop":    {},
+	"ranger":        {},
+	"root":          {},
+	"secret":        {},
+	"shadow":        {},
+	"soccer":        {},
+	"starwars":      {},
+	"summer":        {},
+	"sunshine":      {},
+	"superman":      {},
+	"thomas":        {},
+	"tigger":        {},
+	"trustno":       {},
+	"welcome":       {},
+	"whatever":      {},
+	"winter":        {},
+	"zxcvbnm":       {},
+}
@@ -0,0 +1,111 @@
+package strength
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+var (
+	thousand    = math.Pow(10, 3)
+	million     = math.Pow(10, 6)
+	billion     = math.Pow(10, 9)
+	trillion    = math.Pow(10, 12)
+	quadrillion = math.Pow(10, 15)
+	quintillion = math.Pow(10, 18)
+	sextillion  = math.Pow(10, 21)
+)
+
+const (
+	minute     = 60
+	hour       = minute * 60
+	day        = hour * 24
+	month      = day * 30
+	year       = month * 12
+	decade     = year * 10
+	century    = decade * 10
+	millennium = century * 10
+)
+
+// FormatSecretSecurity makes the data passed easier to read.
+func FormatSecretSecurity(keyspace, avgTimeToCrack float64) (space, time string) {
+	if math.IsInf(keyspace, 1) {
+		return "+Inf", "+Inf"
+	}
+
+	space = fmt.Sprintf("%.2f", keyspace)
+	time = fmt.Sprintf("%.2f seconds", avgTimeToCrack)
+
+	switch {
+	case keyspace >= sextillion:
+		space = fmt.Sprintf("%.2f sextillion", keyspace/sextillion)
+
+	case keyspace >= quintillion:
+		space = fmt.Sprintf("%.2f quintillion", keyspace/quintillion)
+
+	case keyspace >= quadrillion:
+		space = fmt.Sprintf("%.2f quadrillion", keyspace/quadrillion)
+
+	case keyspace >= trillion:
+		space = fmt.Sprintf("%.2f trillion", keyspace/trillion)
+
+	case keyspace >= billion:
+		space = fmt.Sprintf("%.2f billion", keyspace/billion)
+
+	case keyspace >= million:
+		space = fmt.Sprintf("%.2f million", keyspace/million)
+
+	case keyspace >= thousand:
+		space = fmt.Sprintf("%.2f thousand", keyspace/thousand)
+	}
+
+	switch {
+	case avgTimeToCrack >= millennium:
+		time = fmt.Sprintf("%.2f millenniums", avgTimeToCrack/millennium)
+
+	case avgTimeToCrack >= century:
+		time = fmt.Sprintf("%.2f centuries", avgTimeToCrack/century)
+
+	case avgTimeToCrack >= decade:
+		time = fmt.Sprintf("%.2f decades", avgTimeToCrack/decade)
+
+	case avgTimeToCrack >= year:
+		time = fmt.Sprintf("%.2f years", avgTimeToCrack/year)
+
+	case avgTimeToCrack >= month:
+		time = fmt.Sprintf("%.2f months", avgTimeToCrack/month)
+
+	case avgTimeToCrack >= day:
+		time = fmt.Sprintf("%.2f days", avgTimeToCrack/day)
+
+	case avgTimeToCrack >= hour:
+		time = fmt.Sprintf("%.2f hours", avgTimeToCrack/hour)
+
+	case avgTimeToCrack >= minute:
+		time = fmt.Sprintf("%.2f minutes", avgTimeToCrack/minute)
+	}
+
+	return prettify(space), prettify(time)
+}
+
+// prettify adds commas to the number inside the string to make it easier for humans to read.
+//
+// Example: 51334.21 -> 51,334.21
+func prettify(str string) string {
+	idx := strings.IndexByte(str, '.')
+	num := str[:idx]
+
+	sb := strings.Builder{}
+	j := 0
+	for i := len(num) - 1; i >= 0; i-- {
+		sb.WriteByte(num[j])
+		if i%3 == 0 && i != 0 {
+			sb.WriteByte(',')
+		}
+		j++
+	}
+
+	// Append remaining characters
+	sb.WriteString(str[idx:])
+	return sb.String()
+}
@@ -0,0 +1,128 @@
+package strength
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestFormatSecurity(t *testing.T) {
+	cases := []struct {
+		desc             string
+		keyspace         float64
+		timeToCrack      float64
+		expectedKeyspace string
+		expectedTime     string
+	}{
+		{
+			desc:             "Inf",
+			keyspace:         math.Inf(1),
+			timeToCrack:      math.Inf(1),
+			expectedKeyspace: "+Inf",
+			expectedTime:     "+Inf",
+		},
+		{
+			desc:             "Sextillion-Millenniums",
+			keyspace:         sextillion,
+			timeToCrack:      float64(millennium),
+			expectedKeyspace: "1.00 sextillion",
+			expectedTime:     "1.00 millenniums",
+		},
+		{
+			desc:             "Quintillion-Century",
+			keyspace:         quintillion,
+			timeToCrack:      century,
+			expectedKeyspace: "1.00 quintillion",
+			expectedTime:     "1.00 centuries",
+		},
+		{
+			desc:             "Quadrillion-Decades",
+			keyspace:         quadrillion,
+			timeToCrack:      decade,
+			expectedKeyspace: "1.00 quadrillion",
+			expectedTime:     "1.00 decades",
+		},
+		{
+			desc:             "Trillion-Years",
+			keyspace:         trillion,
+			timeToCrack:      year,
+			expectedKeyspace: "1.00 trillion",
+			expectedTime:     "1.00 years",
+		},
+		{
+			desc:             "Billion-Months",
+			keyspace:         billion,
+			timeToCrack:      month,
+			expectedKeyspace: "1.00 billion",
+			expectedTime:     "1.00 months",
+		},
+		{
+			desc:             "Million-Days",
+			keyspace:         million,
+			timeToCrack:      day,
+			expectedKeyspace: "1.00 million",
+			expectedTime:     "1.00 days",
+		},
+		{
+			desc:             "Thousand-Hours",
+			keyspace:         thousand,
+			timeToCrack:      hour,
+			expectedKeyspace: "1.00 thousand",
+			expectedTime:     "1.00 hours",
+		},
+		{
+			desc:             "Minutes",
+			keyspace:         15,
+			timeToCrack:      minute,
+			expectedKeyspace: "15.00",
+			expectedTime:     "1.00 minutes",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			gotKeyspace, gotTime := FormatSecretSecurity(tc.keyspace, tc.timeToCrack)
+
+			if gotKeyspace != tc.expectedKeyspace {
+				t.Errorf("Expected %q, got %q", tc.expectedKeyspace, gotKeyspace)
+			}
+
+			if gotTime != tc.expectedTime {
+				t.Errorf("Expected %q, got %q", tc.expectedTime, gotTime)
+			}
+		})
+	}
+}
+
+func TestBeautify(t *testing.T) {
+	cases := []struct {
+		actual   string
+		expected string
+	}{
+		{
+			actual:   "27738957312183663402227335168.00",
+			expected: "27,738,957,312,183,663,402,227,335,168.00",
+		},
+		{
+			actual:   "7456198.39",
+			expected: "7,456,198.39",
+		},
+		{
+			actual:   "124561.65",
+			expected: "124,561.65",
+		},
+		{
+			actual:   "379.78",
+			expected: "379.78",
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := prettify(tc.actual)
+			if got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
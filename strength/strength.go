@@ -0,0 +1,280 @@
+// Package strength estimates how hard a password is to guess.
+package strength
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/GGP1/atoll"
+)
+
+// Score thresholds, in bits of entropy.
+var thresholds = [...]float64{28, 36, 60, 80}
+
+var labels = [...]string{"very weak", "weak", "fair", "strong", "very strong"}
+
+// MaxScore is the highest score a password can get.
+const MaxScore = len(labels) - 1
+
+// Result contains the estimation of a password's strength.
+type Result struct {
+	// Patterns found in the password that make it easier to guess
+	Patterns []string
+	// Bits of entropy after applying the penalties of the patterns found
+	Bits float64
+	// Score goes from 0 (very weak) to 4 (very strong)
+	Score int
+}
+
+// Label returns the name of the score.
+func (r Result) Label() string {
+	return labels[r.Score]
+}
+
+// Keyspace returns the number of guesses required to find the password.
+func (r Result) Keyspace() float64 {
+	return atoll.Keyspace(r)
+}
+
+// SecondsToCrack returns the average time taken in seconds by a brute force attack to find the password.
+func (r Result) SecondsToCrack() float64 {
+	return atoll.SecondsToCrack(r) / 2
+}
+
+// Entropy returns the password entropy in bits.
+//
+// Along with Generate, it implements atoll.Secret so the keyspace and the time to crack
+// are calculated exactly as the ones of generated secrets.
+func (r Result) Entropy() float64 {
+	return r.Bits
+}
+
+// Generate returns an empty string as estimations can't generate secrets.
+func (r Result) Generate() (string, error) {
+	return "", nil
+}
+
+// Estimate returns the strength of the password.
+//
+// The entropy is calculated with the pool of the atoll levels used by the password
+// and then reduced for every pattern found: common passwords, keyboard walks,
+// sequences, repetitions and years.
+func Estimate(password string) Result {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return Result{}
+	}
+
+	charBits := charEntropy(runes)
+	patterns := make([]string, 0)
+	var bits float64
+
+	base, suffix := splitBase(runes)
+	if len(base) > 2 && isCommon(string(base)) {
+		patterns = append(patterns, "common password")
+		// Guessing the word from the list plus its capitalization and substitutions
+		bits += math.Log2(float64(len(commonPasswords))) + variationBits(runes[:len(base)])
+		runes = suffix
+	}
+
+	predictable, found := predictableChars(runes)
+	patterns = append(patterns, found...)
+
+	for i := 0; i < len(runes); i++ {
+		if isYear(runes, i) {
+			if !contains(patterns, "year") {
+				patterns = append(patterns, "year")
+			}
+			// 200 plausible years
+			bits += math.Log2(200)
+			i += 3
+			continue
+		}
+		if predictable[i] {
+			// The pattern only leaves the direction and length to be guessed
+			bits++
+			continue
+		}
+		bits += charBits
+	}
+
+	r := Result{Patterns: patterns, Bits: bits}
+	for _, t := range thresholds {
+		if bits >= t {
+			r.Score++
+		}
+	}
+	return r
+}
+
+// charEntropy returns the bits of entropy that each character adds depending on the atoll
+// levels used in the password.
+func charEntropy(runes []rune) float64 {
+	var (
+		levels []atoll.Level
+		extra  strings.Builder
+	)
+	used := make(map[atoll.Level]bool)
+	seen := make(map[rune]bool)
+
+	for _, r := range runes {
+		lvl, ok := levelOf(r)
+		if !ok {
+			if !seen[r] {
+				seen[r] = true
+				extra.WriteRune(r)
+			}
+			continue
+		}
+		if !used[lvl] {
+			used[lvl] = true
+			levels = append(levels, lvl)
+		}
+	}
+
+	p := &atoll.Password{Levels: levels, Include: extra.String(), Length: 1}
+	return p.Entropy()
+}
+
+func levelOf(r rune) (atoll.Level, bool) {
+	for _, lvl := range []atoll.Level{atoll.Lower, atoll.Upper, atoll.Digit, atoll.Space, atoll.Special} {
+		if strings.ContainsRune(string(lvl), r) {
+			return lvl, true
+		}
+	}
+	return "", false
+}
+
+// predictableChars returns which characters continue a repetition, sequence or keyboard walk
+// started by the two previous ones, and the names of the patterns found.
+func predictableChars(runes []rune) ([]bool, []string) {
+	predictable := make([]bool, len(runes))
+	var patterns []string
+
+	add := func(pattern string) {
+		if !contains(patterns, pattern) {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	for i := 2; i < len(runes); i++ {
+		a, b, c := unicode.ToLower(runes[i-2]), unicode.ToLower(runes[i-1]), unicode.ToLower(runes[i])
+		switch {
+		case a == b && b == c:
+			predictable[i] = true
+			add("repetition")
+
+		case b-a == c-b && (c-b == 1 || c-b == -1):
+			predictable[i] = true
+			add("sequence")
+
+		case keyboardStep(a, b) != 0 && keyboardStep(a, b) == keyboardStep(b, c):
+			predictable[i] = true
+			add("keyboard pattern")
+		}
+	}
+
+	return predictable, patterns
+}
+
+var keyboardRows = []string{"1234567890", "qwertyuiop", "asdfghjkl", "zxcvbnm"}
+
+// keyboardStep returns the distance between two keys that are next to each other
+// in the same row, or zero if they aren't.
+func keyboardStep(a, b rune) int {
+	for _, row := range keyboardRows {
+		i, j := strings.IndexRune(row, a), strings.IndexRune(row, b)
+		if i == -1 || j == -1 {
+			continue
+		}
+		if d := j - i; d == 1 || d == -1 {
+			return d
+		}
+	}
+	return 0
+}
+
+// splitBase separates the leading letters of the password (undoing common substitutions)
+// from the rest of it, so "P@ssw0rd123!" becomes "password" and "123!".
+func splitBase(runes []rune) (base, suffix []rune) {
+	i := 0
+	for ; i < len(runes); i++ {
+		r := unicode.ToLower(runes[i])
+		if s, ok := substitutions[r]; ok && i > 0 && !endOfBase(runes, i) {
+			r = s
+		}
+		if !unicode.IsLetter(r) {
+			break
+		}
+		base = append(base, r)
+	}
+
+	// Keep the longest common prefix in case the password appends letters to a common one
+	for j := len(base); j > 2; j-- {
+		if isCommon(string(base[:j])) {
+			return base[:j], runes[j:]
+		}
+	}
+	return base, runes[len(base):]
+}
+
+// endOfBase reports whether the rest of the password from i is made only of digits and symbols.
+func endOfBase(runes []rune, i int) bool {
+	for _, r := range runes[i:] {
+		if unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+var substitutions = map[rune]rune{
+	'@': 'a',
+	'4': 'a',
+	'3': 'e',
+	'1': 'i',
+	'!': 'i',
+	'0': 'o',
+	'$': 's',
+	'5': 's',
+	'7': 't',
+}
+
+// variationBits returns the entropy added by capitalization and substitutions.
+func variationBits(runes []rune) float64 {
+	var bits float64
+	for _, r := range runes {
+		if unicode.IsUpper(r) || !unicode.IsLetter(r) {
+			bits++
+		}
+	}
+	return bits
+}
+
+func isYear(runes []rune, i int) bool {
+	if i+4 > len(runes) {
+		return false
+	}
+	y := string(runes[i : i+4])
+	for _, r := range y {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return strings.HasPrefix(y, "19") || strings.HasPrefix(y, "20")
+}
+
+func isCommon(s string) bool {
+	_, ok := commonPasswords[s]
+	return ok
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
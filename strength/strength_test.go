@@ -0,0 +1,67 @@
+package strength
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestEstimate(t *testing.T) {
+	cases := []struct {
+		password string
+		patterns []string
+		score    int
+	}{
+		{password: "", patterns: nil, score: 0},
+		{password: "1", patterns: []string{}, score: 0},
+		{password: "123456", patterns: []string{"sequence"}, score: 0},
+		{password: "aaaaaaaaaaaa", patterns: []string{"repetition"}, score: 0},
+		{password: "poiuytre", patterns: []string{"keyboard pattern"}, score: 0},
+		{password: "password", patterns: []string{"common password"}, score: 0},
+		{password: "qwerty2019", patterns: []string{"common password", "year"}, score: 0},
+		{password: "P@ssw0rd123!", patterns: []string{"common password", "sequence"}, score: 1},
+		{password: "kX9$mP2v", patterns: []string{}, score: 2},
+		{password: "k8#Lp2@xQz!9", patterns: []string{}, score: 3},
+		{password: "correct horse battery staple", patterns: []string{}, score: 4},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.password, func(t *testing.T) {
+			got := Estimate(tc.password)
+
+			if got.Score != tc.score {
+				t.Errorf("Expected score %d, got %d (%.2f bits)", tc.score, got.Score, got.Bits)
+			}
+			if !reflect.DeepEqual(got.Patterns, tc.patterns) {
+				t.Errorf("Expected patterns %v, got %v", tc.patterns, got.Patterns)
+			}
+		})
+	}
+}
+
+func TestEstimatePenalties(t *testing.T) {
+	random := Estimate("x7Kq2mZp")
+	weak := Estimate("abcdefgh")
+
+	if weak.Bits >= random.Bits {
+		t.Errorf("Expected a sequence to have less entropy than a random password: %.2f >= %.2f", weak.Bits, random.Bits)
+	}
+}
+
+func TestResult(t *testing.T) {
+	r := Result{Bits: 40, Score: 2}
+
+	if got := r.Label(); got != "fair" {
+		t.Errorf("Expected %q, got %q", "fair", got)
+	}
+
+	if got := r.Keyspace(); got != math.Pow(2, 40) {
+		t.Errorf("Expected %f, got %f", math.Pow(2, 40), got)
+	}
+
+	// 1 trillion guesses per second on average (half the keyspace)
+	expected := math.Pow(2, 40) / 1000000000000 / 2
+	if got := r.SecondsToCrack(); got != expected {
+		t.Errorf("Expected %f, got %f", expected, got)
+	}
+}
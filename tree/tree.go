@@ -1,9 +1,10 @@
 // Package tree is used to build up a tree from a slice of paths and print
-// it to the standard output.
+// it to a writer.
 package tree
 
 import (
 	"fmt"
+	"io"
 	"strings"
 )
 
@@ -13,20 +14,20 @@ type Node struct {
 	Children []*Node
 }
 
-// Print prints the paths passed as a tree on the console.
-func Print(paths []string) {
+// Print writes the paths passed as a tree to w.
+func Print(w io.Writer, paths []string) {
 	root := Build(paths)
 
 	start := "│  "
 	for i, r := range root.Children {
 		if i == len(root.Children)-1 {
-			fmt.Println("└──", r.Name)
+			fmt.Fprintln(w, "└──", r.Name)
 			start = "   "
 		} else {
-			fmt.Println("├──", r.Name)
+			fmt.Fprintln(w, "├──", r.Name)
 		}
 
-		printChildren(r, "", start)
+		printChildren(w, r, "", start)
 	}
 }
 
@@ -104,19 +105,19 @@ func foundMatch(parent *Node, children []*Node) bool {
 
 // printChildren uses recursion for printing every folder children and adds
 // indentation every time it prints the last element of a branch.
-func printChildren(root *Node, indent, start string) {
+func printChildren(w io.Writer, root *Node, indent, start string) {
 	for i, r := range root.Children {
-		fmt.Print(start)
+		fmt.Fprint(w, start)
 
 		add := " │  "
 
 		if i == len(root.Children)-1 {
-			fmt.Println(indent, "└──", r.Name)
+			fmt.Fprintln(w, indent, "└──", r.Name)
 			add = "    "
 		} else {
-			fmt.Println(indent, "├──", r.Name)
+			fmt.Fprintln(w, indent, "├──", r.Name)
 		}
 
-		printChildren(r, indent+add, start)
+		printChildren(w, r, indent+add, start)
 	}
 }
@@ -1,6 +1,7 @@
 package tree_test
 
 import (
+	"bytes"
 	"strings"
 	"testing"
 
@@ -15,16 +16,21 @@ func TestTreePrint(t *testing.T) {
 		"unsafe/pointer",
 	}
 
-	tree.Print(paths)
-	// Output:
-	// ├── kure
-	// │   └── atoll
-	// │       ├── password
-	// │       └── passphrase
-	// ├── sync
-	// │   └── atomic
-	// └── unsafe
-	//     └── pointer
+	expected := `├── kure
+│   └── atoll
+│       ├── password
+│       └── passphrase
+├── sync
+│   └── atomic
+└── unsafe
+    └── pointer
+`
+
+	buf := new(bytes.Buffer)
+	tree.Print(buf, paths)
+	if got := buf.String(); got != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, got)
+	}
 }
 
 func TestTreeStructure(t *testing.T) {